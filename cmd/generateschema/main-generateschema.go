@@ -20,6 +20,8 @@ const WaveSchemaAiPresetsFileName = "schema/aipresets.json"
 const WaveSchemaWidgetsFileName = "schema/widgets.json"
 const WaveSchemaBgPresetsFileName = "schema/bgpresets.json"
 const WaveSchemaWaveAIFileName = "schema/waveai.json"
+const WaveSchemaAutomationsFileName = "schema/automations.json"
+const WaveSchemaTriggersFileName = "schema/triggers.json"
 
 func generateSchema(template any, dir string) error {
 	settingsSchema := jsonschema.Reflect(template)
@@ -73,4 +75,16 @@ func main() {
 	if err != nil {
 		log.Fatalf("waveai schema error: %v", err)
 	}
+
+	automationsTemplate := make(map[string]wconfig.AutomationRule)
+	err = generateSchema(&automationsTemplate, WaveSchemaAutomationsFileName)
+	if err != nil {
+		log.Fatalf("automations schema error: %v", err)
+	}
+
+	triggersTemplate := make(map[string]wconfig.OutputTrigger)
+	err = generateSchema(&triggersTemplate, WaveSchemaTriggersFileName)
+	if err != nil {
+		log.Fatalf("triggers schema error: %v", err)
+	}
 }
@@ -15,19 +15,26 @@ import (
 	"time"
 
 	"github.com/SalyyS1/SLTerm/pkg/authkey"
+	"github.com/SalyyS1/SLTerm/pkg/automation"
 	"github.com/SalyyS1/SLTerm/pkg/blockcontroller"
 	"github.com/SalyyS1/SLTerm/pkg/blocklogger"
+	"github.com/SalyyS1/SLTerm/pkg/cmdhistory"
+	"github.com/SalyyS1/SLTerm/pkg/eventlog"
 	"github.com/SalyyS1/SLTerm/pkg/filebackup"
 	"github.com/SalyyS1/SLTerm/pkg/filestore"
+	"github.com/SalyyS1/SLTerm/pkg/frecency"
 	"github.com/SalyyS1/SLTerm/pkg/jobcontroller"
 	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/plugin"
 	"github.com/SalyyS1/SLTerm/pkg/petengine"
 	"github.com/SalyyS1/SLTerm/pkg/remote/conncontroller"
 	"github.com/SalyyS1/SLTerm/pkg/remote/fileshare/wshfs"
 	"github.com/SalyyS1/SLTerm/pkg/secretstore"
 	"github.com/SalyyS1/SLTerm/pkg/service"
+	"github.com/SalyyS1/SLTerm/pkg/snapshot"
 	"github.com/SalyyS1/SLTerm/pkg/telemetry"
 	"github.com/SalyyS1/SLTerm/pkg/telemetry/telemetrydata"
+	"github.com/SalyyS1/SLTerm/pkg/termlog"
 	"github.com/SalyyS1/SLTerm/pkg/util/envutil"
 	"github.com/SalyyS1/SLTerm/pkg/util/shellutil"
 	"github.com/SalyyS1/SLTerm/pkg/util/sigutil"
@@ -38,6 +45,7 @@ import (
 	"github.com/SalyyS1/SLTerm/pkg/wconfig"
 	"github.com/SalyyS1/SLTerm/pkg/wcore"
 	"github.com/SalyyS1/SLTerm/pkg/web"
+	"github.com/SalyyS1/SLTerm/pkg/web/share"
 	"github.com/SalyyS1/SLTerm/pkg/wps"
 	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
 	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
@@ -83,6 +91,7 @@ func doShutdown(reason string) {
 		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancelFn()
 		go blockcontroller.StopAllBlockControllersForShutdown()
+		plugin.StopAllPlugins()
 		petengine.Shutdown()
 		shutdownActivityUpdate()
 		sendTelemetryWrapper()
@@ -456,6 +465,20 @@ func maybeStartPprofServer() {
 	}()
 }
 
+// the gateway is opt-in, changes to gateway:* take effect on restart
+func maybeStartGatewayServer() {
+	settings := wconfig.GetWatcher().GetFullConfig().Settings
+	if !settings.GatewayEnabled {
+		return
+	}
+	gatewayListener, err := web.MakeGatewayListener(settings.GatewayListen)
+	if err != nil {
+		log.Printf("error creating gateway listener: %v\n", err)
+		return
+	}
+	go web.RunGatewayServer(gatewayListener)
+}
+
 func main() {
 	log.SetFlags(0) // disable timestamp since electron's winston logger already wraps with timestamp
 	log.SetPrefix("[wavesrv] ")
@@ -581,6 +604,13 @@ func main() {
 	jobcontroller.InitJobController()
 	blockcontroller.InitBlockController()
 	wcore.InitTabIndicatorStore()
+	eventlog.InitEventLog()
+	automation.InitAutomations()
+	snapshot.InitSnapshots()
+	termlog.InitTermLog()
+	share.InitShares()
+	cmdhistory.InitCmdHistory()
+	frecency.InitFrecency()
 	petengine.Init()
 	log.Printf("pet engine initialized")
 	go func() {
@@ -614,6 +644,8 @@ func main() {
 		fmt.Fprintf(os.Stderr, "WAVESRV-ESTART ws:%s web:%s version:%s buildtime:%s\n", wsListener.Addr(), webListener.Addr(), WaveVersion, BuildTime)
 	}()
 	go wshutil.RunWshRpcOverListener(unixListener, nil)
+	plugin.InitPlugins() // plugins connect over the domain socket
+	maybeStartGatewayServer()
 	web.RunWebServer(webListener) // blocking
 	runtime.KeepAlive(waveLock)
 }
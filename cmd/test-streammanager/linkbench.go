@@ -0,0 +1,262 @@
+// Copyright 2026, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
+)
+
+// linkbench mode: a root router and a connserver-style router joined by a bandwidth limited stream link.
+// a leaf on the remote router streams a file (RemoteStreamFileCommand) while a leaf on the root router
+// sends ControllerInputCommand every InputInterval and records the round trip.  it runs with compression
+// and priority lanes off, with priority lanes only, and with both.
+
+const linkBenchChunkSize = 64 * 1024
+const linkBenchRemoteRoute = "bench:remote"
+const linkBenchClientRoute = "bench:client"
+
+type linkBenchOpts struct {
+	Name     string
+	Priority bool
+	Compress bool
+}
+
+type linkBenchResult struct {
+	Opts         linkBenchOpts
+	Latencies    []time.Duration
+	BulkBytes    int64
+	BulkDuration time.Duration
+	LinkStats    wshutil.LinkStreamStats // remote -> server direction
+}
+
+// throttledConn limits the write side to bytesPerSec (like a slow ssh link)
+type throttledConn struct {
+	net.Conn
+	bytesPerSec int64
+	lock        sync.Mutex
+	next        time.Time
+}
+
+func (tc *throttledConn) Write(p []byte) (int, error) {
+	tc.lock.Lock()
+	now := time.Now()
+	if tc.next.Before(now) {
+		tc.next = now
+	}
+	tc.next = tc.next.Add(time.Duration(int64(len(p)) * int64(time.Second) / tc.bytesPerSec))
+	wait := time.Until(tc.next)
+	tc.lock.Unlock()
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+	return tc.Conn.Write(p)
+}
+
+type benchRemoteImpl struct {
+	dataSize int64
+}
+
+func (*benchRemoteImpl) WshServerImpl() {}
+
+func (impl *benchRemoteImpl) ControllerInputCommand(ctx context.Context, data wshrpc.CommandBlockInputData) error {
+	return nil
+}
+
+// streams dataSize bytes of log-like text (compressible, like most files people cat or copy)
+func (impl *benchRemoteImpl) RemoteStreamFileCommand(ctx context.Context, data wshrpc.CommandRemoteStreamFileData) chan wshrpc.RespOrErrorUnion[wshrpc.FileData] {
+	ch := make(chan wshrpc.RespOrErrorUnion[wshrpc.FileData], 16)
+	go func() {
+		defer close(ch)
+		var sent int64
+		lineNum := 0
+		for sent < impl.dataSize {
+			var sb strings.Builder
+			for sb.Len() < linkBenchChunkSize {
+				lineNum++
+				fmt.Fprintf(&sb, "2026-01-01T00:00:%02d.%06dZ INFO worker-%d processed request id=%08x status=200 bytes=%d\n", lineNum%60, lineNum, lineNum%8, lineNum*7919, lineNum%4096)
+			}
+			chunk := sb.String()[:linkBenchChunkSize]
+			sent += int64(len(chunk))
+			select {
+			case ch <- wshrpc.RespOrErrorUnion[wshrpc.FileData]{Response: wshrpc.FileData{Data64: base64.StdEncoding.EncodeToString([]byte(chunk))}}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+type benchClientImpl struct{}
+
+func (*benchClientImpl) WshServerImpl() {}
+
+type benchLink struct {
+	RootRouter   *wshutil.WshRouter
+	RemoteRouter *wshutil.WshRouter
+	RemoteStream *wshutil.LinkStream
+	Close        func()
+}
+
+func makeBenchLink(opts linkBenchOpts, bytesPerSec int64) *benchLink {
+	serverConn, remoteConn := net.Pipe()
+
+	rootRouter := wshutil.NewWshRouter()
+	rootRouter.SetAsRootRouter()
+	serverProxy := wshutil.MakeRpcProxy("bench-server")
+	if opts.Priority {
+		serverProxy.EnablePriorityLane()
+	}
+	serverStream := wshutil.MakeLinkStream(&throttledConn{Conn: serverConn, bytesPerSec: bytesPerSec}, false, !opts.Compress)
+	go serverStream.WriteLoop(serverProxy.ToRemoteCh, serverProxy.ToRemoteHighCh)
+	go serverStream.ReadLoop(serverProxy.FromRemoteCh, nil)
+	rootRouter.RegisterTrustedRouter(serverProxy)
+
+	remoteRouter := wshutil.NewWshRouter()
+	upstreamProxy := wshutil.MakeRpcProxy("bench-upstream")
+	if opts.Priority {
+		upstreamProxy.EnablePriorityLane()
+	}
+	upstreamStream := wshutil.MakeLinkStream(&throttledConn{Conn: remoteConn, bytesPerSec: bytesPerSec}, true, !opts.Compress)
+	go upstreamStream.WriteLoop(upstreamProxy.ToRemoteCh, upstreamProxy.ToRemoteHighCh)
+	go upstreamStream.ReadLoop(upstreamProxy.FromRemoteCh, nil)
+	remoteRouter.RegisterUpstream(upstreamProxy)
+
+	return &benchLink{
+		RootRouter:   rootRouter,
+		RemoteRouter: remoteRouter,
+		RemoteStream: upstreamStream,
+		Close: func() {
+			serverConn.Close()
+			remoteConn.Close()
+		},
+	}
+}
+
+func runLinkBenchOnce(config TestConfig, opts linkBenchOpts) (*linkBenchResult, error) {
+	link := makeBenchLink(opts, config.Bandwidth)
+	defer link.Close()
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancelFn()
+
+	remoteRpc := wshutil.MakeWshRpc(wshrpc.RpcContext{RouteId: linkBenchRemoteRoute}, &benchRemoteImpl{dataSize: config.DataSize}, "bench-remote")
+	if _, err := link.RemoteRouter.RegisterTrustedLeaf(remoteRpc, linkBenchRemoteRoute); err != nil {
+		return nil, fmt.Errorf("registering remote leaf: %w", err)
+	}
+	clientRpc := wshutil.MakeWshRpc(wshrpc.RpcContext{RouteId: linkBenchClientRoute}, &benchClientImpl{}, "bench-client")
+	if _, err := link.RootRouter.RegisterTrustedLeaf(clientRpc, linkBenchClientRoute); err != nil {
+		return nil, fmt.Errorf("registering client leaf: %w", err)
+	}
+	if err := link.RootRouter.WaitForRegister(ctx, linkBenchRemoteRoute); err != nil {
+		return nil, fmt.Errorf("waiting for remote route: %w", err)
+	}
+
+	result := &linkBenchResult{Opts: opts}
+	bulkDone := make(chan error, 1)
+	startTs := time.Now()
+	go func() {
+		respCh := wshclient.RemoteStreamFileCommand(clientRpc, wshrpc.CommandRemoteStreamFileData{Path: "bench.log"}, &wshrpc.RpcOpts{Route: linkBenchRemoteRoute, Timeout: 5 * 60 * 1000})
+		for resp := range respCh {
+			if resp.Error != nil {
+				bulkDone <- resp.Error
+				return
+			}
+			result.BulkBytes += int64(base64.StdEncoding.DecodedLen(len(resp.Response.Data64)))
+		}
+		bulkDone <- nil
+	}()
+
+	ticker := time.NewTicker(config.InputInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-bulkDone:
+			if err != nil {
+				return nil, fmt.Errorf("bulk transfer: %w", err)
+			}
+			result.BulkDuration = time.Since(startTs)
+			result.LinkStats = link.RemoteStream.GetStats()
+			return result, nil
+		case <-ticker.C:
+			inputTs := time.Now()
+			err := wshclient.ControllerInputCommand(clientRpc, wshrpc.CommandBlockInputData{BlockId: "bench", InputData64: "YQ=="}, &wshrpc.RpcOpts{Route: linkBenchRemoteRoute, Timeout: 60 * 1000})
+			if err != nil {
+				return nil, fmt.Errorf("controller input: %w", err)
+			}
+			result.Latencies = append(result.Latencies, time.Since(inputTs))
+		}
+	}
+}
+
+func percentile(sorted []time.Duration, pct float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted)-1) * pct)
+	return sorted[idx]
+}
+
+func (r *linkBenchResult) Report() string {
+	latencies := sortedLatencies(r)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  %s (priority=%v compress=%v)\n", r.Opts.Name, r.Opts.Priority, r.Opts.Compress)
+	fmt.Fprintf(&sb, "    input rtt: n=%d p50=%v p95=%v max=%v\n", len(latencies),
+		percentile(latencies, 0.5).Round(time.Millisecond), percentile(latencies, 0.95).Round(time.Millisecond), percentile(latencies, 1).Round(time.Millisecond))
+	throughput := float64(r.BulkBytes) / r.BulkDuration.Seconds() / (1024 * 1024)
+	fmt.Fprintf(&sb, "    bulk: %d bytes in %v (%.2f MB/s)\n", r.BulkBytes, r.BulkDuration.Round(time.Millisecond), throughput)
+	ratio := 1.0
+	if r.LinkStats.RawBytes > 0 {
+		ratio = float64(r.LinkStats.WireBytes) / float64(r.LinkStats.RawBytes)
+	}
+	fmt.Fprintf(&sb, "    link (remote->server): msgs=%d compressed=%d raw=%d wire=%d (%.0f%%)\n",
+		r.LinkStats.MsgsWritten, r.LinkStats.CompressedMsgs, r.LinkStats.RawBytes, r.LinkStats.WireBytes, ratio*100)
+	return sb.String()
+}
+
+func runLinkBench(config TestConfig) error {
+	fmt.Printf("Starting wsh link benchmark\n")
+	fmt.Printf("  Data Size: %d bytes\n", config.DataSize)
+	fmt.Printf("  Bandwidth: %d bytes/sec\n", config.Bandwidth)
+	fmt.Printf("  Input Interval: %v\n", config.InputInterval)
+	runs := []linkBenchOpts{
+		{Name: "baseline"},
+		{Name: "priority", Priority: true},
+		{Name: "priority+compress", Priority: true, Compress: true},
+	}
+	var results []*linkBenchResult
+	for _, opts := range runs {
+		result, err := runLinkBenchOnce(config, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", opts.Name, err)
+		}
+		results = append(results, result)
+	}
+	fmt.Println("Results:")
+	for _, result := range results {
+		fmt.Print(result.Report())
+	}
+	baseP95 := percentile(sortedLatencies(results[0]), 0.95)
+	newP95 := percentile(sortedLatencies(results[len(results)-1]), 0.95)
+	if newP95 > 0 {
+		fmt.Printf("input p95 improvement: %.1fx\n", float64(baseP95)/float64(newP95))
+	}
+	return nil
+}
+
+func sortedLatencies(r *linkBenchResult) []time.Duration {
+	latencies := append([]time.Duration(nil), r.Latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies
+}
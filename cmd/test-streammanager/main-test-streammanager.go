@@ -24,6 +24,10 @@ type TestConfig struct {
 	WindowSize int
 	SlowReader int
 	Verbose    bool
+
+	// linkbench mode
+	Bandwidth     int64
+	InputInterval time.Duration
 }
 
 var config TestConfig
@@ -37,13 +41,15 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.Flags().StringVar(&config.Mode, "mode", "streammanager", "Writer mode: 'streammanager' or 'writer'")
+	rootCmd.Flags().StringVar(&config.Mode, "mode", "streammanager", "Writer mode: 'streammanager' or 'writer', or 'linkbench' to benchmark input latency on a loaded wsh link")
 	rootCmd.Flags().Int64Var(&config.DataSize, "size", 10*1024*1024, "Total data to transfer (bytes)")
 	rootCmd.Flags().DurationVar(&config.Delay, "delay", 0, "Base delivery delay (e.g., 10ms)")
 	rootCmd.Flags().DurationVar(&config.Skew, "skew", 0, "Delivery skew +/- (e.g., 5ms)")
 	rootCmd.Flags().IntVar(&config.WindowSize, "windowsize", 64*1024, "Window size for both sender and receiver")
 	rootCmd.Flags().IntVar(&config.SlowReader, "slowreader", 0, "Slow reader mode: bytes per second (0=disabled, e.g., 1024)")
 	rootCmd.Flags().BoolVar(&config.Verbose, "verbose", false, "Enable verbose logging")
+	rootCmd.Flags().Int64Var(&config.Bandwidth, "bandwidth", 4*1024*1024, "linkbench: link bandwidth in bytes per second")
+	rootCmd.Flags().DurationVar(&config.InputInterval, "inputinterval", 50*time.Millisecond, "linkbench: interval between input rpcs")
 }
 
 func main() {
@@ -53,6 +59,9 @@ func main() {
 }
 
 func runTest(config TestConfig) error {
+	if config.Mode == "linkbench" {
+		return runLinkBench(config)
+	}
 	if config.Mode != "streammanager" && config.Mode != "writer" {
 		return fmt.Errorf("invalid mode: %s (must be 'streammanager' or 'writer')", config.Mode)
 	}
@@ -0,0 +1,69 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnescapeExpectInput(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"ls -l", "ls -l", false},
+		{`a\tb\r\n`, "a\tb\r\n", false},
+		{`\x04`, "\x04", false},
+		{`\e[A`, "\x1b[A", false},
+		{`c:\\dir`, `c:\dir`, false},
+		{`\d+`, `\d+`, false},
+		{`trailing\`, `trailing\`, false},
+		{`\x4`, "", true},
+		{`\xzz`, "", true},
+	}
+	for _, tt := range tests {
+		got, err := unescapeExpectInput(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("unescapeExpectInput(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("unescapeExpectInput(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseExpectScript(t *testing.T) {
+	script := "# start python\nsend python3\r\nexpect >>> $\n\n  sendraw \\x04\ntimeout 5s\nsleep 100ms\n"
+	steps, err := parseExpectScript(script)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(steps) != 5 {
+		t.Fatalf("got %d steps, want 5", len(steps))
+	}
+	if steps[0].Kind != expectStep_Send || steps[0].Text != "python3\r" {
+		t.Errorf("step 0 = %+v", steps[0])
+	}
+	if steps[1].Kind != expectStep_Expect || steps[1].Re == nil || !steps[1].Re.MatchString(">>> ") {
+		t.Errorf("step 1 = %+v", steps[1])
+	}
+	if steps[2].Kind != expectStep_SendRaw || steps[2].Text != "\x04" {
+		t.Errorf("step 2 = %+v", steps[2])
+	}
+	if steps[3].Kind != expectStep_Timeout || steps[3].Duration != 5*time.Second {
+		t.Errorf("step 3 = %+v", steps[3])
+	}
+	if steps[4].Kind != expectStep_Sleep || steps[4].Duration != 100*time.Millisecond {
+		t.Errorf("step 4 = %+v", steps[4])
+	}
+
+	for _, bad := range []string{"wait foo", "expect (", "sleep soon"} {
+		if _, err := parseExpectScript(bad); err == nil {
+			t.Errorf("parseExpectScript(%q) expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+)
+
+func TestMatchHistory(t *testing.T) {
+	// newest first, as returned by HistoryQueryCommand
+	entries := []wshrpc.HistoryEntry{
+		{Cmd: "git status"},
+		{Cmd: "go test ./..."},
+		{Cmd: "git stash"},
+		{Cmd: "make"},
+	}
+	var got []string
+	for _, match := range matchHistory(entries, "") {
+		got = append(got, match.Entry.Cmd)
+	}
+	if len(got) != 4 || got[0] != "git status" || got[3] != "make" {
+		t.Errorf("empty query should keep the recency order, got %v", got)
+	}
+	matches := matchHistory(entries, "git sta")
+	if len(matches) != 2 || matches[0].Entry.Cmd != "git status" || matches[1].Entry.Cmd != "git stash" {
+		t.Errorf("equal scores should keep the recency order, got %v", matches)
+	}
+	if matches := matchHistory(entries, "gt"); len(matches) == 0 || matches[0].Entry.Cmd != "go test ./..." {
+		t.Errorf("word boundary match should win, got %v", matches)
+	}
+}
+
+func TestEscSeqLen(t *testing.T) {
+	tests := []struct {
+		data string
+		want int
+	}{
+		{"\x1b", 1},
+		{"\x1b[A", 3},
+		{"\x1bOBx", 3},
+		{"\x1b[5~abc", 4},
+		{"\x1bx", 1},
+		{"\x1b[1;5", 5},
+	}
+	for _, tt := range tests {
+		if got := escSeqLen([]byte(tt.data)); got != tt.want {
+			t.Errorf("escSeqLen(%q) = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestMakeHistoryInput(t *testing.T) {
+	if got := makeHistoryInput("ls -l"); got != "ls -l" {
+		t.Errorf("single line: got %q", got)
+	}
+	if got := makeHistoryInput("for i in 1 2\ndo echo $i\ndone"); got != "\x1b[200~for i in 1 2\ndo echo $i\ndone\x1b[201~" {
+		t.Errorf("multi line: got %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetConfigWorkspaceFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantWorkspace string
+		wantArgs      []string
+		wantErr       bool
+	}{
+		{"global", []string{"term:fontsize=12"}, "", []string{"term:fontsize=12"}, false},
+		{"workspace id", []string{"--workspace", "ws1", "term:fontsize=12"}, "ws1", []string{"term:fontsize=12"}, false},
+		{"workspace id with =", []string{"--workspace=ws1", "term:fontsize=12"}, "ws1", []string{"term:fontsize=12"}, false},
+		{"current workspace", []string{"--current-workspace", "term:fontsize=12"}, "workspace", []string{"term:fontsize=12"}, false},
+		{"both", []string{"--workspace", "ws1", "--current-workspace", "term:fontsize=12"}, "", nil, true},
+	}
+	for _, tc := range tests {
+		setConfigWorkspace = ""
+		setConfigCurrentWorkspace = false
+		flags := setConfigCmd.Flags()
+		flags.Lookup("workspace").Changed = false
+		flags.Lookup("current-workspace").Changed = false
+		err := flags.Parse(tc.args)
+		if err == nil {
+			err = setConfigCmd.ValidateFlagGroups()
+		}
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := getSetConfigWorkspaceArg(); got != tc.wantWorkspace {
+			t.Errorf("%s: workspace = %q, want %q", tc.name, got, tc.wantWorkspace)
+		}
+		if got := flags.Args(); !reflect.DeepEqual(got, tc.wantArgs) {
+			t.Errorf("%s: args = %v, want %v", tc.name, got, tc.wantArgs)
+		}
+	}
+}
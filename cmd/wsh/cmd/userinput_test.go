@@ -0,0 +1,41 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAppendPromptInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		hidden   bool
+		want     string
+		wantDone bool
+		wantErr  error
+	}{
+		{"line", []string{"yes\n"}, false, "yes", true, nil},
+		{"split chunks", []string{"ye", "s\r", "next line\n"}, false, "yes", true, nil},
+		{"partial", []string{"pass"}, true, "pass", false, nil},
+		{"backspace", []string{"pa\x7fx", "é\x08s\r"}, true, "pxs", true, nil},
+		{"backspace past start", []string{"\x7f\x7fok\r"}, true, "ok", true, nil},
+		{"ctrl-c", []string{"sec", "\x03ret\r"}, true, "", true, errPromptCancelled},
+	}
+	for _, tc := range tests {
+		var line []byte
+		var done bool
+		var err error
+		for _, chunk := range tc.chunks {
+			line, done, err = appendPromptInput(line, []byte(chunk), tc.hidden)
+			if done {
+				break
+			}
+		}
+		if string(line) != tc.want || done != tc.wantDone || !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: got (%q, %v, %v), want (%q, %v, %v)", tc.name, line, done, err, tc.want, tc.wantDone, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+var automationListJson bool
+var automationTestEvent string
+var automationTestExec bool
+
+var automationCmd = &cobra.Command{
+	Use:   "automation",
+	Short: "manage automation rules (automations.json)",
+}
+
+var automationListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "list automation rules and their status",
+	Args:    cobra.NoArgs,
+	RunE:    automationListRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var automationTestCmd = &cobra.Command{
+	Use:   "test [name]",
+	Short: "check a rule against an event without running it",
+	Long: `Evaluate an automation rule against an event and show which conditions matched and which actions would run.
+Without --event the rule is tested against the last event seen for its trigger.  Actions only run with --run, which is refused for remote and restricted links.`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    automationTestRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var automationEnableCmd = &cobra.Command{
+	Use:     "enable [name]",
+	Short:   "enable an automation rule",
+	Args:    cobra.ExactArgs(1),
+	RunE:    automationSetEnabledRun(true),
+	PreRunE: preRunSetupRpcClient,
+}
+
+var automationDisableCmd = &cobra.Command{
+	Use:     "disable [name]",
+	Short:   "disable an automation rule",
+	Args:    cobra.ExactArgs(1),
+	RunE:    automationSetEnabledRun(false),
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	automationListCmd.Flags().BoolVar(&automationListJson, "json", false, "output as json")
+	automationTestCmd.Flags().StringVarP(&automationTestEvent, "event", "e", "", "event to test against (json, e.g. '{\"event\":\"connchange\",\"data\":{...}}')")
+	automationTestCmd.Flags().BoolVar(&automationTestExec, "run", false, "actually run the actions if the rule matches")
+	rootCmd.AddCommand(automationCmd)
+	automationCmd.AddCommand(automationListCmd)
+	automationCmd.AddCommand(automationTestCmd)
+	automationCmd.AddCommand(automationEnableCmd)
+	automationCmd.AddCommand(automationDisableCmd)
+}
+
+func automationStatus(info wshrpc.AutomationRuleInfo) string {
+	switch {
+	case info.ConfigError != "":
+		return "invalid"
+	case info.Disabled:
+		return "disabled"
+	case info.DryRun:
+		return "dryrun"
+	}
+	return "enabled"
+}
+
+func automationListRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("automation", rtnErr == nil)
+	}()
+
+	rules, err := wshclient.AutomationListCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("listing automations: %w", err)
+	}
+	if automationListJson {
+		barr, err := json.MarshalIndent(rules, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding automations: %w", err)
+		}
+		WriteStdout("%s\n", barr)
+		return nil
+	}
+	if len(rules) == 0 {
+		WriteStdout("no automations configured (add rules to automations.json)\n")
+		return nil
+	}
+	WriteStdout("%-24s %-20s %-9s %-6s %s\n", "NAME", "EVENT", "STATUS", "FIRED", "LAST FIRED")
+	for _, info := range rules {
+		lastFired := "-"
+		if info.LastFireTs > 0 {
+			lastFired = time.UnixMilli(info.LastFireTs).Format(time.DateTime)
+		}
+		WriteStdout("%-24s %-20s %-9s %-6d %s\n", info.Name, info.Event, automationStatus(info), info.FireCount, lastFired)
+		if info.ConfigError != "" {
+			WriteStdout("  error: %s\n", info.ConfigError)
+		} else if info.LastError != "" {
+			WriteStdout("  last error: %s\n", info.LastError)
+		}
+	}
+	return nil
+}
+
+func automationTestRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("automation", rtnErr == nil)
+	}()
+
+	testData := wshrpc.CommandAutomationTestData{Name: args[0], Run: automationTestExec}
+	if automationTestEvent != "" {
+		var event wps.WaveEvent
+		if err := json.Unmarshal([]byte(automationTestEvent), &event); err != nil {
+			return fmt.Errorf("parsing --event: %w", err)
+		}
+		testData.Event = &event
+	}
+	result, err := wshclient.AutomationTestCommand(RpcClient, testData, &wshrpc.RpcOpts{Timeout: 15000})
+	if err != nil {
+		return fmt.Errorf("testing automation: %w", err)
+	}
+	if result.Event != nil {
+		barr, _ := json.Marshal(result.Event)
+		WriteStdout("event: %s\n", barr)
+	}
+	for _, cond := range result.Conditions {
+		mark := "no "
+		if cond.Matched {
+			mark = "yes"
+		}
+		if cond.Error != "" {
+			WriteStdout("  [err] %s: %s\n", cond.Condition, cond.Error)
+			continue
+		}
+		WriteStdout("  [%s] %s\n", mark, cond.Condition)
+	}
+	if !result.Matched {
+		WriteStdout("rule %q does not match\n", result.Name)
+		return nil
+	}
+	WriteStdout("rule %q matches\n", result.Name)
+	for _, action := range result.Actions {
+		switch {
+		case action.Error != "":
+			WriteStdout("  failed: %s (%s)\n", action.Description, action.Error)
+		case action.Ran:
+			WriteStdout("  ran: %s\n", action.Description)
+		default:
+			WriteStdout("  would %s\n", action.Description)
+		}
+	}
+	return nil
+}
+
+func automationSetEnabledRun(enabled bool) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) (rtnErr error) {
+		defer func() {
+			sendActivity("automation", rtnErr == nil)
+		}()
+
+		data := wshrpc.CommandAutomationSetEnabledData{Name: args[0], Enabled: enabled}
+		err := wshclient.AutomationSetEnabledCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 2000})
+		if err != nil {
+			return fmt.Errorf("updating automation: %w", err)
+		}
+		if enabled {
+			WriteStdout("automation %q enabled\n", args[0])
+		} else {
+			WriteStdout("automation %q disabled\n", args[0])
+		}
+		return nil
+	}
+}
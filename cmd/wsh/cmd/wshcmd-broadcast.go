@@ -0,0 +1,212 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+var broadcastGroup string
+var broadcastNoNewline bool
+var inputGroupListJson bool
+
+var broadcastCmd = &cobra.Command{
+	Use:   "broadcast [flags] TEXT...",
+	Short: "send a line of input to every terminal in an input group",
+	Long: `Send TEXT (followed by enter, unless -n is given) to every running, enabled terminal in an
+input group.  The group defaults to the group of the current block (or the block given with -b).
+
+Blocks join a group with "wsh inputgroup join GROUP".  Keystrokes typed into any member are
+also sent to the other members.`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    broadcastRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var inputGroupCmd = &cobra.Command{
+	Use:   "inputgroup",
+	Short: "manage terminal input groups",
+	Long: `Terminals in the same input group share input: keystrokes typed into one member are also sent
+to every other member.  Only running shells take part (cmd blocks and other views are skipped).
+A disabled member stays in the group but neither sends nor receives group input.
+
+join, leave, enable and disable act on the current block (or the block given with -b).`,
+}
+
+var inputGroupListCmd = &cobra.Command{
+	Use:     "list [GROUP]",
+	Short:   "list input group members",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    inputGroupListRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var inputGroupJoinCmd = &cobra.Command{
+	Use:     "join GROUP",
+	Short:   "add the block to an input group",
+	Args:    cobra.ExactArgs(1),
+	RunE:    inputGroupSetRun(wshrpc.InputGroupAction_Join),
+	PreRunE: preRunSetupRpcClient,
+}
+
+var inputGroupLeaveCmd = &cobra.Command{
+	Use:     "leave",
+	Short:   "remove the block from its input group",
+	Args:    cobra.NoArgs,
+	RunE:    inputGroupSetRun(wshrpc.InputGroupAction_Leave),
+	PreRunE: preRunSetupRpcClient,
+}
+
+var inputGroupEnableCmd = &cobra.Command{
+	Use:     "enable",
+	Short:   "resume sending and receiving group input",
+	Args:    cobra.NoArgs,
+	RunE:    inputGroupSetRun(wshrpc.InputGroupAction_Enable),
+	PreRunE: preRunSetupRpcClient,
+}
+
+var inputGroupDisableCmd = &cobra.Command{
+	Use:     "disable",
+	Short:   "pause group input for the block without leaving the group",
+	Args:    cobra.NoArgs,
+	RunE:    inputGroupSetRun(wshrpc.InputGroupAction_Disable),
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	broadcastCmd.Flags().StringVarP(&broadcastGroup, "group", "g", "", "input group (defaults to the block's group)")
+	broadcastCmd.Flags().BoolVarP(&broadcastNoNewline, "no-newline", "n", false, "do not send enter after TEXT")
+	inputGroupListCmd.Flags().BoolVar(&inputGroupListJson, "json", false, "output as json")
+	rootCmd.AddCommand(broadcastCmd)
+	rootCmd.AddCommand(inputGroupCmd)
+	inputGroupCmd.AddCommand(inputGroupListCmd)
+	inputGroupCmd.AddCommand(inputGroupJoinCmd)
+	inputGroupCmd.AddCommand(inputGroupLeaveCmd)
+	inputGroupCmd.AddCommand(inputGroupEnableCmd)
+	inputGroupCmd.AddCommand(inputGroupDisableCmd)
+}
+
+func getBlockInputGroup() (string, error) {
+	fullORef, err := resolveBlockArg()
+	if err != nil {
+		return "", err
+	}
+	meta, err := wshclient.GetMetaCommand(RpcClient, wshrpc.CommandGetMetaData{ORef: *fullORef}, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return "", fmt.Errorf("getting block metadata: %w", err)
+	}
+	return meta.GetString(waveobj.MetaKey_TermInputGroup, ""), nil
+}
+
+func broadcastRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("broadcast", rtnErr == nil)
+	}()
+
+	group := broadcastGroup
+	if group == "" {
+		var err error
+		group, err = getBlockInputGroup()
+		if err != nil {
+			return err
+		}
+		if group == "" {
+			return fmt.Errorf("block is not in an input group (use --group)")
+		}
+	}
+	text := strings.Join(args, " ")
+	if !broadcastNoNewline {
+		text += "\r"
+	}
+	data := wshrpc.CommandInputGroupBroadcastData{
+		Group:       group,
+		InputData64: base64.StdEncoding.EncodeToString([]byte(text)),
+	}
+	numSent, err := wshclient.InputGroupBroadcastCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("broadcasting input: %w", err)
+	}
+	if numSent == 0 {
+		return fmt.Errorf("no running terminals in input group %q", group)
+	}
+	return nil
+}
+
+func inputGroupListRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("inputgroup", rtnErr == nil)
+	}()
+
+	data := wshrpc.CommandInputGroupListData{}
+	if len(args) > 0 {
+		data.Group = args[0]
+	}
+	members, err := wshclient.InputGroupListCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("listing input groups: %w", err)
+	}
+	if inputGroupListJson {
+		barr, err := json.MarshalIndent(members, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding input groups: %w", err)
+		}
+		WriteStdout("%s\n", barr)
+		return nil
+	}
+	if len(members) == 0 {
+		WriteStdout("no input group members\n")
+		return nil
+	}
+	WriteStdout("%-16s %-8s %-8s %-9s %s\n", "GROUP", "BLOCK", "VIEW", "STATUS", "CONNECTION")
+	for _, member := range members {
+		status := "stopped"
+		if member.Disabled {
+			status = "disabled"
+		} else if member.Running {
+			status = "running"
+		}
+		connName := member.ConnName
+		if connName == "" {
+			connName = "local"
+		}
+		WriteStdout("%-16s %-8s %-8s %-9s %s\n", member.Group, member.BlockId[:8], member.View, status, connName)
+	}
+	return nil
+}
+
+func inputGroupSetRun(action string) RunEFnType {
+	return func(cmd *cobra.Command, args []string) (rtnErr error) {
+		defer func() {
+			sendActivity("inputgroup", rtnErr == nil)
+		}()
+
+		fullORef, err := resolveBlockArg()
+		if err != nil {
+			return err
+		}
+		if fullORef.OType != waveobj.OType_Block {
+			return fmt.Errorf("%s is not a block", fullORef)
+		}
+		data := wshrpc.CommandInputGroupSetData{
+			Action:   action,
+			BlockIds: []string{fullORef.OID},
+		}
+		if len(args) > 0 {
+			data.Group = args[0]
+		}
+		err = wshclient.InputGroupSetCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 2000})
+		if err != nil {
+			return fmt.Errorf("updating input group: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+var configFile string
+var configImportStrategy string
+var configImportDryRun bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "manage config history and bundles",
+	Long:  "View and roll back recorded versions of settings.json and connections.json, or move a setup between machines with export/import",
+}
+
+var configHistoryCmd = &cobra.Command{
+	Use:     "history",
+	Short:   "list recorded versions of a config file",
+	Args:    cobra.NoArgs,
+	RunE:    configHistoryRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var configDiffCmd = &cobra.Command{
+	Use:     "diff [version]",
+	Short:   "show changes between a recorded version and the current config",
+	Args:    cobra.ExactArgs(1),
+	RunE:    configDiffRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var configRollbackCmd = &cobra.Command{
+	Use:     "rollback [version]",
+	Short:   "restore a recorded version of a config file",
+	Args:    cobra.ExactArgs(1),
+	RunE:    configRollbackRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var configExportCmd = &cobra.Command{
+	Use:     "export [bundle-file]",
+	Short:   "export settings, connections, themes, widgets, presets, pet data and tsunami apps as a bundle",
+	Long:    "Export a portable config bundle. Writes to stdout if no file (or \"-\") is given. Secret values are not exported, only their names.",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    configExportRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var configImportCmd = &cobra.Command{
+	Use:     "import [bundle-file]",
+	Short:   "import a config bundle",
+	Long:    "Import a config bundle created with 'wsh config export'. Reads from stdin if no file (or \"-\") is given.",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    configImportRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	configHistoryCmd.Flags().StringVarP(&configFile, "file", "f", "settings", "config file to operate on (settings or connections)")
+	configDiffCmd.Flags().StringVarP(&configFile, "file", "f", "settings", "config file to operate on (settings or connections)")
+	configRollbackCmd.Flags().StringVarP(&configFile, "file", "f", "settings", "config file to operate on (settings or connections)")
+	configImportCmd.Flags().StringVarP(&configImportStrategy, "strategy", "s", wshrpc.ConfigImportStrategy_Merge, "conflict strategy: merge (keep existing values) or overwrite (bundle wins)")
+	configImportCmd.Flags().BoolVarP(&configImportDryRun, "dry-run", "n", false, "report what would change without writing anything")
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configHistoryCmd)
+	configCmd.AddCommand(configDiffCmd)
+	configCmd.AddCommand(configRollbackCmd)
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+}
+
+func parseConfigVersion(arg string) (int, error) {
+	version, err := strconv.Atoi(arg)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid version %q: must be a positive integer", arg)
+	}
+	return version, nil
+}
+
+func formatConfigValue(val any) string {
+	if val == nil {
+		return "<unset>"
+	}
+	barr, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(barr)
+}
+
+func configHistoryRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("config", rtnErr == nil)
+	}()
+
+	entries, err := wshclient.ConfigHistoryListCommand(RpcClient, wshrpc.CommandConfigHistoryData{File: configFile}, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("listing config history: %w", err)
+	}
+	if len(entries) == 0 {
+		WriteStdout("no history recorded for %s\n", configFile)
+		return nil
+	}
+	WriteStdout("%-8s %-26s %-10s %s\n", "VERSION", "TIMESTAMP", "SOURCE", "SIZE")
+	for _, entry := range entries {
+		WriteStdout("%-8d %-26s %-10s %d\n", entry.Version, entry.Timestamp, entry.Source, entry.Size)
+	}
+	return nil
+}
+
+func configDiffRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("config", rtnErr == nil)
+	}()
+
+	version, err := parseConfigVersion(args[0])
+	if err != nil {
+		return err
+	}
+	diffs, err := wshclient.ConfigHistoryDiffCommand(RpcClient, wshrpc.CommandConfigHistoryData{File: configFile, Version: version}, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("diffing config: %w", err)
+	}
+	if len(diffs) == 0 {
+		WriteStdout("no differences between version %d and current config\n", version)
+		return nil
+	}
+	for _, diff := range diffs {
+		if diff.OldValue != nil {
+			WriteStdout("- %s: %s\n", diff.Key, formatConfigValue(diff.OldValue))
+		}
+		if diff.NewValue != nil {
+			WriteStdout("+ %s: %s\n", diff.Key, formatConfigValue(diff.NewValue))
+		}
+	}
+	return nil
+}
+
+func configRollbackRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("config", rtnErr == nil)
+	}()
+
+	version, err := parseConfigVersion(args[0])
+	if err != nil {
+		return err
+	}
+	err = wshclient.ConfigHistoryRollbackCommand(RpcClient, wshrpc.CommandConfigHistoryData{File: configFile, Version: version}, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("rolling back config: %w", err)
+	}
+	WriteStdout("%s rolled back to version %d\n", configFile, version)
+	return nil
+}
+
+func configExportRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("config", rtnErr == nil)
+	}()
+
+	bundle, err := wshclient.ConfigExportCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 30000})
+	if err != nil {
+		return fmt.Errorf("exporting config: %w", err)
+	}
+	barr, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding bundle: %w", err)
+	}
+	if len(args) == 0 || args[0] == "-" {
+		WriteStdout("%s\n", barr)
+		return nil
+	}
+	err = os.WriteFile(args[0], append(barr, '\n'), 0600)
+	if err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	WriteStderr("exported %d file(s) to %s\n", len(bundle.Files), args[0])
+	if len(bundle.SecretNames) > 0 {
+		WriteStderr("secret values are not included, re-create them on the target machine: %v\n", bundle.SecretNames)
+	}
+	return nil
+}
+
+func configImportRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("config", rtnErr == nil)
+	}()
+
+	var barr []byte
+	var err error
+	if len(args) == 0 || args[0] == "-" {
+		barr, err = io.ReadAll(WrappedStdin)
+	} else {
+		barr, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("reading bundle: %w", err)
+	}
+	var bundle wshrpc.ConfigBundle
+	err = json.Unmarshal(barr, &bundle)
+	if err != nil {
+		return fmt.Errorf("parsing bundle: %w", err)
+	}
+	data := wshrpc.CommandConfigImportData{
+		Bundle:   bundle,
+		Strategy: configImportStrategy,
+		DryRun:   configImportDryRun,
+	}
+	result, err := wshclient.ConfigImportCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 30000})
+	if err != nil {
+		return fmt.Errorf("importing config: %w", err)
+	}
+	verb := "wrote"
+	if result.DryRun {
+		verb = "would write"
+	}
+	for _, path := range result.Written {
+		WriteStdout("%s %s\n", verb, path)
+	}
+	for _, conflict := range result.Conflicts {
+		target := conflict.Section + "/" + conflict.Path
+		if conflict.Key != "" {
+			target += " [" + conflict.Key + "]"
+		}
+		WriteStdout("conflict %s: %s\n", target, conflict.Resolution)
+	}
+	for _, name := range result.MissingSecrets {
+		WriteStdout("missing secret %s (set it with 'wsh secret set %s=...')\n", name, name)
+	}
+	for _, warning := range result.Warnings {
+		WriteStderr("warning: %s\n", warning)
+	}
+	WriteStdout("%d written, %d unchanged, %d conflict(s)\n", len(result.Written), len(result.Unchanged), len(result.Conflicts))
+	return nil
+}
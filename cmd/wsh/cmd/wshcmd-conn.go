@@ -16,7 +16,7 @@ import (
 var connCmd = &cobra.Command{
 	Use:   "conn",
 	Short: "manage SL Terminal connections",
-	Long:  "Commands to manage SL Terminal SSH and WSL connections",
+	Long:  "Commands to manage SL Terminal SSH, WSL and TLS connections",
 }
 
 var connStatusCmd = &cobra.Command{
@@ -66,6 +66,14 @@ var connEnsureCmd = &cobra.Command{
 	PreRunE: preRunSetupRpcClient,
 }
 
+var connTlsFingerprintCmd = &cobra.Command{
+	Use:     "tlsfingerprint",
+	Short:   "print this client's certificate fingerprint (for authorized_clients on `wsh connserver --listen` hosts)",
+	Args:    cobra.NoArgs,
+	RunE:    connTlsFingerprintRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
 func init() {
 	rootCmd.AddCommand(connCmd)
 	connCmd.AddCommand(connStatusCmd)
@@ -74,10 +82,11 @@ func init() {
 	connCmd.AddCommand(connDisconnectAllCmd)
 	connCmd.AddCommand(connConnectCmd)
 	connCmd.AddCommand(connEnsureCmd)
+	connCmd.AddCommand(connTlsFingerprintCmd)
 }
 
 func validateConnectionName(name string) error {
-	if !strings.HasPrefix(name, "wsl://") {
+	if !strings.HasPrefix(name, "wsl://") && !strings.HasPrefix(name, "tls://") {
 		_, err := remote.ParseOpts(name)
 		if err != nil {
 			return fmt.Errorf("cannot parse connection name: %w", err)
@@ -185,6 +194,7 @@ func connConnectRun(cmd *cobra.Command, args []string) error {
 	data := wshrpc.ConnRequest{
 		Host:       connName,
 		LogBlockId: RpcContext.BlockId,
+		CliPrompts: enableCliPrompts(),
 	}
 	err := wshclient.ConnConnectCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 60000})
 	if err != nil {
@@ -210,3 +220,12 @@ func connEnsureRun(cmd *cobra.Command, args []string) error {
 	WriteStdout("wsh ensured on connection %q\n", connName)
 	return nil
 }
+
+func connTlsFingerprintRun(cmd *cobra.Command, args []string) error {
+	fingerprint, err := wshclient.ConnTlsFingerprintCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("getting tls fingerprint: %w", err)
+	}
+	WriteStdout("%s\n", fingerprint)
+	return nil
+}
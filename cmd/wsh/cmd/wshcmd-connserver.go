@@ -48,6 +48,9 @@ var connServerRouter bool
 var connServerRouterDomainSocket bool
 var connServerConnName string
 var connServerDev bool
+var connServerNoCompress bool
+var connServerListen string
+var connServerAllowClients []string
 var ConnServerWshRouter *wshutil.WshRouter
 var connServerInitialEnv map[string]string
 
@@ -56,6 +59,9 @@ func init() {
 	serverCmd.Flags().BoolVar(&connServerRouterDomainSocket, "router-domainsocket", false, "run in local router mode (domain socket upstream)")
 	serverCmd.Flags().StringVar(&connServerConnName, "conn", "", "connection name")
 	serverCmd.Flags().BoolVar(&connServerDev, "dev", false, "enable dev mode with file logging and PID in logs")
+	serverCmd.Flags().BoolVar(&connServerNoCompress, "nocompress", false, "don't negotiate compression on the upstream link")
+	serverCmd.Flags().StringVar(&connServerListen, "listen", "", "accept mutual tls connections on this address (for tls:// connections, no ssh needed)")
+	serverCmd.Flags().StringArrayVar(&connServerAllowClients, "allow-client", nil, "client certificate fingerprint to accept in --listen mode (in addition to ~/.slterm/tls/authorized_clients)")
 	rootCmd.AddCommand(serverCmd)
 }
 
@@ -306,13 +312,15 @@ func serverRunRouterDomainSocket(jwtToken string) error {
 
 	// create proxy for the domain socket connection
 	upstreamProxy := wshutil.MakeRpcProxy("connserver-upstream")
+	upstreamProxy.EnablePriorityLane()
+	linkStream := wshutil.MakeLinkStream(conn, true, connServerNoCompress)
 
 	// goroutine to write to the domain socket
 	go func() {
 		defer func() {
 			panichandler.PanicHandler("serverRunRouterDomainSocket:WriteLoop", recover())
 		}()
-		writeErr := wshutil.AdaptOutputChToStream(upstreamProxy.ToRemoteCh, conn)
+		writeErr := linkStream.WriteLoop(upstreamProxy.ToRemoteCh, upstreamProxy.ToRemoteHighCh)
 		if writeErr != nil {
 			log.Printf("error writing to upstream domain socket: %v\n", writeErr)
 		}
@@ -327,7 +335,7 @@ func serverRunRouterDomainSocket(jwtToken string) error {
 			log.Printf("upstream domain socket closed, shutting down")
 			wshutil.DoShutdown("", 0, true)
 		}()
-		wshutil.AdaptStreamToMsgCh(conn, upstreamProxy.FromRemoteCh, nil)
+		linkStream.ReadLoop(upstreamProxy.FromRemoteCh, nil)
 	}()
 
 	// register the domain socket connection as upstream
@@ -453,6 +461,14 @@ func serverRun(cmd *cobra.Command, args []string) error {
 			log.SetPrefix(fmt.Sprintf("[PID:%d] ", os.Getpid()))
 		}
 	}
+	if connServerListen != "" {
+		sigutil.InstallSIGUSR1Handler()
+		err := serverRunListen(connServerListen)
+		if err != nil && logFile != nil {
+			fmt.Fprintf(logFile, "serverRunListen error: %v\n", err)
+		}
+		return err
+	}
 	if connServerConnName == "" {
 		if logFile != nil {
 			fmt.Fprintf(logFile, "--conn parameter is required\n")
@@ -0,0 +1,170 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/util/tlsutil"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+)
+
+// `wsh connserver --listen ADDR` serves tls:// connections for hosts without sshd.  wavesrv dials in
+// with mutual tls, sends a hello line naming its connection, and gets the version line back.  the
+// stream is then handed to a `wsh connserver --router` child (the same mode wsl uses), so each
+// session gets a fresh router and a dropped connection shuts its connserver down cleanly.  durable
+// jobs run under their own job managers and survive until wavesrv reconnects.
+
+const connServerTlsHandshakeTimeout = 10 * time.Second
+const connServerSessionWaitDelay = 2 * time.Second
+
+var tlsListenLock = &sync.Mutex{}
+var tlsListenCurrent net.Conn
+
+func getConnServerTlsDir() string {
+	return filepath.Join(wavebase.GetHomeDir(), wavebase.RemoteWaveHomeDirName, "tls")
+}
+
+func getConnServerAllowedClients(authorizedFile string) []string {
+	fps, err := tlsutil.ReadFingerprintFile(authorizedFile)
+	if err != nil {
+		log.Printf("error reading %s: %v\n", authorizedFile, err)
+	}
+	return append(fps, connServerAllowClients...)
+}
+
+func serverRunListen(addr string) error {
+	tlsDir := getConnServerTlsDir()
+	cert, err := tlsutil.LoadOrCreateCert(filepath.Join(tlsDir, "server.crt"), filepath.Join(tlsDir, "server.key"), "wsh-connserver")
+	if err != nil {
+		return fmt.Errorf("loading server certificate: %w", err)
+	}
+	authorizedFile := filepath.Join(tlsDir, "authorized_clients")
+	allowedFn := func() []string {
+		// re-read on every handshake so newly authorized clients don't need a restart
+		return getConnServerAllowedClients(authorizedFile)
+	}
+	tlsConfig := &tls.Config{
+		Certificates:          []tls.Certificate{cert},
+		ClientAuth:            tls.RequireAnyClientCert,
+		VerifyPeerCertificate: tlsutil.MakePinnedVerifier(allowedFn),
+		MinVersion:            tls.VersionTLS13,
+	}
+	listener, err := tls.Listen("tcp", addr, tlsConfig)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", addr, err)
+	}
+	defer listener.Close()
+	WriteStdout("wsh connserver listening on %s\n", listener.Addr())
+	WriteStdout("server fingerprint (conn:tlsfingerprint): %s\n", tlsutil.CertFingerprint(cert))
+	if len(allowedFn()) == 0 {
+		WriteStdout("no clients are authorized yet, add the output of `wsh conn tlsfingerprint` to %s\n", authorizedFile)
+	}
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return err
+		}
+		if err != nil {
+			log.Printf("error accepting connection: %v\n", err)
+			continue
+		}
+		go handleTlsListenConn(conn.(*tls.Conn))
+	}
+}
+
+func handleTlsListenConn(conn *tls.Conn) {
+	defer func() {
+		panichandler.PanicHandler("handleTlsListenConn", recover())
+	}()
+	defer conn.Close()
+	remoteAddr := conn.RemoteAddr().String()
+	conn.SetDeadline(time.Now().Add(connServerTlsHandshakeTimeout))
+	err := conn.Handshake()
+	if err != nil {
+		log.Printf("tls handshake with %s failed: %v\n", remoteAddr, err)
+		return
+	}
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		log.Printf("error reading hello from %s: %v\n", remoteAddr, err)
+		return
+	}
+	hello, err := tlsutil.ParseHelloLine(line)
+	if err != nil {
+		log.Printf("%s: %v\n", remoteAddr, err)
+		return
+	}
+	connAddr := strings.TrimPrefix(hello.Conn, "tls://")
+	if !strings.HasPrefix(hello.Conn, "tls://") || connAddr == "" || strings.ContainsAny(connAddr, " \t\r\n/") {
+		log.Printf("%s: invalid connection name %q\n", remoteAddr, hello.Conn)
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	_, err = fmt.Fprintf(conn, "wsh v%s\n", wavebase.WaveVersion)
+	if err != nil {
+		log.Printf("error writing version to %s: %v\n", remoteAddr, err)
+		return
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		log.Printf("cannot find wsh executable: %v\n", err)
+		return
+	}
+	cmdArgs := []string{"connserver", "--router", "--conn", hello.Conn}
+	if connServerDev {
+		cmdArgs = append(cmdArgs, "--dev")
+	}
+	cmd := exec.Command(exePath, cmdArgs...)
+	cmd.Stdin = reader
+	cmd.Stdout = conn
+	cmd.Stderr = os.Stderr
+	cmd.WaitDelay = connServerSessionWaitDelay
+	replaceTlsListenSession(conn)
+	defer clearTlsListenSession(conn)
+	log.Printf("accepted %s from %s, starting connserver\n", hello.Conn, remoteAddr)
+	err = cmd.Start()
+	if err != nil {
+		log.Printf("error starting connserver for %s: %v\n", remoteAddr, err)
+		return
+	}
+	err = cmd.Wait()
+	log.Printf("connserver for %s (%s) exited: %v\n", hello.Conn, remoteAddr, err)
+}
+
+// only one wavesrv is served at a time.  a new session replaces the old one (after a wavesrv
+// restart the old tcp connection can linger until keepalives notice it is gone).  closing the old
+// conn ends its child's stdin, which shuts that connserver down.
+func replaceTlsListenSession(conn net.Conn) {
+	tlsListenLock.Lock()
+	oldConn := tlsListenCurrent
+	tlsListenCurrent = conn
+	tlsListenLock.Unlock()
+	if oldConn == nil {
+		return
+	}
+	log.Printf("replacing session from %s\n", oldConn.RemoteAddr())
+	oldConn.Close()
+}
+
+func clearTlsListenSession(conn net.Conn) {
+	tlsListenLock.Lock()
+	defer tlsListenLock.Unlock()
+	if tlsListenCurrent == conn {
+		tlsListenCurrent = nil
+	}
+}
@@ -5,9 +5,15 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
 	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
 )
 
 var debugCmd = &cobra.Command{
@@ -58,3 +64,138 @@ func debugBlockIdsRun(cmd *cobra.Command, args []string) error {
 	WriteStdout("%s\n", string(barr))
 	return nil
 }
+
+var debugRpcStatsCmd = &cobra.Command{
+	Use:   "rpcstats",
+	Short: "show per-command rpc metrics and link stats from the router",
+	Args:  cobra.NoArgs,
+	RunE:  debugRpcStatsRun,
+}
+
+var debugRpcTraceCmd = &cobra.Command{
+	Use:   "rpctrace",
+	Short: "show sampled rpc traces (enable with --sample)",
+	Long: `Show recently traced rpcs with their route hops through the router.  Tracing is off until a sample
+rate is set, e.g. "wsh debug rpctrace --sample 0.1" traces 10% of requests, "--sample 0" turns it off.`,
+	Args: cobra.NoArgs,
+	RunE: debugRpcTraceRun,
+}
+
+var debugRpcConn string
+var debugRpcJson bool
+var debugRpcStatsReset bool
+var debugRpcTraceSample float64
+var debugRpcTraceClear bool
+var debugRpcTraceMax int
+
+func init() {
+	for _, cmd := range []*cobra.Command{debugRpcStatsCmd, debugRpcTraceCmd} {
+		cmd.Flags().StringVarP(&debugRpcConn, "conn", "c", "", "query the connserver for this connection instead of the main server")
+		cmd.Flags().BoolVar(&debugRpcJson, "json", false, "output as json")
+		debugCmd.AddCommand(cmd)
+	}
+	debugRpcStatsCmd.Flags().BoolVar(&debugRpcStatsReset, "reset", false, "reset the command counters after reading them")
+	debugRpcTraceCmd.Flags().Float64Var(&debugRpcTraceSample, "sample", 0, "set the trace sample rate (0-1)")
+	debugRpcTraceCmd.Flags().BoolVar(&debugRpcTraceClear, "clear", false, "clear the trace buffer after reading it")
+	debugRpcTraceCmd.Flags().IntVarP(&debugRpcTraceMax, "num", "n", 50, "max number of traces to show")
+}
+
+func debugRpcOpts() *wshrpc.RpcOpts {
+	opts := &wshrpc.RpcOpts{Timeout: 5000}
+	if debugRpcConn != "" {
+		opts.Route = wshutil.MakeConnectionRouteId(debugRpcConn)
+	}
+	return opts
+}
+
+func writeDebugJson(v any) error {
+	barr, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	WriteStdout("%s\n", string(barr))
+	return nil
+}
+
+// approximate percentile from the latency histogram (upper bound of the bucket)
+func rpcLatencyPercentile(stats wshrpc.RpcCommandStats, bucketsMs []int64, pct float64) string {
+	if stats.Count == 0 {
+		return "-"
+	}
+	target := int64(math.Ceil(float64(stats.Count) * pct))
+	var total int64
+	for idx, count := range stats.Latencies {
+		total += count
+		if total >= target {
+			if idx < len(bucketsMs) {
+				return fmt.Sprintf("%d", bucketsMs[idx])
+			}
+			break
+		}
+	}
+	return fmt.Sprintf(">%d", bucketsMs[len(bucketsMs)-1])
+}
+
+func debugRpcStatsRun(cmd *cobra.Command, args []string) error {
+	stats, err := wshclient.RpcStatsCommand(RpcClient, wshrpc.CommandRpcStatsData{Reset: debugRpcStatsReset}, debugRpcOpts())
+	if err != nil {
+		return fmt.Errorf("getting rpc stats: %w", err)
+	}
+	if debugRpcJson {
+		return writeDebugJson(stats)
+	}
+	since := time.UnixMilli(stats.SinceTs).Format(time.DateTime)
+	WriteStdout("rpc stats since %s (latencies in ms)\n", since)
+	WriteStdout("%-32s %8s %6s %6s %6s %8s %6s %6s %8s\n", "COMMAND", "COUNT", "ERRS", "TMOUT", "INFL", "AVG", "P50", "P95", "MAX")
+	for _, cs := range stats.Commands {
+		avg := "-"
+		if cs.Count > 0 {
+			avg = fmt.Sprintf("%.1f", float64(cs.TotalMs)/float64(cs.Count))
+		}
+		inFlight := fmt.Sprintf("%d", cs.InFlight)
+		if cs.Stale > 0 {
+			inFlight = fmt.Sprintf("%d+%d", cs.InFlight, cs.Stale)
+		}
+		WriteStdout("%-32s %8d %6d %6d %6s %8s %6s %6s %8d\n", cs.Command, cs.Count, cs.Errors, cs.Timeouts, inFlight, avg,
+			rpcLatencyPercentile(cs, stats.LatencyBucketsMs, 0.5), rpcLatencyPercentile(cs, stats.LatencyBucketsMs, 0.95), cs.MaxMs)
+	}
+	WriteStdout("\n%-5s %-40s %-7s %10s %10s %12s %12s %s\n", "LINK", "NAME", "KIND", "MSGS-OUT", "MSGS-IN", "BYTES-OUT", "BYTES-IN", "BACKLOG")
+	for _, ls := range stats.Links {
+		WriteStdout("%-5d %-40s %-7s %10d %10d %12d %12d %d (max %d)\n", ls.LinkId, ls.Name, ls.Kind, ls.MsgsSent, ls.MsgsRecv, ls.BytesSent, ls.BytesRecv, ls.Backlog, ls.BacklogHighWater)
+		if len(ls.Routes) > 0 {
+			WriteStdout("      routes: %s\n", strings.Join(ls.Routes, " "))
+		}
+	}
+	return nil
+}
+
+func debugRpcTraceRun(cmd *cobra.Command, args []string) error {
+	data := wshrpc.CommandRpcTraceData{Clear: debugRpcTraceClear, MaxEntries: debugRpcTraceMax}
+	if cmd.Flags().Changed("sample") {
+		data.SampleRate = &debugRpcTraceSample
+	}
+	trace, err := wshclient.RpcTraceCommand(RpcClient, data, debugRpcOpts())
+	if err != nil {
+		return fmt.Errorf("getting rpc trace: %w", err)
+	}
+	if debugRpcJson {
+		return writeDebugJson(trace)
+	}
+	if trace.SampleRate == 0 {
+		WriteStdout("rpc tracing is off (enable with --sample)\n")
+	} else {
+		WriteStdout("rpc trace sample rate %g\n", trace.SampleRate)
+	}
+	for _, entry := range trace.Entries {
+		ts := time.UnixMilli(entry.StartTs).Format("15:04:05.000")
+		WriteStdout("%s %6dms %-28s %s [%s]", ts, entry.DurationMs, entry.Command, entry.Route, strings.Join(entry.Hops, " -> "))
+		if entry.Responses > 1 {
+			WriteStdout(" responses=%d", entry.Responses)
+		}
+		if entry.Error != "" {
+			WriteStdout(" error=%q", entry.Error)
+		}
+		WriteStdout("\n")
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+var eventsScopes []string
+var eventsHistory int
+var eventsPubData string
+var eventsPubPersist int
+var eventsWaitFilters []string
+var eventsWaitTimeout time.Duration
+var eventsHistorySince string
+var eventsHistoryUntil string
+var eventsHistoryLimit int
+var eventsHistoryScope string
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "subscribe to and publish events",
+	Long:  "Subscribe to, wait for and publish SL Terminal events (connchange, blockclose, controllerstatus, block:jobstatus, ...)",
+}
+
+var eventsSubCmd = &cobra.Command{
+	Use:     "sub [event]",
+	Short:   "print events as newline-delimited json until interrupted",
+	Args:    cobra.ExactArgs(1),
+	RunE:    eventsSubRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var eventsPubCmd = &cobra.Command{
+	Use:     "pub [event]",
+	Short:   "publish a custom event (event names must start with \"" + wps.UserEventPrefix + "\")",
+	Args:    cobra.ExactArgs(1),
+	RunE:    eventsPubRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var eventsWaitCmd = &cobra.Command{
+	Use:   "wait [event]",
+	Short: "wait for a matching event, print it and exit",
+	Long: `Wait for an event and print it as json.  Filters use a jq-like path syntax and are and-ed together:
+  --filter .data.status==done   --filter '.data.exitcode!=0'   --filter '.data.values.cpu>=90'   --filter .data.connected   --filter '.scopes[0]==block:123'`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    eventsWaitRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var eventsHistoryCmd = &cobra.Command{
+	Use:   "history [event]",
+	Short: "query the durable event log",
+	Long: `Print events from the durable event log as newline-delimited json (oldest first).
+Only events listed in the "eventlog:events" setting are recorded, e.g. "eventlog:events": ["connchange", "blockclose", "user:*"].
+Retention is set with "eventlog:retentiondays" and "eventlog:maxperevent", per event type with
+"eventlog:retention", e.g. "eventlog:retention": {"connchange": {"retentiondays": 90, "maxevents": 50000}}.`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    eventsHistoryRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{eventsSubCmd, eventsPubCmd, eventsWaitCmd} {
+		cmd.Flags().StringArrayVarP(&eventsScopes, "scope", "s", nil, "event scope (e.g. block:<id>, repeatable)")
+	}
+	eventsSubCmd.Flags().IntVar(&eventsHistory, "history", 0, "print up to N persisted past events before new ones")
+	eventsPubCmd.Flags().StringVarP(&eventsPubData, "data", "d", "", "event data (json, or a plain string)")
+	eventsPubCmd.Flags().IntVar(&eventsPubPersist, "persist", 0, "keep the last N events of this type for --history readers")
+	eventsWaitCmd.Flags().StringArrayVarP(&eventsWaitFilters, "filter", "f", nil, "only match events where PATH[OP VALUE] holds, OP is one of == != > >= < <= (repeatable)")
+	eventsWaitCmd.Flags().DurationVarP(&eventsWaitTimeout, "timeout", "t", 0, "give up after this long (e.g. 30s, 5m), 0 waits forever")
+	eventsHistoryCmd.Flags().StringVarP(&eventsHistoryScope, "scope", "s", "", "only events with this scope")
+	eventsHistoryCmd.Flags().StringVar(&eventsHistorySince, "since", "", "start time (duration ago like 12h, or RFC3339)")
+	eventsHistoryCmd.Flags().StringVar(&eventsHistoryUntil, "until", "", "end time (duration ago like 1h, or RFC3339)")
+	eventsHistoryCmd.Flags().IntVarP(&eventsHistoryLimit, "limit", "n", 100, "maximum number of events (most recent)")
+	rootCmd.AddCommand(eventsCmd)
+	eventsCmd.AddCommand(eventsSubCmd)
+	eventsCmd.AddCommand(eventsPubCmd)
+	eventsCmd.AddCommand(eventsWaitCmd)
+	eventsCmd.AddCommand(eventsHistoryCmd)
+}
+
+func makeEventSubRequest(eventName string) wps.SubscriptionRequest {
+	return wps.SubscriptionRequest{
+		Event:     eventName,
+		Scopes:    eventsScopes,
+		AllScopes: len(eventsScopes) == 0,
+	}
+}
+
+func writeEventJson(event *wps.WaveEvent) {
+	barr, err := json.Marshal(event)
+	if err != nil {
+		WriteStderr("[error] encoding event: %v\n", err)
+		return
+	}
+	WriteStdout("%s\n", barr)
+}
+
+func makeInterruptCh() chan os.Signal {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	return sigCh
+}
+
+func eventsSubRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("events", rtnErr == nil)
+	}()
+
+	eventName := args[0]
+	sigCh := makeInterruptCh()
+	// the listener runs on the rpc goroutine, it must never block (events are dropped if stdout can't
+	// keep up)
+	eventCh := make(chan *wps.WaveEvent, 256)
+	var droppedEvents atomic.Int64
+	RpcClient.EventListener.On(eventName, func(event *wps.WaveEvent) {
+		select {
+		case eventCh <- event:
+		default:
+			droppedEvents.Add(1)
+		}
+	})
+	if eventsHistory > 0 {
+		var historyScopes []string
+		if len(eventsScopes) == 0 {
+			historyScopes = []string{""}
+		} else {
+			historyScopes = eventsScopes
+		}
+		for _, scope := range historyScopes {
+			readData := wshrpc.CommandEventReadHistoryData{Event: eventName, Scope: scope, MaxItems: eventsHistory}
+			history, err := wshclient.EventReadHistoryCommand(RpcClient, readData, &wshrpc.RpcOpts{Timeout: 2000})
+			if err != nil {
+				return fmt.Errorf("reading event history: %w", err)
+			}
+			for _, event := range history {
+				writeEventJson(event)
+			}
+		}
+	}
+	err := wshclient.EventSubCommand(RpcClient, makeEventSubRequest(eventName), &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("subscribing to %s: %w", eventName, err)
+	}
+	for {
+		select {
+		case event := <-eventCh:
+			if dropped := droppedEvents.Swap(0); dropped > 0 {
+				WriteStderr("[warning] dropped %d %s events\n", dropped, eventName)
+			}
+			writeEventJson(event)
+		case <-sigCh:
+			wshclient.EventUnsubCommand(RpcClient, eventName, &wshrpc.RpcOpts{Timeout: 2000})
+			return nil
+		}
+	}
+}
+
+func eventsPubRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("events", rtnErr == nil)
+	}()
+
+	eventName := args[0]
+	if !wps.IsUserEvent(eventName) {
+		return fmt.Errorf("custom events must be in the %q namespace (e.g. %sbuild-done)", wps.UserEventPrefix, wps.UserEventPrefix)
+	}
+	event := wps.WaveEvent{
+		Event:   eventName,
+		Scopes:  eventsScopes,
+		Persist: eventsPubPersist,
+	}
+	if eventsPubData != "" {
+		var data any
+		if err := json.Unmarshal([]byte(eventsPubData), &data); err != nil {
+			data = eventsPubData
+		}
+		event.Data = data
+	}
+	err := wshclient.EventPublishCommand(RpcClient, event, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("publishing %s: %w", eventName, err)
+	}
+	return nil
+}
+
+func eventsWaitRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("events", rtnErr == nil)
+	}()
+
+	eventName := args[0]
+	var filters []*wps.EventFilter
+	for _, expr := range eventsWaitFilters {
+		filter, err := wps.ParseEventFilter(expr)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, filter)
+	}
+	sigCh := makeInterruptCh()
+	matchCh := make(chan *wps.WaveEvent, 1)
+	RpcClient.EventListener.On(eventName, func(event *wps.WaveEvent) {
+		if !wps.MatchEventFilters(event, filters) {
+			return
+		}
+		select {
+		case matchCh <- event:
+		default:
+		}
+	})
+	err := wshclient.EventSubCommand(RpcClient, makeEventSubRequest(eventName), &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("subscribing to %s: %w", eventName, err)
+	}
+	defer wshclient.EventUnsubCommand(RpcClient, eventName, &wshrpc.RpcOpts{Timeout: 2000})
+	var timeoutCh <-chan time.Time
+	if eventsWaitTimeout > 0 {
+		timeoutCh = time.After(eventsWaitTimeout)
+	}
+	select {
+	case event := <-matchCh:
+		writeEventJson(event)
+		return nil
+	case <-timeoutCh:
+		return fmt.Errorf("timed out after %v waiting for %s", eventsWaitTimeout, eventName)
+	case <-sigCh:
+		return fmt.Errorf("interrupted waiting for %s", eventName)
+	}
+}
+
+// accepts a duration before now ("12h", "30m") or an RFC3339 timestamp, returns unix ms (0 for "")
+func parseEventTime(val string, now time.Time) (int64, error) {
+	if val == "" {
+		return 0, nil
+	}
+	if dur, err := time.ParseDuration(val); err == nil {
+		return now.Add(-dur).UnixMilli(), nil
+	}
+	ts, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (use a duration like 12h or an RFC3339 time)", val)
+	}
+	return ts.UnixMilli(), nil
+}
+
+func eventsHistoryRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("events", rtnErr == nil)
+	}()
+
+	now := time.Now()
+	startTs, err := parseEventTime(eventsHistorySince, now)
+	if err != nil {
+		return err
+	}
+	endTs, err := parseEventTime(eventsHistoryUntil, now)
+	if err != nil {
+		return err
+	}
+	readData := wshrpc.CommandEventReadHistoryData{
+		Event:    args[0],
+		Scope:    eventsHistoryScope,
+		MaxItems: eventsHistoryLimit,
+		StartTs:  startTs,
+		EndTs:    endTs,
+		Durable:  true,
+	}
+	events, err := wshclient.EventReadHistoryCommand(RpcClient, readData, &wshrpc.RpcOpts{Timeout: 10000})
+	if err != nil {
+		return fmt.Errorf("reading event log: %w", err)
+	}
+	for _, event := range events {
+		writeEventJson(event)
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/util/termutil"
+	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+const (
+	expectStep_Send    = "send"
+	expectStep_SendRaw = "sendraw"
+	expectStep_Expect  = "expect"
+	expectStep_Sleep   = "sleep"
+	expectStep_Timeout = "timeout"
+)
+
+var expectSteps []expectStep
+var expectScriptFile string
+var expectTimeout time.Duration
+var expectEcho bool
+
+var expectCmd = &cobra.Command{
+	Use:   "expect [flags]",
+	Short: "drive a terminal block by waiting for its output and sending input",
+	Long: `Run a sequence of steps against a terminal block (the current block, or the one given with -b):
+send input, wait for output matching a regexp, sleep.  Steps come from the --send, --send-raw,
+--expect and --sleep flags (run in the order given) or from a script file.  Exits non-zero if an
+expect times out or the block's process exits first.
+
+Only output printed after wsh expect starts is matched.  Output is matched line by line with escape
+sequences removed, the current unterminated line (e.g. a prompt) is matched too.  Each match consumes
+the output up to its end.
+
+Script format (one step per line, blank lines and lines starting with # are ignored):
+
+  expect >>> $
+  send print(6*7)       # sends the text followed by enter
+  expect ^42$
+  sendraw \x04          # no enter, escapes: \r \n \t \e \xHH \\
+  sleep 500ms
+  timeout 2m            # timeout for the following expects
+
+Example:
+
+  wsh expect -b 2 --send "python3" --expect '>>> $' --send 'print(6*7)' --expect '^42$'`,
+	Args:    cobra.NoArgs,
+	RunE:    expectRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+type expectStep struct {
+	Kind     string
+	Text     string // input for send/sendraw, the regexp for expect
+	Re       *regexp.Regexp
+	Duration time.Duration
+}
+
+// a flag that appends a step each time it is set, so mixed --send/--expect flags keep their order
+type expectStepFlag struct {
+	kind string
+}
+
+func (f *expectStepFlag) String() string {
+	return ""
+}
+
+func (f *expectStepFlag) Set(val string) error {
+	step, err := makeExpectStep(f.kind, val)
+	if err != nil {
+		return err
+	}
+	expectSteps = append(expectSteps, step)
+	return nil
+}
+
+func (f *expectStepFlag) Type() string {
+	return "string"
+}
+
+func init() {
+	expectCmd.Flags().Var(&expectStepFlag{kind: expectStep_Send}, "send", "send TEXT followed by enter (repeatable)")
+	expectCmd.Flags().Var(&expectStepFlag{kind: expectStep_SendRaw}, "send-raw", "send TEXT without enter (repeatable)")
+	expectCmd.Flags().VarP(&expectStepFlag{kind: expectStep_Expect}, "expect", "e", "wait for output matching REGEX (repeatable)")
+	expectCmd.Flags().Var(&expectStepFlag{kind: expectStep_Sleep}, "sleep", "pause for a duration, e.g. 500ms (repeatable)")
+	expectCmd.Flags().StringVarP(&expectScriptFile, "script", "f", "", "read steps from a script file (- for stdin)")
+	expectCmd.Flags().DurationVarP(&expectTimeout, "timeout", "t", 30*time.Second, "timeout for each expect")
+	expectCmd.Flags().BoolVar(&expectEcho, "echo", false, "copy the block's output to stdout")
+	rootCmd.AddCommand(expectCmd)
+}
+
+// handles \r \n \t \e \xHH and \\ (other backslashes are kept as is)
+func unescapeExpectInput(text string) (string, error) {
+	var sb strings.Builder
+	for idx := 0; idx < len(text); idx++ {
+		ch := text[idx]
+		if ch != '\\' || idx+1 >= len(text) {
+			sb.WriteByte(ch)
+			continue
+		}
+		idx++
+		switch text[idx] {
+		case 'r':
+			sb.WriteByte('\r')
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'e':
+			sb.WriteByte(0x1b)
+		case '\\':
+			sb.WriteByte('\\')
+		case 'x':
+			if idx+2 >= len(text) {
+				return "", fmt.Errorf("incomplete \\x escape in %q", text)
+			}
+			val, err := strconv.ParseUint(text[idx+1:idx+3], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid \\x escape in %q", text)
+			}
+			sb.WriteByte(byte(val))
+			idx += 2
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(text[idx])
+		}
+	}
+	return sb.String(), nil
+}
+
+func makeExpectStep(kind string, arg string) (expectStep, error) {
+	step := expectStep{Kind: kind, Text: arg}
+	var err error
+	switch kind {
+	case expectStep_Send, expectStep_SendRaw:
+		step.Text, err = unescapeExpectInput(arg)
+		if err != nil {
+			return step, err
+		}
+		if kind == expectStep_Send {
+			step.Text += "\r"
+		}
+	case expectStep_Expect:
+		step.Re, err = regexp.Compile(arg)
+		if err != nil {
+			return step, fmt.Errorf("invalid regexp %q: %w", arg, err)
+		}
+	case expectStep_Sleep, expectStep_Timeout:
+		step.Duration, err = time.ParseDuration(arg)
+		if err != nil {
+			return step, fmt.Errorf("invalid %s duration %q", kind, arg)
+		}
+	default:
+		return step, fmt.Errorf("unknown step %q", kind)
+	}
+	return step, nil
+}
+
+func parseExpectScript(content string) ([]expectStep, error) {
+	var steps []expectStep
+	for lineNum, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		kind, arg, _ := strings.Cut(strings.TrimLeft(line, " \t"), " ")
+		step, err := makeExpectStep(kind, arg)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum+1, err)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// collects a block's output (from blockfile events) for the expect steps
+type expectOutput struct {
+	lock     sync.Mutex
+	matcher  termutil.OutputMatcher
+	exited   bool
+	notifyCh chan struct{}
+}
+
+func (eo *expectOutput) notify() {
+	select {
+	case eo.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (eo *expectOutput) handleBlockFileEvent(blockId string, event *wps.WaveEvent) {
+	var fileData wps.WSFileEventData
+	if err := utilfn.ReUnmarshal(&fileData, event.Data); err != nil {
+		return
+	}
+	if fileData.ZoneId != blockId || fileData.FileName != wavebase.BlockFile_Term || fileData.FileOp != wps.FileOp_Append {
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(fileData.Data64)
+	if err != nil {
+		return
+	}
+	if expectEcho {
+		os.Stdout.Write(data)
+	}
+	eo.lock.Lock()
+	eo.matcher.Write(data)
+	eo.lock.Unlock()
+	eo.notify()
+}
+
+func (eo *expectOutput) handleControllerStatusEvent(blockId string, event *wps.WaveEvent) {
+	var status struct {
+		BlockId         string `json:"blockid"`
+		ShellProcStatus string `json:"shellprocstatus"`
+	}
+	if err := utilfn.ReUnmarshal(&status, event.Data); err != nil || status.BlockId != blockId {
+		return
+	}
+	if status.ShellProcStatus == "done" {
+		eo.lock.Lock()
+		eo.exited = true
+		eo.lock.Unlock()
+		eo.notify()
+	}
+}
+
+func (eo *expectOutput) wait(step expectStep, timeout time.Duration, sigCh chan os.Signal) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		eo.lock.Lock()
+		match := eo.matcher.Match(step.Re)
+		exited := eo.exited
+		eo.lock.Unlock()
+		if match != nil {
+			return nil
+		}
+		if exited {
+			return fmt.Errorf("block process exited while waiting for %q", step.Text)
+		}
+		select {
+		case <-eo.notifyCh:
+		case <-timer.C:
+			return fmt.Errorf("timed out after %v waiting for %q", timeout, step.Text)
+		case <-sigCh:
+			return fmt.Errorf("interrupted waiting for %q", step.Text)
+		}
+	}
+}
+
+func expectRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("expect", rtnErr == nil)
+	}()
+
+	steps := expectSteps
+	if expectScriptFile != "" {
+		if len(steps) > 0 {
+			return fmt.Errorf("cannot combine --script with --send/--expect/--sleep")
+		}
+		var content []byte
+		var err error
+		if expectScriptFile == "-" {
+			content, err = io.ReadAll(WrappedStdin)
+		} else {
+			content, err = os.ReadFile(expectScriptFile)
+		}
+		if err != nil {
+			return fmt.Errorf("reading script: %w", err)
+		}
+		steps, err = parseExpectScript(string(content))
+		if err != nil {
+			return fmt.Errorf("parsing script: %w", err)
+		}
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("no steps, use --send/--expect or --script")
+	}
+	fullORef, err := resolveBlockArg()
+	if err != nil {
+		return err
+	}
+	if fullORef.OType != waveobj.OType_Block {
+		return fmt.Errorf("%s is not a block", fullORef)
+	}
+	blockId := fullORef.OID
+
+	output := &expectOutput{notifyCh: make(chan struct{}, 1)}
+	RpcClient.EventListener.On(wps.Event_BlockFile, func(event *wps.WaveEvent) {
+		output.handleBlockFileEvent(blockId, event)
+	})
+	RpcClient.EventListener.On(wps.Event_ControllerStatus, func(event *wps.WaveEvent) {
+		output.handleControllerStatusEvent(blockId, event)
+	})
+	for _, eventName := range []string{wps.Event_BlockFile, wps.Event_ControllerStatus} {
+		subReq := wps.SubscriptionRequest{Event: eventName, Scopes: []string{fullORef.String()}}
+		err = wshclient.EventSubCommand(RpcClient, subReq, &wshrpc.RpcOpts{Timeout: 2000})
+		if err != nil {
+			return fmt.Errorf("subscribing to %s: %w", eventName, err)
+		}
+		defer wshclient.EventUnsubCommand(RpcClient, eventName, &wshrpc.RpcOpts{Timeout: 2000})
+	}
+
+	sigCh := makeInterruptCh()
+	timeout := expectTimeout
+	for _, step := range steps {
+		switch step.Kind {
+		case expectStep_Send, expectStep_SendRaw:
+			inputData := wshrpc.CommandBlockInputData{
+				BlockId:     blockId,
+				InputData64: base64.StdEncoding.EncodeToString([]byte(step.Text)),
+			}
+			err = wshclient.ControllerInputCommand(RpcClient, inputData, &wshrpc.RpcOpts{Timeout: 2000})
+			if err != nil {
+				return fmt.Errorf("sending input: %w", err)
+			}
+		case expectStep_Expect:
+			err = output.wait(step, timeout, sigCh)
+			if err != nil {
+				return err
+			}
+		case expectStep_Sleep:
+			select {
+			case <-time.After(step.Duration):
+			case <-sigCh:
+				return fmt.Errorf("interrupted")
+			}
+		case expectStep_Timeout:
+			timeout = step.Duration
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+//go:build linux
+
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const ttyInputWaitTimeout = 2 * time.Second
+
+// waits until numBytes of input are queued on the tty (or the timeout), input that arrives after the
+// tty leaves raw mode would be echoed by the line discipline
+func waitForTtyInput(fd int, numBytes int) {
+	for start := time.Now(); time.Since(start) < ttyInputWaitTimeout; {
+		queued, err := unix.IoctlGetInt(fd, unix.TIOCINQ)
+		if err != nil || queued >= numBytes {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"time"
+)
+
+// no portable way to read the queued input count, give the input a moment to arrive before leaving raw mode
+func waitForTtyInput(fd int, numBytes int) {
+	time.Sleep(200 * time.Millisecond)
+}
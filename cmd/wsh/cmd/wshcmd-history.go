@@ -0,0 +1,407 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/util/fuzzyutil"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"golang.org/x/term"
+)
+
+const historyMaxCandidates = 10000
+
+var historyQuery string
+var historyCwd string
+var historyConn string
+var historySuccess bool
+var historyFailed bool
+var historyPrint bool
+var historyList bool
+var historyJson bool
+var historyLimit int
+
+var historyCmd = &cobra.Command{
+	Use:   "history [flags]",
+	Short: "search the command history of every block and connection",
+	Long: `Search the commands run in every terminal block (local, ssh and wsl, any shell) with a fuzzy
+finder.  Commands are recorded by shell integration, commands starting with a space are not
+recorded.  The selected command is inserted at the prompt of the current block (or the block
+given with -b), use --print to print it instead.
+
+Picker keys: type to filter (space separated terms must all match, uppercase makes a term case
+sensitive), up/down or ctrl-p/ctrl-n to move, pgup/pgdown, enter to select, esc or ctrl-c to quit.
+
+  wsh history                     # pick from all history
+  wsh history --cwd . --failed    # commands that failed in this directory
+  wsh history --conn local -q git # local commands, start with the query "git"
+  wsh history --list -q docker    # print matches without the picker`,
+	Args:    cobra.NoArgs,
+	RunE:    historyRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	historyCmd.Flags().StringVarP(&historyQuery, "query", "q", "", "initial query")
+	historyCmd.Flags().StringVar(&historyCwd, "cwd", "", "only commands run in this directory (\".\" for the current directory)")
+	historyCmd.Flags().StringVar(&historyConn, "conn", "", "only commands run on this connection (\"local\" for local commands)")
+	historyCmd.Flags().BoolVar(&historySuccess, "success", false, "only commands that exited with 0")
+	historyCmd.Flags().BoolVar(&historyFailed, "failed", false, "only commands that exited with a non-zero code")
+	historyCmd.Flags().BoolVarP(&historyPrint, "print", "p", false, "print the selected command instead of inserting it")
+	historyCmd.Flags().BoolVar(&historyList, "list", false, "print the matching commands (best match first) without the picker")
+	historyCmd.Flags().BoolVar(&historyJson, "json", false, "with --list, print the matching entries as json")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 0, "with --list, max number of commands to print")
+	rootCmd.AddCommand(historyCmd)
+}
+
+type historyMatch struct {
+	Entry     wshrpc.HistoryEntry
+	Score     int
+	Positions []int
+}
+
+// entries are newest first, ties keep that order
+func matchHistory(entries []wshrpc.HistoryEntry, query string) []historyMatch {
+	matcher := fuzzyutil.MakeMatcher(query)
+	var rtn []historyMatch
+	for _, entry := range entries {
+		score, positions, ok := matcher.Match(entry.Cmd)
+		if !ok {
+			continue
+		}
+		rtn = append(rtn, historyMatch{Entry: entry, Score: score, Positions: positions})
+	}
+	sort.SliceStable(rtn, func(i, j int) bool {
+		return rtn[i].Score > rtn[j].Score
+	})
+	return rtn
+}
+
+func historyRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("history", rtnErr == nil)
+	}()
+
+	queryData := wshrpc.CommandHistoryQueryData{
+		Connection: historyConn,
+		Unique:     true,
+		MaxItems:   historyMaxCandidates,
+	}
+	if historyCwd != "" {
+		cwd, err := filepath.Abs(historyCwd)
+		if err != nil {
+			return fmt.Errorf("resolving --cwd: %w", err)
+		}
+		queryData.Cwd = cwd
+	}
+	if historySuccess && historyFailed {
+		return fmt.Errorf("--success and --failed cannot be used together")
+	}
+	if historySuccess {
+		queryData.ExitStatus = "success"
+	} else if historyFailed {
+		queryData.ExitStatus = "failed"
+	}
+	entries, err := wshclient.HistoryQueryCommand(RpcClient, queryData, &wshrpc.RpcOpts{Timeout: 10000})
+	if err != nil {
+		return fmt.Errorf("reading history: %w", err)
+	}
+	if historyList {
+		return historyListRun(entries)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no matching history (commands are recorded by shell integration)")
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return fmt.Errorf("the picker needs a terminal (use --list)")
+	}
+	var blockId string
+	if !historyPrint {
+		blockORef, err := resolveBlockArg()
+		if err == nil && blockORef.OType == waveobj.OType_Block {
+			blockId = blockORef.OID
+		}
+	}
+	picker := &historyPicker{entries: entries, query: []rune(historyQuery)}
+	selected, err := picker.run(blockId)
+	if err != nil {
+		return err
+	}
+	if selected == "" || blockId != "" {
+		return nil
+	}
+	WriteStdout("%s\n", selected)
+	return nil
+}
+
+func historyListRun(entries []wshrpc.HistoryEntry) error {
+	matches := matchHistory(entries, historyQuery)
+	if historyLimit > 0 && len(matches) > historyLimit {
+		matches = matches[:historyLimit]
+	}
+	if historyJson {
+		rtn := make([]wshrpc.HistoryEntry, 0, len(matches))
+		for _, match := range matches {
+			rtn = append(rtn, match.Entry)
+		}
+		barr, err := json.MarshalIndent(rtn, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding history: %w", err)
+		}
+		WriteStdout("%s\n", barr)
+		return nil
+	}
+	for _, match := range matches {
+		WriteStdout("%s\n", match.Entry.Cmd)
+	}
+	return nil
+}
+
+func formatHistoryAge(ts int64) string {
+	age := time.Since(time.UnixMilli(ts))
+	switch {
+	case age < time.Minute:
+		return "now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	case age < 365*24*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	default:
+		return fmt.Sprintf("%dy", int(age.Hours()/(24*365)))
+	}
+}
+
+// the text to send to the shell, multi-line commands are sent as a bracketed paste so they are
+// inserted instead of run
+func makeHistoryInput(cmdStr string) string {
+	if strings.ContainsAny(cmdStr, "\r\n") {
+		return "\x1b[200~" + cmdStr + "\x1b[201~"
+	}
+	return cmdStr
+}
+
+// full screen fuzzy picker (drawn on stderr in the alternate screen, keys from stdin in raw mode)
+type historyPicker struct {
+	entries  []wshrpc.HistoryEntry
+	query    []rune
+	matches  []historyMatch
+	selected int
+	offset   int
+}
+
+func (p *historyPicker) updateMatches() {
+	p.matches = matchHistory(p.entries, string(p.query))
+	p.selected = 0
+	p.offset = 0
+}
+
+func (p *historyPicker) listHeight() int {
+	_, height, err := term.GetSize(int(os.Stderr.Fd()))
+	if err != nil || height < 4 {
+		height = 24
+	}
+	return height - 2
+}
+
+func (p *historyPicker) move(delta int) {
+	p.selected = max(0, min(p.selected+delta, len(p.matches)-1))
+	listHeight := p.listHeight()
+	if p.selected < p.offset {
+		p.offset = p.selected
+	} else if p.selected >= p.offset+listHeight {
+		p.offset = p.selected - listHeight + 1
+	}
+}
+
+func (p *historyPicker) render() {
+	width, height, err := term.GetSize(int(os.Stderr.Fd()))
+	if err != nil || width < 20 || height < 4 {
+		width, height = 80, 24
+	}
+	var buf strings.Builder
+	buf.WriteString("\x1b[H")
+	buf.WriteString("\x1b[1;36m>\x1b[0m " + string(p.query) + "\x1b[K\r\n")
+	fmt.Fprintf(&buf, "\x1b[2m  %d/%d\x1b[0m\x1b[K", len(p.matches), len(p.entries))
+	for row := 0; row < height-2; row++ {
+		buf.WriteString("\r\n")
+		idx := p.offset + row
+		if idx < len(p.matches) {
+			p.renderMatch(&buf, p.matches[idx], idx == p.selected, width)
+		}
+		buf.WriteString("\x1b[K")
+	}
+	fmt.Fprintf(&buf, "\x1b[1;%dH", min(3+len(p.query), width))
+	os.Stderr.WriteString(buf.String())
+}
+
+func (p *historyPicker) renderMatch(buf *strings.Builder, match historyMatch, isSelected bool, width int) {
+	info := formatHistoryAge(match.Entry.Ts)
+	infoColor := "\x1b[2m"
+	if match.Entry.ExitCode != nil && *match.Entry.ExitCode != 0 {
+		info = fmt.Sprintf("exit %d  %s", *match.Entry.ExitCode, info)
+		infoColor = "\x1b[31m"
+	}
+	textWidth := width - 3 - len(info) - 2
+	baseStyle := ""
+	if isSelected {
+		baseStyle = "\x1b[1;48;5;236m"
+		buf.WriteString("\x1b[1;31m▌\x1b[0m" + baseStyle + " ")
+	} else {
+		buf.WriteString("  ")
+	}
+	posIdx := 0
+	var numRunes int
+	for runeIdx, ch := range []rune(match.Entry.Cmd) {
+		if numRunes >= textWidth {
+			break
+		}
+		for posIdx < len(match.Positions) && match.Positions[posIdx] < runeIdx {
+			posIdx++
+		}
+		if ch == '\n' || ch == '\r' {
+			ch = '↵'
+		} else if ch < ' ' {
+			ch = ' '
+		}
+		if numRunes == textWidth-1 && utf8.RuneCountInString(match.Entry.Cmd) > runeIdx+1 {
+			ch = '…'
+		}
+		if posIdx < len(match.Positions) && match.Positions[posIdx] == runeIdx {
+			buf.WriteString("\x1b[32m" + string(ch) + "\x1b[39m")
+		} else {
+			buf.WriteRune(ch)
+		}
+		numRunes++
+	}
+	buf.WriteString(strings.Repeat(" ", max(textWidth-numRunes, 0)+2))
+	buf.WriteString(infoColor + info + "\x1b[0m")
+}
+
+// returns the selected command ("" if the picker was cancelled).  if blockId is set the command is
+// sent to the block while the tty is still in raw mode so it isn't echoed before the shell reads it.
+func (p *historyPicker) run(blockId string) (string, error) {
+	stdinFd := int(os.Stdin.Fd())
+	oldState, err := term.MakeRaw(stdinFd)
+	if err != nil {
+		return "", fmt.Errorf("setting terminal to raw mode: %w", err)
+	}
+	defer term.Restore(stdinFd, oldState)
+	os.Stderr.WriteString("\x1b[?1049h\x1b[2J")
+	p.updateMatches()
+	selected, err := p.loop()
+	os.Stderr.WriteString("\x1b[?1049l")
+	if err != nil || selected == "" || blockId == "" {
+		return selected, err
+	}
+	input := makeHistoryInput(selected)
+	inputData := wshrpc.CommandBlockInputData{
+		BlockId:     blockId,
+		InputData64: base64.StdEncoding.EncodeToString([]byte(input)),
+	}
+	err = wshclient.ControllerInputCommand(RpcClient, inputData, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return "", fmt.Errorf("inserting command: %w", err)
+	}
+	waitForTtyInput(stdinFd, len(input))
+	return selected, nil
+}
+
+func (p *historyPicker) loop() (string, error) {
+	buf := make([]byte, 256)
+	for {
+		p.render()
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("reading input: %w", err)
+		}
+		data := buf[:n]
+		for len(data) > 0 {
+			if data[0] == 0x1b {
+				if len(data) == 1 {
+					return "", nil
+				}
+				seqLen := escSeqLen(data)
+				switch string(data[:seqLen]) {
+				case "\x1b[A", "\x1bOA":
+					p.move(-1)
+				case "\x1b[B", "\x1bOB":
+					p.move(1)
+				case "\x1b[5~":
+					p.move(-p.listHeight())
+				case "\x1b[6~":
+					p.move(p.listHeight())
+				}
+				data = data[seqLen:]
+				continue
+			}
+			switch data[0] {
+			case 0x03, 0x07, 0x04: // ctrl-c, ctrl-g, ctrl-d
+				return "", nil
+			case '\r', '\n':
+				if len(p.matches) == 0 {
+					return "", nil
+				}
+				return p.matches[p.selected].Entry.Cmd, nil
+			case 0x10, 0x0b: // ctrl-p, ctrl-k
+				p.move(-1)
+			case 0x0e: // ctrl-n
+				p.move(1)
+			case 0x7f, 0x08:
+				if len(p.query) > 0 {
+					p.query = p.query[:len(p.query)-1]
+					p.updateMatches()
+				}
+			case 0x15: // ctrl-u
+				p.query = nil
+				p.updateMatches()
+			case 0x17: // ctrl-w
+				trimmed := strings.TrimRight(string(p.query), " ")
+				p.query = []rune(trimmed[:strings.LastIndex(trimmed, " ")+1])
+				p.updateMatches()
+			default:
+				ch, size := utf8.DecodeRune(data)
+				if ch >= ' ' && ch != utf8.RuneError {
+					p.query = append(p.query, ch)
+					p.updateMatches()
+				}
+				data = data[size:]
+				continue
+			}
+			data = data[1:]
+		}
+	}
+}
+
+// length of the escape sequence at the start of data (CSI and SS3 sequences, otherwise just the ESC)
+func escSeqLen(data []byte) int {
+	if len(data) < 2 {
+		return 1
+	}
+	if data[1] == 'O' && len(data) >= 3 {
+		return 3
+	}
+	if data[1] != '[' {
+		return 1
+	}
+	for idx := 2; idx < len(data); idx++ {
+		if data[idx] >= 0x40 && data[idx] <= 0x7e {
+			return idx + 1
+		}
+	}
+	return len(data)
+}
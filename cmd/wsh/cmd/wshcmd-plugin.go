@@ -0,0 +1,234 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
+)
+
+const pluginCommandTimeoutMs = 10 * 60 * 1000
+
+var pluginListJson bool
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "manage plugins (executables in <configdir>/plugins)",
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "list installed plugins and their status",
+	Args:    cobra.NoArgs,
+	RunE:    pluginListRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var pluginEnableCmd = &cobra.Command{
+	Use:     "enable [name]",
+	Short:   "enable a plugin (grants the capabilities its manifest asks for)",
+	Args:    cobra.ExactArgs(1),
+	RunE:    pluginSetEnabledRun(true),
+	PreRunE: preRunSetupRpcClient,
+}
+
+var pluginDisableCmd = &cobra.Command{
+	Use:     "disable [name]",
+	Short:   "disable (and stop) a plugin",
+	Args:    cobra.ExactArgs(1),
+	RunE:    pluginSetEnabledRun(false),
+	PreRunE: preRunSetupRpcClient,
+}
+
+var pluginRestartCmd = &cobra.Command{
+	Use:     "restart [name]",
+	Short:   "restart a plugin (re-reading its manifest)",
+	Args:    cobra.ExactArgs(1),
+	RunE:    pluginRestartRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	pluginListCmd.Flags().BoolVar(&pluginListJson, "json", false, "output as json")
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+	pluginCmd.AddCommand(pluginEnableCmd)
+	pluginCmd.AddCommand(pluginDisableCmd)
+	pluginCmd.AddCommand(pluginRestartCmd)
+}
+
+func findPlugin(plugins []wshrpc.PluginInfo, name string) *wshrpc.PluginInfo {
+	for idx := range plugins {
+		if plugins[idx].Name == name {
+			return &plugins[idx]
+		}
+	}
+	return nil
+}
+
+func pluginListRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("plugin", rtnErr == nil)
+	}()
+
+	plugins, err := wshclient.PluginListCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("listing plugins: %w", err)
+	}
+	if pluginListJson {
+		barr, err := json.MarshalIndent(plugins, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding plugins: %w", err)
+		}
+		WriteStdout("%s\n", barr)
+		return nil
+	}
+	if len(plugins) == 0 {
+		WriteStdout("no plugins installed\n")
+		return nil
+	}
+	WriteStdout("%-20s %-10s %-8s %s\n", "NAME", "STATUS", "PID", "WSH COMMANDS")
+	for _, info := range plugins {
+		pid := "-"
+		if info.Pid > 0 {
+			pid = fmt.Sprint(info.Pid)
+		}
+		var wshCmds []string
+		for _, wshCmd := range info.WshCommands {
+			wshCmds = append(wshCmds, wshCmd.Name)
+		}
+		WriteStdout("%-20s %-10s %-8s %s\n", info.Name, info.Status, pid, strings.Join(wshCmds, ", "))
+		if info.Error != "" {
+			WriteStdout("  error: %s\n", info.Error)
+		}
+	}
+	return nil
+}
+
+func pluginSetEnabledRun(enabled bool) RunEFnType {
+	return func(cmd *cobra.Command, args []string) (rtnErr error) {
+		defer func() {
+			sendActivity("plugin", rtnErr == nil)
+		}()
+
+		err := wshclient.PluginSetEnabledCommand(RpcClient, wshrpc.CommandPluginSetEnabledData{Name: args[0], Enabled: enabled}, &wshrpc.RpcOpts{Timeout: 5000})
+		if err != nil {
+			return fmt.Errorf("updating plugin: %w", err)
+		}
+		if !enabled {
+			WriteStdout("plugin %q disabled\n", args[0])
+			return nil
+		}
+		WriteStdout("plugin %q enabled\n", args[0])
+		plugins, err := wshclient.PluginListCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 2000})
+		if err == nil {
+			if info := findPlugin(plugins, args[0]); info != nil {
+				WriteStdout("capabilities: %s\n", strings.Join(info.Capabilities, ", "))
+			}
+		}
+		return nil
+	}
+}
+
+func pluginRestartRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("plugin", rtnErr == nil)
+	}()
+
+	err := wshclient.PluginRestartCommand(RpcClient, args[0], &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("restarting plugin: %w", err)
+	}
+	WriteStdout("plugin %q restarted\n", args[0])
+	return nil
+}
+
+// isUnknownSubcommand reports whether args name a subcommand wsh doesn't know (a candidate for a plugin)
+func isUnknownSubcommand(args []string) bool {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return false
+	}
+	switch args[0] {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
+	}
+	foundCmd, _, err := rootCmd.Find(args)
+	return err != nil && foundCmd == rootCmd
+}
+
+// runPluginCommand dispatches an unknown subcommand to the plugin that registered it.
+// plugin output is streamed to stdout/stderr and the plugin's exit code becomes wsh's.
+func runPluginCommand(args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("plugincmd", rtnErr == nil)
+	}()
+
+	err := preRunSetupRpcClient(nil, args)
+	if err != nil {
+		return err
+	}
+	plugins, err := wshclient.PluginListCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("listing plugins: %w", err)
+	}
+	var pluginInfo *wshrpc.PluginInfo
+	var wshCmd *wshrpc.PluginWshCommand
+	for idx := range plugins {
+		if !plugins[idx].Enabled {
+			continue
+		}
+		for cmdIdx := range plugins[idx].WshCommands {
+			if plugins[idx].WshCommands[cmdIdx].Name == args[0] {
+				pluginInfo = &plugins[idx]
+				wshCmd = &plugins[idx].WshCommands[cmdIdx]
+				break
+			}
+		}
+		if wshCmd != nil {
+			break
+		}
+	}
+	if wshCmd == nil {
+		return fmt.Errorf("unknown command %q for \"wsh\"\nRun 'wsh --help' for usage.", args[0])
+	}
+	cwd, _ := os.Getwd()
+	cmdData := wshrpc.PluginWshCommandData{
+		Args:    args[1:],
+		Cwd:     cwd,
+		BlockId: RpcContext.BlockId,
+	}
+	handler, err := RpcClient.SendComplexRequest(wshCmd.Command, cmdData, &wshrpc.RpcOpts{Route: wshutil.MakePluginRouteId(pluginInfo.Name), Timeout: pluginCommandTimeoutMs})
+	if err != nil {
+		return fmt.Errorf("sending to plugin %q: %w", pluginInfo.Name, err)
+	}
+	for !handler.ResponseDone() {
+		resp, err := handler.NextResponse()
+		if err != nil {
+			return fmt.Errorf("plugin %q: %w", pluginInfo.Name, err)
+		}
+		var output wshrpc.PluginWshCommandOutput
+		err = utilfn.ReUnmarshal(&output, resp)
+		if err != nil {
+			return fmt.Errorf("plugin %q sent a bad response: %w", pluginInfo.Name, err)
+		}
+		if output.Stdout != "" {
+			WriteStdout("%s", output.Stdout)
+		}
+		if output.Stderr != "" {
+			WriteStderr("%s", output.Stderr)
+		}
+		if output.ExitCode != nil {
+			WshExitCode = *output.ExitCode
+		}
+	}
+	return nil
+}
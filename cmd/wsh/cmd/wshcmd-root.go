@@ -236,6 +236,14 @@ func Execute() {
 		}
 	}()
 	rootCmd.PersistentFlags().StringVarP(&blockArg, "block", "b", "", "for commands which require a block id")
+	if isUnknownSubcommand(os.Args[1:]) {
+		err := runPluginCommand(os.Args[1:])
+		if err != nil {
+			WriteStderr("Error: %v\n", err)
+			wshutil.DoShutdown("", 1, true)
+		}
+		return
+	}
 	err := rootCmd.Execute()
 	if err != nil {
 		wshutil.DoShutdown("", 1, true)
@@ -7,22 +7,38 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
 	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
 	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
 )
 
 var setConfigCmd = &cobra.Command{
-	Use:     "setconfig",
+	Use:     "setconfig [--workspace id | --current-workspace] key=value ...",
 	Short:   "set config",
+	Long:    "set config values in settings.json, or in a workspace's settings layer with --workspace id or --current-workspace",
 	Args:    cobra.MinimumNArgs(1),
 	RunE:    setConfigRun,
 	PreRunE: preRunSetupRpcClient,
 }
 
+var setConfigWorkspace string
+var setConfigCurrentWorkspace bool
+
 func init() {
+	setConfigCmd.Flags().StringVar(&setConfigWorkspace, "workspace", "", "set the value for this workspace (id) instead of globally")
+	setConfigCmd.Flags().BoolVar(&setConfigCurrentWorkspace, "current-workspace", false, "set the value for the current workspace instead of globally")
+	setConfigCmd.MarkFlagsMutuallyExclusive("workspace", "current-workspace")
 	rootCmd.AddCommand(setConfigCmd)
 }
 
+// returns the workspace to set the values for ("" to set them globally), as accepted by resolveSimpleId
+func getSetConfigWorkspaceArg() string {
+	if setConfigCurrentWorkspace {
+		return "workspace"
+	}
+	return setConfigWorkspace
+}
+
 func setConfigRun(cmd *cobra.Command, args []string) (rtnErr error) {
 	defer func() {
 		sendActivity("setconfig", rtnErr == nil)
@@ -34,6 +50,22 @@ func setConfigRun(cmd *cobra.Command, args []string) (rtnErr error) {
 		return err
 	}
 	commandData := wshrpc.MetaSettingsType{MetaMapType: meta}
+	if workspaceArg := getSetConfigWorkspaceArg(); workspaceArg != "" {
+		oref, err := resolveSimpleId(workspaceArg)
+		if err != nil {
+			return fmt.Errorf("resolving workspace: %w", err)
+		}
+		if oref.OType != waveobj.OType_Workspace {
+			return fmt.Errorf("%q is not a workspace (got %s)", workspaceArg, oref.OType)
+		}
+		wsData := wshrpc.CommandSetWorkspaceConfigData{WorkspaceId: oref.OID, Meta: commandData}
+		err = wshclient.SetWorkspaceConfigCommand(RpcClient, wsData, &wshrpc.RpcOpts{Timeout: 2000})
+		if err != nil {
+			return fmt.Errorf("setting workspace config: %w", err)
+		}
+		WriteStdout("workspace config set\n")
+		return nil
+	}
 	err = wshclient.SetConfigCommand(RpcClient, commandData, &wshrpc.RpcOpts{Timeout: 2000})
 	if err != nil {
 		return fmt.Errorf("setting config: %w", err)
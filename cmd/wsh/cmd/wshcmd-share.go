@@ -0,0 +1,205 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+var shareWritable bool
+var shareExpires time.Duration
+var shareStopAll bool
+var shareListAll bool
+var shareListJson bool
+
+var shareCmd = &cobra.Command{
+	Use:   "share",
+	Short: "share a terminal's live output over a local web link",
+	Long: `Share the live output of a terminal block with people on your network.  "wsh share start" prints a
+link that opens a read-only viewer in any browser (the link contains the access token, treat it like a
+password).  Shares expire after an hour by default and stop when the terminal is closed.
+
+The share listener uses "share:listen" (default: all interfaces, random port) and links use
+"share:host" (default: the first non-loopback address).  Traffic is plain http, only share on
+networks you trust.`,
+}
+
+var shareStartCmd = &cobra.Command{
+	Use:     "start",
+	Short:   "start sharing the current block (or the block given with -b)",
+	Args:    cobra.NoArgs,
+	RunE:    shareStartRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var shareStopCmd = &cobra.Command{
+	Use:     "stop [SHAREID]",
+	Short:   "stop a share (default: all shares of the current block)",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    shareStopRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var shareListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "list the current block's shares (--all for every block)",
+	Args:    cobra.NoArgs,
+	RunE:    shareListRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	shareStartCmd.Flags().BoolVarP(&shareWritable, "write", "w", false, "let viewers ask for write access (each request must be approved)")
+	shareStartCmd.Flags().DurationVarP(&shareExpires, "expires", "e", time.Hour, "stop sharing after this long (0 for never)")
+	shareStopCmd.Flags().BoolVarP(&shareStopAll, "all", "a", false, "stop all shares of every block")
+	shareListCmd.Flags().BoolVarP(&shareListAll, "all", "a", false, "list the shares of every block")
+	shareListCmd.Flags().BoolVar(&shareListJson, "json", false, "output as json")
+	rootCmd.AddCommand(shareCmd)
+	shareCmd.AddCommand(shareStartCmd)
+	shareCmd.AddCommand(shareStopCmd)
+	shareCmd.AddCommand(shareListCmd)
+}
+
+func resolveShareBlockId() (string, error) {
+	fullORef, err := resolveBlockArg()
+	if err != nil {
+		return "", err
+	}
+	if fullORef.OType != waveobj.OType_Block {
+		return "", fmt.Errorf("%s is not a block", fullORef)
+	}
+	return fullORef.OID, nil
+}
+
+func formatShareExpiry(expiresTs int64) string {
+	if expiresTs == 0 {
+		return "never"
+	}
+	remaining := time.Until(time.UnixMilli(expiresTs)).Round(time.Second)
+	if remaining <= 0 {
+		return "expired"
+	}
+	return "in " + remaining.String()
+}
+
+func shareStartRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("share", rtnErr == nil)
+	}()
+
+	blockId, err := resolveShareBlockId()
+	if err != nil {
+		return err
+	}
+	if shareExpires < 0 {
+		return fmt.Errorf("--expires must not be negative")
+	}
+	data := wshrpc.CommandShareStartData{
+		BlockId:     blockId,
+		Writable:    shareWritable,
+		ExpiresSecs: int(shareExpires.Seconds()),
+	}
+	if shareExpires == 0 {
+		data.ExpiresSecs = -1
+	} else if data.ExpiresSecs == 0 {
+		data.ExpiresSecs = 1
+	}
+	info, err := wshclient.ShareStartCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("starting share: %w", err)
+	}
+	access := "read-only"
+	if info.Writable {
+		access = "viewers may request write access"
+	}
+	WriteStdout("%s\n", info.Url)
+	WriteStderr("sharing block %s (%s, expires %s), stop with: wsh share stop %s\n", blockId[:8], access, formatShareExpiry(info.ExpiresTs), info.ShareId)
+	return nil
+}
+
+func shareStopRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("share", rtnErr == nil)
+	}()
+
+	var stopReqs []wshrpc.CommandShareStopData
+	if len(args) > 0 {
+		stopReqs = append(stopReqs, wshrpc.CommandShareStopData{ShareId: args[0]})
+	} else if shareStopAll {
+		shares, err := wshclient.ShareListCommand(RpcClient, wshrpc.CommandShareListData{}, &wshrpc.RpcOpts{Timeout: 2000})
+		if err != nil {
+			return fmt.Errorf("listing shares: %w", err)
+		}
+		for _, info := range shares {
+			stopReqs = append(stopReqs, wshrpc.CommandShareStopData{ShareId: info.ShareId})
+		}
+	} else {
+		blockId, err := resolveShareBlockId()
+		if err != nil {
+			return err
+		}
+		stopReqs = append(stopReqs, wshrpc.CommandShareStopData{BlockId: blockId})
+	}
+	var numStopped int
+	for _, stopReq := range stopReqs {
+		num, err := wshclient.ShareStopCommand(RpcClient, stopReq, &wshrpc.RpcOpts{Timeout: 2000})
+		if err != nil {
+			return fmt.Errorf("stopping share: %w", err)
+		}
+		numStopped += num
+	}
+	if numStopped == 0 {
+		WriteStdout("no shares to stop\n")
+		return nil
+	}
+	WriteStdout("stopped %d share(s)\n", numStopped)
+	return nil
+}
+
+func shareListRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("share", rtnErr == nil)
+	}()
+
+	var data wshrpc.CommandShareListData
+	if !shareListAll {
+		blockId, err := resolveShareBlockId()
+		if err != nil {
+			return err
+		}
+		data.BlockId = blockId
+	}
+	shares, err := wshclient.ShareListCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("listing shares: %w", err)
+	}
+	if shareListJson {
+		barr, err := json.MarshalIndent(shares, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding shares: %w", err)
+		}
+		WriteStdout("%s\n", barr)
+		return nil
+	}
+	if len(shares) == 0 {
+		WriteStdout("no active shares\n")
+		return nil
+	}
+	WriteStdout("%-36s %-8s %-7s %-7s %-12s %s\n", "SHAREID", "BLOCK", "ACCESS", "VIEWERS", "EXPIRES", "URL")
+	for _, info := range shares {
+		access := "read"
+		if info.Writable {
+			access = "write"
+		}
+		WriteStdout("%-36s %-8s %-7s %-7d %-12s %s\n", info.ShareId, info.BlockId[:8], access, info.Viewers, formatShareExpiry(info.ExpiresTs), info.Url)
+	}
+	return nil
+}
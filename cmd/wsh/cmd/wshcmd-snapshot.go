@@ -0,0 +1,157 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+var snapshotWorkspace string
+var snapshotListJson bool
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "save and restore workspace snapshots",
+	Long: `Snapshots capture a workspace's tabs, layout, block settings, each terminal's working directory,
+connection and recent output.  Restoring opens the tabs again (in the current workspace), reconnects
+and starts each shell in its saved directory below the old scrollback.
+
+A snapshot is also taken automatically when a workspace is closed (the newest 10 are kept).`,
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "list snapshots",
+	Args:    cobra.NoArgs,
+	RunE:    snapshotListRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:     "create [name]",
+	Short:   "snapshot the current workspace",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    snapshotCreateRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:     "restore [id|name]",
+	Short:   "restore a snapshot's tabs into the current workspace",
+	Args:    cobra.ExactArgs(1),
+	RunE:    snapshotRestoreRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:     "delete [id|name]",
+	Short:   "delete a snapshot",
+	Args:    cobra.ExactArgs(1),
+	RunE:    snapshotDeleteRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	snapshotListCmd.Flags().BoolVar(&snapshotListJson, "json", false, "output as json")
+	for _, cmd := range []*cobra.Command{snapshotCreateCmd, snapshotRestoreCmd} {
+		cmd.Flags().StringVar(&snapshotWorkspace, "workspace", "workspace", "workspace id (defaults to the current workspace)")
+	}
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotDeleteCmd)
+}
+
+func snapshotListRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("snapshot", rtnErr == nil)
+	}()
+
+	snapshots, err := wshclient.SnapshotListCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("listing snapshots: %w", err)
+	}
+	if snapshotListJson {
+		barr, err := json.MarshalIndent(snapshots, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding snapshots: %w", err)
+		}
+		WriteStdout("%s\n", barr)
+		return nil
+	}
+	if len(snapshots) == 0 {
+		WriteStdout("no snapshots\n")
+		return nil
+	}
+	WriteStdout("%-8s %-24s %-19s %-5s %s\n", "ID", "NAME", "CREATED", "TABS", "BLOCKS")
+	for _, snap := range snapshots {
+		created := time.UnixMilli(snap.CreatedTs).Format(time.DateTime)
+		auto := ""
+		if snap.Auto {
+			auto = "(auto)"
+		}
+		WriteStdout("%-8s %-24s %-19s %-5d %-6d %s\n", snap.SnapshotId[:8], snap.Name, created, snap.NumTabs, snap.NumBlocks, auto)
+	}
+	return nil
+}
+
+func snapshotCreateRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("snapshot", rtnErr == nil)
+	}()
+
+	workspaceId, err := resolveWorkspaceArg(snapshotWorkspace)
+	if err != nil {
+		return err
+	}
+	data := wshrpc.CommandSnapshotData{WorkspaceId: workspaceId}
+	if len(args) > 0 {
+		data.Name = args[0]
+	}
+	info, err := wshclient.SnapshotCreateCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 10000})
+	if err != nil {
+		return fmt.Errorf("creating snapshot: %w", err)
+	}
+	WriteStdout("snapshot %q created (id %s, %d tabs, %d blocks)\n", info.Name, info.SnapshotId[:8], info.NumTabs, info.NumBlocks)
+	return nil
+}
+
+func snapshotRestoreRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("snapshot", rtnErr == nil)
+	}()
+
+	workspaceId, err := resolveWorkspaceArg(snapshotWorkspace)
+	if err != nil {
+		return err
+	}
+	data := wshrpc.CommandSnapshotData{WorkspaceId: workspaceId, SnapshotId: args[0]}
+	tabIds, err := wshclient.SnapshotRestoreCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 10000})
+	if err != nil {
+		return fmt.Errorf("restoring snapshot: %w", err)
+	}
+	WriteStdout("restored %d tab(s)\n", len(tabIds))
+	return nil
+}
+
+func snapshotDeleteRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("snapshot", rtnErr == nil)
+	}()
+
+	data := wshrpc.CommandSnapshotData{SnapshotId: args[0]}
+	err := wshclient.SnapshotDeleteCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("deleting snapshot: %w", err)
+	}
+	WriteStdout("snapshot deleted\n")
+	return nil
+}
@@ -50,6 +50,7 @@ func sshRun(cmd *cobra.Command, args []string) (rtnErr error) {
 		Keywords: wconfig.ConnKeywords{
 			SshIdentityFile: identityFiles,
 		},
+		CliPrompts: enableCliPrompts(),
 	}
 	wshclient.ConnConnectCommand(RpcClient, connOpts, &wshrpc.RpcOpts{Timeout: 60000})
 
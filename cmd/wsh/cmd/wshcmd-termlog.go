@@ -0,0 +1,120 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+var termLogFile string
+var termLogRaw bool
+var termLogNoTimestamps bool
+
+var termLogCmd = &cobra.Command{
+	Use:   "log [on|off|status]",
+	Short: "log a terminal's output to a file",
+	Long: `Turn logging of the current block's output (or the block given with -b) on or off.  With no
+argument logging is toggled.  Logs go to <data dir>/termlogs/<blockid>.log unless --file is given
+(or "term:logdir" is set).  Escape sequences are stripped and each line is timestamped unless --raw
+or --no-timestamps is given.
+
+Logs are rotated when they reach "term:logmaxsizemb" (default 10) or are older than
+"term:logrotatehours" (default off).  Rotated logs are gzipped and the newest "term:logkeep"
+(default 5) are kept.  Logging can be turned on for every terminal with "term:log" in settings.json.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"on", "off", "status"},
+	RunE:      termLogRun,
+	PreRunE:   preRunSetupRpcClient,
+}
+
+func init() {
+	termLogCmd.Flags().StringVar(&termLogFile, "file", "", "log file in the logs dir (relative to it)")
+	termLogCmd.Flags().BoolVar(&termLogRaw, "raw", false, "keep escape sequences (raw pty output)")
+	termLogCmd.Flags().BoolVar(&termLogNoTimestamps, "no-timestamps", false, "do not prefix lines with a timestamp")
+	termCmd.AddCommand(termLogCmd)
+}
+
+func printTermLogStatus(status *wshrpc.TermLogStatusData) {
+	if !status.Enabled {
+		WriteStdout("logging is off (%s)\n", status.Path)
+		return
+	}
+	format := "text"
+	if !status.StripAnsi {
+		format = "raw"
+	}
+	if status.Timestamps {
+		format += ", timestamped"
+	}
+	WriteStdout("logging to %s (%s, %d bytes)\n", status.Path, format, status.Size)
+	if status.Dropped > 0 {
+		WriteStdout("%d chunks of output were dropped (the system was busy)\n", status.Dropped)
+	}
+}
+
+func termLogRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("term:log", rtnErr == nil)
+	}()
+
+	fullORef, err := resolveBlockArg()
+	if err != nil {
+		return err
+	}
+	if fullORef.OType != waveobj.OType_Block {
+		return fmt.Errorf("%s is not a block", fullORef)
+	}
+	status, err := wshclient.TermLogStatusCommand(RpcClient, fullORef.OID, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("getting log status: %w", err)
+	}
+	action := "toggle"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	var enable bool
+	switch action {
+	case "status":
+		printTermLogStatus(status)
+		return nil
+	case "on":
+		enable = true
+	case "off":
+		enable = false
+	case "toggle":
+		enable = !status.Enabled
+	default:
+		return fmt.Errorf("invalid argument %q (expected on, off or status)", action)
+	}
+	meta := waveobj.MetaMapType{waveobj.MetaKey_TermLog: enable}
+	if enable {
+		if cmd.Flags().Changed("file") {
+			meta[waveobj.MetaKey_TermLogFile] = termLogFile
+		}
+		if cmd.Flags().Changed("raw") {
+			meta[waveobj.MetaKey_TermLogStripAnsi] = !termLogRaw
+		}
+		if cmd.Flags().Changed("no-timestamps") {
+			meta[waveobj.MetaKey_TermLogTimestamps] = !termLogNoTimestamps
+		}
+	}
+	err = wshclient.SetMetaCommand(RpcClient, wshrpc.CommandSetMetaData{ORef: *fullORef, Meta: meta}, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("updating block: %w", err)
+	}
+	status, err = wshclient.TermLogStatusCommand(RpcClient, fullORef.OID, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("getting log status: %w", err)
+	}
+	if enable && !status.Enabled {
+		return fmt.Errorf("block is not a terminal")
+	}
+	printTermLogStatus(status)
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/termtheme"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+var themeFormat string
+var themeImportName string
+var themeImportKey string
+var themeImportForce bool
+var themeExportOutput string
+
+var themeCmd = &cobra.Command{
+	Use:   "theme",
+	Short: "import and export terminal themes",
+	Long:  "Convert terminal color schemes to and from iTerm2 (.itermcolors), Windows Terminal (JSON), base16 (YAML) and Alacritty (TOML/YAML) formats",
+}
+
+var themeImportCmd = &cobra.Command{
+	Use:     "import [file]",
+	Short:   "import a color scheme into termthemes.json",
+	Args:    cobra.ExactArgs(1),
+	RunE:    themeImportRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var themeExportCmd = &cobra.Command{
+	Use:     "export [theme]",
+	Short:   "export a terminal theme to another terminal's format",
+	Args:    cobra.ExactArgs(1),
+	RunE:    themeExportRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	formatList := strings.Join(termtheme.AllFormats, ", ")
+	themeImportCmd.Flags().StringVar(&themeFormat, "format", "", "source format ("+formatList+"), detected from the file if not set")
+	themeImportCmd.Flags().StringVar(&themeImportName, "name", "", "display name for the imported theme")
+	themeImportCmd.Flags().StringVar(&themeImportKey, "key", "", "key to store the theme under in termthemes.json (defaults to a slug of the name)")
+	themeImportCmd.Flags().BoolVarP(&themeImportForce, "force", "f", false, "replace an existing theme with the same key")
+	themeExportCmd.Flags().StringVar(&themeFormat, "format", termtheme.Format_ITerm2, "target format ("+formatList+")")
+	themeExportCmd.Flags().StringVarP(&themeExportOutput, "output", "o", "", "write to file instead of stdout")
+	rootCmd.AddCommand(themeCmd)
+	themeCmd.AddCommand(themeImportCmd)
+	themeCmd.AddCommand(themeExportCmd)
+}
+
+func themeImportRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("theme", rtnErr == nil)
+	}()
+
+	fileName := args[0]
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("reading theme file: %w", err)
+	}
+	format := themeFormat
+	if format == "" {
+		format, err = termtheme.DetectFormat(fileName, data)
+		if err != nil {
+			return err
+		}
+	}
+	defaultName := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	themes, err := termtheme.Parse(format, data, defaultName)
+	if err != nil {
+		return fmt.Errorf("parsing %s theme: %w", format, err)
+	}
+	if len(themes) > 1 && (themeImportName != "" || themeImportKey != "") {
+		return fmt.Errorf("%s contains %d schemes, --name and --key can only be used with a single scheme", fileName, len(themes))
+	}
+
+	fullConfig, err := wshclient.GetFullConfigCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("getting config: %w", err)
+	}
+	var maxOrder float64
+	for _, theme := range fullConfig.TermThemes {
+		maxOrder = max(maxOrder, theme.DisplayOrder)
+	}
+	for _, named := range themes {
+		theme := named.Theme
+		if themeImportName != "" {
+			theme.DisplayName = themeImportName
+		}
+		themeKey := themeImportKey
+		if themeKey == "" {
+			themeKey = termtheme.MakeThemeKey(theme.DisplayName)
+		}
+		if themeKey == "" {
+			return fmt.Errorf("cannot derive a theme key from %q, use --key", theme.DisplayName)
+		}
+		if _, exists := fullConfig.TermThemes[themeKey]; exists && !themeImportForce {
+			return fmt.Errorf("theme %q already exists (use --force to replace it or --key to pick another key)", themeKey)
+		}
+		maxOrder++
+		theme.DisplayOrder = maxOrder
+		err = wshclient.SetTermThemeCommand(RpcClient, wshrpc.CommandSetTermThemeData{ThemeKey: themeKey, Theme: theme}, &wshrpc.RpcOpts{Timeout: 2000})
+		if err != nil {
+			return fmt.Errorf("saving theme %q: %w", themeKey, err)
+		}
+		WriteStdout("imported %q as %s\n", theme.DisplayName, themeKey)
+	}
+	return nil
+}
+
+func findTermTheme(themes map[string]wconfig.TermThemeType, nameOrKey string) (string, wconfig.TermThemeType, bool) {
+	if theme, ok := themes[nameOrKey]; ok {
+		return nameOrKey, theme, true
+	}
+	for key, theme := range themes {
+		if strings.EqualFold(theme.DisplayName, nameOrKey) {
+			return key, theme, true
+		}
+	}
+	return "", wconfig.TermThemeType{}, false
+}
+
+func themeExportRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("theme", rtnErr == nil)
+	}()
+
+	fullConfig, err := wshclient.GetFullConfigCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("getting config: %w", err)
+	}
+	themeKey, theme, ok := findTermTheme(fullConfig.TermThemes, args[0])
+	if !ok {
+		return fmt.Errorf("theme %q not found", args[0])
+	}
+	name := theme.DisplayName
+	if name == "" {
+		name = themeKey
+	}
+	out, err := termtheme.Format(themeFormat, name, theme)
+	if err != nil {
+		return fmt.Errorf("converting theme: %w", err)
+	}
+	if themeExportOutput == "" {
+		WriteStdout("%s", out)
+		return nil
+	}
+	err = os.WriteFile(themeExportOutput, out, 0644)
+	if err != nil {
+		return fmt.Errorf("writing theme file: %w", err)
+	}
+	WriteStderr("exported %s to %s\n", themeKey, themeExportOutput)
+	return nil
+}
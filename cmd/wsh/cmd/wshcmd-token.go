@@ -4,24 +4,68 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/SalyyS1/SLTerm/pkg/util/shellutil"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
 )
 
+var tokenCreateCommands []string
+var tokenListJson bool
+
+// "wsh token [token] [shell-type]" (swap token exchange during shell startup) is used internally,
+// the subcommands manage api tokens for the local http gateway ("gateway:enabled").
 var tokenCmd = &cobra.Command{
-	Use:    "token [token] [shell-type]",
-	Short:  "exchange token for shell initialization script",
-	RunE:   tokenCmdRun,
-	Hidden: true,
+	Use:   "token",
+	Short: "manage api tokens for the local http gateway",
+	RunE:  tokenCmdRun,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "create an api token (printed once)",
+	Long: `Create a long-lived api token for the local http gateway.  --command limits the token to the given
+wshrpc commands (repeatable), use --command '*' to allow every gateway command.`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    tokenCreateRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "list api tokens",
+	Args:    cobra.NoArgs,
+	RunE:    tokenListRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:     "revoke [id|name]",
+	Short:   "revoke an api token",
+	Args:    cobra.ExactArgs(1),
+	RunE:    tokenRevokeRun,
+	PreRunE: preRunSetupRpcClient,
 }
 
 func init() {
+	tokenCreateCmd.Flags().StringArrayVarP(&tokenCreateCommands, "command", "c", nil, "command the token may call (repeatable, '*' for all)")
+	tokenListCmd.Flags().BoolVar(&tokenListJson, "json", false, "output as json")
 	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenListCmd)
+	tokenCmd.AddCommand(tokenRevokeCmd)
 }
 
 func tokenCmdRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	if len(args) == 0 {
+		OutputHelpMessage(cmd)
+		return nil
+	}
 	if len(args) != 2 {
 		OutputHelpMessage(cmd)
 		return fmt.Errorf("wsh token requires exactly 2 arguments, got %d", len(args))
@@ -43,3 +87,67 @@ func tokenCmdRun(cmd *cobra.Command, args []string) (rtnErr error) {
 	WriteStdout("%s\n", rtnData.InitScriptText)
 	return nil
 }
+
+func tokenCreateRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("token", rtnErr == nil)
+	}()
+
+	if len(tokenCreateCommands) == 0 {
+		return fmt.Errorf("at least one --command is required (use --command '*' to allow all gateway commands)")
+	}
+	data := wshrpc.CommandApiTokenCreateData{Name: args[0], Commands: tokenCreateCommands}
+	rtn, err := wshclient.ApiTokenCreateCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("creating token: %w", err)
+	}
+	WriteStdout("%s\n", rtn.Token)
+	WriteStderr("token %q created (id %s), it will not be shown again\n", rtn.Name, rtn.Id)
+	return nil
+}
+
+func tokenListRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("token", rtnErr == nil)
+	}()
+
+	tokens, err := wshclient.ApiTokenListCommand(RpcClient, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("listing tokens: %w", err)
+	}
+	if tokenListJson {
+		barr, err := json.MarshalIndent(tokens, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding tokens: %w", err)
+		}
+		WriteStdout("%s\n", barr)
+		return nil
+	}
+	if len(tokens) == 0 {
+		WriteStdout("no api tokens\n")
+		return nil
+	}
+	WriteStdout("%-36s %-20s %-19s %-19s %s\n", "ID", "NAME", "CREATED", "LAST USED", "COMMANDS")
+	for _, token := range tokens {
+		lastUsed := "-"
+		if token.LastUsedTs > 0 {
+			lastUsed = time.UnixMilli(token.LastUsedTs).Format(time.DateTime)
+		}
+		created := time.UnixMilli(token.CreatedTs).Format(time.DateTime)
+		WriteStdout("%-36s %-20s %-19s %-19s %s\n", token.Id, token.Name, created, lastUsed, strings.Join(token.Commands, ","))
+	}
+	return nil
+}
+
+func tokenRevokeRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("token", rtnErr == nil)
+	}()
+
+	err := wshclient.ApiTokenRevokeCommand(RpcClient, args[0], &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("revoking token: %w", err)
+	}
+	WriteStdout("token %q revoked\n", args[0])
+	return nil
+}
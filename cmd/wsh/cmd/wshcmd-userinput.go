@@ -0,0 +1,194 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"golang.org/x/term"
+)
+
+// serves prompts (ssh passwords, host key confirmations, ...) that the server routes back to this
+// wsh process when it has no frontend window to show them in.  see userinput.CliProvider.
+type cliPromptServerImpl struct{}
+
+func (*cliPromptServerImpl) WshServerImpl() {}
+
+var cliPromptLock = &sync.Mutex{}
+
+// stdin is read by a single goroutine (started by the first prompt) that hands the chunks to the
+// prompts over stdinCh.  a prompt that times out leaves no reader of its own behind that would take
+// the answer to the next prompt (or the tty state) with it.
+var stdinReaderOnce sync.Once
+var stdinCh chan []byte
+
+func startStdinReader() {
+	stdinReaderOnce.Do(func() {
+		stdinCh = make(chan []byte, 16)
+		go func() {
+			defer close(stdinCh)
+			buf := make([]byte, 1024)
+			for {
+				n, err := os.Stdin.Read(buf)
+				if n > 0 {
+					stdinCh <- bytes.Clone(buf[:n])
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+	})
+}
+
+// drops input that was typed while no prompt was waiting
+func discardStaleInput() {
+	for {
+		select {
+		case _, ok := <-stdinCh:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// enableCliPrompts registers this process to answer prompts on its tty.
+// returns false (and does nothing) if stdin is not a terminal.
+func enableCliPrompts() bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	RpcClient.SetServerImpl(&cliPromptServerImpl{})
+	return true
+}
+
+func stripPromptMarkdown(text string) string {
+	text = strings.ReplaceAll(text, "**", "")
+	return strings.ReplaceAll(text, "  \n", "\n")
+}
+
+var errPromptCancelled = errors.New("cancelled")
+
+// adds a chunk of tty input to line.  hidden input is read in raw mode, so it handles backspace and
+// ctrl-c itself.  returns true once the line is complete (the rest of the chunk is dropped).
+func appendPromptInput(line []byte, chunk []byte, hidden bool) ([]byte, bool, error) {
+	for _, b := range chunk {
+		switch {
+		case b == '\r' || b == '\n':
+			return line, true, nil
+		case hidden && b == 0x03:
+			return nil, true, errPromptCancelled
+		case hidden && (b == 0x7f || b == 0x08):
+			if len(line) > 0 {
+				_, size := utf8.DecodeLastRune(line)
+				line = line[:len(line)-size]
+			}
+		default:
+			line = append(line, b)
+		}
+	}
+	return line, false, nil
+}
+
+// reads a line from the tty, hidden input isn't echoed.  the tty is always restored and the prompt
+// can be abandoned when ctx times out.
+func readPromptLine(ctx context.Context, hidden bool) (string, error) {
+	newLine := "\n"
+	if hidden {
+		fd := int(os.Stdin.Fd())
+		oldState, err := term.MakeRaw(fd)
+		if err != nil {
+			return "", fmt.Errorf("cannot turn off echo: %w", err)
+		}
+		defer term.Restore(fd, oldState)
+		newLine = "\r\n"
+	}
+	startStdinReader()
+	discardStaleInput()
+	var line []byte
+	for {
+		select {
+		case chunk, ok := <-stdinCh:
+			if !ok {
+				fmt.Fprint(os.Stderr, newLine)
+				return "", io.EOF
+			}
+			var done bool
+			var err error
+			line, done, err = appendPromptInput(line, chunk, hidden)
+			if done {
+				if hidden {
+					fmt.Fprint(os.Stderr, newLine)
+				}
+				return string(line), err
+			}
+		case <-ctx.Done():
+			fmt.Fprint(os.Stderr, newLine)
+			return "", fmt.Errorf("timed out waiting for input")
+		}
+	}
+}
+
+func readPromptYesNo(ctx context.Context, question string, okLabel string, cancelLabel string) (bool, error) {
+	if okLabel == "" {
+		okLabel = "yes"
+	}
+	if cancelLabel == "" {
+		cancelLabel = "no"
+	}
+	fmt.Fprintf(os.Stderr, "%s [y=%s / n=%s]: ", question, okLabel, cancelLabel)
+	line, err := readPromptLine(ctx, false)
+	if err != nil {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes" || answer == strings.ToLower(okLabel), nil
+}
+
+func (*cliPromptServerImpl) CliUserInputCommand(ctx context.Context, data wshrpc.CommandCliUserInputData) (*wshrpc.CliUserInputRtnData, error) {
+	cliPromptLock.Lock()
+	defer cliPromptLock.Unlock()
+
+	queryText := data.QueryText
+	if data.Markdown {
+		queryText = stripPromptMarkdown(queryText)
+	}
+	fmt.Fprintf(os.Stderr, "\n")
+	if data.Title != "" {
+		fmt.Fprintf(os.Stderr, "[%s]\n", data.Title)
+	}
+	rtn := &wshrpc.CliUserInputRtnData{}
+	var err error
+	switch data.ResponseType {
+	case "confirm":
+		fmt.Fprintf(os.Stderr, "%s\n", queryText)
+		rtn.Confirm, err = readPromptYesNo(ctx, "continue?", data.OkLabel, data.CancelLabel)
+	default:
+		fmt.Fprintf(os.Stderr, "%s\n> ", queryText)
+		rtn.Text, err = readPromptLine(ctx, !data.PublicText)
+		rtn.Confirm = err == nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data.CheckBoxMsg != "" && rtn.Confirm {
+		rtn.CheckboxStat, err = readPromptYesNo(ctx, data.CheckBoxMsg, "", "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rtn, nil
+}
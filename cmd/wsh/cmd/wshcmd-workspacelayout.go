@@ -0,0 +1,194 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+var workspaceLayoutWorkspace string
+var workspaceLayoutParams []string
+var workspaceSaveOutput string
+var workspaceSaveForce bool
+
+var workspaceApplyCommand = &cobra.Command{
+	Use:   "apply FILE|NAME",
+	Short: "Open the tabs described by a layout file",
+	Long: `Open the tabs described by a layout file (json or yaml) in the current workspace.
+
+FILE is read locally.  If no such file exists, NAME is looked up in the layouts directory
+of the config dir (layouts/NAME.json, .yaml or .yml).
+
+Strings in the file may reference params as ${name} (use $$ for a literal $).  Params are set
+with --param name=value and default to the file's "params" section.  ${cwd} defaults to the
+current directory and ${layoutdir} to the directory containing FILE.
+
+Example layout:
+
+  tabs:
+    - name: dev
+      layout:
+        children:
+          - cwd: ${project}
+            cmd: npm run dev
+            size: 2
+          - children:
+              - cwd: ${project}
+              - view: preview
+                meta: { file: "${project}" }`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    workspaceApplyRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+var workspaceSaveCommand = &cobra.Command{
+	Use:   "save [NAME]",
+	Short: "Save the current workspace's tabs as a layout file",
+	Long: `Save the current workspace's tabs, splits, sizes, views, connections and working
+directories as a layout file.  With NAME the file is written to layouts/NAME.json in the
+config dir, with -o it is written to a local file (- for stdout).
+
+--param name=value replaces occurrences of value with ${name} so the file can be shared,
+e.g. --param project=$PWD.`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    workspaceSaveRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{workspaceApplyCommand, workspaceSaveCommand} {
+		cmd.Flags().StringVar(&workspaceLayoutWorkspace, "workspace", "workspace", "workspace id (defaults to the current workspace)")
+		cmd.Flags().StringArrayVarP(&workspaceLayoutParams, "param", "p", nil, "layout param as name=value (repeatable)")
+		workspaceCommand.AddCommand(cmd)
+	}
+	workspaceSaveCommand.Flags().StringVarP(&workspaceSaveOutput, "output", "o", "", "write the layout to a local file instead (- for stdout)")
+	workspaceSaveCommand.Flags().BoolVarP(&workspaceSaveForce, "force", "f", false, "overwrite an existing layout")
+}
+
+func parseLayoutParams(params []string) (map[string]string, error) {
+	rtn := make(map[string]string)
+	for _, param := range params {
+		name, val, ok := strings.Cut(param, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid param %q, expected name=value", param)
+		}
+		rtn[name] = val
+	}
+	return rtn, nil
+}
+
+// resolves a workspace id ("workspace" is the current workspace)
+func resolveWorkspaceArg(workspaceArg string) (string, error) {
+	oref, err := resolveSimpleId(workspaceArg)
+	if err != nil {
+		return "", fmt.Errorf("resolving workspace: %w", err)
+	}
+	if oref.OType != waveobj.OType_Workspace {
+		return "", fmt.Errorf("%q is not a workspace (got %s)", workspaceArg, oref.OType)
+	}
+	return oref.OID, nil
+}
+
+func workspaceApplyRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("workspace", rtnErr == nil)
+	}()
+	params, err := parseLayoutParams(workspaceLayoutParams)
+	if err != nil {
+		return err
+	}
+	workspaceId, err := resolveWorkspaceArg(workspaceLayoutWorkspace)
+	if err != nil {
+		return err
+	}
+	defaultParams := make(map[string]string)
+	if cwd, err := os.Getwd(); err == nil {
+		defaultParams["cwd"] = cwd
+	}
+	data := wshrpc.CommandWorkspaceApplyLayoutData{WorkspaceId: workspaceId}
+	content, err := os.ReadFile(args[0])
+	if err == nil {
+		data.Content = string(content)
+		if absPath, err := filepath.Abs(args[0]); err == nil {
+			defaultParams["layoutdir"] = filepath.Dir(absPath)
+		}
+	} else if os.IsNotExist(err) && !strings.ContainsAny(args[0], `/\`) {
+		data.Name = args[0]
+		switch filepath.Ext(args[0]) {
+		case ".json", ".yaml", ".yml":
+			data.Name = strings.TrimSuffix(args[0], filepath.Ext(args[0]))
+		}
+	} else {
+		return fmt.Errorf("reading layout file: %w", err)
+	}
+	for name, val := range defaultParams {
+		if _, ok := params[name]; !ok {
+			params[name] = val
+		}
+	}
+	data.Params = params
+	tabIds, err := wshclient.WorkspaceApplyLayoutCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 10000})
+	if err != nil {
+		return fmt.Errorf("applying layout: %w", err)
+	}
+	WriteStdout("opened %d tab(s)\n", len(tabIds))
+	return nil
+}
+
+func workspaceSaveRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("workspace", rtnErr == nil)
+	}()
+	if len(args) == 0 && workspaceSaveOutput == "" {
+		return fmt.Errorf("a layout NAME or --output is required")
+	}
+	if len(args) > 0 && workspaceSaveOutput != "" {
+		return fmt.Errorf("cannot use both a layout NAME and --output")
+	}
+	params, err := parseLayoutParams(workspaceLayoutParams)
+	if err != nil {
+		return err
+	}
+	workspaceId, err := resolveWorkspaceArg(workspaceLayoutWorkspace)
+	if err != nil {
+		return err
+	}
+	data := wshrpc.CommandWorkspaceSaveLayoutData{
+		WorkspaceId: workspaceId,
+		Params:      params,
+		Overwrite:   workspaceSaveForce,
+	}
+	if len(args) > 0 {
+		data.Name = args[0]
+	}
+	rtn, err := wshclient.WorkspaceSaveLayoutCommand(RpcClient, data, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("saving layout: %w", err)
+	}
+	if workspaceSaveOutput == "-" {
+		WriteStdout("%s", rtn.Content)
+		return nil
+	}
+	if workspaceSaveOutput != "" {
+		if _, err := os.Stat(workspaceSaveOutput); err == nil && !workspaceSaveForce {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", workspaceSaveOutput)
+		}
+		err = os.WriteFile(workspaceSaveOutput, []byte(rtn.Content), 0644)
+		if err != nil {
+			return fmt.Errorf("writing layout file: %w", err)
+		}
+		WriteStdout("layout saved to %s\n", workspaceSaveOutput)
+		return nil
+	}
+	WriteStdout("layout saved to %s\n", rtn.Path)
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/SalyyS1/SLTerm/pkg/util/shellutil"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"golang.org/x/term"
+)
+
+var zList bool
+var zPrint bool
+var zConn string
+var zJson bool
+var zLimit int
+
+var zCmd = &cobra.Command{
+	Use:   "z [flags] KEYWORD...",
+	Short: "jump to a frequently and recently used directory",
+	Long: `Jump to the best matching directory you have visited on this connection.  Visited directories
+are recorded from the cwd reported by shell integration and ranked by frecency (how often and how
+recently you went there).  Every keyword must appear in the path, in order and case-insensitive,
+and the last one must match the last path component.  The current directory is skipped.
+
+The current block's shell (or the block given with -b) is sent a cd command, use --print to print
+the directory instead (e.g. cd "$(wsh z -p src)").  Without keywords the ranked list is printed.
+On ssh/wsl/tls connections only that connection's directories can be read, and the default remote
+capabilities don't allow sending input to a block, use cd "$(wsh z -p KEYWORD)" there (or add
+"controllerinput" to "wsh:remotecapabilities").
+
+  wsh z slterm       # cd to the best match for "slterm"
+  wsh z src api      # a path containing "src" followed by "api"
+  wsh z -l proj      # list the matches with their scores`,
+	Args:    cobra.ArbitraryArgs,
+	RunE:    zRun,
+	PreRunE: preRunSetupRpcClient,
+}
+
+func init() {
+	zCmd.Flags().BoolVarP(&zList, "list", "l", false, "list the matching directories (best first)")
+	zCmd.Flags().BoolVarP(&zPrint, "print", "p", false, "print the best match instead of changing to it")
+	zCmd.Flags().StringVar(&zConn, "conn", "", "directories visited on this connection (default: the connection wsh runs on, \"local\" for local)")
+	zCmd.Flags().BoolVar(&zJson, "json", false, "with --list, output as json")
+	zCmd.Flags().IntVarP(&zLimit, "limit", "n", 20, "with --list, max number of directories to print")
+	rootCmd.AddCommand(zCmd)
+}
+
+// the cd command for the block's shell, it starts with a space so it stays out of the shell's history
+// (and wsh history)
+func makeCdCommand(shellType string, dir string) (string, error) {
+	if strings.ContainsAny(dir, "\r\n") {
+		return "", fmt.Errorf("cannot change to a directory with a newline in its name")
+	}
+	var quoted string
+	var cdCmd string
+	switch shellType {
+	case "pwsh", "powershell":
+		quoted, cdCmd = shellutil.HardQuotePowerShell(dir), "Set-Location -LiteralPath "
+	case "fish":
+		quoted, cdCmd = shellutil.HardQuoteFish(dir), "cd "
+	default:
+		quoted, cdCmd = shellutil.HardQuote(dir), "cd -- "
+	}
+	if quoted == "" {
+		return "", fmt.Errorf("directory name is too long")
+	}
+	return " " + cdCmd + quoted + "\r", nil
+}
+
+// returns the best match that still exists, entries for directories that are gone are removed
+func zFindDir(connName string, keywords []string) (string, error) {
+	if len(keywords) == 1 {
+		if finfo, err := os.Stat(keywords[0]); err == nil && finfo.IsDir() {
+			return filepath.Abs(keywords[0])
+		}
+	}
+	queryData := wshrpc.CommandDirFrecencyQueryData{Connection: connName, Keywords: keywords, MaxItems: 50}
+	queryData.Exclude, _ = os.Getwd()
+	dirs, err := wshclient.DirFrecencyQueryCommand(RpcClient, queryData, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return "", fmt.Errorf("reading directories: %w", err)
+	}
+	// directories of another connection can't be checked from here
+	canStat := connName == RpcContext.Conn || (connName == "local" && RpcContext.Conn == "")
+	for _, dir := range dirs {
+		if canStat {
+			if finfo, err := os.Stat(dir.Path); err != nil || !finfo.IsDir() {
+				removeData := wshrpc.CommandDirFrecencyRemoveData{Connection: connName, Path: dir.Path}
+				wshclient.DirFrecencyRemoveCommand(RpcClient, removeData, &wshrpc.RpcOpts{Timeout: 2000})
+				continue
+			}
+		}
+		return dir.Path, nil
+	}
+	return "", fmt.Errorf("no match found for %q", strings.Join(keywords, " "))
+}
+
+func zRun(cmd *cobra.Command, args []string) (rtnErr error) {
+	defer func() {
+		sendActivity("z", rtnErr == nil)
+	}()
+
+	connName := zConn
+	if connName == "" {
+		connName = RpcContext.Conn
+	}
+	if zList || len(args) == 0 {
+		return zListRun(connName, args)
+	}
+	dir, err := zFindDir(connName, args)
+	if err != nil {
+		return err
+	}
+	if zPrint {
+		WriteStdout("%s\n", dir)
+		return nil
+	}
+	blockORef, err := resolveBlockArg()
+	if err != nil {
+		return err
+	}
+	if blockORef.OType != waveobj.OType_Block {
+		return fmt.Errorf("%s is not a block", blockORef)
+	}
+	rtInfo, err := wshclient.GetRTInfoCommand(RpcClient, wshrpc.CommandGetRTInfoData{ORef: *blockORef}, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("getting shell type: %w", err)
+	}
+	var shellType string
+	if rtInfo != nil {
+		shellType = rtInfo.ShellType
+	}
+	cdCmd, err := makeCdCommand(shellType, dir)
+	if err != nil {
+		return err
+	}
+	// the tty must not echo the command before the shell reads it
+	stdinFd := int(os.Stdin.Fd())
+	if term.IsTerminal(stdinFd) {
+		oldState, err := term.MakeRaw(stdinFd)
+		if err == nil {
+			defer term.Restore(stdinFd, oldState)
+		}
+	}
+	inputData := wshrpc.CommandBlockInputData{
+		BlockId:     blockORef.OID,
+		InputData64: base64.StdEncoding.EncodeToString([]byte(cdCmd)),
+	}
+	err = wshclient.ControllerInputCommand(RpcClient, inputData, &wshrpc.RpcOpts{Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("sending cd command: %w (use cd \"$(wsh z -p %s)\" instead)", err, strings.Join(args, " "))
+	}
+	if term.IsTerminal(stdinFd) {
+		waitForTtyInput(stdinFd, len(cdCmd))
+	}
+	return nil
+}
+
+func zListRun(connName string, keywords []string) error {
+	queryData := wshrpc.CommandDirFrecencyQueryData{Connection: connName, Keywords: keywords, MaxItems: zLimit}
+	dirs, err := wshclient.DirFrecencyQueryCommand(RpcClient, queryData, &wshrpc.RpcOpts{Timeout: 5000})
+	if err != nil {
+		return fmt.Errorf("reading directories: %w", err)
+	}
+	if zJson {
+		barr, err := json.MarshalIndent(dirs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding directories: %w", err)
+		}
+		WriteStdout("%s\n", barr)
+		return nil
+	}
+	if len(dirs) == 0 {
+		WriteStderr("no directories recorded yet (directories are recorded from shell integration cwd reports)\n")
+		return nil
+	}
+	for _, dir := range dirs {
+		WriteStdout("%8.1f %5s  %s\n", dir.Score, formatHistoryAge(dir.LastTs), dir.Path)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestMakeCdCommand(t *testing.T) {
+	tests := []struct {
+		shellType string
+		dir       string
+		want      string
+	}{
+		{"zsh", "/home/me/src", " cd -- /home/me/src\r"},
+		{"bash", "/tmp/a dir/$x", " cd -- \"/tmp/a dir/\\$x\"\r"},
+		{"fish", "/tmp/a dir", " cd \"/tmp/a dir\"\r"},
+		{"pwsh", `C:\Users\me`, " Set-Location -LiteralPath \"C:\\Users\\me\"\r"},
+	}
+	for _, tt := range tests {
+		got, err := makeCdCommand(tt.shellType, tt.dir)
+		if err != nil || got != tt.want {
+			t.Errorf("makeCdCommand(%q, %q) = %q, %v, want %q", tt.shellType, tt.dir, got, err, tt.want)
+		}
+	}
+	if _, err := makeCdCommand("bash", "/tmp/a\nb"); err == nil {
+		t.Errorf("expected an error for a newline in the directory")
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_eventlog_event_ts;
+DROP TABLE IF EXISTS db_eventlog;
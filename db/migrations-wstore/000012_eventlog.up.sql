@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS db_eventlog (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ts int NOT NULL,
+    event varchar(100) NOT NULL,
+    scopes json NOT NULL,
+    sender varchar(100) NOT NULL DEFAULT '',
+    data json
+);
+CREATE INDEX IF NOT EXISTS idx_eventlog_event_ts ON db_eventlog (event, ts);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS db_apitoken (
+    id varchar(36) PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    tokenhash varchar(64) NOT NULL UNIQUE,
+    commands json NOT NULL,
+    createdts int NOT NULL,
+    lastusedts int NOT NULL DEFAULT 0
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_cmdhistory_cmd;
+DROP TABLE IF EXISTS db_cmdhistory;
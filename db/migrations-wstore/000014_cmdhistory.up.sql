@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS db_cmdhistory (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ts int NOT NULL,
+    cmd text NOT NULL,
+    blockid varchar(36) NOT NULL,
+    connection varchar(200) NOT NULL DEFAULT '',
+    shelltype varchar(20) NOT NULL DEFAULT '',
+    cwd text NOT NULL DEFAULT '',
+    exitcode int,
+    durationms int NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_cmdhistory_cmd ON db_cmdhistory (cmd);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS db_dirfrecency (
+    connection varchar(200) NOT NULL,
+    path text NOT NULL,
+    rank real NOT NULL,
+    lastts int NOT NULL,
+    PRIMARY KEY (connection, path)
+);
@@ -3,12 +3,17 @@
 
 import { WindowService } from "@/app/store/services";
 import { RpcResponseHelper, WshClient } from "@/app/store/wshclient";
+import { makeTabRouteId } from "@/app/store/wshrouter";
 import { RpcApi } from "@/app/store/wshclientapi";
 import { Notification, net, safeStorage, shell } from "electron";
 import { getResolvedUpdateChannel } from "emain/updater";
 import { unamePlatform } from "./emain-platform";
 import { getWebContentsByBlockId, webGetSelector } from "./emain-web";
-import { createBrowserWindow, getWaveWindowById, getWaveWindowByWorkspaceId } from "./emain-window";
+import { fireAndForget } from "../frontend/util/util";
+import { createBrowserWindow, getWaveWindowById, getWaveWindowByTabId, getWaveWindowByWorkspaceId } from "./emain-window";
+
+// notifications with a click handler must stay referenced until they are dismissed
+const pendingNotifications = new Set<Notification>();
 
 export class ElectronWshClientType extends WshClient {
     constructor() {
@@ -32,11 +37,39 @@ export class ElectronWshClientType extends WshClient {
     }
 
     async handle_notify(rh: RpcResponseHelper, notificationOptions: WaveNotificationOptions) {
-        new Notification({
+        const tabId = notificationOptions.tabid;
+        if (notificationOptions.onlyifunfocused && tabId) {
+            const ww = getWaveWindowByTabId(tabId);
+            if (ww != null && ww.isFocused() && ww.activeTabView?.waveTabId == tabId) {
+                return;
+            }
+        }
+        const notification = new Notification({
             title: notificationOptions.title,
             body: notificationOptions.body,
             silent: notificationOptions.silent,
-        }).show();
+        });
+        if (tabId) {
+            pendingNotifications.add(notification);
+            notification.on("close", () => pendingNotifications.delete(notification));
+            notification.on("click", () => {
+                pendingNotifications.delete(notification);
+                fireAndForget(async () => {
+                    const ww = getWaveWindowByTabId(tabId);
+                    if (ww == null) {
+                        return;
+                    }
+                    ww.focus();
+                    await ww.setActiveTab(tabId, true);
+                    if (notificationOptions.blockid) {
+                        await RpcApi.SetBlockFocusCommand(ElectronWshClient, notificationOptions.blockid, {
+                            route: makeTabRouteId(tabId),
+                        });
+                    }
+                });
+            });
+        }
+        notification.show();
     }
 
     async handle_getupdatechannel(rh: RpcResponseHelper): Promise<string> {
@@ -1,9 +1,11 @@
 // Copyright 2025, Salyvn.
 // SPDX-License-Identifier: Apache-2.0
 
+import automationsSchema from "../../../schema/automations.json";
 import bgpresetsSchema from "../../../schema/bgpresets.json";
 import connectionsSchema from "../../../schema/connections.json";
 import settingsSchema from "../../../schema/settings.json";
+import triggersSchema from "../../../schema/triggers.json";
 import widgetsSchema from "../../../schema/widgets.json";
 
 type SchemaInfo = {
@@ -33,6 +35,16 @@ const MonacoSchemas: SchemaInfo[] = [
         fileMatch: ["*/WAVECONFIGPATH/widgets.json"],
         schema: widgetsSchema,
     },
+    {
+        uri: "wave://schema/automations.json",
+        fileMatch: ["*/WAVECONFIGPATH/automations.json"],
+        schema: automationsSchema,
+    },
+    {
+        uri: "wave://schema/triggers.json",
+        fileMatch: ["*/WAVECONFIGPATH/triggers.json"],
+        schema: triggersSchema,
+    },
 ];
 
 export { MonacoSchemas };
@@ -25,6 +25,7 @@ import {
     isBlank,
     isLocalConnName,
     isWslConnName,
+    mergeMeta,
 } from "@/util/util";
 import { atom, Atom, PrimitiveAtom, useAtomValue } from "jotai";
 import { globalStore } from "./jotaiStore";
@@ -102,7 +103,13 @@ function initGlobalAtoms(initOpts: GlobalInitOptions) {
     const fullConfigAtom = atom(null) as PrimitiveAtom<FullConfigType>;
     const waveaiModeConfigAtom = atom(null) as PrimitiveAtom<Record<string, AIModeConfigType>>;
     const settingsAtom = atom((get) => {
-        return get(fullConfigAtom)?.settings ?? {};
+        const settings = get(fullConfigAtom)?.settings ?? {};
+        // the workspace settings layer sits between settings.json and connections.json
+        const workspaceSettings = get(workspaceAtom)?.meta?.["workspace:settings"];
+        if (workspaceSettings == null) {
+            return settings;
+        }
+        return mergeMeta(settings as MetaType, workspaceSettings as MetaType) as SettingsType;
     }) as Atom<SettingsType>;
     const hasCustomAIPresetsAtom = atom((get) => {
         const fullConfig = get(fullConfigAtom);
@@ -459,7 +466,7 @@ function getBlockTermDurableAtom(blockId: string): Atom<null | boolean> {
             return null;
         }
 
-        // 3. Check config hierarchy: blockmeta → connection → global (default true)
+        // 3. Check config hierarchy: blockmeta → connection → workspace → global (default true)
         const durableConfigAtom = getOverrideConfigAtom(blockId, "term:durable");
         const durableConfig = get(durableConfigAtom);
         if (durableConfig != null) {
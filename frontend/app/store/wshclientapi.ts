@@ -17,6 +17,25 @@ class RpcApiType {
         return client.wshRpcCall("aisendmessage", data, opts);
     }
 
+    // command "apitokencreate" [call]
+    ApiTokenCreateCommand(
+        client: WshClient,
+        data: CommandApiTokenCreateData,
+        opts?: RpcOpts
+    ): Promise<ApiTokenCreateRtnData> {
+        return client.wshRpcCall("apitokencreate", data, opts);
+    }
+
+    // command "apitokenlist" [call]
+    ApiTokenListCommand(client: WshClient, opts?: RpcOpts): Promise<ApiTokenInfo[]> {
+        return client.wshRpcCall("apitokenlist", null, opts);
+    }
+
+    // command "apitokenrevoke" [call]
+    ApiTokenRevokeCommand(client: WshClient, data: string, opts?: RpcOpts): Promise<void> {
+        return client.wshRpcCall("apitokenrevoke", data, opts);
+    }
+
     // command "authenticate" [call]
     AuthenticateCommand(client: WshClient, data: string, opts?: RpcOpts): Promise<CommandAuthenticateRtnData> {
         return client.wshRpcCall("authenticate", data, opts);
@@ -67,6 +86,29 @@ class RpcApiType {
         return client.wshRpcCall("authenticatetokenverify", data, opts);
     }
 
+    // command "automationlist" [call]
+    AutomationListCommand(client: WshClient, opts?: RpcOpts): Promise<AutomationRuleInfo[]> {
+        return client.wshRpcCall("automationlist", null, opts);
+    }
+
+    // command "automationsetenabled" [call]
+    AutomationSetEnabledCommand(
+        client: WshClient,
+        data: CommandAutomationSetEnabledData,
+        opts?: RpcOpts
+    ): Promise<void> {
+        return client.wshRpcCall("automationsetenabled", data, opts);
+    }
+
+    // command "automationtest" [call]
+    AutomationTestCommand(
+        client: WshClient,
+        data: CommandAutomationTestData,
+        opts?: RpcOpts
+    ): Promise<AutomationTestResult> {
+        return client.wshRpcCall("automationtest", data, opts);
+    }
+
     // command "blockinfo" [call]
     BlockInfoCommand(client: WshClient, data: string, opts?: RpcOpts): Promise<BlockInfoData> {
         return client.wshRpcCall("blockinfo", data, opts);
@@ -96,6 +138,48 @@ class RpcApiType {
         return client.wshRpcCall("checkgoversion", null, opts);
     }
 
+    // command "cliuserinput" [call]
+    CliUserInputCommand(
+        client: WshClient,
+        data: CommandCliUserInputData,
+        opts?: RpcOpts
+    ): Promise<CliUserInputRtnData> {
+        return client.wshRpcCall("cliuserinput", data, opts);
+    }
+
+    // command "configexport" [call]
+    ConfigExportCommand(client: WshClient, opts?: RpcOpts): Promise<ConfigBundle> {
+        return client.wshRpcCall("configexport", null, opts);
+    }
+
+    // command "confighistorydiff" [call]
+    ConfigHistoryDiffCommand(
+        client: WshClient,
+        data: CommandConfigHistoryData,
+        opts?: RpcOpts
+    ): Promise<ConfigDiffEntry[]> {
+        return client.wshRpcCall("confighistorydiff", data, opts);
+    }
+
+    // command "confighistorylist" [call]
+    ConfigHistoryListCommand(
+        client: WshClient,
+        data: CommandConfigHistoryData,
+        opts?: RpcOpts
+    ): Promise<ConfigHistoryEntry[]> {
+        return client.wshRpcCall("confighistorylist", data, opts);
+    }
+
+    // command "confighistoryrollback" [call]
+    ConfigHistoryRollbackCommand(client: WshClient, data: CommandConfigHistoryData, opts?: RpcOpts): Promise<void> {
+        return client.wshRpcCall("confighistoryrollback", data, opts);
+    }
+
+    // command "configimport" [call]
+    ConfigImportCommand(client: WshClient, data: CommandConfigImportData, opts?: RpcOpts): Promise<ConfigImportResult> {
+        return client.wshRpcCall("configimport", data, opts);
+    }
+
     // command "connconnect" [call]
     ConnConnectCommand(client: WshClient, data: ConnRequest, opts?: RpcOpts): Promise<void> {
         return client.wshRpcCall("connconnect", data, opts);
@@ -131,6 +215,11 @@ class RpcApiType {
         return client.wshRpcCall("connstatus", null, opts);
     }
 
+    // command "conntlsfingerprint" [call]
+    ConnTlsFingerprintCommand(client: WshClient, opts?: RpcOpts): Promise<string> {
+        return client.wshRpcCall("conntlsfingerprint", null, opts);
+    }
+
     // command "connupdatewsh" [call]
     ConnUpdateWshCommand(client: WshClient, data: RemoteInfo, opts?: RpcOpts): Promise<boolean> {
         return client.wshRpcCall("connupdatewsh", data, opts);
@@ -195,6 +284,20 @@ class RpcApiType {
         return client.wshRpcCall("deletesubblock", data, opts);
     }
 
+    // command "dirfrecencyquery" [call]
+    DirFrecencyQueryCommand(
+        client: WshClient,
+        data: CommandDirFrecencyQueryData,
+        opts?: RpcOpts
+    ): Promise<DirFrecencyEntry[]> {
+        return client.wshRpcCall("dirfrecencyquery", data, opts);
+    }
+
+    // command "dirfrecencyremove" [call]
+    DirFrecencyRemoveCommand(client: WshClient, data: CommandDirFrecencyRemoveData, opts?: RpcOpts): Promise<void> {
+        return client.wshRpcCall("dirfrecencyremove", data, opts);
+    }
+
     // command "dismisswshfail" [call]
     DismissWshFailCommand(client: WshClient, data: string, opts?: RpcOpts): Promise<void> {
         return client.wshRpcCall("dismisswshfail", data, opts);
@@ -455,6 +558,34 @@ class RpcApiType {
         return client.wshRpcCall("getwaveairatelimit", null, opts);
     }
 
+    // command "historyquery" [call]
+    HistoryQueryCommand(client: WshClient, data: CommandHistoryQueryData, opts?: RpcOpts): Promise<HistoryEntry[]> {
+        return client.wshRpcCall("historyquery", data, opts);
+    }
+
+    // command "inputgroupbroadcast" [call]
+    InputGroupBroadcastCommand(
+        client: WshClient,
+        data: CommandInputGroupBroadcastData,
+        opts?: RpcOpts
+    ): Promise<number> {
+        return client.wshRpcCall("inputgroupbroadcast", data, opts);
+    }
+
+    // command "inputgrouplist" [call]
+    InputGroupListCommand(
+        client: WshClient,
+        data: CommandInputGroupListData,
+        opts?: RpcOpts
+    ): Promise<InputGroupMember[]> {
+        return client.wshRpcCall("inputgrouplist", data, opts);
+    }
+
+    // command "inputgroupset" [call]
+    InputGroupSetCommand(client: WshClient, data: CommandInputGroupSetData, opts?: RpcOpts): Promise<void> {
+        return client.wshRpcCall("inputgroupset", data, opts);
+    }
+
     // command "jobcmdexited" [call]
     JobCmdExitedCommand(client: WshClient, data: CommandJobCmdExitedData, opts?: RpcOpts): Promise<void> {
         return client.wshRpcCall("jobcmdexited", data, opts);
@@ -595,6 +726,21 @@ class RpcApiType {
         return client.wshRpcCall("path", data, opts);
     }
 
+    // command "pluginlist" [call]
+    PluginListCommand(client: WshClient, opts?: RpcOpts): Promise<PluginInfo[]> {
+        return client.wshRpcCall("pluginlist", null, opts);
+    }
+
+    // command "pluginrestart" [call]
+    PluginRestartCommand(client: WshClient, data: string, opts?: RpcOpts): Promise<void> {
+        return client.wshRpcCall("pluginrestart", data, opts);
+    }
+
+    // command "pluginsetenabled" [call]
+    PluginSetEnabledCommand(client: WshClient, data: CommandPluginSetEnabledData, opts?: RpcOpts): Promise<void> {
+        return client.wshRpcCall("pluginsetenabled", data, opts);
+    }
+
     // command "publishapp" [call]
     PublishAppCommand(
         client: WshClient,
@@ -760,6 +906,16 @@ class RpcApiType {
         return client.wshRpcCall("routeunannounce", null, opts);
     }
 
+    // command "rpcstats" [call]
+    RpcStatsCommand(client: WshClient, data: CommandRpcStatsData, opts?: RpcOpts): Promise<RpcStatsData> {
+        return client.wshRpcCall("rpcstats", data, opts);
+    }
+
+    // command "rpctrace" [call]
+    RpcTraceCommand(client: WshClient, data: CommandRpcTraceData, opts?: RpcOpts): Promise<RpcTraceData> {
+        return client.wshRpcCall("rpctrace", data, opts);
+    }
+
     // command "sendtelemetry" [call]
     SendTelemetryCommand(client: WshClient, opts?: RpcOpts): Promise<void> {
         return client.wshRpcCall("sendtelemetry", null, opts);
@@ -800,11 +956,56 @@ class RpcApiType {
         return client.wshRpcCall("setsecrets", data, opts);
     }
 
+    // command "settermtheme" [call]
+    SetTermThemeCommand(client: WshClient, data: CommandSetTermThemeData, opts?: RpcOpts): Promise<void> {
+        return client.wshRpcCall("settermtheme", data, opts);
+    }
+
     // command "setvar" [call]
     SetVarCommand(client: WshClient, data: CommandVarData, opts?: RpcOpts): Promise<void> {
         return client.wshRpcCall("setvar", data, opts);
     }
 
+    // command "setworkspaceconfig" [call]
+    SetWorkspaceConfigCommand(client: WshClient, data: CommandSetWorkspaceConfigData, opts?: RpcOpts): Promise<void> {
+        return client.wshRpcCall("setworkspaceconfig", data, opts);
+    }
+
+    // command "sharelist" [call]
+    ShareListCommand(client: WshClient, data: CommandShareListData, opts?: RpcOpts): Promise<ShareInfo[]> {
+        return client.wshRpcCall("sharelist", data, opts);
+    }
+
+    // command "sharestart" [call]
+    ShareStartCommand(client: WshClient, data: CommandShareStartData, opts?: RpcOpts): Promise<ShareInfo> {
+        return client.wshRpcCall("sharestart", data, opts);
+    }
+
+    // command "sharestop" [call]
+    ShareStopCommand(client: WshClient, data: CommandShareStopData, opts?: RpcOpts): Promise<number> {
+        return client.wshRpcCall("sharestop", data, opts);
+    }
+
+    // command "snapshotcreate" [call]
+    SnapshotCreateCommand(client: WshClient, data: CommandSnapshotData, opts?: RpcOpts): Promise<SnapshotInfo> {
+        return client.wshRpcCall("snapshotcreate", data, opts);
+    }
+
+    // command "snapshotdelete" [call]
+    SnapshotDeleteCommand(client: WshClient, data: CommandSnapshotData, opts?: RpcOpts): Promise<void> {
+        return client.wshRpcCall("snapshotdelete", data, opts);
+    }
+
+    // command "snapshotlist" [call]
+    SnapshotListCommand(client: WshClient, opts?: RpcOpts): Promise<SnapshotInfo[]> {
+        return client.wshRpcCall("snapshotlist", null, opts);
+    }
+
+    // command "snapshotrestore" [call]
+    SnapshotRestoreCommand(client: WshClient, data: CommandSnapshotData, opts?: RpcOpts): Promise<string[]> {
+        return client.wshRpcCall("snapshotrestore", data, opts);
+    }
+
     // command "startbuilder" [call]
     StartBuilderCommand(client: WshClient, data: CommandStartBuilderData, opts?: RpcOpts): Promise<void> {
         return client.wshRpcCall("startbuilder", data, opts);
@@ -862,6 +1063,11 @@ class RpcApiType {
         return client.wshRpcCall("termgetscrollbacklines", data, opts);
     }
 
+    // command "termlogstatus" [call]
+    TermLogStatusCommand(client: WshClient, data: string, opts?: RpcOpts): Promise<TermLogStatusData> {
+        return client.wshRpcCall("termlogstatus", data, opts);
+    }
+
     // command "test" [call]
     TestCommand(client: WshClient, data: string, opts?: RpcOpts): Promise<void> {
         return client.wshRpcCall("test", data, opts);
@@ -943,11 +1149,29 @@ class RpcApiType {
         return client.wshRpcCall("webselector", data, opts);
     }
 
+    // command "workspaceapplylayout" [call]
+    WorkspaceApplyLayoutCommand(
+        client: WshClient,
+        data: CommandWorkspaceApplyLayoutData,
+        opts?: RpcOpts
+    ): Promise<string[]> {
+        return client.wshRpcCall("workspaceapplylayout", data, opts);
+    }
+
     // command "workspacelist" [call]
     WorkspaceListCommand(client: WshClient, opts?: RpcOpts): Promise<WorkspaceInfoData[]> {
         return client.wshRpcCall("workspacelist", null, opts);
     }
 
+    // command "workspacesavelayout" [call]
+    WorkspaceSaveLayoutCommand(
+        client: WshClient,
+        data: CommandWorkspaceSaveLayoutData,
+        opts?: RpcOpts
+    ): Promise<CommandWorkspaceSaveLayoutRtnData> {
+        return client.wshRpcCall("workspacesavelayout", data, opts);
+    }
+
     // command "writeappfile" [call]
     WriteAppFileCommand(client: WshClient, data: CommandWriteAppFileData, opts?: RpcOpts): Promise<void> {
         return client.wshRpcCall("writeappfile", data, opts);
@@ -1,6 +1,7 @@
 // Copyright 2025, Salyvn.
 // SPDX-License-Identifier: Apache-2.0
 
+import { waveEventSubscribe } from "@/app/store/wps";
 import { globalStore } from "@/store/global";
 import * as jotai from "jotai";
 import * as React from "react";
@@ -148,6 +149,18 @@ const PetOverlay: React.FC = () => {
         };
     }, [petEnabled]);
 
+    // Reactions sent by output triggers / automations ("petreaction" actions)
+    React.useEffect(() => {
+        if (!petEnabled) return;
+        return waveEventSubscribe({
+            eventType: "pet:reaction",
+            handler: (event) => {
+                const text = (event.data as PetReactionEventData)?.text;
+                if (text) petController.showTerminalReaction(text);
+            },
+        });
+    }, [petEnabled]);
+
     // ============================================================
     // Food falling animation — falls to BOTTOM of container
     // ============================================================
@@ -112,6 +112,8 @@ export class TermViewModel implements ViewModel {
     blockJobStatusAtom: jotai.PrimitiveAtom<BlockJobStatusData>;
     blockJobStatusVersionTs: number;
     blockJobStatusUnsubFn: () => void;
+    shareStatusAtom: jotai.PrimitiveAtom<ShareStatusEventData>;
+    shareStatusUnsubFn: () => void;
     termBPMUnsubFn: () => void;
     isCmdController: jotai.Atom<boolean>;
     shellProcStatusReceived: boolean;
@@ -237,6 +239,22 @@ export class TermViewModel implements ViewModel {
                     }
                 }
             }
+            const shareStatus = get(this.shareStatusAtom);
+            if (shareStatus?.shares > 0) {
+                const viewers = shareStatus.viewers ?? 0;
+                rtn.push({
+                    elemtype: "iconbutton",
+                    icon: "tower-broadcast",
+                    iconColor: viewers > 0 ? "var(--warning-color)" : null,
+                    title: `Shared (${viewers} ${viewers == 1 ? "viewer" : "viewers"}), stop with "wsh share stop"`,
+                    noAction: true,
+                });
+                rtn.push({
+                    elemtype: "text",
+                    text: String(viewers),
+                    noGrow: true,
+                });
+            }
             const isMI = get(this.tabModel.isTermMultiInput);
             if (isMI && this.isBasicTerm(get)) {
                 rtn.push({
@@ -408,6 +426,24 @@ export class TermViewModel implements ViewModel {
                 this.handleBlockJobStatusUpdate(event.data);
             },
         });
+        this.shareStatusAtom = jotai.atom(null) as jotai.PrimitiveAtom<ShareStatusEventData>;
+        RpcApi.ShareListCommand(TabRpcClient, { blockid: blockId })
+            .then((shares) => {
+                if (shares?.length > 0) {
+                    const viewers = shares.reduce((acc, share) => acc + (share.viewers ?? 0), 0);
+                    globalStore.set(this.shareStatusAtom, { blockid: blockId, shares: shares.length, viewers });
+                }
+            })
+            .catch((error) => {
+                console.log("error getting initial share status", error);
+            });
+        this.shareStatusUnsubFn = waveEventSubscribe({
+            eventType: "share:status",
+            scope: `block:${blockId}`,
+            handler: (event) => {
+                globalStore.set(this.shareStatusAtom, event.data);
+            },
+        });
         this.termBPMUnsubFn = globalStore.sub(this.termBPMAtom, () => {
             if (this.termRef.current?.terminal) {
                 const allowBPM = globalStore.get(this.termBPMAtom) ?? true;
@@ -557,6 +593,7 @@ export class TermViewModel implements ViewModel {
         DefaultRouter.unregisterRoute(makeFeBlockRouteId(this.blockId));
         this.shellProcStatusUnsubFn?.();
         this.blockJobStatusUnsubFn?.();
+        this.shareStatusUnsubFn?.();
         this.termBPMUnsubFn?.();
         this.inputBatcher?.dispose();
     }
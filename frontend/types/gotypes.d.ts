@@ -84,6 +84,22 @@ declare global {
         message?: string;
     };
 
+    // wshrpc.ApiTokenCreateRtnData
+    type ApiTokenCreateRtnData = {
+        id: string;
+        name: string;
+        token: string;
+    };
+
+    // wshrpc.ApiTokenInfo
+    type ApiTokenInfo = {
+        id: string;
+        name: string;
+        commands: string[];
+        createdts: number;
+        lastusedts?: number;
+    };
+
     // wshrpc.AppInfo
     type AppInfo = {
         appid: string;
@@ -107,6 +123,81 @@ declare global {
         iconcolor: string;
     };
 
+    // wconfig.AutomationAction
+    type AutomationAction = {
+        type: string;
+        cmd?: string;
+        cwd?: string;
+        connection?: string;
+        blockid?: string;
+        tabid?: string;
+        input?: string;
+        title?: string;
+        body?: string;
+        silent?: boolean;
+        icon?: string;
+        color?: string;
+        event?: string;
+        scopes?: string[];
+        data?: any;
+    };
+
+    // wshrpc.AutomationActionResult
+    type AutomationActionResult = {
+        type: string;
+        description: string;
+        ran?: boolean;
+        error?: string;
+    };
+
+    // wshrpc.AutomationConditionResult
+    type AutomationConditionResult = {
+        condition: string;
+        matched: boolean;
+        error?: string;
+    };
+
+    // wconfig.AutomationRule
+    type AutomationRule = {
+        "display:name"?: string;
+        disabled?: boolean;
+        dryrun?: boolean;
+        cooldownsecs?: number;
+        maxperhour?: number;
+        trigger: AutomationTrigger;
+        actions: AutomationAction[];
+    };
+
+    // wshrpc.AutomationRuleInfo
+    type AutomationRuleInfo = {
+        name: string;
+        displayname?: string;
+        event: string;
+        disabled?: boolean;
+        dryrun?: boolean;
+        numactions: number;
+        firecount: number;
+        lastfirets?: number;
+        lasterror?: string;
+        configerror?: string;
+    };
+
+    // wshrpc.AutomationTestResult
+    type AutomationTestResult = {
+        name: string;
+        event?: WaveEvent;
+        matched: boolean;
+        conditions?: AutomationConditionResult[];
+        actions?: AutomationActionResult[];
+    };
+
+    // wconfig.AutomationTrigger
+    type AutomationTrigger = {
+        event: string;
+        scopes?: string[];
+        conditions?: string[];
+    };
+
     // waveobj.Block
     type Block = WaveObj & {
         parentoref?: string;
@@ -181,6 +272,13 @@ declare global {
         secretbindingscomplete: boolean;
     };
 
+    // wshrpc.CliUserInputRtnData
+    type CliUserInputRtnData = {
+        text?: string;
+        confirm?: boolean;
+        checkboxstat?: boolean;
+    };
+
     // waveobj.Client
     type Client = WaveObj & {
         windowids: string[];
@@ -196,6 +294,12 @@ declare global {
         newactivetabid?: string;
     };
 
+    // wshrpc.CommandApiTokenCreateData
+    type CommandApiTokenCreateData = {
+        name: string;
+        commands: string[];
+    };
+
     // wshrpc.CommandAuthenticateJobManagerData
     type CommandAuthenticateJobManagerData = {
         jobid: string;
@@ -220,6 +324,19 @@ declare global {
         token: string;
     };
 
+    // wshrpc.CommandAutomationSetEnabledData
+    type CommandAutomationSetEnabledData = {
+        name: string;
+        enabled: boolean;
+    };
+
+    // wshrpc.CommandAutomationTestData
+    type CommandAutomationTestData = {
+        name: string;
+        event?: WaveEvent;
+        run?: boolean;
+    };
+
     // wshrpc.CommandBlockInputData
     type CommandBlockInputData = {
         blockid: string;
@@ -241,6 +358,32 @@ declare global {
         errorstring?: string;
     };
 
+    // wshrpc.CommandCliUserInputData
+    type CommandCliUserInputData = {
+        querytext: string;
+        responsetype: string;
+        title?: string;
+        markdown?: boolean;
+        timeoutms?: number;
+        checkboxmsg?: string;
+        publictext?: boolean;
+        oklabel?: string;
+        cancellabel?: string;
+    };
+
+    // wshrpc.CommandConfigHistoryData
+    type CommandConfigHistoryData = {
+        file?: string;
+        version?: number;
+    };
+
+    // wshrpc.CommandConfigImportData
+    type CommandConfigImportData = {
+        bundle: ConfigBundle;
+        strategy?: string;
+        dryrun?: boolean;
+    };
+
     // wshrpc.CommandConnServerInitData
     type CommandConnServerInitData = {
         clientid: string;
@@ -295,6 +438,20 @@ declare global {
         recursive: boolean;
     };
 
+    // wshrpc.CommandDirFrecencyQueryData
+    type CommandDirFrecencyQueryData = {
+        connection?: string;
+        keywords?: string[];
+        exclude?: string;
+        maxitems?: number;
+    };
+
+    // wshrpc.CommandDirFrecencyRemoveData
+    type CommandDirFrecencyRemoveData = {
+        connection?: string;
+        path: string;
+    };
+
     // wshrpc.CommandDisposeData
     type CommandDisposeData = {
         routeid: string;
@@ -327,6 +484,9 @@ declare global {
         event: string;
         scope: string;
         maxitems: number;
+        startts?: number;
+        endts?: number;
+        durable?: boolean;
     };
 
     // wshrpc.CommandFileCopyData
@@ -362,6 +522,34 @@ declare global {
         chatid: string;
     };
 
+    // wshrpc.CommandHistoryQueryData
+    type CommandHistoryQueryData = {
+        cwd?: string;
+        connection?: string;
+        blockid?: string;
+        exitstatus?: "success" | "failed";
+        unique?: boolean;
+        maxitems?: number;
+    };
+
+    // wshrpc.CommandInputGroupBroadcastData
+    type CommandInputGroupBroadcastData = {
+        group: string;
+        inputdata64: string;
+    };
+
+    // wshrpc.CommandInputGroupListData
+    type CommandInputGroupListData = {
+        group?: string;
+    };
+
+    // wshrpc.CommandInputGroupSetData
+    type CommandInputGroupSetData = {
+        action: string;
+        blockids: string[];
+        group?: string;
+    };
+
     // wshrpc.CommandJobCmdExitedData
     type CommandJobCmdExitedData = {
         jobid: string;
@@ -450,6 +638,12 @@ declare global {
         message: string;
     };
 
+    // wshrpc.CommandPluginSetEnabledData
+    type CommandPluginSetEnabledData = {
+        name: string;
+        enabled: boolean;
+    };
+
     // wshrpc.CommandPublishAppData
     type CommandPublishAppData = {
         appid: string;
@@ -555,6 +749,18 @@ declare global {
         builderid: string;
     };
 
+    // wshrpc.CommandRpcStatsData
+    type CommandRpcStatsData = {
+        reset?: boolean;
+    };
+
+    // wshrpc.CommandRpcTraceData
+    type CommandRpcTraceData = {
+        samplerate?: number;
+        clear?: boolean;
+        maxentries?: number;
+    };
+
     // wshrpc.CommandSetMetaData
     type CommandSetMetaData = {
         oref: ORef;
@@ -568,6 +774,43 @@ declare global {
         delete?: boolean;
     };
 
+    // wshrpc.CommandSetTermThemeData
+    type CommandSetTermThemeData = {
+        themekey: string;
+        theme: TermThemeType;
+    };
+
+    // wshrpc.CommandSetWorkspaceConfigData
+    type CommandSetWorkspaceConfigData = {
+        workspaceid: string;
+        meta: SettingsType;
+    };
+
+    // wshrpc.CommandShareListData
+    type CommandShareListData = {
+        blockid?: string;
+    };
+
+    // wshrpc.CommandShareStartData
+    type CommandShareStartData = {
+        blockid: string;
+        writable?: boolean;
+        expiressecs?: number;
+    };
+
+    // wshrpc.CommandShareStopData
+    type CommandShareStopData = {
+        shareid?: string;
+        blockid?: string;
+    };
+
+    // wshrpc.CommandSnapshotData
+    type CommandSnapshotData = {
+        workspaceid?: string;
+        snapshotid?: string;
+        name?: string;
+    };
+
     // wshrpc.CommandStartBuilderData
     type CommandStartBuilderData = {
         builderid: string;
@@ -689,6 +932,28 @@ declare global {
         opts?: WebSelectorOpts;
     };
 
+    // wshrpc.CommandWorkspaceApplyLayoutData
+    type CommandWorkspaceApplyLayoutData = {
+        workspaceid: string;
+        name?: string;
+        content?: string;
+        params?: {[key: string]: string};
+    };
+
+    // wshrpc.CommandWorkspaceSaveLayoutData
+    type CommandWorkspaceSaveLayoutData = {
+        workspaceid: string;
+        name?: string;
+        params?: {[key: string]: string};
+        overwrite?: boolean;
+    };
+
+    // wshrpc.CommandWorkspaceSaveLayoutRtnData
+    type CommandWorkspaceSaveLayoutRtnData = {
+        path?: string;
+        content: string;
+    };
+
     // wshrpc.CommandWriteAppFileData
     type CommandWriteAppFileData = {
         appid: string;
@@ -719,12 +984,63 @@ declare global {
         data64: string;
     };
 
+    // wshrpc.ConfigBundle
+    type ConfigBundle = {
+        version: number;
+        createdts: number;
+        files: ConfigBundleFile[];
+        secretnames?: string[];
+        stripped?: string[];
+    };
+
+    // wshrpc.ConfigBundleFile
+    type ConfigBundleFile = {
+        section: string;
+        path: string;
+        config?: MetaType;
+        data64?: string;
+    };
+
+    // wconfig.ConfigDiffEntry
+    type ConfigDiffEntry = {
+        key: string;
+        oldvalue?: any;
+        newvalue?: any;
+    };
+
     // wconfig.ConfigError
     type ConfigError = {
         file: string;
         err: string;
     };
 
+    // wconfig.ConfigHistoryEntry
+    type ConfigHistoryEntry = {
+        file: string;
+        version: number;
+        timestamp: string;
+        source: string;
+        size: number;
+    };
+
+    // wshrpc.ConfigImportConflict
+    type ConfigImportConflict = {
+        section: string;
+        path: string;
+        key?: string;
+        resolution: string;
+    };
+
+    // wshrpc.ConfigImportResult
+    type ConfigImportResult = {
+        dryrun?: boolean;
+        written?: string[];
+        unchanged?: string[];
+        conflicts?: ConfigImportConflict[];
+        missingsecrets?: string[];
+        warnings?: string[];
+    };
+
     // wshrpc.ConnConfigRequest
     type ConnConfigRequest = {
         host: string;
@@ -744,6 +1060,7 @@ declare global {
         "conn:wshpath"?: string;
         "conn:shellpath"?: string;
         "conn:ignoresshconfig"?: boolean;
+        "conn:tlsfingerprint"?: string;
         "display:hidden"?: boolean;
         "display:order"?: number;
         "term:*"?: boolean;
@@ -751,6 +1068,8 @@ declare global {
         "term:fontfamily"?: string;
         "term:theme"?: string;
         "term:durable"?: boolean;
+        "term:triggers"?: string[];
+        "term:cmdnotify"?: boolean;
         "cmd:env"?: {[key: string]: string};
         "cmd:initscript"?: string;
         "cmd:initscript.sh"?: string;
@@ -781,6 +1100,7 @@ declare global {
         host: string;
         keywords?: ConnKeywords;
         logblockid?: string;
+        cliprompts?: boolean;
     };
 
     // wshrpc.ConnStatus
@@ -817,6 +1137,15 @@ declare global {
         modifiedtime: string;
     };
 
+    // wshrpc.DirFrecencyEntry
+    type DirFrecencyEntry = {
+        connection?: string;
+        path: string;
+        rank: number;
+        lastts: number;
+        score: number;
+    };
+
     // vdom.DomRect
     type DomRect = {
         top: number;
@@ -827,6 +1156,12 @@ declare global {
         height: number;
     };
 
+    // wconfig.EventLogRetentionType
+    type EventLogRetentionType = {
+        retentiondays?: number;
+        maxevents?: number;
+    };
+
     // wshrpc.FetchSuggestionsData
     type FetchSuggestionsData = {
         suggestiontype: string;
@@ -836,6 +1171,9 @@ declare global {
         "file:cwd"?: string;
         "file:dironly"?: boolean;
         "file:connection"?: string;
+        "history:cwd"?: string;
+        "history:connection"?: string;
+        "dir:connection"?: string;
     };
 
     // wshrpc.FetchSuggestionsResponse
@@ -937,9 +1275,35 @@ declare global {
         connections: {[key: string]: ConnKeywords};
         bookmarks: {[key: string]: WebBookmark};
         waveai: {[key: string]: AIModeConfigType};
+        automations: {[key: string]: AutomationRule};
+        triggers: {[key: string]: OutputTrigger};
         configerrors: ConfigError[];
     };
 
+    // wshrpc.HistoryEntry
+    type HistoryEntry = {
+        id: number;
+        ts: number;
+        cmd: string;
+        blockid: string;
+        connection?: string;
+        shelltype?: string;
+        cwd?: string;
+        exitcode?: number;
+        durationms?: number;
+        count?: number;
+    };
+
+    // wshrpc.InputGroupMember
+    type InputGroupMember = {
+        group: string;
+        blockid: string;
+        view?: string;
+        connname?: string;
+        disabled?: boolean;
+        running?: boolean;
+    };
+
     // waveobj.Job
     type Job = WaveObj & {
         connection: string;
@@ -1040,6 +1404,7 @@ declare global {
         "cmd:shell"?: boolean;
         "cmd:allowconnchange"?: boolean;
         "cmd:jwt"?: boolean;
+        "cmd:capabilities"?: string[];
         "cmd:env"?: {[key: string]: string};
         "cmd:cwd"?: string;
         "cmd:initscript"?: string;
@@ -1099,6 +1464,14 @@ declare global {
         "term:bellsound"?: boolean;
         "term:bellindicator"?: boolean;
         "term:durable"?: boolean;
+        "term:inputgroup"?: string;
+        "term:inputgroupdisabled"?: boolean;
+        "term:triggers"?: string[];
+        "term:log"?: boolean;
+        "term:logfile"?: string;
+        "term:logstripansi"?: boolean;
+        "term:logtimestamps"?: boolean;
+        "term:cmdnotify"?: boolean;
         "web:zoom"?: number;
         "web:hidenav"?: boolean;
         "web:partition"?: string;
@@ -1116,6 +1489,7 @@ declare global {
         "vdom:correlationid"?: string;
         "vdom:route"?: string;
         "vdom:persist"?: boolean;
+        "workspace:settings"?: {[key: string]: any};
         "onboarding:githubstar"?: boolean;
         "onboarding:lastversion"?: string;
         count?: number;
@@ -1158,6 +1532,18 @@ declare global {
         "waveai:maxoutputtokens"?: number;
     };
 
+    // wconfig.OutputTrigger
+    type OutputTrigger = {
+        "display:name"?: string;
+        disabled?: boolean;
+        optin?: boolean;
+        pattern: string;
+        connections?: string[];
+        cooldownsecs?: number;
+        maxperhour?: number;
+        actions: AutomationAction[];
+    };
+
     // wshrpc.PathCommandData
     type PathCommandData = {
         pathtype: string;
@@ -1166,6 +1552,36 @@ declare global {
         tabid: string;
     };
 
+    // wshrpc.PetReactionEventData
+    type PetReactionEventData = {
+        text: string;
+    };
+
+    // wshrpc.PluginInfo
+    type PluginInfo = {
+        name: string;
+        displayname?: string;
+        description?: string;
+        dir: string;
+        route: string;
+        enabled?: boolean;
+        status: string;
+        pid?: number;
+        restarts?: number;
+        error?: string;
+        commands?: string[];
+        wshcommands?: PluginWshCommand[];
+        events?: string[];
+        capabilities: string[];
+    };
+
+    // wshrpc.PluginWshCommand
+    type PluginWshCommand = {
+        name: string;
+        description?: string;
+        command: string;
+    };
+
     // waveobj.Point
     type Point = {
         x: number;
@@ -1188,6 +1604,19 @@ declare global {
         buildoutput: string;
     };
 
+    // wshrpc.RpcCommandStats
+    type RpcCommandStats = {
+        command: string;
+        count: number;
+        errors?: number;
+        timeouts?: number;
+        inflight?: number;
+        stale?: number;
+        totalms: number;
+        maxms: number;
+        latencies: number[];
+    };
+
     // wshrpc.RpcContext
     type RpcContext = {
         sockname?: string;
@@ -1196,6 +1625,21 @@ declare global {
         blockid?: string;
         conn?: string;
         isrouter?: boolean;
+        capabilities?: string[];
+    };
+
+    // wshrpc.RpcLinkStats
+    type RpcLinkStats = {
+        linkid: number;
+        name: string;
+        kind?: string;
+        routes?: string[];
+        msgssent: number;
+        msgsrecv: number;
+        bytessent: number;
+        bytesrecv: number;
+        backlog?: number;
+        backloghighwater?: number;
     };
 
     // wshutil.RpcMessage
@@ -1211,6 +1655,7 @@ declare global {
         error?: string;
         datatype?: string;
         data?: any;
+        restricted?: boolean;
     };
 
     // wshrpc.RpcOpts
@@ -1220,6 +1665,35 @@ declare global {
         route?: string;
     };
 
+    // wshrpc.RpcStatsData
+    type RpcStatsData = {
+        ts: number;
+        sincets: number;
+        latencybucketsms: number[];
+        commands: RpcCommandStats[];
+        links: RpcLinkStats[];
+        tracesamplerate?: number;
+    };
+
+    // wshrpc.RpcTraceData
+    type RpcTraceData = {
+        samplerate: number;
+        entries: RpcTraceEntry[];
+    };
+
+    // wshrpc.RpcTraceEntry
+    type RpcTraceEntry = {
+        reqid: string;
+        command: string;
+        source?: string;
+        route: string;
+        hops: string[];
+        startts: number;
+        durationms: number;
+        responses: number;
+        error?: string;
+    };
+
     // waveobj.RuntimeOpts
     type RuntimeOpts = {
         termsize?: TermSize;
@@ -1278,6 +1752,16 @@ declare global {
         "term:bellsound"?: boolean;
         "term:bellindicator"?: boolean;
         "term:durable"?: boolean;
+        "term:log"?: boolean;
+        "term:logdir"?: string;
+        "term:logstripansi"?: boolean;
+        "term:logtimestamps"?: boolean;
+        "term:logmaxsizemb"?: number;
+        "term:logrotatehours"?: number;
+        "term:logkeep"?: number;
+        "term:cmdnotify"?: boolean;
+        "term:cmdnotifysecs"?: number;
+        "term:cmdnotifybell"?: boolean;
         "editor:minimapenabled"?: boolean;
         "editor:stickyscrollenabled"?: boolean;
         "editor:wordwrap"?: boolean;
@@ -1324,9 +1808,36 @@ declare global {
         "conn:askbeforewshinstall"?: boolean;
         "conn:wshenabled"?: boolean;
         "conn:localhostdisplayname"?: string;
+        "conn:default"?: string;
+        "wsh:*"?: boolean;
+        "wsh:localcapabilities"?: string[];
+        "wsh:remotecapabilities"?: string[];
         "debug:*"?: boolean;
         "debug:pprofport"?: number;
         "debug:pprofmemprofilerate"?: number;
+        "debug:metrics"?: boolean;
+        "config:*"?: boolean;
+        "config:historymaxversions"?: number;
+        "config:historymaxdays"?: number;
+        "eventlog:*"?: boolean;
+        "eventlog:events"?: string[];
+        "eventlog:retentiondays"?: number;
+        "eventlog:maxperevent"?: number;
+        "eventlog:retention"?: {[key: string]: EventLogRetentionType};
+        "gateway:*"?: boolean;
+        "gateway:enabled"?: boolean;
+        "gateway:listen"?: string;
+        "history:*"?: boolean;
+        "history:disabled"?: boolean;
+        "history:maxentries"?: number;
+        "frecency:*"?: boolean;
+        "frecency:disabled"?: boolean;
+        "frecency:maxage"?: number;
+        "share:*"?: boolean;
+        "share:listen"?: string;
+        "share:host"?: string;
+        "plugins:*"?: boolean;
+        "plugins:enabled"?: string[];
         "tsunami:*"?: boolean;
         "tsunami:scaffoldpath"?: string;
         "tsunami:sdkreplacepath"?: string;
@@ -1334,6 +1845,35 @@ declare global {
         "tsunami:gopath"?: string;
     };
 
+    // wshrpc.ShareInfo
+    type ShareInfo = {
+        shareid: string;
+        blockid: string;
+        url: string;
+        writable?: boolean;
+        viewers: number;
+        createdts: number;
+        expirests?: number;
+    };
+
+    // wshrpc.ShareStatusEventData
+    type ShareStatusEventData = {
+        blockid: string;
+        shares: number;
+        viewers: number;
+    };
+
+    // wshrpc.SnapshotInfo
+    type SnapshotInfo = {
+        snapshotid: string;
+        name: string;
+        workspacename?: string;
+        createdts: number;
+        auto?: boolean;
+        numtabs: number;
+        numblocks: number;
+    };
+
     // waveobj.StickerClickOptsType
     type StickerClickOptsType = {
         sendinput?: string;
@@ -1386,6 +1926,7 @@ declare global {
         "file:path"?: string;
         "file:name"?: string;
         "url:url"?: string;
+        "history:cmd"?: string;
     };
 
     // telemetrydata.TEvent
@@ -1538,6 +2079,17 @@ declare global {
         indicator: TabIndicator;
     };
 
+    // wshrpc.TermLogStatusData
+    type TermLogStatusData = {
+        blockid: string;
+        enabled: boolean;
+        path: string;
+        size: number;
+        stripansi: boolean;
+        timestamps: boolean;
+        dropped?: number;
+    };
+
     // waveobj.TermSize
     type TermSize = {
         rows: number;
@@ -1884,6 +2436,7 @@ declare global {
         scopes?: string[];
         sender?: string;
         persist?: number;
+        ts?: number;
         data?: any;
     };
 
@@ -1938,6 +2491,9 @@ declare global {
         title?: string;
         body?: string;
         silent?: boolean;
+        tabid?: string;
+        blockid?: string;
+        onlyifunfocused?: boolean;
     };
 
     // waveobj.WaveObj
@@ -0,0 +1,198 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// long-lived, revocable api tokens for the local http gateway (see pkg/web/gateway.go).
+// only a sha256 of each token is stored; the token itself is shown once, at creation.
+package apitoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/SalyyS1/SLTerm/pkg/util/dbutil"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+const TokenPrefix = "slt_"
+const AllCommands = "*"
+
+// wshrpc commands that may be called through the gateway.  "eventsub" grants the /api/v1/events stream.
+var GatewayCommands = map[string]bool{
+	"automationlist":         true,
+	"blockinfo":              true,
+	"blockslist":             true,
+	"connlist":               true,
+	"connstatus":             true,
+	"controllerinput":        true,
+	"createblock":            true,
+	"deleteblock":            true,
+	"eventpublish":           true,
+	"eventreadhistory":       true,
+	"eventsub":               true,
+	"fileappend":             true,
+	"filecreate":             true,
+	"filedelete":             true,
+	"fileinfo":               true,
+	"filelist":               true,
+	"fileliststream":         true,
+	"filemkdir":              true,
+	"fileread":               true,
+	"filereadstream":         true,
+	"filewrite":              true,
+	"getallvars":             true,
+	"getfullconfig":          true,
+	"getmeta":                true,
+	"getvar":                 true,
+	"resolveids":             true,
+	"setconfig":              true,
+	"setmeta":                true,
+	"setvar":                 true,
+	"streamcpudata":          true,
+	"termgetscrollbacklines": true,
+	"waveinfo":               true,
+	"workspacelist":          true,
+}
+
+type tokenRow struct {
+	Id         string `db:"id"`
+	Name       string `db:"name"`
+	TokenHash  string `db:"tokenhash"`
+	Commands   string `db:"commands"`
+	CreatedTs  int64  `db:"createdts"`
+	LastUsedTs int64  `db:"lastusedts"`
+}
+
+func (row tokenRow) toInfo() (*wshrpc.ApiTokenInfo, error) {
+	info := &wshrpc.ApiTokenInfo{
+		Id:         row.Id,
+		Name:       row.Name,
+		CreatedTs:  row.CreatedTs,
+		LastUsedTs: row.LastUsedTs,
+	}
+	if err := json.Unmarshal([]byte(row.Commands), &info.Commands); err != nil {
+		return nil, fmt.Errorf("decoding commands for token %s: %w", row.Id, err)
+	}
+	return info, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func GatewayCommandList() []string {
+	var rtn []string
+	for command := range GatewayCommands {
+		rtn = append(rtn, command)
+	}
+	sort.Strings(rtn)
+	return rtn
+}
+
+func IsCommandAllowed(info *wshrpc.ApiTokenInfo, command string) bool {
+	if !GatewayCommands[command] {
+		return false
+	}
+	for _, allowed := range info.Commands {
+		if allowed == AllCommands || allowed == command {
+			return true
+		}
+	}
+	return false
+}
+
+func Create(ctx context.Context, data wshrpc.CommandApiTokenCreateData) (*wshrpc.ApiTokenCreateRtnData, error) {
+	name := strings.TrimSpace(data.Name)
+	if name == "" {
+		return nil, fmt.Errorf("token name is required")
+	}
+	if len(data.Commands) == 0 {
+		return nil, fmt.Errorf("at least one command is required (or %q for all gateway commands)", AllCommands)
+	}
+	for _, command := range data.Commands {
+		if command != AllCommands && !GatewayCommands[command] {
+			return nil, fmt.Errorf("command %q is not available through the gateway", command)
+		}
+	}
+	randBytes := make([]byte, 32)
+	if _, err := rand.Read(randBytes); err != nil {
+		return nil, fmt.Errorf("generating token: %w", err)
+	}
+	token := TokenPrefix + base64.RawURLEncoding.EncodeToString(randBytes)
+	row := tokenRow{
+		Id:        uuid.New().String(),
+		Name:      name,
+		TokenHash: hashToken(token),
+		Commands:  dbutil.QuickJson(data.Commands),
+		CreatedTs: time.Now().UnixMilli(),
+	}
+	err := wstore.WithTx(ctx, func(tx *wstore.TxWrap) error {
+		if tx.Exists(`SELECT id FROM db_apitoken WHERE name = ?`, name) {
+			return fmt.Errorf("a token named %q already exists", name)
+		}
+		query := `INSERT INTO db_apitoken (id, name, tokenhash, commands, createdts, lastusedts) VALUES (?, ?, ?, ?, ?, 0)`
+		tx.Exec(query, row.Id, row.Name, row.TokenHash, row.Commands, row.CreatedTs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &wshrpc.ApiTokenCreateRtnData{Id: row.Id, Name: row.Name, Token: token}, nil
+}
+
+func List(ctx context.Context) ([]wshrpc.ApiTokenInfo, error) {
+	return wstore.WithTxRtn(ctx, func(tx *wstore.TxWrap) ([]wshrpc.ApiTokenInfo, error) {
+		var rows []tokenRow
+		tx.Select(&rows, `SELECT id, name, tokenhash, commands, createdts, lastusedts FROM db_apitoken ORDER BY createdts`)
+		rtn := make([]wshrpc.ApiTokenInfo, 0, len(rows))
+		for _, row := range rows {
+			info, err := row.toInfo()
+			if err != nil {
+				return nil, err
+			}
+			rtn = append(rtn, *info)
+		}
+		return rtn, nil
+	})
+}
+
+// Revoke deletes a token by id or name
+func Revoke(ctx context.Context, idOrName string) error {
+	return wstore.WithTx(ctx, func(tx *wstore.TxWrap) error {
+		result := tx.Exec(`DELETE FROM db_apitoken WHERE id = ? OR name = ?`, idOrName, idOrName)
+		if result == nil {
+			return nil // tx error is returned by WithTx
+		}
+		if num, _ := result.RowsAffected(); num == 0 {
+			return fmt.Errorf("token %q not found", idOrName)
+		}
+		return nil
+	})
+}
+
+// Validate looks up a presented token and records its use
+func Validate(ctx context.Context, token string) (*wshrpc.ApiTokenInfo, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, fmt.Errorf("invalid api token")
+	}
+	return wstore.WithTxRtn(ctx, func(tx *wstore.TxWrap) (*wshrpc.ApiTokenInfo, error) {
+		var row tokenRow
+		found := tx.Get(&row, `SELECT id, name, tokenhash, commands, createdts, lastusedts FROM db_apitoken WHERE tokenhash = ?`, hashToken(token))
+		if !found {
+			return nil, fmt.Errorf("invalid api token")
+		}
+		row.LastUsedTs = time.Now().UnixMilli()
+		tx.Exec(`UPDATE db_apitoken SET lastusedts = ? WHERE id = ?`, row.LastUsedTs, row.Id)
+		return row.toInfo()
+	})
+}
@@ -0,0 +1,27 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package apitoken
+
+import (
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+)
+
+func TestIsCommandAllowed(t *testing.T) {
+	scoped := &wshrpc.ApiTokenInfo{Commands: []string{"getmeta", "setmeta"}}
+	if !IsCommandAllowed(scoped, "getmeta") {
+		t.Errorf("getmeta should be allowed")
+	}
+	if IsCommandAllowed(scoped, "fileread") {
+		t.Errorf("fileread should not be allowed for a scoped token")
+	}
+	all := &wshrpc.ApiTokenInfo{Commands: []string{AllCommands}}
+	if !IsCommandAllowed(all, "fileread") {
+		t.Errorf("fileread should be allowed for a '*' token")
+	}
+	if IsCommandAllowed(all, "authenticate") {
+		t.Errorf("commands outside the gateway list should never be allowed")
+	}
+}
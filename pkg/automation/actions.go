@@ -0,0 +1,299 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/SalyyS1/SLTerm/pkg/blockcontroller"
+	"github.com/SalyyS1/SLTerm/pkg/util/shellutil"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wcore"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+var templateRe = regexp.MustCompile(`\$\{(\.[^}]*)\}`)
+
+func validateAction(action wconfig.AutomationAction) error {
+	switch action.Type {
+	case ActionType_RunCommand:
+		if action.Cmd == "" {
+			return fmt.Errorf("cmd is required")
+		}
+	case ActionType_Notify:
+		if action.Title == "" && action.Body == "" {
+			return fmt.Errorf("title or body is required")
+		}
+	case ActionType_TabIndicator:
+		if action.Icon == "" {
+			return fmt.Errorf("icon is required")
+		}
+	case ActionType_Input:
+		if action.BlockId == "" || action.Input == "" {
+			return fmt.Errorf("blockid and input are required")
+		}
+	case ActionType_Publish:
+		if !wps.IsUserEvent(action.Event) {
+			return fmt.Errorf("can only publish custom events (%q namespace), got %q", wps.UserEventPrefix, action.Event)
+		}
+	case ActionType_PetReaction:
+		if action.Body == "" {
+			return fmt.Errorf("body is required")
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", action.Type)
+	}
+	return nil
+}
+
+// expands ${.path} references against the triggering event
+func expandTemplate(str string, eventVal any) string {
+	return expandTemplateWith(str, eventVal, nil)
+}
+
+// like expandTemplate, every expanded value is passed through escapeFn
+func expandTemplateWith(str string, eventVal any, escapeFn func(string) string) string {
+	return templateRe.ReplaceAllStringFunc(str, func(match string) string {
+		val := lookupTemplateValue(match, eventVal)
+		if escapeFn != nil {
+			return escapeFn(val)
+		}
+		return val
+	})
+}
+
+func lookupTemplateValue(match string, eventVal any) string {
+	path, err := wps.ParseEventPath(match[2 : len(match)-1])
+	if err != nil {
+		return ""
+	}
+	val, found := wps.LookupEventPath(eventVal, path)
+	if !found || val == nil {
+		return ""
+	}
+	if strVal, ok := val.(string); ok {
+		return strVal
+	}
+	barr, _ := json.Marshal(val)
+	return string(barr)
+}
+
+// event values can come from anywhere (output triggers expand lines a program printed), they must not
+// be able to submit or edit the line they are typed into
+func stripControlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// values expanded into a shell command are quoted so they are always a single word
+func shellQuoteValue(s string) string {
+	quoted := shellutil.HardQuote(stripControlChars(s))
+	if quoted == "" {
+		return `""`
+	}
+	return quoted
+}
+
+func expandAny(val any, eventVal any) any {
+	switch v := val.(type) {
+	case string:
+		return expandTemplate(v, eventVal)
+	case map[string]any:
+		rtn := make(map[string]any, len(v))
+		for key, elem := range v {
+			rtn[key] = expandAny(elem, eventVal)
+		}
+		return rtn
+	case []any:
+		rtn := make([]any, len(v))
+		for idx, elem := range v {
+			rtn[idx] = expandAny(elem, eventVal)
+		}
+		return rtn
+	}
+	return val
+}
+
+func expandAction(action wconfig.AutomationAction, eventVal any) wconfig.AutomationAction {
+	action.Cmd = expandTemplateWith(action.Cmd, eventVal, shellQuoteValue)
+	action.Cwd = expandTemplate(action.Cwd, eventVal)
+	action.Connection = expandTemplate(action.Connection, eventVal)
+	action.BlockId = expandTemplate(action.BlockId, eventVal)
+	action.TabId = expandTemplate(action.TabId, eventVal)
+	action.Input = expandTemplateWith(action.Input, eventVal, stripControlChars)
+	action.Title = expandTemplate(action.Title, eventVal)
+	action.Body = expandTemplate(action.Body, eventVal)
+	action.Icon = expandTemplate(action.Icon, eventVal)
+	action.Color = expandTemplate(action.Color, eventVal)
+	var scopes []string
+	for _, scope := range action.Scopes {
+		scopes = append(scopes, expandTemplate(scope, eventVal))
+	}
+	action.Scopes = scopes
+	action.Data = expandAny(action.Data, eventVal)
+	return action
+}
+
+func describeAction(action wconfig.AutomationAction) string {
+	switch action.Type {
+	case ActionType_RunCommand:
+		if action.BlockId != "" {
+			return fmt.Sprintf("run %q in block %s", action.Cmd, action.BlockId)
+		}
+		return fmt.Sprintf("run %q in a new block", action.Cmd)
+	case ActionType_Notify:
+		return fmt.Sprintf("notify %q: %q", action.Title, action.Body)
+	case ActionType_TabIndicator:
+		return fmt.Sprintf("set tab indicator %q", action.Icon)
+	case ActionType_Input:
+		return fmt.Sprintf("send input %q to block %s", action.Input, action.BlockId)
+	case ActionType_Publish:
+		return fmt.Sprintf("publish %s", action.Event)
+	case ActionType_PetReaction:
+		return fmt.Sprintf("pet reaction %q", action.Body)
+	}
+	return action.Type
+}
+
+// tab for new blocks / indicators: explicit tabid, else the tab of the event's tab or block scope
+func resolveTabId(ctx context.Context, action wconfig.AutomationAction, event *wps.WaveEvent) (string, error) {
+	if action.TabId != "" {
+		return action.TabId, nil
+	}
+	if action.BlockId != "" {
+		return wstore.DBFindTabForBlockId(ctx, action.BlockId)
+	}
+	for _, scope := range event.Scopes {
+		oref := waveobj.ParseORefNoErr(scope)
+		if oref == nil {
+			continue
+		}
+		switch oref.OType {
+		case waveobj.OType_Tab:
+			return oref.OID, nil
+		case waveobj.OType_Block:
+			return wstore.DBFindTabForBlockId(ctx, oref.OID)
+		}
+	}
+	return "", fmt.Errorf("no tabid set and the %q event has no tab or block scope", event.Event)
+}
+
+func runActions(ctx context.Context, actions []wconfig.AutomationAction, event *wps.WaveEvent, eventVal any, run bool) []wshrpc.AutomationActionResult {
+	var rtn []wshrpc.AutomationActionResult
+	for _, action := range actions {
+		action = expandAction(action, eventVal)
+		result := wshrpc.AutomationActionResult{
+			Type:        action.Type,
+			Description: describeAction(action),
+		}
+		if run {
+			err := executeAction(ctx, action, event)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Ran = true
+			}
+		}
+		rtn = append(rtn, result)
+	}
+	return rtn
+}
+
+func executeAction(ctx context.Context, action wconfig.AutomationAction, event *wps.WaveEvent) error {
+	if err := validateAction(action); err != nil {
+		return err
+	}
+	switch action.Type {
+	case ActionType_RunCommand:
+		if action.BlockId != "" {
+			return blockcontroller.SendInput(action.BlockId, &blockcontroller.BlockInputUnion{InputData: []byte(action.Cmd + "\r")})
+		}
+		tabId, err := resolveTabId(ctx, action, event)
+		if err != nil {
+			return err
+		}
+		return runCommandInNewBlock(ctx, tabId, action)
+	case ActionType_Notify:
+		notifyOpts := wshrpc.WaveNotificationOptions{Title: action.Title, Body: action.Body, Silent: action.Silent}
+		return wshclient.NotifyCommand(wshclient.GetBareRpcClient(), notifyOpts, &wshrpc.RpcOpts{Route: wshutil.ElectronRoute, Timeout: 2000})
+	case ActionType_TabIndicator:
+		tabId, err := resolveTabId(ctx, action, event)
+		if err != nil {
+			return err
+		}
+		wps.Broker.Publish(wps.WaveEvent{
+			Event:  wps.Event_TabIndicator,
+			Scopes: []string{waveobj.MakeORef(waveobj.OType_Tab, tabId).String()},
+			Sender: AutomationSender,
+			Data: wshrpc.TabIndicatorEventData{
+				TabId:     tabId,
+				Indicator: &wshrpc.TabIndicator{Icon: action.Icon, Color: action.Color, ClearOnFocus: true},
+			},
+		})
+		return nil
+	case ActionType_Input:
+		return blockcontroller.SendInput(action.BlockId, &blockcontroller.BlockInputUnion{InputData: []byte(action.Input)})
+	case ActionType_Publish:
+		wps.Broker.Publish(wps.WaveEvent{
+			Event:  action.Event,
+			Scopes: action.Scopes,
+			Sender: AutomationSender,
+			Data:   action.Data,
+		})
+		return nil
+	case ActionType_PetReaction:
+		wps.Broker.Publish(wps.WaveEvent{
+			Event:  wps.Event_PetReaction,
+			Sender: AutomationSender,
+			Data:   wshrpc.PetReactionEventData{Text: action.Body},
+		})
+		return nil
+	}
+	return fmt.Errorf("unknown action type %q", action.Type)
+}
+
+func runCommandInNewBlock(ctx context.Context, tabId string, action wconfig.AutomationAction) error {
+	ctx = waveobj.ContextWithUpdates(ctx)
+	meta := waveobj.MetaMapType{
+		waveobj.MetaKey_View:          "term",
+		waveobj.MetaKey_Controller:    "cmd",
+		waveobj.MetaKey_Cmd:           action.Cmd,
+		waveobj.MetaKey_CmdShell:      true,
+		waveobj.MetaKey_CmdRunOnce:    true,
+		waveobj.MetaKey_CmdRunOnStart: true,
+	}
+	if action.Cwd != "" {
+		meta[waveobj.MetaKey_CmdCwd] = action.Cwd
+	}
+	if action.Connection != "" {
+		meta[waveobj.MetaKey_Connection] = action.Connection
+	}
+	blockData, err := wcore.CreateBlock(ctx, tabId, &waveobj.BlockDef{Meta: meta}, nil)
+	if err != nil {
+		return fmt.Errorf("error creating block: %w", err)
+	}
+	layoutAction := waveobj.LayoutActionData{
+		ActionType: wcore.LayoutActionDataType_Insert,
+		BlockId:    blockData.OID,
+	}
+	err = wcore.QueueLayoutActionForTab(ctx, tabId, layoutAction)
+	if err != nil {
+		return fmt.Errorf("error queuing layout action: %w", err)
+	}
+	wps.Broker.SendUpdateEvents(waveobj.ContextGetUpdatesRtn(ctx))
+	return nil
+}
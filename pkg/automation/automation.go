@@ -0,0 +1,337 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// automation rules ("when <event> happens, run <actions>"), configured in automations.json.
+// the engine observes every published wps event, matches it against the enabled rules and
+// runs the actions of matching rules (subject to per-rule rate limits).  output triggers
+// (triggers.json, see outputtrigger.go) run the same actions when terminal output matches a regexp.
+package automation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+)
+
+const (
+	ActionType_RunCommand   = "runcommand"
+	ActionType_Notify       = "notify"
+	ActionType_TabIndicator = "tabindicator"
+	ActionType_Input        = "input"
+	ActionType_Publish      = "publish"
+	ActionType_PetReaction  = "petreaction"
+)
+
+const DefaultCooldown = time.Second
+const AutomationSender = "automation"
+
+const eventQueueSize = 256
+const actionTimeout = 10 * time.Second
+
+type ruleState struct {
+	FireTimes  []int64 // fire times (unix ms) in the last hour, for maxperhour
+	FireCount  int
+	LastFireTs int64
+	LastError  string
+}
+
+type engine struct {
+	lock       *sync.Mutex
+	rules      map[string]wconfig.AutomationRule
+	ruleErrors map[string]string
+	triggers   map[string]bool // event names with at least one enabled rule
+	states     map[string]*ruleState
+	lastEvents map[string]*wps.WaveEvent
+	eventQueue chan wps.WaveEvent
+}
+
+var globalEngine = &engine{
+	lock:       &sync.Mutex{},
+	rules:      make(map[string]wconfig.AutomationRule),
+	ruleErrors: make(map[string]string),
+	triggers:   make(map[string]bool),
+	states:     make(map[string]*ruleState),
+	lastEvents: make(map[string]*wps.WaveEvent),
+	eventQueue: make(chan wps.WaveEvent, eventQueueSize),
+}
+
+func InitAutomations() {
+	watcher := wconfig.GetWatcher()
+	globalEngine.setRules(watcher.GetFullConfig().Automations)
+	watcher.RegisterUpdateHandler(func(newConfig wconfig.FullConfigType) {
+		globalEngine.setRules(newConfig.Automations)
+	})
+	wps.Broker.AddEventObserver(globalEngine)
+	go globalEngine.runLoop()
+	initOutputTriggers()
+}
+
+func validateRule(rule wconfig.AutomationRule) error {
+	if rule.Trigger.Event == "" {
+		return fmt.Errorf("trigger.event is required")
+	}
+	for _, cond := range rule.Trigger.Conditions {
+		if _, err := wps.ParseEventFilter(cond); err != nil {
+			return err
+		}
+	}
+	if len(rule.Actions) == 0 {
+		return fmt.Errorf("no actions")
+	}
+	for idx, action := range rule.Actions {
+		if err := validateAction(action); err != nil {
+			return fmt.Errorf("action %d (%s): %w", idx, action.Type, err)
+		}
+	}
+	return nil
+}
+
+func (e *engine) setRules(rules map[string]wconfig.AutomationRule) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.rules = make(map[string]wconfig.AutomationRule)
+	e.ruleErrors = make(map[string]string)
+	e.triggers = make(map[string]bool)
+	for name, rule := range rules {
+		e.rules[name] = rule
+		if err := validateRule(rule); err != nil {
+			log.Printf("automation %q is invalid: %v\n", name, err)
+			e.ruleErrors[name] = err.Error()
+			continue
+		}
+		if !rule.Disabled {
+			e.triggers[rule.Trigger.Event] = true
+		}
+	}
+}
+
+// ObserveEvent is called synchronously from wps.Broker.Publish, matching happens on the engine goroutine
+func (e *engine) ObserveEvent(event wps.WaveEvent) {
+	e.lock.Lock()
+	isTrigger := e.triggers[event.Event]
+	if isTrigger {
+		e.lastEvents[event.Event] = &event
+	}
+	e.lock.Unlock()
+	if !isTrigger {
+		return
+	}
+	select {
+	case e.eventQueue <- event:
+	default:
+		log.Printf("automation: event queue full, dropping %q event\n", event.Event)
+	}
+}
+
+func (e *engine) runLoop() {
+	defer func() {
+		panichandler.PanicHandler("automation:runLoop", recover())
+	}()
+	for event := range e.eventQueue {
+		e.processEvent(&event)
+	}
+}
+
+type firedRule struct {
+	Name string
+	Rule wconfig.AutomationRule
+}
+
+func (e *engine) processEvent(event *wps.WaveEvent) {
+	eventVal, err := wps.EventToGeneric(event)
+	if err != nil {
+		log.Printf("automation: cannot decode %q event: %v\n", event.Event, err)
+		return
+	}
+	var fired []firedRule
+	e.lock.Lock()
+	for name, rule := range e.rules {
+		if rule.Disabled || e.ruleErrors[name] != "" || rule.Trigger.Event != event.Event {
+			continue
+		}
+		if matched, _ := evalTrigger(rule.Trigger, event, eventVal); !matched {
+			continue
+		}
+		if !e.checkRateLimit_nolock(name, rule, time.Now()) {
+			log.Printf("automation %q rate limited\n", name)
+			continue
+		}
+		fired = append(fired, firedRule{Name: name, Rule: rule})
+	}
+	e.lock.Unlock()
+	for _, fr := range fired {
+		go e.fireRule(fr.Name, fr.Rule, event, eventVal)
+	}
+}
+
+func (e *engine) fireRule(name string, rule wconfig.AutomationRule, event *wps.WaveEvent, eventVal any) {
+	defer func() {
+		panichandler.PanicHandler("automation:fireRule", recover())
+	}()
+	ctx, cancelFn := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancelFn()
+	results := runActions(ctx, rule.Actions, event, eventVal, !rule.DryRun)
+	var lastErr string
+	for _, result := range results {
+		if rule.DryRun {
+			log.Printf("automation %q (dryrun): would %s\n", name, result.Description)
+		}
+		if result.Error != "" {
+			log.Printf("automation %q: %s failed: %s\n", name, result.Type, result.Error)
+			lastErr = result.Error
+		}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if state := e.states[name]; state != nil {
+		state.LastError = lastErr
+	}
+}
+
+func scopeMatches(patterns []string, scopes []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		for _, scope := range scopes {
+			if pattern == scope || utilfn.StarMatchString(pattern, scope, ":") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func evalTrigger(trigger wconfig.AutomationTrigger, event *wps.WaveEvent, eventVal any) (bool, []wshrpc.AutomationConditionResult) {
+	var results []wshrpc.AutomationConditionResult
+	matched := trigger.Event == event.Event && scopeMatches(trigger.Scopes, event.Scopes)
+	if len(trigger.Scopes) > 0 {
+		results = append(results, wshrpc.AutomationConditionResult{
+			Condition: fmt.Sprintf("scope in %v", trigger.Scopes),
+			Matched:   scopeMatches(trigger.Scopes, event.Scopes),
+		})
+	}
+	for _, cond := range trigger.Conditions {
+		filter, err := wps.ParseEventFilter(cond)
+		if err != nil {
+			matched = false
+			results = append(results, wshrpc.AutomationConditionResult{Condition: cond, Error: err.Error()})
+			continue
+		}
+		condMatched := filter.Match(eventVal)
+		matched = matched && condMatched
+		results = append(results, wshrpc.AutomationConditionResult{Condition: cond, Matched: condMatched})
+	}
+	return matched, results
+}
+
+// returns true (and records the run) if the rule is allowed to fire now
+func (e *engine) checkRateLimit_nolock(name string, rule wconfig.AutomationRule, now time.Time) bool {
+	state := e.states[name]
+	if state == nil {
+		state = &ruleState{}
+		e.states[name] = state
+	}
+	return state.allow(rule.CooldownSecs, rule.MaxPerHour, now)
+}
+
+// returns true (and records the run) if a run is allowed at now given the cooldown (default 1s) and hourly limit
+func (state *ruleState) allow(cooldownSecs float64, maxPerHour int, now time.Time) bool {
+	nowMs := now.UnixMilli()
+	cooldown := DefaultCooldown
+	if cooldownSecs > 0 {
+		cooldown = time.Duration(cooldownSecs * float64(time.Second))
+	}
+	if state.LastFireTs > 0 && nowMs-state.LastFireTs < cooldown.Milliseconds() {
+		return false
+	}
+	hourAgo := now.Add(-time.Hour).UnixMilli()
+	fireTimes := state.FireTimes[:0]
+	for _, ts := range state.FireTimes {
+		if ts > hourAgo {
+			fireTimes = append(fireTimes, ts)
+		}
+	}
+	state.FireTimes = fireTimes
+	if maxPerHour > 0 && len(state.FireTimes) >= maxPerHour {
+		return false
+	}
+	state.FireTimes = append(state.FireTimes, nowMs)
+	state.FireCount++
+	state.LastFireTs = nowMs
+	return true
+}
+
+func ListRules() []wshrpc.AutomationRuleInfo {
+	e := globalEngine
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	rtn := make([]wshrpc.AutomationRuleInfo, 0, len(e.rules))
+	for name, rule := range e.rules {
+		info := wshrpc.AutomationRuleInfo{
+			Name:        name,
+			DisplayName: rule.DisplayName,
+			Event:       rule.Trigger.Event,
+			Disabled:    rule.Disabled,
+			DryRun:      rule.DryRun,
+			NumActions:  len(rule.Actions),
+			ConfigError: e.ruleErrors[name],
+		}
+		if state := e.states[name]; state != nil {
+			info.FireCount = state.FireCount
+			info.LastFireTs = state.LastFireTs
+			info.LastError = state.LastError
+		}
+		rtn = append(rtn, info)
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		return rtn[i].Name < rtn[j].Name
+	})
+	return rtn
+}
+
+// TestRule evaluates a rule against an event (or the last event seen for its trigger) without
+// touching its rate limit.  actions are described, and only executed when run is true.
+func TestRule(ctx context.Context, data wshrpc.CommandAutomationTestData) (*wshrpc.AutomationTestResult, error) {
+	e := globalEngine
+	e.lock.Lock()
+	rule, ok := e.rules[data.Name]
+	lastEvent := e.lastEvents[rule.Trigger.Event]
+	e.lock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("automation %q not found", data.Name)
+	}
+	if err := validateRule(rule); err != nil {
+		return nil, fmt.Errorf("automation %q is invalid: %w", data.Name, err)
+	}
+	event := data.Event
+	if event == nil {
+		event = lastEvent
+	}
+	if event == nil {
+		event = &wps.WaveEvent{Event: rule.Trigger.Event}
+	}
+	if event.Event == "" {
+		event.Event = rule.Trigger.Event
+	}
+	eventVal, err := wps.EventToGeneric(event)
+	if err != nil {
+		return nil, fmt.Errorf("decoding event: %w", err)
+	}
+	rtn := &wshrpc.AutomationTestResult{Name: data.Name, Event: event}
+	rtn.Matched, rtn.Conditions = evalTrigger(rule.Trigger, event, eventVal)
+	if !rtn.Matched {
+		return rtn, nil
+	}
+	rtn.Actions = runActions(ctx, rule.Actions, event, eventVal, data.Run)
+	return rtn, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package automation
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+)
+
+func TestEvalTrigger(t *testing.T) {
+	event := &wps.WaveEvent{
+		Event:  wps.Event_BlockJobStatus,
+		Scopes: []string{"block:abc"},
+		Data:   map[string]any{"blockid": "abc", "status": "done", "exitcode": 1},
+	}
+	eventVal, err := wps.EventToGeneric(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trigger := wconfig.AutomationTrigger{
+		Event:      wps.Event_BlockJobStatus,
+		Scopes:     []string{"block:*"},
+		Conditions: []string{".data.status==done", ".data.exitcode!=0"},
+	}
+	if matched, _ := evalTrigger(trigger, event, eventVal); !matched {
+		t.Errorf("expected trigger to match")
+	}
+	trigger.Conditions = append(trigger.Conditions, ".data.exitcode>5")
+	matched, results := evalTrigger(trigger, event, eventVal)
+	if matched {
+		t.Errorf("expected trigger not to match")
+	}
+	if len(results) != 4 || results[3].Matched {
+		t.Errorf("unexpected condition results: %+v", results)
+	}
+	trigger = wconfig.AutomationTrigger{Event: wps.Event_BlockJobStatus, Scopes: []string{"tab:*"}}
+	if matched, _ := evalTrigger(trigger, event, eventVal); matched {
+		t.Errorf("expected scope mismatch")
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	eventVal := map[string]any{
+		"scopes": []any{"block:abc"},
+		"data":   map[string]any{"blockid": "abc", "exitcode": float64(2)},
+	}
+	tests := map[string]string{
+		"echo ${.data.blockid}":        "echo abc",
+		"exit ${.data.exitcode}":       "exit 2",
+		"${.scopes[0]} ${.data.nope}!": "block:abc !",
+		"no templates":                 "no templates",
+	}
+	for input, want := range tests {
+		if got := expandTemplate(input, eventVal); got != want {
+			t.Errorf("expandTemplate(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestExpandActionEscaping(t *testing.T) {
+	eventVal := map[string]any{
+		"data": map[string]any{"line": "done; rm -rf ~\r", "groups": []any{"$(id)`id`"}},
+	}
+	action := wconfig.AutomationAction{
+		Type:  ActionType_RunCommand,
+		Cmd:   "echo ${.data.line} ${.data.groups[0]}",
+		Input: "got ${.data.line}",
+		Title: "${.data.line}",
+	}
+	expanded := expandAction(action, eventVal)
+	if want := "echo \"done; rm -rf ~ \" \"\\$(id)\\`id\\`\""; expanded.Cmd != want {
+		t.Errorf("cmd = %q, want %q", expanded.Cmd, want)
+	}
+	if want := "got done; rm -rf ~ "; expanded.Input != want {
+		t.Errorf("input = %q, want %q", expanded.Input, want)
+	}
+	if expanded.Title != "done; rm -rf ~\r" {
+		t.Errorf("title should not be escaped, got %q", expanded.Title)
+	}
+	trigger := wconfig.OutputTrigger{
+		Pattern: "error: (.*)",
+		Actions: []wconfig.AutomationAction{{Type: ActionType_Input, Input: "${.data.groups[0]}\r"}},
+	}
+	if _, err := compileTrigger("t", trigger); err == nil {
+		t.Errorf("expected output trigger with a templated input to be rejected")
+	}
+	trigger.Actions[0].Input = "retry\r"
+	if _, err := compileTrigger("t", trigger); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	e := &engine{lock: &sync.Mutex{}, states: make(map[string]*ruleState)}
+	rule := wconfig.AutomationRule{CooldownSecs: 10, MaxPerHour: 2}
+	now := time.Now()
+	if !e.checkRateLimit_nolock("r", rule, now) {
+		t.Fatalf("first run should be allowed")
+	}
+	if e.checkRateLimit_nolock("r", rule, now.Add(5*time.Second)) {
+		t.Errorf("run inside cooldown should be limited")
+	}
+	if !e.checkRateLimit_nolock("r", rule, now.Add(11*time.Second)) {
+		t.Errorf("run after cooldown should be allowed")
+	}
+	if e.checkRateLimit_nolock("r", rule, now.Add(30*time.Second)) {
+		t.Errorf("third run in an hour should be limited by maxperhour")
+	}
+	if !e.checkRateLimit_nolock("r", rule, now.Add(61*time.Minute)) {
+		t.Errorf("run after an hour should be allowed")
+	}
+	if e.states["r"].FireCount != 3 {
+		t.Errorf("expected 3 recorded runs, got %d", e.states["r"].FireCount)
+	}
+}
+
+func TestSelectTriggers(t *testing.T) {
+	triggers := []*compiledTrigger{
+		{Name: "global"},
+		{Name: "optin", Trigger: wconfig.OutputTrigger{OptIn: true}},
+		{Name: "prod", Trigger: wconfig.OutputTrigger{Connections: []string{"*@prod-*"}}},
+		{Name: "local", Trigger: wconfig.OutputTrigger{Connections: []string{"local"}}},
+	}
+	names := func(cts []*compiledTrigger) string {
+		var rtn []string
+		for _, ct := range cts {
+			rtn = append(rtn, ct.Name)
+		}
+		return strings.Join(rtn, ",")
+	}
+	tests := []struct {
+		connName  string
+		connList  []string
+		blockList []string
+		want      string
+	}{
+		{"", nil, nil, "global,local"},
+		{"root@prod-db", nil, nil, "global,prod"},
+		{"root@prod-db", []string{"optin"}, []string{"-global"}, "optin,prod"},
+		{"root@prod-db", []string{"-prod"}, []string{"prod"}, "global,prod"},
+		{"user@dev", nil, []string{"local"}, "global,local"},
+	}
+	for _, test := range tests {
+		got := names(selectTriggers(triggers, test.connName, test.connList, test.blockList))
+		if got != test.want {
+			t.Errorf("selectTriggers(%q, %v, %v) = %q, want %q", test.connName, test.connList, test.blockList, got, test.want)
+		}
+	}
+}
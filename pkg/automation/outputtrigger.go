@@ -0,0 +1,373 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package automation
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/util/termutil"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+// output triggers (triggers.json) match regexes against the lines a terminal prints.  the engine
+// observes the blockfile append events that the shell and durable job controllers already publish,
+// so the pty read loops only pay for a channel send.  chunks are reassembled into lines and escape
+// sequences are removed before matching.  the unterminated last line is matched as well (so prompts
+// like "Continue? [y/N] " can be answered), a trigger that fired on a partial line doesn't fire again
+// when that line is completed.
+
+const outputQueueSize = 1024
+const blockTriggersCacheTime = 5 * time.Second
+
+type OutputTriggerEventData struct {
+	BlockId    string   `json:"blockid"`
+	Connection string   `json:"connection,omitempty"`
+	Trigger    string   `json:"trigger"`
+	Line       string   `json:"line"`
+	Match      string   `json:"match"`
+	Groups     []string `json:"groups,omitempty"` // groups[0] is the first capture group
+}
+
+type compiledTrigger struct {
+	Name    string
+	Trigger wconfig.OutputTrigger
+	Re      *regexp.Regexp
+}
+
+type outputChunk struct {
+	BlockId string
+	Data64  string
+	Closed  bool
+}
+
+type blockOutputState struct {
+	Lines        termutil.LineSplitter
+	PartialFired map[string]bool // triggers that fired on the current partial line
+	ConnName     string
+	Triggers     []*compiledTrigger
+	ResolvedTs   time.Time
+	ConfigGen    int
+}
+
+type outputEngine struct {
+	lock      *sync.Mutex
+	triggers  []*compiledTrigger // sorted by name
+	configGen int
+	states    map[string]*ruleState // rate limits, keyed by "<trigger>:<blockid>"
+	active    atomic.Bool
+	dropped   atomic.Int64
+	queue     chan outputChunk
+	blocks    map[string]*blockOutputState // only used by the runLoop goroutine
+}
+
+var globalOutputEngine = &outputEngine{
+	lock:   &sync.Mutex{},
+	states: make(map[string]*ruleState),
+	queue:  make(chan outputChunk, outputQueueSize),
+	blocks: make(map[string]*blockOutputState),
+}
+
+func initOutputTriggers() {
+	watcher := wconfig.GetWatcher()
+	globalOutputEngine.setTriggers(watcher.GetFullConfig().Triggers)
+	watcher.RegisterUpdateHandler(func(newConfig wconfig.FullConfigType) {
+		globalOutputEngine.setTriggers(newConfig.Triggers)
+	})
+	wps.Broker.AddEventObserver(globalOutputEngine)
+	go globalOutputEngine.runLoop()
+}
+
+func compileTrigger(name string, trigger wconfig.OutputTrigger) (*compiledTrigger, error) {
+	if trigger.Pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	re, err := regexp.Compile(trigger.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	for _, connPattern := range trigger.Connections {
+		if _, err := path.Match(connPattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid connection pattern %q: %w", connPattern, err)
+		}
+	}
+	if len(trigger.Actions) == 0 {
+		return nil, fmt.Errorf("no actions")
+	}
+	for idx, action := range trigger.Actions {
+		if action.Type == ActionType_Input && action.BlockId == "" {
+			action.BlockId = "-" // defaults to the matching block
+		}
+		// the matched output is untrusted, it is never typed into a shell
+		if action.Type == ActionType_Input && (templateRe.MatchString(action.Input) || templateRe.MatchString(action.BlockId)) {
+			return nil, fmt.Errorf("action %d (%s): input actions of output triggers cannot use ${...} templates", idx, action.Type)
+		}
+		if err := validateAction(action); err != nil {
+			return nil, fmt.Errorf("action %d (%s): %w", idx, action.Type, err)
+		}
+	}
+	return &compiledTrigger{Name: name, Trigger: trigger, Re: re}, nil
+}
+
+func (e *outputEngine) setTriggers(triggers map[string]wconfig.OutputTrigger) {
+	var compiled []*compiledTrigger
+	for name, trigger := range triggers {
+		if trigger.Disabled {
+			continue
+		}
+		ct, err := compileTrigger(name, trigger)
+		if err != nil {
+			log.Printf("output trigger %q is invalid: %v\n", name, err)
+			continue
+		}
+		compiled = append(compiled, ct)
+	}
+	sort.Slice(compiled, func(i, j int) bool {
+		return compiled[i].Name < compiled[j].Name
+	})
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.triggers = compiled
+	e.configGen++
+	e.active.Store(len(compiled) > 0)
+}
+
+// ObserveEvent is called synchronously from wps.Broker.Publish (from the pty read loops for blockfile
+// events), so it only queues the chunk.  decoding and matching happen on the runLoop goroutine.
+func (e *outputEngine) ObserveEvent(event wps.WaveEvent) {
+	if !e.active.Load() {
+		return
+	}
+	var chunk outputChunk
+	switch event.Event {
+	case wps.Event_BlockFile:
+		fileData, ok := event.Data.(*wps.WSFileEventData)
+		if !ok || fileData.FileOp != wps.FileOp_Append || fileData.FileName != wavebase.BlockFile_Term {
+			return
+		}
+		if !event.HasScope(waveobj.MakeORef(waveobj.OType_Block, fileData.ZoneId).String()) {
+			return
+		}
+		chunk = outputChunk{BlockId: fileData.ZoneId, Data64: fileData.Data64}
+	case wps.Event_BlockClose:
+		blockId, ok := event.Data.(string)
+		if !ok {
+			return
+		}
+		chunk = outputChunk{BlockId: blockId, Closed: true}
+	default:
+		return
+	}
+	select {
+	case e.queue <- chunk:
+	default:
+		if e.dropped.Add(1) == 1 {
+			log.Printf("output triggers: queue full, dropping terminal output\n")
+		}
+	}
+}
+
+func (e *outputEngine) runLoop() {
+	defer func() {
+		panichandler.PanicHandler("automation:outputTriggerLoop", recover())
+	}()
+	for chunk := range e.queue {
+		e.processChunk(chunk)
+	}
+}
+
+func (e *outputEngine) processChunk(chunk outputChunk) {
+	if chunk.Closed {
+		delete(e.blocks, chunk.BlockId)
+		e.lock.Lock()
+		for key := range e.states {
+			if strings.HasSuffix(key, ":"+chunk.BlockId) {
+				delete(e.states, key)
+			}
+		}
+		e.lock.Unlock()
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(chunk.Data64)
+	if err != nil {
+		return
+	}
+	state := e.getBlockState(chunk.BlockId)
+	if len(state.Triggers) == 0 {
+		state.Lines = termutil.LineSplitter{}
+		return
+	}
+	for _, line := range state.Lines.Write(data) {
+		for _, ct := range state.Triggers {
+			if state.PartialFired[ct.Name] {
+				continue
+			}
+			e.matchLine(ct, chunk.BlockId, state.ConnName, line)
+		}
+		state.PartialFired = nil
+	}
+	partial := state.Lines.Partial()
+	if partial == "" {
+		return
+	}
+	for _, ct := range state.Triggers {
+		if state.PartialFired[ct.Name] {
+			continue
+		}
+		if e.matchLine(ct, chunk.BlockId, state.ConnName, partial) {
+			if state.PartialFired == nil {
+				state.PartialFired = make(map[string]bool)
+			}
+			state.PartialFired[ct.Name] = true
+		}
+	}
+}
+
+// returns the block's state, re-resolving which triggers apply when the config changed or the cache expired
+func (e *outputEngine) getBlockState(blockId string) *blockOutputState {
+	state := e.blocks[blockId]
+	if state == nil {
+		state = &blockOutputState{}
+		e.blocks[blockId] = state
+	}
+	e.lock.Lock()
+	allTriggers := e.triggers
+	configGen := e.configGen
+	e.lock.Unlock()
+	if state.ConfigGen == configGen && time.Since(state.ResolvedTs) < blockTriggersCacheTime {
+		return state
+	}
+	state.ConfigGen = configGen
+	state.ResolvedTs = time.Now()
+	state.Triggers = nil
+	ctx, cancelFn := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelFn()
+	block, _ := wstore.DBGet[*waveobj.Block](ctx, blockId)
+	if block == nil || block.Meta.GetString(waveobj.MetaKey_View, "") != "term" {
+		return state
+	}
+	state.ConnName = block.Meta.GetString(waveobj.MetaKey_Connection, "")
+	var connList []string
+	if connConfig, ok := wconfig.GetWatcher().GetFullConfig().Connections[state.ConnName]; ok {
+		connList = connConfig.TermTriggers
+	}
+	state.Triggers = selectTriggers(allTriggers, state.ConnName, connList, block.Meta.GetStringList(waveobj.MetaKey_TermTriggers))
+	return state
+}
+
+func connNameMatches(patterns []string, connName string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	if connName == "" {
+		connName = "local"
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, connName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// picks the triggers that apply to a block.  a trigger is on by default unless it is opt-in or its
+// "connections" don't match.  the connection's "term:triggers" and then the block's "term:triggers"
+// turn triggers on ("name") or off ("-name").
+func selectTriggers(triggers []*compiledTrigger, connName string, connList []string, blockList []string) []*compiledTrigger {
+	var rtn []*compiledTrigger
+	for _, ct := range triggers {
+		enabled := !ct.Trigger.OptIn && connNameMatches(ct.Trigger.Connections, connName)
+		for _, name := range append(append([]string{}, connList...), blockList...) {
+			if name == ct.Name {
+				enabled = true
+			} else if name == "-"+ct.Name {
+				enabled = false
+			}
+		}
+		if enabled {
+			rtn = append(rtn, ct)
+		}
+	}
+	return rtn
+}
+
+// returns true if the trigger matched (even if it was rate limited)
+func (e *outputEngine) matchLine(ct *compiledTrigger, blockId string, connName string, line string) bool {
+	match := ct.Re.FindStringSubmatch(line)
+	if match == nil {
+		return false
+	}
+	e.lock.Lock()
+	stateKey := ct.Name + ":" + blockId
+	state := e.states[stateKey]
+	if state == nil {
+		state = &ruleState{}
+		e.states[stateKey] = state
+	}
+	allowed := state.allow(ct.Trigger.CooldownSecs, ct.Trigger.MaxPerHour, time.Now())
+	e.lock.Unlock()
+	if !allowed {
+		return true
+	}
+	event := &wps.WaveEvent{
+		Event:  wps.Event_TermOutput,
+		Scopes: []string{waveobj.MakeORef(waveobj.OType_Block, blockId).String()},
+		Data: OutputTriggerEventData{
+			BlockId:    blockId,
+			Connection: connName,
+			Trigger:    ct.Name,
+			Line:       line,
+			Match:      match[0],
+			Groups:     match[1:],
+		},
+	}
+	go e.fireTrigger(ct, blockId, event)
+	return true
+}
+
+func (e *outputEngine) fireTrigger(ct *compiledTrigger, blockId string, event *wps.WaveEvent) {
+	defer func() {
+		panichandler.PanicHandler("automation:fireOutputTrigger", recover())
+	}()
+	eventVal, err := wps.EventToGeneric(event)
+	if err != nil {
+		log.Printf("output trigger %q: cannot encode event: %v\n", ct.Name, err)
+		return
+	}
+	actions := make([]wconfig.AutomationAction, len(ct.Trigger.Actions))
+	for idx, action := range ct.Trigger.Actions {
+		if action.Type == ActionType_Input && action.BlockId == "" {
+			action.BlockId = blockId
+		}
+		actions[idx] = action
+	}
+	ctx, cancelFn := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancelFn()
+	var lastErr string
+	for _, result := range runActions(ctx, actions, event, eventVal, true) {
+		if result.Error != "" {
+			log.Printf("output trigger %q: %s failed: %s\n", ct.Name, result.Type, result.Error)
+			lastErr = result.Error
+		}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if state := e.states[ct.Name+":"+blockId]; state != nil {
+		state.LastError = lastErr
+	}
+}
@@ -22,7 +22,9 @@ import (
 	"github.com/SalyyS1/SLTerm/pkg/util/shellutil"
 	"github.com/SalyyS1/SLTerm/pkg/wavebase"
 	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
 	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
 	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
 	"github.com/SalyyS1/SLTerm/pkg/wslconn"
 	"github.com/SalyyS1/SLTerm/pkg/wstore"
@@ -120,8 +122,22 @@ func getAllControllers() map[string]Controller {
 	return result
 }
 
+// number of block controllers by shell proc status (for the /metrics endpoint)
+func GetControllerStatusCounts() map[string]int {
+	rtn := make(map[string]int)
+	for _, controller := range getAllControllers() {
+		status := controller.GetRuntimeStatus().ShellProcStatus
+		if status == "" {
+			status = "unknown"
+		}
+		rtn[status]++
+	}
+	return rtn
+}
+
 func InitBlockController() {
 	rpcClient := wshclient.GetBareRpcClient()
+	wps.Broker.AddEventObserver(globalInputGroupCache)
 	rpcClient.EventListener.On(wps.Event_BlockClose, handleBlockCloseEvent)
 	wshclient.EventSubCommand(rpcClient, wps.SubscriptionRequest{
 		Event:     wps.Event_BlockClose,
@@ -297,7 +313,7 @@ func DestroyBlockController(blockId string) {
 
 func sendConnMonitorInputNotification(controller Controller) {
 	connName := controller.GetConnName()
-	if connName == "" || conncontroller.IsLocalConnName(connName) || conncontroller.IsWslConnName(connName) {
+	if connName == "" || conncontroller.IsLocalConnName(connName) || conncontroller.IsWslConnName(connName) || conncontroller.IsTlsConnName(connName) {
 		return
 	}
 
@@ -323,6 +339,22 @@ func SendInput(blockId string, inputUnion *BlockInputUnion) error {
 	return controller.SendInput(inputUnion)
 }
 
+// like SendInput, for input the user typed into the block.  it is also sent to the rest of the
+// block's input group.
+func SendUserInput(blockId string, inputUnion *BlockInputUnion) error {
+	controller := getController(blockId)
+	if controller == nil {
+		return fmt.Errorf("no controller found for block %s", blockId)
+	}
+	sendConnMonitorInputNotification(controller)
+	err := controller.SendInput(inputUnion)
+	if err != nil {
+		return err
+	}
+	fanOutGroupInput(blockId, controller, inputUnion.InputData)
+	return nil
+}
+
 // only call this on shutdown
 func StopAllBlockControllersForShutdown() {
 	controllers := getAllControllers()
@@ -448,6 +480,13 @@ func CheckConnStatus(blockId string) error {
 		}
 		return nil
 	}
+	if conncontroller.IsTlsConnName(connName) {
+		isConnected, _ := conncontroller.IsConnected(connName)
+		if !isConnected {
+			return fmt.Errorf("not connected: %s", conncontroller.GetTlsConn(connName).GetStatus())
+		}
+		return nil
+	}
 	opts, err := remote.ParseOpts(connName)
 	if err != nil {
 		return fmt.Errorf("error parsing connection name: %w", err)
@@ -463,6 +502,23 @@ func CheckConnStatus(blockId string) error {
 	return nil
 }
 
+// resolves the wsh capabilities for a block's token.  "cmd:capabilities" wins over the
+// wsh:localcapabilities / wsh:remotecapabilities defaults.  remote blocks are also limited by the
+// capabilities of their connserver (wsh:remotecapabilities), so they can only be tightened.
+func resolveWshCapabilities(blockMeta waveobj.MetaMapType, isRemote bool) []string {
+	if _, ok := blockMeta[waveobj.MetaKey_CmdCapabilities]; ok {
+		return wshrpc.NormalizeCapabilities(blockMeta.GetStringList(waveobj.MetaKey_CmdCapabilities))
+	}
+	return getDefaultWshCapabilities(isRemote)
+}
+
+func getDefaultWshCapabilities(isRemote bool) []string {
+	if isRemote {
+		return conncontroller.GetRemoteWshCapabilities()
+	}
+	return wshrpc.NormalizeCapabilities(wconfig.GetWatcher().GetFullConfig().Settings.WshLocalCapabilities)
+}
+
 func makeSwapToken(ctx context.Context, logCtx context.Context, blockId string, blockMeta waveobj.MetaMapType, remoteName string, shellType string) *shellutil.TokenSwapEntry {
 	token := &shellutil.TokenSwapEntry{
 		Token: uuid.New().String(),
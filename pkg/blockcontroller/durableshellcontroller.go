@@ -218,6 +218,26 @@ func (dsc *DurableShellController) SendInput(inputUnion *BlockInputUnion) error
 	return jobcontroller.SendInput(context.Background(), data)
 }
 
+// ssh and tls connections can both run durable jobs
+func getRemoteJobConn(connName string) (shellexec.RemoteJobConn, error) {
+	if conncontroller.IsTlsConnName(connName) {
+		conn := conncontroller.MaybeGetTlsConn(connName)
+		if conn == nil {
+			return nil, fmt.Errorf("connection %q not found", connName)
+		}
+		return conn, nil
+	}
+	opts, err := remote.ParseOpts(connName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh remote name (%s): %w", connName, err)
+	}
+	conn := conncontroller.MaybeGetConn(opts)
+	if conn == nil {
+		return nil, fmt.Errorf("connection %q not found", connName)
+	}
+	return conn, nil
+}
+
 func (dsc *DurableShellController) startNewJob(ctx context.Context, blockMeta waveobj.MetaMapType, connName string) (string, error) {
 	termSize := waveobj.TermSize{
 		Rows: shellutil.DefaultTermRows,
@@ -225,13 +245,9 @@ func (dsc *DurableShellController) startNewJob(ctx context.Context, blockMeta wa
 	}
 	cmdStr := blockMeta.GetString(waveobj.MetaKey_Cmd, "")
 	cwd := blockMeta.GetString(waveobj.MetaKey_CmdCwd, "")
-	opts, err := remote.ParseOpts(connName)
+	conn, err := getRemoteJobConn(connName)
 	if err != nil {
-		return "", fmt.Errorf("invalid ssh remote name (%s): %w", connName, err)
-	}
-	conn := conncontroller.MaybeGetConn(opts)
-	if conn == nil {
-		return "", fmt.Errorf("connection %q not found", connName)
+		return "", err
 	}
 	connRoute := wshutil.MakeConnectionRouteId(connName)
 	remoteInfo, err := wshclient.RemoteGetInfoCommand(wshclient.GetBareRpcClient(), &wshrpc.RpcOpts{Route: connRoute, Timeout: 2000})
@@ -246,6 +262,8 @@ func (dsc *DurableShellController) startNewJob(ctx context.Context, blockMeta wa
 		SockName:  sockName,
 		BlockId:   dsc.BlockId,
 		Conn:      connName,
+
+		Capabilities: resolveWshCapabilities(blockMeta, true),
 	}
 	jwtStr, err := wshutil.MakeClientJWTToken(rpcContext)
 	if err != nil {
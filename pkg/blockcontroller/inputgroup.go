@@ -0,0 +1,174 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package blockcontroller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+// input groups: input typed into one member of a "term:inputgroup" is also sent to every other
+// enabled member.  membership lives in block meta so it survives restarts.  only running shell
+// controllers take part -- cmd and tsunami blocks never send or receive group input, so typing
+// into a group can't echo keystrokes into a program that isn't a shell.  only input the user typed
+// into the block (see SendUserInput) is fanned out, programmatic input (automations, wsh, share
+// viewers) goes to its block only.
+
+// the active input group of each block, read from the db on first use and dropped when the block is
+// updated or closed, so typing doesn't hit the db
+type inputGroupCache struct {
+	lock   sync.Mutex
+	gen    uint64
+	groups map[string]string
+}
+
+var globalInputGroupCache = &inputGroupCache{groups: make(map[string]string)}
+
+func (c *inputGroupCache) ObserveEvent(event wps.WaveEvent) {
+	switch event.Event {
+	case wps.Event_WaveObjUpdate:
+		update, ok := event.Data.(waveobj.WaveObjUpdate)
+		if !ok || update.OType != waveobj.OType_Block {
+			return
+		}
+		c.invalidate(update.OID)
+	case wps.Event_BlockClose:
+		if blockId, ok := event.Data.(string); ok {
+			c.invalidate(blockId)
+		}
+	}
+}
+
+func (c *inputGroupCache) invalidate(blockId string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.gen++
+	delete(c.groups, blockId)
+}
+
+func (c *inputGroupCache) getGroup(ctx context.Context, blockId string) string {
+	c.lock.Lock()
+	group, ok := c.groups[blockId]
+	gen := c.gen
+	c.lock.Unlock()
+	if ok {
+		return group
+	}
+	block, _ := wstore.DBGet[*waveobj.Block](ctx, blockId)
+	if block != nil {
+		group = getActiveInputGroup(block.Meta)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	// don't cache a value read before an invalidation
+	if c.gen == gen && block != nil {
+		c.groups[blockId] = group
+	}
+	return group
+}
+
+// returns the block's input group ("" if none or if the member is disabled)
+func getActiveInputGroup(meta waveobj.MetaMapType) string {
+	if meta.GetBool(waveobj.MetaKey_TermInputGroupDisabled, false) {
+		return ""
+	}
+	return meta.GetString(waveobj.MetaKey_TermInputGroup, "")
+}
+
+func isGroupInputController(controller Controller) bool {
+	switch c := controller.(type) {
+	case *ShellController:
+		if c.ControllerType != BlockController_Shell {
+			return false
+		}
+	case *DurableShellController:
+	default:
+		return false
+	}
+	return controller.GetRuntimeStatus().ShellProcStatus == Status_Running
+}
+
+// sends data to every running, enabled member of the group (except excludeBlockId).  returns the number of blocks written to.
+func sendGroupInput(ctx context.Context, group string, excludeBlockId string, data []byte) int {
+	var numSent int
+	for blockId, controller := range getAllControllers() {
+		if blockId == excludeBlockId || !isGroupInputController(controller) {
+			continue
+		}
+		if globalInputGroupCache.getGroup(ctx, blockId) != group {
+			continue
+		}
+		sendConnMonitorInputNotification(controller)
+		err := controller.SendInput(&BlockInputUnion{InputData: data})
+		if err != nil {
+			log.Printf("error sending input group %q input to block %s: %v\n", group, blockId, err)
+			continue
+		}
+		numSent++
+	}
+	return numSent
+}
+
+// called after input was sent to blockId, copies it to the rest of the block's input group
+func fanOutGroupInput(blockId string, controller Controller, data []byte) {
+	if len(data) == 0 || !isGroupInputController(controller) {
+		return
+	}
+	ctx, cancelFn := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancelFn()
+	group := globalInputGroupCache.getGroup(ctx, blockId)
+	if group == "" {
+		return
+	}
+	sendGroupInput(ctx, group, blockId, data)
+}
+
+// sends data to all running, enabled members of the group.  returns the number of blocks written to.
+func BroadcastGroupInput(ctx context.Context, group string, data []byte) (int, error) {
+	if group == "" {
+		return 0, fmt.Errorf("no input group specified")
+	}
+	return sendGroupInput(ctx, group, "", data), nil
+}
+
+// lists the members of an input group (all groups if group is "")
+func ListInputGroupMembers(ctx context.Context, group string) ([]wshrpc.InputGroupMember, error) {
+	blocks, err := wstore.DBGetAllObjsByType[*waveobj.Block](ctx, waveobj.OType_Block)
+	if err != nil {
+		return nil, fmt.Errorf("error getting blocks: %w", err)
+	}
+	var rtn []wshrpc.InputGroupMember
+	for _, block := range blocks {
+		memberGroup := block.Meta.GetString(waveobj.MetaKey_TermInputGroup, "")
+		if memberGroup == "" || (group != "" && memberGroup != group) {
+			continue
+		}
+		member := wshrpc.InputGroupMember{
+			Group:    memberGroup,
+			BlockId:  block.OID,
+			View:     block.Meta.GetString(waveobj.MetaKey_View, ""),
+			ConnName: block.Meta.GetString(waveobj.MetaKey_Connection, ""),
+			Disabled: block.Meta.GetBool(waveobj.MetaKey_TermInputGroupDisabled, false),
+		}
+		if controller := getController(block.OID); controller != nil {
+			member.Running = isGroupInputController(controller)
+		}
+		rtn = append(rtn, member)
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		if rtn[i].Group != rtn[j].Group {
+			return rtn[i].Group < rtn[j].Group
+		}
+		return rtn[i].BlockId < rtn[j].BlockId
+	})
+	return rtn, nil
+}
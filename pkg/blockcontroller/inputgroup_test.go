@@ -0,0 +1,113 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package blockcontroller
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+)
+
+// registers a running local shell controller for blockId with its input group already cached (so
+// the test never reads the db)
+func addTestShell(t *testing.T, blockId string, group string) *ShellController {
+	sc := &ShellController{
+		Lock:           &sync.Mutex{},
+		ControllerType: BlockController_Shell,
+		BlockId:        blockId,
+		ProcStatus:     Status_Running,
+		RunLock:        &atomic.Bool{},
+		ShellInputCh:   make(chan *BlockInputUnion, 8),
+	}
+	registryLock.Lock()
+	controllerRegistry[blockId] = sc
+	registryLock.Unlock()
+	globalInputGroupCache.lock.Lock()
+	globalInputGroupCache.groups[blockId] = group
+	globalInputGroupCache.lock.Unlock()
+	t.Cleanup(func() {
+		deleteController(blockId)
+		globalInputGroupCache.invalidate(blockId)
+	})
+	return sc
+}
+
+func drainInput(sc *ShellController) []string {
+	var rtn []string
+	for {
+		select {
+		case input := <-sc.ShellInputCh:
+			rtn = append(rtn, string(input.InputData))
+		default:
+			return rtn
+		}
+	}
+}
+
+func TestGroupInputFanOut(t *testing.T) {
+	a := addTestShell(t, "ig-a", "g1")
+	b := addTestShell(t, "ig-b", "g1")
+	c := addTestShell(t, "ig-c", "g2")
+	d := addTestShell(t, "ig-d", "")
+
+	if err := SendUserInput("ig-a", &BlockInputUnion{InputData: []byte("ls\r")}); err != nil {
+		t.Fatalf("SendUserInput: %v", err)
+	}
+	// the sender gets its input exactly once, only members of its group get a copy
+	want := map[*ShellController]int{a: 1, b: 1, c: 0, d: 0}
+	for sc, n := range want {
+		if got := drainInput(sc); len(got) != n {
+			t.Errorf("block %s got %q, want %d inputs", sc.BlockId, got, n)
+		}
+	}
+
+	// input to a block that isn't in a group stays there
+	if err := SendUserInput("ig-d", &BlockInputUnion{InputData: []byte("x")}); err != nil {
+		t.Fatalf("SendUserInput: %v", err)
+	}
+	if got := drainInput(d); len(got) != 1 {
+		t.Errorf("block ig-d got %q, want 1 input", got)
+	}
+	for _, sc := range []*ShellController{a, b, c} {
+		if got := drainInput(sc); len(got) != 0 {
+			t.Errorf("block %s got %q from an ungrouped block", sc.BlockId, got)
+		}
+	}
+}
+
+func TestProgrammaticInputNotFannedOut(t *testing.T) {
+	a := addTestShell(t, "ig-p1", "g1")
+	b := addTestShell(t, "ig-p2", "g1")
+
+	if err := SendInput("ig-p1", &BlockInputUnion{InputData: []byte("echo hi\r")}); err != nil {
+		t.Fatalf("SendInput: %v", err)
+	}
+	if got := drainInput(a); len(got) != 1 {
+		t.Errorf("target got %q, want 1 input", got)
+	}
+	if got := drainInput(b); len(got) != 0 {
+		t.Errorf("group member got programmatic input %q", got)
+	}
+}
+
+func TestInputGroupCacheInvalidation(t *testing.T) {
+	addTestShell(t, "ig-i1", "g1")
+	addTestShell(t, "ig-i2", "g1")
+
+	globalInputGroupCache.ObserveEvent(wps.WaveEvent{
+		Event: wps.Event_WaveObjUpdate,
+		Data:  waveobj.WaveObjUpdate{UpdateType: waveobj.UpdateType_Update, OType: waveobj.OType_Block, OID: "ig-i1"},
+	})
+	globalInputGroupCache.ObserveEvent(wps.WaveEvent{Event: wps.Event_BlockClose, Data: "ig-i2"})
+	globalInputGroupCache.lock.Lock()
+	defer globalInputGroupCache.lock.Unlock()
+	for _, blockId := range []string{"ig-i1", "ig-i2"} {
+		if _, ok := globalInputGroupCache.groups[blockId]; ok {
+			t.Errorf("block %s still cached after update", blockId)
+		}
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/SalyyS1/SLTerm/pkg/wavebase"
 	"github.com/SalyyS1/SLTerm/pkg/waveobj"
 	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wcore"
 	"github.com/SalyyS1/SLTerm/pkg/wps"
 	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
 	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
@@ -356,6 +357,8 @@ func (bc *ShellController) getConnUnion(logCtx context.Context, remoteName strin
 	} else if conncontroller.IsLocalConnName(remoteName) {
 		rtn.ConnType = ConnType_Local
 		rtn.WshEnabled = wshEnabled
+	} else if conncontroller.IsTlsConnName(remoteName) {
+		return ConnUnion{}, fmt.Errorf("tls connection %s only supports durable terminals (term:durable)", remoteName)
 	} else {
 		opts, err := remote.ParseOpts(remoteName)
 		if err != nil {
@@ -373,7 +376,7 @@ func (bc *ShellController) getConnUnion(logCtx context.Context, remoteName strin
 		rtn.SshConn = conn
 		rtn.WshEnabled = wshEnabled && conn.WshEnabled.Load()
 	}
-	err := rtn.getRemoteInfoAndShellType(blockMeta)
+	err := rtn.getRemoteInfoAndShellType(bc.TabId, blockMeta)
 	if err != nil {
 		return ConnUnion{}, err
 	}
@@ -444,6 +447,8 @@ func (bc *ShellController) setupAndStartShellProcess(logCtx context.Context, rc
 				SockName:  sockName,
 				BlockId:   bc.BlockId,
 				Conn:      wslConn.GetName(),
+
+				Capabilities: resolveWshCapabilities(blockMeta, true),
 			}
 			jwtStr, err := wshutil.MakeClientJWTToken(rpcContext)
 			if err != nil {
@@ -477,6 +482,8 @@ func (bc *ShellController) setupAndStartShellProcess(logCtx context.Context, rc
 				SockName:  sockName,
 				BlockId:   bc.BlockId,
 				Conn:      conn.Opts.String(),
+
+				Capabilities: resolveWshCapabilities(blockMeta, true),
 			}
 			jwtStr, err := wshutil.MakeClientJWTToken(rpcContext)
 			if err != nil {
@@ -503,6 +510,8 @@ func (bc *ShellController) setupAndStartShellProcess(logCtx context.Context, rc
 				ProcRoute: true,
 				SockName:  sockName,
 				BlockId:   bc.BlockId,
+
+				Capabilities: resolveWshCapabilities(blockMeta, false),
 			}
 			jwtStr, err := wshutil.MakeClientJWTToken(rpcContext)
 			if err != nil {
@@ -512,7 +521,7 @@ func (bc *ShellController) setupAndStartShellProcess(logCtx context.Context, rc
 			swapToken.Env[wshutil.WaveJwtTokenVarName] = jwtStr
 		}
 		cmdOpts.ShellPath = connUnion.ShellPath
-		cmdOpts.ShellOpts = getLocalShellOpts(blockMeta)
+		cmdOpts.ShellOpts = getLocalShellOpts(bc.TabId, blockMeta)
 		shellProc, err = shellexec.StartLocalShellProc(logCtx, rc.TermSize, cmdStr, cmdOpts, remoteName)
 		if err != nil {
 			return nil, err
@@ -629,7 +638,7 @@ func (bc *ShellController) manageRunningShellProcess(shellProc *shellexec.ShellP
 	return nil
 }
 
-func (union *ConnUnion) getRemoteInfoAndShellType(blockMeta waveobj.MetaMapType) error {
+func (union *ConnUnion) getRemoteInfoAndShellType(tabId string, blockMeta waveobj.MetaMapType) error {
 	if !union.WshEnabled {
 		return nil
 	}
@@ -644,7 +653,7 @@ func (union *ConnUnion) getRemoteInfoAndShellType(blockMeta waveobj.MetaMapType)
 		union.ShellPath = remoteInfo.Shell
 		union.HomeDir = remoteInfo.HomeDir
 	} else {
-		shellPath, err := getLocalShellPath(blockMeta)
+		shellPath, err := getLocalShellPath(tabId, blockMeta)
 		if err != nil {
 			return err
 		}
@@ -680,7 +689,7 @@ func checkCloseOnExit(blockId string, exitCode int) {
 	}
 }
 
-func getLocalShellPath(blockMeta waveobj.MetaMapType) (string, error) {
+func getLocalShellPath(tabId string, blockMeta waveobj.MetaMapType) (string, error) {
 	shellPath := blockMeta.GetString(waveobj.MetaKey_TermLocalShellPath, "")
 	if shellPath != "" {
 		return shellPath, nil
@@ -703,25 +712,32 @@ func getLocalShellPath(blockMeta waveobj.MetaMapType) (string, error) {
 		return "", fmt.Errorf("unsupported local connection type: %q", connName)
 	}
 
-	settings := wconfig.GetWatcher().GetFullConfig().Settings
+	settings := resolveTabSettings(tabId)
 	if settings.TermLocalShellPath != "" {
 		return settings.TermLocalShellPath, nil
 	}
 	return shellutil.DetectLocalShellPath(), nil
 }
 
-func getLocalShellOpts(blockMeta waveobj.MetaMapType) []string {
+func getLocalShellOpts(tabId string, blockMeta waveobj.MetaMapType) []string {
 	if blockMeta.HasKey(waveobj.MetaKey_TermLocalShellOpts) {
 		opts := blockMeta.GetStringList(waveobj.MetaKey_TermLocalShellOpts)
 		return append([]string{}, opts...)
 	}
-	settings := wconfig.GetWatcher().GetFullConfig().Settings
+	settings := resolveTabSettings(tabId)
 	if len(settings.TermLocalShellOpts) > 0 {
 		return append([]string{}, settings.TermLocalShellOpts...)
 	}
 	return nil
 }
 
+// global settings with the tab's workspace settings layer applied
+func resolveTabSettings(tabId string) wconfig.SettingsType {
+	ctx, cancelFn := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancelFn()
+	return wcore.ResolveTabSettings(ctx, tabId)
+}
+
 // for "cmd" type blocks
 func createCmdStrAndOpts(blockId string, blockMeta waveobj.MetaMapType, connName string) (string, *shellexec.CommandOptsType, error) {
 	var cmdStr string
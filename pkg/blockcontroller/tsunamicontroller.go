@@ -21,7 +21,6 @@ import (
 	"github.com/SalyyS1/SLTerm/pkg/waveapputil"
 	"github.com/SalyyS1/SLTerm/pkg/wavebase"
 	"github.com/SalyyS1/SLTerm/pkg/waveobj"
-	"github.com/SalyyS1/SLTerm/pkg/wconfig"
 	"github.com/SalyyS1/SLTerm/pkg/wps"
 	"github.com/SalyyS1/SLTerm/pkg/wstore"
 	"github.com/SalyyS1/SLTerm/tsunami/build"
@@ -117,7 +116,7 @@ func (c *TsunamiController) Start(ctx context.Context, blockMeta waveobj.MetaMap
 	defer c.runLock.Unlock()
 
 	scaffoldPath := waveapputil.GetTsunamiScaffoldPath()
-	settings := wconfig.GetWatcher().GetFullConfig().Settings
+	settings := resolveTabSettings(c.tabId)
 	sdkReplacePath := settings.TsunamiSdkReplacePath
 	sdkVersion := settings.TsunamiSdkVersion
 	if sdkVersion == "" {
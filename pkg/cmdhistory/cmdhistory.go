@@ -0,0 +1,296 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// unified command history.  shell integration reports every command the shell runs (OSC 16162 "C")
+// and its exit code ("D"), the terminal forwards those as shell:lastcmd / shell:lastcmdexitcode rtinfo
+// updates.  each command is written to db_cmdhistory with its block, connection, shell type and cwd so
+// history can be searched across all blocks, connections and shells (see "wsh history").
+package cmdhistory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+const DefaultMaxEntries = 50000
+const DefaultQueryItems = 1000
+const MaxQueryItems = 50000
+const MaxCmdLen = 8192
+
+const opQueueSize = 256
+const compactInterval = time.Hour
+
+const (
+	opType_Start = "start"
+	opType_Done  = "done"
+)
+
+type historyOp struct {
+	OpType   string
+	BlockId  string
+	Cmd      string // start only, empty if the shell didn't report the command
+	ExitCode *int   // done only
+	Ts       int64
+}
+
+// the row of the command that is running in a block (only touched by writeLoop)
+type runningCmd struct {
+	RowId   int64
+	StartTs int64
+}
+
+var opQueue = make(chan historyOp, opQueueSize)
+
+type historyRow struct {
+	Id         int64         `db:"id"`
+	Ts         int64         `db:"ts"`
+	Cmd        string        `db:"cmd"`
+	BlockId    string        `db:"blockid"`
+	Connection string        `db:"connection"`
+	ShellType  string        `db:"shelltype"`
+	Cwd        string        `db:"cwd"`
+	ExitCode   sql.NullInt64 `db:"exitcode"`
+	DurationMs int64         `db:"durationms"`
+	Count      int           `db:"count"`
+}
+
+func InitCmdHistory() {
+	go writeLoop()
+	go compactLoop()
+}
+
+func isEnabled() bool {
+	return !wconfig.GetWatcher().GetFullConfig().Settings.HistoryDisabled
+}
+
+// HandleRTInfoUpdate is called with every rtinfo update, it never blocks.  a command start sets
+// shell:lastcmd (and clears the exit code), a command end only sets shell:lastcmdexitcode.
+func HandleRTInfoUpdate(oref waveobj.ORef, data map[string]any) {
+	if oref.OType != waveobj.OType_Block {
+		return
+	}
+	var op historyOp
+	if cmdVal, ok := data["shell:lastcmd"]; ok {
+		cmdStr, _ := cmdVal.(string)
+		op = historyOp{OpType: opType_Start, BlockId: oref.OID, Cmd: cmdStr}
+	} else if exitVal, ok := data["shell:lastcmdexitcode"]; ok {
+		op = historyOp{OpType: opType_Done, BlockId: oref.OID}
+		if exitCode, ok := exitVal.(float64); ok {
+			exitCodeInt := int(exitCode)
+			op.ExitCode = &exitCodeInt
+		}
+	} else {
+		return
+	}
+	op.Ts = time.Now().UnixMilli()
+	select {
+	case opQueue <- op:
+	default:
+		log.Printf("cmdhistory: queue full, dropping %s for block %s\n", op.OpType, op.BlockId)
+	}
+}
+
+// commands starting with a space are not recorded (like bash's HISTCONTROL=ignorespace), neither are
+// the placeholders the terminal uses for oversized commands
+func shouldRecord(cmd string) bool {
+	if strings.TrimSpace(cmd) == "" || strings.HasPrefix(cmd, " ") || len(cmd) > MaxCmdLen {
+		return false
+	}
+	if strings.HasPrefix(cmd, "# command too large") {
+		return false
+	}
+	return true
+}
+
+func writeLoop() {
+	defer func() {
+		panichandler.PanicHandler("cmdhistory:writeLoop", recover())
+	}()
+	running := make(map[string]runningCmd)
+	for op := range opQueue {
+		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+		err := processOp(ctx, op, running)
+		cancelFn()
+		if err != nil {
+			log.Printf("cmdhistory: error processing %s for block %s: %v\n", op.OpType, op.BlockId, err)
+		}
+	}
+}
+
+func processOp(ctx context.Context, op historyOp, running map[string]runningCmd) error {
+	if op.OpType == opType_Done {
+		cur, ok := running[op.BlockId]
+		if !ok {
+			return nil
+		}
+		delete(running, op.BlockId)
+		return wstore.WithTx(ctx, func(tx *wstore.TxWrap) error {
+			query := `UPDATE db_cmdhistory SET exitcode = ?, durationms = ? WHERE id = ?`
+			tx.Exec(query, op.ExitCode, op.Ts-cur.StartTs, cur.RowId)
+			return nil
+		})
+	}
+	delete(running, op.BlockId)
+	if !isEnabled() || !shouldRecord(op.Cmd) {
+		return nil
+	}
+	entry := wshrpc.HistoryEntry{Ts: op.Ts, Cmd: op.Cmd, BlockId: op.BlockId}
+	block, err := wstore.DBGet[*waveobj.Block](ctx, op.BlockId)
+	if err != nil {
+		return fmt.Errorf("getting block: %w", err)
+	}
+	if block != nil {
+		entry.Connection = block.Meta.GetString(waveobj.MetaKey_Connection, "")
+		entry.Cwd = block.Meta.GetString(waveobj.MetaKey_CmdCwd, "")
+	}
+	if rtInfo := wstore.GetRTInfo(waveobj.MakeORef(waveobj.OType_Block, op.BlockId)); rtInfo != nil {
+		entry.ShellType = rtInfo.ShellType
+	}
+	rowId, err := insertEntry(ctx, entry)
+	if err != nil {
+		return err
+	}
+	running[op.BlockId] = runningCmd{RowId: rowId, StartTs: op.Ts}
+	return nil
+}
+
+func insertEntry(ctx context.Context, entry wshrpc.HistoryEntry) (int64, error) {
+	return wstore.WithTxRtn(ctx, func(tx *wstore.TxWrap) (int64, error) {
+		query := `INSERT INTO db_cmdhistory (ts, cmd, blockid, connection, shelltype, cwd, exitcode, durationms) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+		result := tx.Exec(query, entry.Ts, entry.Cmd, entry.BlockId, entry.Connection, entry.ShellType, entry.Cwd, entry.ExitCode, entry.DurationMs)
+		if result == nil {
+			return 0, nil
+		}
+		return result.LastInsertId()
+	})
+}
+
+// QueryHistory returns matching entries newest first
+func QueryHistory(ctx context.Context, data wshrpc.CommandHistoryQueryData) ([]wshrpc.HistoryEntry, error) {
+	maxItems := data.MaxItems
+	if maxItems <= 0 {
+		maxItems = DefaultQueryItems
+	}
+	if maxItems > MaxQueryItems {
+		maxItems = MaxQueryItems
+	}
+	var where []string
+	var args []any
+	if data.Cwd != "" {
+		where = append(where, `cwd = ?`)
+		args = append(args, data.Cwd)
+	}
+	if data.Connection != "" {
+		connName := data.Connection
+		if connName == "local" {
+			connName = ""
+		}
+		where = append(where, `connection = ?`)
+		args = append(args, connName)
+	}
+	if data.BlockId != "" {
+		where = append(where, `blockid = ?`)
+		args = append(args, data.BlockId)
+	}
+	switch data.ExitStatus {
+	case "":
+	case "success":
+		where = append(where, `exitcode = 0`)
+	case "failed":
+		where = append(where, `exitcode IS NOT NULL AND exitcode != 0`)
+	default:
+		return nil, fmt.Errorf("invalid exit status %q (must be \"success\" or \"failed\")", data.ExitStatus)
+	}
+	whereStr := ""
+	if len(where) > 0 {
+		whereStr = ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	// for unique queries sqlite takes the bare columns from the row with max(id), the latest run
+	query := `SELECT id, ts, cmd, blockid, connection, shelltype, cwd, exitcode, durationms, 1 AS count FROM db_cmdhistory` + whereStr + ` ORDER BY id DESC LIMIT ?`
+	if data.Unique {
+		query = `SELECT max(id) AS id, ts, cmd, blockid, connection, shelltype, cwd, exitcode, durationms, count(*) AS count FROM db_cmdhistory` + whereStr + ` GROUP BY cmd ORDER BY id DESC LIMIT ?`
+	}
+	args = append(args, maxItems)
+	return wstore.WithTxRtn(ctx, func(tx *wstore.TxWrap) ([]wshrpc.HistoryEntry, error) {
+		var rows []historyRow
+		tx.Select(&rows, query, args...)
+		rtn := make([]wshrpc.HistoryEntry, 0, len(rows))
+		for _, row := range rows {
+			rtn = append(rtn, row.toEntry(data.Unique))
+		}
+		return rtn, nil
+	})
+}
+
+func (row historyRow) toEntry(unique bool) wshrpc.HistoryEntry {
+	entry := wshrpc.HistoryEntry{
+		Id:         row.Id,
+		Ts:         row.Ts,
+		Cmd:        row.Cmd,
+		BlockId:    row.BlockId,
+		Connection: row.Connection,
+		ShellType:  row.ShellType,
+		Cwd:        row.Cwd,
+		DurationMs: row.DurationMs,
+	}
+	if row.ExitCode.Valid {
+		exitCode := int(row.ExitCode.Int64)
+		entry.ExitCode = &exitCode
+	}
+	if unique {
+		entry.Count = row.Count
+	}
+	return entry
+}
+
+func getMaxEntries() int {
+	settings := wconfig.GetWatcher().GetFullConfig().Settings
+	if settings.HistoryMaxEntries != nil {
+		return int(*settings.HistoryMaxEntries)
+	}
+	return DefaultMaxEntries
+}
+
+// Compact drops the oldest entries beyond history:maxentries (0 keeps everything).  returns the
+// number of entries removed.
+func Compact(ctx context.Context) (int64, error) {
+	maxEntries := getMaxEntries()
+	if maxEntries <= 0 {
+		return 0, nil
+	}
+	return wstore.WithTxRtn(ctx, func(tx *wstore.TxWrap) (int64, error) {
+		query := `DELETE FROM db_cmdhistory WHERE id <= (SELECT id FROM db_cmdhistory ORDER BY id DESC LIMIT 1 OFFSET ?)`
+		result := tx.Exec(query, maxEntries)
+		if result == nil {
+			return 0, nil
+		}
+		return result.RowsAffected()
+	})
+}
+
+func compactLoop() {
+	defer func() {
+		panichandler.PanicHandler("cmdhistory:compactLoop", recover())
+	}()
+	for {
+		ctx, cancelFn := context.WithTimeout(context.Background(), 30*time.Second)
+		removed, err := Compact(ctx)
+		cancelFn()
+		if err != nil {
+			log.Printf("cmdhistory: error compacting: %v\n", err)
+		} else if removed > 0 {
+			log.Printf("cmdhistory: compacted %d entries\n", removed)
+		}
+		time.Sleep(compactInterval)
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// notifications for long-running commands.  shell integration marks the start (shell:lastcmd) and the
+// end (shell:lastcmdexitcode) of every command, when a command that ran for at least term:cmdnotifysecs
+// finishes in a block that isn't focused a desktop notification is sent (with the command, its exit
+// code and how long it ran), the block's tab gets an indicator and, with term:cmdnotifybell, the system
+// bell is rung.  term:cmdnotify can be turned off in the settings, for a connection or for a block.
+package cmdnotify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wcore"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+const DefaultNotifySecs = 30
+const NotifySender = "cmdnotify"
+
+const maxCmdDisplayLen = 120
+const notifyTimeout = 5 * time.Second
+const minNotifyDuration = time.Second
+
+type runningCmd struct {
+	Cmd     string
+	StartTs time.Time
+}
+
+type doneCmd struct {
+	BlockId  string
+	Cmd      string
+	ExitCode *int
+	Duration time.Duration
+}
+
+var runningLock = &sync.Mutex{}
+var running = make(map[string]runningCmd)
+
+// HandleRTInfoUpdate is called with every rtinfo update, it never blocks.  a command start sets
+// shell:lastcmd (and clears the exit code), a command end only sets shell:lastcmdexitcode.
+func HandleRTInfoUpdate(oref waveobj.ORef, data map[string]any) {
+	if oref.OType != waveobj.OType_Block {
+		return
+	}
+	now := time.Now()
+	if cmdVal, ok := data["shell:lastcmd"]; ok {
+		cmdStr, _ := cmdVal.(string)
+		runningLock.Lock()
+		running[oref.OID] = runningCmd{Cmd: cmdStr, StartTs: now}
+		runningLock.Unlock()
+		return
+	}
+	exitVal, ok := data["shell:lastcmdexitcode"]
+	if !ok {
+		return
+	}
+	runningLock.Lock()
+	cur, found := running[oref.OID]
+	delete(running, oref.OID)
+	runningLock.Unlock()
+	if !found {
+		return
+	}
+	done := doneCmd{BlockId: oref.OID, Cmd: cur.Cmd, Duration: now.Sub(cur.StartTs)}
+	if exitCode, ok := exitVal.(float64); ok {
+		exitCodeInt := int(exitCode)
+		done.ExitCode = &exitCodeInt
+	}
+	// quick commands are the common case, don't look up the block's config for them
+	if done.Duration < minNotifyDuration {
+		return
+	}
+	go func() {
+		defer func() {
+			panichandler.PanicHandler("cmdnotify:handleDone", recover())
+		}()
+		ctx, cancelFn := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancelFn()
+		err := handleDone(ctx, done)
+		if err != nil {
+			log.Printf("cmdnotify: error handling command end for block %s: %v\n", done.BlockId, err)
+		}
+	}()
+}
+
+type notifyConfig struct {
+	Enabled bool
+	MinSecs float64
+	Bell    bool
+}
+
+// block meta overrides the connection config, which overrides the settings (of the block's workspace)
+func resolveNotifyConfig(block *waveobj.Block, workspace *waveobj.Workspace) notifyConfig {
+	settings := wcore.ResolveSettingsForWorkspace(workspace)
+	config := notifyConfig{Enabled: true, MinSecs: DefaultNotifySecs}
+	if settings.TermCmdNotify != nil {
+		config.Enabled = *settings.TermCmdNotify
+	}
+	if settings.TermCmdNotifySecs != nil && *settings.TermCmdNotifySecs > 0 {
+		config.MinSecs = *settings.TermCmdNotifySecs
+	}
+	if settings.TermCmdNotifyBell != nil {
+		config.Bell = *settings.TermCmdNotifyBell
+	}
+	connName := block.Meta.GetString(waveobj.MetaKey_Connection, "")
+	if connConfig, ok := wconfig.GetWatcher().GetFullConfig().Connections[connName]; ok && connConfig.TermCmdNotify != nil {
+		config.Enabled = *connConfig.TermCmdNotify
+	}
+	config.Enabled = block.Meta.GetBool(waveobj.MetaKey_TermCmdNotify, config.Enabled)
+	return config
+}
+
+func handleDone(ctx context.Context, done doneCmd) error {
+	block, err := wstore.DBGet[*waveobj.Block](ctx, done.BlockId)
+	if err != nil || block == nil {
+		return err
+	}
+	tabId, err := wstore.DBFindTabForBlockId(ctx, done.BlockId)
+	if err != nil {
+		return fmt.Errorf("finding tab: %w", err)
+	}
+	var workspace *waveobj.Workspace
+	if workspaceId, _ := wstore.DBFindWorkspaceForTabId(ctx, tabId); workspaceId != "" {
+		workspace, _ = wstore.DBGet[*waveobj.Workspace](ctx, workspaceId)
+	}
+	config := resolveNotifyConfig(block, workspace)
+	if !config.Enabled || done.Duration.Seconds() < config.MinSecs {
+		return nil
+	}
+	blockFocused := isBlockFocused(ctx, workspace, tabId, done.BlockId)
+	connName := block.Meta.GetString(waveobj.MetaKey_Connection, "")
+	title, body := makeNotifyMessage(done, connName)
+	// a focused block can still be in a window the user isn't looking at, electron drops the
+	// notification in that case only if the window has focus
+	notifyOpts := wshrpc.WaveNotificationOptions{
+		Title:           title,
+		Body:            body,
+		Silent:          config.Bell && !blockFocused,
+		TabId:           tabId,
+		BlockId:         done.BlockId,
+		OnlyIfUnfocused: blockFocused,
+	}
+	err = wshclient.NotifyCommand(wshclient.GetBareRpcClient(), notifyOpts, &wshrpc.RpcOpts{Route: wshutil.ElectronRoute, Timeout: 2000})
+	if err != nil {
+		return fmt.Errorf("sending notification: %w", err)
+	}
+	if blockFocused {
+		return nil
+	}
+	wps.Broker.Publish(wps.WaveEvent{
+		Event:  wps.Event_TabIndicator,
+		Scopes: []string{waveobj.MakeORef(waveobj.OType_Tab, tabId).String()},
+		Sender: NotifySender,
+		Data: wshrpc.TabIndicatorEventData{
+			TabId:     tabId,
+			Indicator: makeTabIndicator(done.ExitCode),
+		},
+	})
+	if config.Bell {
+		err = wshclient.ElectronSystemBellCommand(wshclient.GetBareRpcClient(), &wshrpc.RpcOpts{Route: wshutil.ElectronRoute, Timeout: 2000})
+		if err != nil {
+			return fmt.Errorf("ringing bell: %w", err)
+		}
+	}
+	return nil
+}
+
+// a block is focused when its tab is the active tab of a workspace that is open in a window and the
+// tab's layout has the block focused
+func isBlockFocused(ctx context.Context, workspace *waveobj.Workspace, tabId string, blockId string) bool {
+	if workspace == nil || workspace.ActiveTabId != tabId {
+		return false
+	}
+	windowId, err := wstore.DBFindWindowForWorkspaceId(ctx, workspace.OID)
+	if err != nil || windowId == "" {
+		return false
+	}
+	focusData, err := wshclient.GetFocusedBlockDataCommand(wshclient.GetBareRpcClient(), &wshrpc.RpcOpts{Route: wshutil.MakeTabRouteId(tabId), Timeout: 2000})
+	if err != nil || focusData == nil {
+		return false
+	}
+	return focusData.BlockId == blockId
+}
+
+func makeTabIndicator(exitCode *int) *wshrpc.TabIndicator {
+	if exitCode != nil && *exitCode != 0 {
+		return &wshrpc.TabIndicator{Icon: "circle-xmark", Color: "#ef4444", ClearOnFocus: true, Priority: 2}
+	}
+	return &wshrpc.TabIndicator{Icon: "circle-check", Color: "#22c55e", ClearOnFocus: true, Priority: 1.5}
+}
+
+func makeNotifyMessage(done doneCmd, connName string) (string, string) {
+	title := "Command finished"
+	if done.ExitCode != nil && *done.ExitCode != 0 {
+		title = fmt.Sprintf("Command failed (exit %d)", *done.ExitCode)
+	}
+	cmdStr := strings.Join(strings.Fields(done.Cmd), " ")
+	if cmdStr == "" {
+		cmdStr = "(unknown command)"
+	}
+	if len(cmdStr) > maxCmdDisplayLen {
+		cmdStr = strings.ToValidUTF8(cmdStr[:maxCmdDisplayLen-3], "") + "..."
+	}
+	detail := "ran for " + FormatDuration(done.Duration)
+	if connName != "" && connName != "local" {
+		detail += " on " + connName
+	}
+	return title, cmdStr + "\n" + detail
+}
+
+// FormatDuration formats d as e.g. "45s", "3m 12s" or "1h 05m"
+func FormatDuration(d time.Duration) string {
+	secs := int64(d.Round(time.Second) / time.Second)
+	switch {
+	case secs < 60:
+		return fmt.Sprintf("%ds", secs)
+	case secs < 3600:
+		return fmt.Sprintf("%dm %02ds", secs/60, secs%60)
+	default:
+		return fmt.Sprintf("%dh %02dm", secs/3600, (secs%3600)/60)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmdnotify
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{45 * time.Second, "45s"},
+		{59600 * time.Millisecond, "1m 00s"},
+		{3*time.Minute + 12*time.Second, "3m 12s"},
+		{time.Hour + 5*time.Minute + 30*time.Second, "1h 05m"},
+		{26 * time.Hour, "26h 00m"},
+	}
+	for _, test := range tests {
+		if got := FormatDuration(test.d); got != test.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", test.d, got, test.want)
+		}
+	}
+}
+
+func TestMakeNotifyMessage(t *testing.T) {
+	exitCode := 0
+	title, body := makeNotifyMessage(doneCmd{Cmd: "make  build\n", ExitCode: &exitCode, Duration: 90 * time.Second}, "")
+	if title != "Command finished" || body != "make build\nran for 1m 30s" {
+		t.Errorf("got %q / %q", title, body)
+	}
+	exitCode = 2
+	title, body = makeNotifyMessage(doneCmd{Cmd: "go test ./...", ExitCode: &exitCode, Duration: 40 * time.Second}, "user@host")
+	if title != "Command failed (exit 2)" || body != "go test ./...\nran for 40s on user@host" {
+		t.Errorf("got %q / %q", title, body)
+	}
+	title, _ = makeNotifyMessage(doneCmd{Cmd: "sleep 60", Duration: time.Minute}, "local")
+	if title != "Command finished" {
+		t.Errorf("unknown exit code: got title %q", title)
+	}
+	_, body = makeNotifyMessage(doneCmd{Cmd: strings.Repeat("é", 100), Duration: time.Minute}, "")
+	cmdLine := strings.Split(body, "\n")[0]
+	if len(cmdLine) > maxCmdDisplayLen || !strings.HasSuffix(cmdLine, "...") || !strings.HasPrefix(cmdLine, "éé") {
+		t.Errorf("long command not truncated: %q", cmdLine)
+	}
+	if strings.ContainsRune(cmdLine, '�') {
+		t.Errorf("truncated command has an invalid rune: %q", cmdLine)
+	}
+}
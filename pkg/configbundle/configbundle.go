@@ -0,0 +1,465 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package configbundle exports and imports a portable snapshot of a user's setup
+// (config files, pet data and local tsunami app sources) as a single versioned bundle.
+package configbundle
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/petengine"
+	"github.com/SalyyS1/SLTerm/pkg/secretstore"
+	"github.com/SalyyS1/SLTerm/pkg/waveappstore"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+)
+
+const BundleVersion = 1
+
+const PetDirName = "pet"
+const MaxAppFileSize = 1024 * 1024
+
+// keys that only make sense on the machine they were written on.  they are left out of exported bundles.
+var hostSpecificSettingsKeys = []string{
+	wconfig.ConfigKey_TermLocalShellPath,
+	wconfig.ConfigKey_TermGitBashPath,
+	wconfig.ConfigKey_TsunamiScaffoldPath,
+	wconfig.ConfigKey_TsunamiSdkReplacePath,
+	wconfig.ConfigKey_TsunamiGoPath,
+}
+
+var hostSpecificConnKeys = []string{
+	"conn:wshpath",
+	"conn:shellpath",
+	"ssh:identityfile",
+	"ssh:identityagent",
+	"ssh:userknownhostsfile",
+	"ssh:globalknownhostsfile",
+}
+
+// returns the config part names ("settings", "connections", "termthemes", ...) following the FullConfigType layout
+func getConfigParts() []string {
+	parts := []string{"settings"}
+	for _, subdir := range wconfig.GetConfigSubdirs() {
+		parts = append(parts, filepath.Base(subdir))
+	}
+	return parts
+}
+
+// returns the files (relative to the config dir, slash separated) that make up a config part: <part>.json and <part>/*.json
+func listConfigPartFiles(partName string) []string {
+	configDir := wavebase.GetWaveConfigDir()
+	var rtn []string
+	if _, err := os.Stat(filepath.Join(configDir, partName+".json")); err == nil {
+		rtn = append(rtn, partName+".json")
+	}
+	dirEnts, err := os.ReadDir(filepath.Join(configDir, partName))
+	if err != nil {
+		return rtn
+	}
+	for _, ent := range dirEnts {
+		if ent.IsDir() || !strings.HasSuffix(ent.Name(), ".json") {
+			continue
+		}
+		rtn = append(rtn, path.Join(partName, ent.Name()))
+	}
+	return rtn
+}
+
+// config files are <part>.json or <part>/<name>.json for one of the config parts (not plugins/<x>/plugin.json etc.)
+func isConfigPartFile(p string) bool {
+	if path.Ext(p) != ".json" {
+		return false
+	}
+	partName, fileName, isSubFile := strings.Cut(p, "/")
+	if !isSubFile {
+		partName = strings.TrimSuffix(p, ".json")
+	} else if strings.Contains(fileName, "/") {
+		return false
+	}
+	for _, part := range getConfigParts() {
+		if part == partName {
+			return true
+		}
+	}
+	return false
+}
+
+func stripKeys(m waveobj.MetaMapType, keys []string, prefix string, stripped *[]string) {
+	for _, key := range keys {
+		if _, ok := m[key]; ok {
+			delete(m, key)
+			*stripped = append(*stripped, prefix+key)
+		}
+	}
+}
+
+func stripHostSpecific(partName string, fileName string, m waveobj.MetaMapType, stripped *[]string) {
+	switch partName {
+	case "settings":
+		stripKeys(m, hostSpecificSettingsKeys, fileName+":", stripped)
+	case "connections":
+		for connName, connVal := range m {
+			connMap, ok := connVal.(map[string]any)
+			if !ok {
+				continue
+			}
+			stripKeys(connMap, hostSpecificConnKeys, fileName+":"+connName+"/", stripped)
+		}
+	}
+}
+
+func exportConfigFiles(bundle *wshrpc.ConfigBundle) error {
+	for _, partName := range getConfigParts() {
+		for _, fileName := range listConfigPartFiles(partName) {
+			m, cerrs := wconfig.ReadWaveHomeConfigFileRaw(fileName)
+			if len(cerrs) > 0 {
+				return fmt.Errorf("cannot export invalid config file %s: %s", fileName, cerrs[0].Err)
+			}
+			if len(m) == 0 {
+				continue
+			}
+			stripHostSpecific(partName, fileName, m, &bundle.Stripped)
+			bundle.Files = append(bundle.Files, wshrpc.ConfigBundleFile{
+				Section: wshrpc.ConfigBundleSection_Config,
+				Path:    fileName,
+				Config:  m,
+			})
+		}
+	}
+	return nil
+}
+
+func exportPetFiles(bundle *wshrpc.ConfigBundle) error {
+	petDir := filepath.Join(wavebase.GetWaveDataDir(), PetDirName)
+	dirEnts, err := os.ReadDir(petDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading pet directory: %w", err)
+	}
+	for _, ent := range dirEnts {
+		if ent.IsDir() || !strings.HasSuffix(ent.Name(), ".json") {
+			continue
+		}
+		barr, err := os.ReadFile(filepath.Join(petDir, ent.Name()))
+		if err != nil {
+			return fmt.Errorf("reading pet file %s: %w", ent.Name(), err)
+		}
+		bundle.Files = append(bundle.Files, wshrpc.ConfigBundleFile{
+			Section: wshrpc.ConfigBundleSection_Pet,
+			Path:    ent.Name(),
+			Data64:  base64.StdEncoding.EncodeToString(barr),
+		})
+	}
+	return nil
+}
+
+// only published (local) apps are exported, drafts stay on the machine they were made on
+func exportTsunamiApps(bundle *wshrpc.ConfigBundle) error {
+	apps, err := waveappstore.ListAllApps()
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		appNS, appName, err := waveappstore.ParseAppId(app.AppId)
+		if err != nil || appNS != waveappstore.AppNSLocal {
+			continue
+		}
+		appDir, err := waveappstore.GetAppDir(app.AppId)
+		if err != nil {
+			return err
+		}
+		err = filepath.WalkDir(appDir, func(fullPath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if fullPath != appDir && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(appDir, fullPath)
+			if err != nil {
+				return err
+			}
+			if info.Size() > MaxAppFileSize {
+				log.Printf("configbundle: skipping large app file %s/%s (%d bytes)\n", appName, relPath, info.Size())
+				return nil
+			}
+			barr, err := os.ReadFile(fullPath)
+			if err != nil {
+				return err
+			}
+			bundle.Files = append(bundle.Files, wshrpc.ConfigBundleFile{
+				Section: wshrpc.ConfigBundleSection_Tsunami,
+				Path:    path.Join(appName, filepath.ToSlash(relPath)),
+				Data64:  base64.StdEncoding.EncodeToString(barr),
+			})
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("reading app %s: %w", app.AppId, err)
+		}
+	}
+	return nil
+}
+
+// Export builds a bundle from the user's config dir, pet data and local tsunami apps.
+// Secret values are never exported, only their names (so an import can report which ones need to be re-created).
+func Export() (*wshrpc.ConfigBundle, error) {
+	bundle := &wshrpc.ConfigBundle{
+		Version:   BundleVersion,
+		CreatedTs: time.Now().UnixMilli(),
+	}
+	if err := exportConfigFiles(bundle); err != nil {
+		return nil, err
+	}
+	if err := exportPetFiles(bundle); err != nil {
+		return nil, err
+	}
+	if err := exportTsunamiApps(bundle); err != nil {
+		return nil, err
+	}
+	secretNames, err := secretstore.GetSecretNames()
+	if err != nil {
+		log.Printf("configbundle: cannot read secret names: %v\n", err)
+	} else {
+		sort.Strings(secretNames)
+		bundle.SecretNames = secretNames
+	}
+	return bundle, nil
+}
+
+func validateBundlePath(p string) error {
+	if p == "" || !filepath.IsLocal(filepath.FromSlash(p)) {
+		return fmt.Errorf("invalid path %q in bundle", p)
+	}
+	return nil
+}
+
+// merges top-level keys of a bundled config file into the existing one, reporting conflicting keys
+func mergeConfigFile(file wshrpc.ConfigBundleFile, existing waveobj.MetaMapType, overwrite bool, result *wshrpc.ConfigImportResult) waveobj.MetaMapType {
+	merged := make(waveobj.MetaMapType)
+	for k, v := range existing {
+		merged[k] = v
+	}
+	keys := make([]string, 0, len(file.Config))
+	for k := range file.Config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		newVal := file.Config[k]
+		oldVal, exists := existing[k]
+		if !exists {
+			merged[k] = newVal
+			continue
+		}
+		if reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+		resolution := "kept"
+		if overwrite {
+			resolution = "overwritten"
+			merged[k] = newVal
+		}
+		result.Conflicts = append(result.Conflicts, wshrpc.ConfigImportConflict{
+			Section:    file.Section,
+			Path:       file.Path,
+			Key:        k,
+			Resolution: resolution,
+		})
+	}
+	return merged
+}
+
+func importConfigFile(file wshrpc.ConfigBundleFile, overwrite bool, dryRun bool, result *wshrpc.ConfigImportResult) error {
+	if !isConfigPartFile(file.Path) {
+		return fmt.Errorf("invalid config file path %q in bundle", file.Path)
+	}
+	existing, cerrs := wconfig.ReadWaveHomeConfigFileRaw(file.Path)
+	if len(cerrs) > 0 {
+		return fmt.Errorf("existing config file %s is invalid, fix or remove it before importing: %s", file.Path, cerrs[0].Err)
+	}
+	merged := mergeConfigFile(file, existing, overwrite, result)
+	if reflect.DeepEqual(map[string]any(merged), map[string]any(existing)) || (len(merged) == 0 && len(existing) == 0) {
+		result.Unchanged = append(result.Unchanged, file.Section+"/"+file.Path)
+		return nil
+	}
+	result.Written = append(result.Written, file.Section+"/"+file.Path)
+	if dryRun {
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(filepath.Join(wavebase.GetWaveConfigDir(), file.Path)), 0755)
+	if err != nil {
+		return err
+	}
+	return wconfig.WriteWaveHomeConfigFile(file.Path, merged)
+}
+
+// raw files are compared as a whole: a differing file is a conflict, kept or replaced depending on the strategy.
+// returns true if the file should be written.
+func importRawFile(file wshrpc.ConfigBundleFile, fullPath string, overwrite bool, result *wshrpc.ConfigImportResult) (bool, error) {
+	barr, err := base64.StdEncoding.DecodeString(file.Data64)
+	if err != nil {
+		return false, fmt.Errorf("decoding %s/%s: %w", file.Section, file.Path, err)
+	}
+	existing, err := os.ReadFile(fullPath)
+	if err == nil {
+		if bytes.Equal(existing, barr) {
+			result.Unchanged = append(result.Unchanged, file.Section+"/"+file.Path)
+			return false, nil
+		}
+		resolution := "kept"
+		if overwrite {
+			resolution = "overwritten"
+		}
+		result.Conflicts = append(result.Conflicts, wshrpc.ConfigImportConflict{
+			Section:    file.Section,
+			Path:       file.Path,
+			Resolution: resolution,
+		})
+		if !overwrite {
+			return false, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+	result.Written = append(result.Written, file.Section+"/"+file.Path)
+	return true, nil
+}
+
+func importPetFile(file wshrpc.ConfigBundleFile, overwrite bool, dryRun bool, result *wshrpc.ConfigImportResult) (bool, error) {
+	if strings.Contains(file.Path, "/") {
+		return false, fmt.Errorf("invalid pet file path %q in bundle", file.Path)
+	}
+	fullPath := filepath.Join(wavebase.GetWaveDataDir(), PetDirName, file.Path)
+	write, err := importRawFile(file, fullPath, overwrite, result)
+	if err != nil || !write || dryRun {
+		return write, err
+	}
+	barr, _ := base64.StdEncoding.DecodeString(file.Data64)
+	err = os.MkdirAll(filepath.Dir(fullPath), 0755)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(fullPath, barr, 0644)
+}
+
+func importTsunamiFile(file wshrpc.ConfigBundleFile, overwrite bool, dryRun bool, result *wshrpc.ConfigImportResult) error {
+	appName, fileName, ok := strings.Cut(file.Path, "/")
+	if !ok {
+		return fmt.Errorf("invalid app file path %q in bundle", file.Path)
+	}
+	appId := waveappstore.MakeAppId(waveappstore.AppNSLocal, appName)
+	if err := waveappstore.ValidateAppId(appId); err != nil {
+		return fmt.Errorf("invalid app in bundle: %w", err)
+	}
+	appDir, err := waveappstore.GetAppDir(appId)
+	if err != nil {
+		return err
+	}
+	write, err := importRawFile(file, filepath.Join(appDir, filepath.FromSlash(fileName)), overwrite, result)
+	if err != nil || !write || dryRun {
+		return err
+	}
+	barr, _ := base64.StdEncoding.DecodeString(file.Data64)
+	return waveappstore.WriteAppFile(appId, fileName, barr)
+}
+
+// Import applies a bundle.  With the "merge" strategy existing values win on conflict, with "overwrite" the bundle wins.
+// Every conflict is reported either way.  A dry run computes the full result without touching any files.
+func Import(data wshrpc.CommandConfigImportData) (*wshrpc.ConfigImportResult, error) {
+	bundle := data.Bundle
+	if bundle.Version == 0 || bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d (this version of SLTerm supports up to %d)", bundle.Version, BundleVersion)
+	}
+	strategy := data.Strategy
+	if strategy == "" {
+		strategy = wshrpc.ConfigImportStrategy_Merge
+	}
+	if strategy != wshrpc.ConfigImportStrategy_Merge && strategy != wshrpc.ConfigImportStrategy_Overwrite {
+		return nil, fmt.Errorf("invalid import strategy %q (must be %q or %q)", strategy, wshrpc.ConfigImportStrategy_Merge, wshrpc.ConfigImportStrategy_Overwrite)
+	}
+	overwrite := strategy == wshrpc.ConfigImportStrategy_Overwrite
+	for _, file := range bundle.Files {
+		if err := validateBundlePath(file.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &wshrpc.ConfigImportResult{DryRun: data.DryRun}
+	var petChanged bool
+	for _, file := range bundle.Files {
+		var err error
+		switch file.Section {
+		case wshrpc.ConfigBundleSection_Config:
+			err = importConfigFile(file, overwrite, data.DryRun, result)
+		case wshrpc.ConfigBundleSection_Pet:
+			var written bool
+			written, err = importPetFile(file, overwrite, data.DryRun, result)
+			petChanged = petChanged || written
+		case wshrpc.ConfigBundleSection_Tsunami:
+			err = importTsunamiFile(file, overwrite, data.DryRun, result)
+		default:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped %s: unknown section %q", file.Path, file.Section))
+		}
+		if err != nil {
+			return result, fmt.Errorf("importing %s/%s: %w", file.Section, file.Path, err)
+		}
+	}
+
+	if len(bundle.SecretNames) > 0 {
+		localNames, err := secretstore.GetSecretNames()
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("cannot check secrets: %v", err))
+		} else {
+			localSet := make(map[string]bool)
+			for _, name := range localNames {
+				localSet[name] = true
+			}
+			for _, name := range bundle.SecretNames {
+				if !localSet[name] {
+					result.MissingSecrets = append(result.MissingSecrets, name)
+				}
+			}
+		}
+	}
+	if len(bundle.Stripped) > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d host-specific setting(s) were not included in the bundle and may need to be set manually", len(bundle.Stripped)))
+	}
+
+	if !data.DryRun {
+		if petChanged {
+			petengine.GetStore().Load()
+		}
+		for _, cerr := range wconfig.ReadFullConfig().ConfigErrors {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("config error in %s: %s", cerr.File, cerr.Err))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package configbundle
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+)
+
+// points the config and data dirs at temp dirs and writes the given config files (relative path -> contents)
+func initTestDirs(t *testing.T, files map[string]string) {
+	wavebase.ConfigHome_VarCache = t.TempDir()
+	wavebase.DataHome_VarCache = t.TempDir()
+	for fileName, content := range files {
+		fullPath := filepath.Join(wavebase.GetWaveConfigDir(), filepath.FromSlash(fileName))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("creating dir for %s: %v", fileName, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", fileName, err)
+		}
+	}
+}
+
+func readConfigFile(t *testing.T, fileName string) string {
+	barr, err := os.ReadFile(filepath.Join(wavebase.GetWaveConfigDir(), filepath.FromSlash(fileName)))
+	if err != nil {
+		t.Fatalf("reading %s: %v", fileName, err)
+	}
+	return string(barr)
+}
+
+func makeSettingsBundle(settings waveobj.MetaMapType) wshrpc.ConfigBundle {
+	return wshrpc.ConfigBundle{
+		Version: BundleVersion,
+		Files: []wshrpc.ConfigBundleFile{
+			{Section: wshrpc.ConfigBundleSection_Config, Path: wconfig.SettingsFile, Config: settings},
+		},
+	}
+}
+
+func TestImportStrategies(t *testing.T) {
+	bundle := makeSettingsBundle(waveobj.MetaMapType{"term:fontsize": float64(14), "term:theme": "dracula"})
+	tests := []struct {
+		strategy     string
+		wantFontSize float64
+		wantConflict string
+	}{
+		{wshrpc.ConfigImportStrategy_Merge, 12, "kept"},
+		{wshrpc.ConfigImportStrategy_Overwrite, 14, "overwritten"},
+	}
+	for _, tc := range tests {
+		initTestDirs(t, map[string]string{wconfig.SettingsFile: `{"term:fontsize": 12}`})
+		result, err := Import(wshrpc.CommandConfigImportData{Bundle: bundle, Strategy: tc.strategy})
+		if err != nil {
+			t.Fatalf("%s: import: %v", tc.strategy, err)
+		}
+		wantConflicts := []wshrpc.ConfigImportConflict{
+			{Section: wshrpc.ConfigBundleSection_Config, Path: wconfig.SettingsFile, Key: "term:fontsize", Resolution: tc.wantConflict},
+		}
+		if !reflect.DeepEqual(result.Conflicts, wantConflicts) {
+			t.Errorf("%s: got conflicts %v, want %v", tc.strategy, result.Conflicts, wantConflicts)
+		}
+		settings, cerrs := wconfig.ReadWaveHomeConfigFileRaw(wconfig.SettingsFile)
+		if len(cerrs) > 0 {
+			t.Fatalf("%s: reading settings: %v", tc.strategy, cerrs[0])
+		}
+		if settings["term:fontsize"] != tc.wantFontSize || settings["term:theme"] != "dracula" {
+			t.Errorf("%s: got settings %v", tc.strategy, settings)
+		}
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	const settingsContent = `{"term:fontsize": 12}`
+	initTestDirs(t, map[string]string{wconfig.SettingsFile: settingsContent})
+	bundle := makeSettingsBundle(waveobj.MetaMapType{"term:fontsize": float64(14)})
+	bundle.Files = append(bundle.Files,
+		wshrpc.ConfigBundleFile{Section: wshrpc.ConfigBundleSection_Config, Path: "termthemes/mine.json", Config: waveobj.MetaMapType{"mine": map[string]any{"background": "#000000"}}},
+		wshrpc.ConfigBundleFile{Section: wshrpc.ConfigBundleSection_Pet, Path: "state.json", Data64: "e30="},
+	)
+	result, err := Import(wshrpc.CommandConfigImportData{Bundle: bundle, Strategy: wshrpc.ConfigImportStrategy_Overwrite, DryRun: true})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	wantWritten := []string{"config/settings.json", "config/termthemes/mine.json", "pet/state.json"}
+	if !result.DryRun || !reflect.DeepEqual(result.Written, wantWritten) || len(result.Conflicts) != 1 {
+		t.Errorf("got result %+v, want written %v and one conflict", result, wantWritten)
+	}
+	if got := readConfigFile(t, wconfig.SettingsFile); got != settingsContent {
+		t.Errorf("dry run changed settings.json to %s", got)
+	}
+	for _, fullPath := range []string{
+		filepath.Join(wavebase.GetWaveConfigDir(), "termthemes"),
+		filepath.Join(wavebase.GetWaveDataDir(), PetDirName),
+	} {
+		if _, err := os.Stat(fullPath); !os.IsNotExist(err) {
+			t.Errorf("dry run created %s", fullPath)
+		}
+	}
+}
+
+func TestValidateBundlePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"settings.json", false},
+		{"termthemes/mine.json", false},
+		{"", true},
+		{"../settings.json", true},
+		{"termthemes/../../escape.json", true},
+		{"/etc/passwd", true},
+	}
+	for _, tc := range tests {
+		if err := validateBundlePath(tc.path); (err != nil) != tc.wantErr {
+			t.Errorf("%q: got %v, wantErr %v", tc.path, err, tc.wantErr)
+		}
+	}
+	initTestDirs(t, nil)
+	bundle := makeSettingsBundle(waveobj.MetaMapType{"term:fontsize": float64(14)})
+	bundle.Files[0].Path = "../settings.json"
+	if _, err := Import(wshrpc.CommandConfigImportData{Bundle: bundle}); err == nil {
+		t.Errorf("import accepted a path outside the config dir")
+	}
+}
+
+func TestImportConfigFilePaths(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"settings.json", false},
+		{"connections.json", false},
+		{"termthemes/mine.json", false},
+		{"plugins/foo/plugin.json", true},
+		{"notapart.json", true},
+		{"notapart/x.json", true},
+		{"settings.txt", true},
+	}
+	for _, tc := range tests {
+		initTestDirs(t, nil)
+		file := wshrpc.ConfigBundleFile{Section: wshrpc.ConfigBundleSection_Config, Path: tc.path, Config: waveobj.MetaMapType{"x": "y"}}
+		err := importConfigFile(file, false, true, &wshrpc.ConfigImportResult{})
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%q: got %v, wantErr %v", tc.path, err, tc.wantErr)
+		}
+	}
+}
+
+func TestExportStripsHostSpecific(t *testing.T) {
+	initTestDirs(t, map[string]string{
+		wconfig.SettingsFile:    `{"term:fontsize": 12, "term:localshellpath": "/usr/local/bin/fish"}`,
+		wconfig.ConnectionsFile: `{"user@host": {"conn:wshenabled": true, "ssh:identityfile": ["~/.ssh/id_work"]}}`,
+	})
+	var bundle wshrpc.ConfigBundle
+	if err := exportConfigFiles(&bundle); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	wantStripped := []string{"settings.json:term:localshellpath", "connections.json:user@host/ssh:identityfile"}
+	if !reflect.DeepEqual(bundle.Stripped, wantStripped) {
+		t.Errorf("got stripped %v, want %v", bundle.Stripped, wantStripped)
+	}
+	for _, file := range bundle.Files {
+		switch file.Path {
+		case wconfig.SettingsFile:
+			if _, ok := file.Config["term:localshellpath"]; ok || file.Config["term:fontsize"] != float64(12) {
+				t.Errorf("got exported settings %v", file.Config)
+			}
+		case wconfig.ConnectionsFile:
+			connMap, _ := file.Config["user@host"].(map[string]any)
+			if _, ok := connMap["ssh:identityfile"]; ok || connMap["conn:wshenabled"] != true {
+				t.Errorf("got exported connection %v", connMap)
+			}
+		}
+	}
+	if len(bundle.Files) != 2 {
+		t.Errorf("got %d exported files, want 2", len(bundle.Files))
+	}
+}
+
+func TestImportUnknownSection(t *testing.T) {
+	initTestDirs(t, nil)
+	bundle := wshrpc.ConfigBundle{
+		Version: BundleVersion,
+		Files:   []wshrpc.ConfigBundleFile{{Section: "themes-v2", Path: "x.json"}},
+	}
+	result, err := Import(wshrpc.CommandConfigImportData{Bundle: bundle, DryRun: true})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(result.Warnings) != 1 || len(result.Written) != 0 {
+		t.Errorf("got result %+v, want one unknown section warning", result)
+	}
+}
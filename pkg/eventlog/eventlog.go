@@ -0,0 +1,324 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// durable event log.  events listed in the "eventlog:events" setting are written to the
+// db_eventlog table (in addition to the in-memory wps ring) so they survive restarts and can
+// be queried by time range and scope.  retention is eventlog:retentiondays / eventlog:maxperevent,
+// overridden per event type by "eventlog:retention".
+package eventlog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+const DefaultRetentionDays = 30
+const DefaultMaxPerEvent = 10000
+const DefaultQueryItems = 100
+const MaxQueryItems = 10000
+
+const writeQueueSize = 1024
+const writeBatchSize = 100
+const compactInterval = time.Hour
+
+type eventLogger struct {
+	lock     *sync.Mutex
+	patterns []string
+	queue    chan eventLogRow
+}
+
+var globalLogger = &eventLogger{
+	lock:  &sync.Mutex{},
+	queue: make(chan eventLogRow, writeQueueSize),
+}
+
+type ReadOpts struct {
+	Event    string
+	Scope    string // "" for all scopes
+	StartTs  int64  // inclusive, 0 for no lower bound
+	EndTs    int64  // inclusive, 0 for no upper bound
+	MaxItems int
+}
+
+type eventLogRow struct {
+	Id     int64          `db:"id"`
+	Ts     int64          `db:"ts"`
+	Event  string         `db:"event"`
+	Scopes string         `db:"scopes"`
+	Sender string         `db:"sender"`
+	Data   sql.NullString `db:"data"`
+}
+
+func InitEventLog() {
+	watcher := wconfig.GetWatcher()
+	globalLogger.setPatterns(watcher.GetFullConfig().Settings.EventLogEvents)
+	watcher.RegisterUpdateHandler(func(newConfig wconfig.FullConfigType) {
+		globalLogger.setPatterns(newConfig.Settings.EventLogEvents)
+	})
+	wps.Broker.AddEventObserver(globalLogger)
+	go writeLoop()
+	go compactLoop()
+}
+
+func (l *eventLogger) setPatterns(patterns []string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.patterns = patterns
+}
+
+// patterns are event names, "*" matches a single ":" separated part (e.g. "user:*")
+func (l *eventLogger) shouldLog(eventName string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, pattern := range l.patterns {
+		if pattern == eventName || utilfn.StarMatchString(pattern, eventName, ":") {
+			return true
+		}
+	}
+	return false
+}
+
+func IsEventLogged(eventName string) bool {
+	return globalLogger.shouldLog(eventName)
+}
+
+// ObserveEvent is called synchronously from wps.Broker.Publish, so it never blocks.  the event is
+// serialized here (the publisher may reuse its data once Publish returns) and written by writeLoop.
+// if the writer falls behind, events are dropped (and logged).
+func (l *eventLogger) ObserveEvent(event wps.WaveEvent) {
+	if !l.shouldLog(event.Event) {
+		return
+	}
+	row, err := makeEventLogRow(event)
+	if err != nil {
+		log.Printf("eventlog: error serializing %q event: %v\n", event.Event, err)
+		return
+	}
+	select {
+	case l.queue <- row:
+	default:
+		log.Printf("eventlog: write queue full, dropping %q event\n", event.Event)
+	}
+}
+
+func makeEventLogRow(event wps.WaveEvent) (eventLogRow, error) {
+	scopes := event.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	scopesJson, err := json.Marshal(scopes)
+	if err != nil {
+		return eventLogRow{}, err
+	}
+	row := eventLogRow{Ts: event.Ts, Event: event.Event, Scopes: string(scopesJson), Sender: event.Sender}
+	if event.Data != nil {
+		dataJson, err := json.Marshal(event.Data)
+		if err != nil {
+			return eventLogRow{}, err
+		}
+		row.Data = sql.NullString{String: string(dataJson), Valid: true}
+	}
+	return row, nil
+}
+
+func writeLoop() {
+	defer func() {
+		panichandler.PanicHandler("eventlog:writeLoop", recover())
+	}()
+	for row := range globalLogger.queue {
+		batch := []eventLogRow{row}
+	drain:
+		for len(batch) < writeBatchSize {
+			select {
+			case next := <-globalLogger.queue:
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+		err := insertEvents(ctx, batch)
+		cancelFn()
+		if err != nil {
+			log.Printf("eventlog: error writing %d events: %v\n", len(batch), err)
+		}
+	}
+}
+
+func insertEvents(ctx context.Context, rows []eventLogRow) error {
+	return wstore.WithTx(ctx, func(tx *wstore.TxWrap) error {
+		query := `INSERT INTO db_eventlog (ts, event, scopes, sender, data) VALUES (?, ?, ?, ?, ?)`
+		for _, row := range rows {
+			tx.Exec(query, row.Ts, row.Event, row.Scopes, row.Sender, row.Data)
+		}
+		return nil
+	})
+}
+
+// ReadEvents returns the most recent MaxItems matching events, oldest first (same order as wps.Broker.ReadEventHistory)
+func ReadEvents(ctx context.Context, opts ReadOpts) ([]*wps.WaveEvent, error) {
+	if opts.Event == "" {
+		return nil, fmt.Errorf("event name is required")
+	}
+	maxItems := opts.MaxItems
+	if maxItems <= 0 {
+		maxItems = DefaultQueryItems
+	}
+	if maxItems > MaxQueryItems {
+		maxItems = MaxQueryItems
+	}
+	return wstore.WithTxRtn(ctx, func(tx *wstore.TxWrap) ([]*wps.WaveEvent, error) {
+		query := `SELECT id, ts, event, scopes, sender, data FROM db_eventlog WHERE event = ?`
+		args := []any{opts.Event}
+		if opts.Scope != "" {
+			query += ` AND EXISTS (SELECT 1 FROM json_each(db_eventlog.scopes) WHERE value = ?)`
+			args = append(args, opts.Scope)
+		}
+		if opts.StartTs > 0 {
+			query += ` AND ts >= ?`
+			args = append(args, opts.StartTs)
+		}
+		if opts.EndTs > 0 {
+			query += ` AND ts <= ?`
+			args = append(args, opts.EndTs)
+		}
+		query += ` ORDER BY ts DESC, id DESC LIMIT ?`
+		args = append(args, maxItems)
+		var rows []eventLogRow
+		tx.Select(&rows, query, args...)
+		rtn := make([]*wps.WaveEvent, 0, len(rows))
+		for idx := len(rows) - 1; idx >= 0; idx-- {
+			event, err := rows[idx].toEvent()
+			if err != nil {
+				return nil, fmt.Errorf("decoding event log row %d: %w", rows[idx].Id, err)
+			}
+			rtn = append(rtn, event)
+		}
+		return rtn, nil
+	})
+}
+
+func (row eventLogRow) toEvent() (*wps.WaveEvent, error) {
+	event := &wps.WaveEvent{
+		Event:  row.Event,
+		Sender: row.Sender,
+		Ts:     row.Ts,
+	}
+	if err := json.Unmarshal([]byte(row.Scopes), &event.Scopes); err != nil {
+		return nil, err
+	}
+	if len(event.Scopes) == 0 {
+		event.Scopes = nil
+	}
+	if row.Data.Valid && row.Data.String != "" {
+		if err := json.Unmarshal([]byte(row.Data.String), &event.Data); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+type retentionPolicy struct {
+	RetentionDays int // 0 for no age limit
+	MaxEvents     int // 0 for no count limit
+}
+
+// the "eventlog:retention" entry for the event (an exact name wins over a pattern, patterns are tried in
+// sorted order), unset fields fall back to eventlog:retentiondays / eventlog:maxperevent
+func getRetentionPolicy(settings wconfig.SettingsType, eventName string) retentionPolicy {
+	policy := retentionPolicy{RetentionDays: DefaultRetentionDays, MaxEvents: DefaultMaxPerEvent}
+	if settings.EventLogRetentionDays != nil {
+		policy.RetentionDays = int(*settings.EventLogRetentionDays)
+	}
+	if settings.EventLogMaxPerEvent != nil {
+		policy.MaxEvents = int(*settings.EventLogMaxPerEvent)
+	}
+	override, ok := settings.EventLogRetention[eventName]
+	if !ok {
+		patterns := make([]string, 0, len(settings.EventLogRetention))
+		for pattern := range settings.EventLogRetention {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			if utilfn.StarMatchString(pattern, eventName, ":") {
+				override, ok = settings.EventLogRetention[pattern], true
+				break
+			}
+		}
+	}
+	if !ok {
+		return policy
+	}
+	if override.RetentionDays != nil {
+		policy.RetentionDays = int(*override.RetentionDays)
+	}
+	if override.MaxEvents != nil {
+		policy.MaxEvents = int(*override.MaxEvents)
+	}
+	return policy
+}
+
+// Compact applies the retention policy of each logged event type: drops rows older than its retention
+// days and keeps at most its max events.  returns the number of rows removed.
+func Compact(ctx context.Context) (int64, error) {
+	settings := wconfig.GetWatcher().GetFullConfig().Settings
+	return wstore.WithTxRtn(ctx, func(tx *wstore.TxWrap) (int64, error) {
+		var removed int64
+		eventNames := tx.SelectStrings(`SELECT DISTINCT event FROM db_eventlog`)
+		for _, eventName := range eventNames {
+			policy := getRetentionPolicy(settings, eventName)
+			if policy.RetentionDays > 0 {
+				olderThan := time.Now().AddDate(0, 0, -policy.RetentionDays).UnixMilli()
+				result := tx.Exec(`DELETE FROM db_eventlog WHERE event = ? AND ts < ?`, eventName, olderThan)
+				removed += rowsAffected(result)
+			}
+			if policy.MaxEvents > 0 {
+				query := `DELETE FROM db_eventlog WHERE event = ? AND id <= (SELECT id FROM db_eventlog WHERE event = ? ORDER BY id DESC LIMIT 1 OFFSET ?)`
+				result := tx.Exec(query, eventName, eventName, policy.MaxEvents)
+				removed += rowsAffected(result)
+			}
+		}
+		return removed, nil
+	})
+}
+
+func rowsAffected(result sql.Result) int64 {
+	if result == nil {
+		return 0
+	}
+	num, err := result.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return num
+}
+
+func compactLoop() {
+	defer func() {
+		panichandler.PanicHandler("eventlog:compactLoop", recover())
+	}()
+	for {
+		ctx, cancelFn := context.WithTimeout(context.Background(), 30*time.Second)
+		removed, err := Compact(ctx)
+		cancelFn()
+		if err != nil {
+			log.Printf("eventlog: error compacting: %v\n", err)
+		} else if removed > 0 {
+			log.Printf("eventlog: compacted %d events\n", removed)
+		}
+		time.Sleep(compactInterval)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package eventlog
+
+import (
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+)
+
+func TestGetRetentionPolicy(t *testing.T) {
+	days := func(n int64) *int64 { return &n }
+	settings := wconfig.SettingsType{
+		EventLogRetentionDays: days(7),
+		EventLogRetention: map[string]wconfig.EventLogRetentionType{
+			"connchange": {RetentionDays: days(90), MaxEvents: days(0)},
+			"user:*":     {MaxEvents: days(50)},
+			"user:build": {RetentionDays: days(1)},
+		},
+	}
+	tests := []struct {
+		eventName string
+		want      retentionPolicy
+	}{
+		{"blockclose", retentionPolicy{RetentionDays: 7, MaxEvents: DefaultMaxPerEvent}},
+		{"connchange", retentionPolicy{RetentionDays: 90, MaxEvents: 0}},
+		{"user:deploy", retentionPolicy{RetentionDays: 7, MaxEvents: 50}},
+		// an exact entry wins over a matching pattern
+		{"user:build", retentionPolicy{RetentionDays: 1, MaxEvents: DefaultMaxPerEvent}},
+	}
+	for _, tc := range tests {
+		if got := getRetentionPolicy(settings, tc.eventName); got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.eventName, got, tc.want)
+		}
+	}
+}
+
+func TestMakeEventLogRowCopiesData(t *testing.T) {
+	data := map[string]any{"status": "connected"}
+	scopes := []string{"conn:prod"}
+	row, err := makeEventLogRow(wps.WaveEvent{Event: "connchange", Scopes: scopes, Data: data, Ts: 1000})
+	if err != nil {
+		t.Fatalf("makeEventLogRow: %v", err)
+	}
+	// the publisher reusing its data after Publish returns must not change the logged event
+	data["status"] = "disconnected"
+	scopes[0] = "conn:dev"
+	event, err := row.toEvent()
+	if err != nil {
+		t.Fatalf("toEvent: %v", err)
+	}
+	if event.Scopes[0] != "conn:prod" || event.Data.(map[string]any)["status"] != "connected" {
+		t.Errorf("logged event changed after publish: %+v", event)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package filebackup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+)
+
+// versioned snapshots of a file, stored with the same layout as the regular backups:
+// a .bak file holding the contents next to a .json metadata file, in a directory keyed by basename + dirhash8
+
+const HistoryDirName = "config-history"
+
+type HistoryMetadata struct {
+	BackupMetadata
+	Version int    `json:"version"`
+	Source  string `json:"source"`
+	Hash    string `json:"hash"`
+	Size    int    `json:"size"`
+}
+
+var historyLock = &sync.Mutex{}
+
+func getHistoryDir(absFilePath string) string {
+	dir := filepath.Dir(absFilePath)
+	basename := filepath.Base(absFilePath)
+	hash := sha256.Sum256([]byte(dir))
+	dirHash8 := hex.EncodeToString(hash[:])[:8]
+	return filepath.Join(wavebase.GetWaveDataDir(), HistoryDirName, fmt.Sprintf("%s.%s", basename, dirHash8))
+}
+
+func historyBaseName(version int) string {
+	return fmt.Sprintf("v%06d", version)
+}
+
+func listHistoryNoLock(absFilePath string) ([]HistoryMetadata, error) {
+	historyDir := getHistoryDir(absFilePath)
+	entries, err := os.ReadDir(historyDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+	var rtn []HistoryMetadata
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		metadataData, err := os.ReadFile(filepath.Join(historyDir, entry.Name()))
+		if err != nil {
+			log.Printf("failed to read history metadata %s: %v\n", entry.Name(), err)
+			continue
+		}
+		var metadata HistoryMetadata
+		err = json.Unmarshal(metadataData, &metadata)
+		if err != nil {
+			log.Printf("failed to unmarshal history metadata %s: %v\n", entry.Name(), err)
+			continue
+		}
+		rtn = append(rtn, metadata)
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		return rtn[i].Version < rtn[j].Version
+	})
+	return rtn, nil
+}
+
+// ListHistory returns the recorded snapshots for absFilePath, oldest first
+func ListHistory(absFilePath string) ([]HistoryMetadata, error) {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+	return listHistoryNoLock(absFilePath)
+}
+
+// RecordHistory snapshots the current contents of absFilePath as a new version.
+// No snapshot is made (and nil is returned) if the file does not exist or is unchanged since the latest version.
+func RecordHistory(absFilePath string, source string) (*HistoryMetadata, error) {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+
+	fileInfo, err := os.Stat(absFilePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file for history: %w", err)
+	}
+	fileData, err := os.ReadFile(absFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file for history: %w", err)
+	}
+	dataHash := sha256.Sum256(fileData)
+	hashStr := hex.EncodeToString(dataHash[:])
+
+	history, err := listHistoryNoLock(absFilePath)
+	if err != nil {
+		return nil, err
+	}
+	nextVersion := 1
+	if len(history) > 0 {
+		latest := history[len(history)-1]
+		if latest.Hash == hashStr {
+			return nil, nil
+		}
+		nextVersion = latest.Version + 1
+	}
+
+	historyDir := getHistoryDir(absFilePath)
+	err = os.MkdirAll(historyDir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	baseName := historyBaseName(nextVersion)
+	err = os.WriteFile(filepath.Join(historyDir, baseName+".bak"), fileData, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write history file: %w", err)
+	}
+	metadata := HistoryMetadata{
+		BackupMetadata: BackupMetadata{
+			FullPath:  absFilePath,
+			Timestamp: time.Now().Format(time.RFC3339),
+			Perm:      fmt.Sprintf("%04o", fileInfo.Mode().Perm()),
+		},
+		Version: nextVersion,
+		Source:  source,
+		Hash:    hashStr,
+		Size:    len(fileData),
+	}
+	metadataJSON, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal history metadata: %w", err)
+	}
+	err = os.WriteFile(filepath.Join(historyDir, baseName+".json"), metadataJSON, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write history metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// ReadHistory returns the contents and metadata of a recorded version of absFilePath
+func ReadHistory(absFilePath string, version int) ([]byte, *HistoryMetadata, error) {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+	history, err := listHistoryNoLock(absFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, metadata := range history {
+		if metadata.Version != version {
+			continue
+		}
+		barr, err := os.ReadFile(filepath.Join(getHistoryDir(absFilePath), historyBaseName(version)+".bak"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read history file: %w", err)
+		}
+		return barr, &metadata, nil
+	}
+	return nil, nil, fmt.Errorf("version %d not found in history for %s", version, filepath.Base(absFilePath))
+}
+
+// PruneHistory removes versions beyond maxVersions or older than maxAge (zero disables a limit).
+// The latest version is always kept.  Returns the number of versions removed.
+func PruneHistory(absFilePath string, maxVersions int, maxAge time.Duration) (int, error) {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+	history, err := listHistoryNoLock(absFilePath)
+	if err != nil {
+		return 0, err
+	}
+	historyDir := getHistoryDir(absFilePath)
+	cutoffTime := time.Now().Add(-maxAge)
+	var removedCount int
+	for idx, metadata := range history {
+		if idx == len(history)-1 {
+			break
+		}
+		remaining := len(history) - idx
+		tooMany := maxVersions > 0 && remaining > maxVersions
+		tooOld := false
+		if maxAge > 0 {
+			ts, err := time.Parse(time.RFC3339, metadata.Timestamp)
+			tooOld = err == nil && ts.Before(cutoffTime)
+		}
+		if !tooMany && !tooOld {
+			continue
+		}
+		baseName := historyBaseName(metadata.Version)
+		os.Remove(filepath.Join(historyDir, baseName+".bak"))
+		err := os.Remove(filepath.Join(historyDir, baseName+".json"))
+		if err != nil {
+			log.Printf("failed to remove history version %d for %s: %v\n", metadata.Version, filepath.Base(absFilePath), err)
+			continue
+		}
+		removedCount++
+	}
+	return removedCount, nil
+}
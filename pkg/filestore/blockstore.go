@@ -393,6 +393,56 @@ type FlushStats struct {
 	NumCommitted    int
 }
 
+// cache and flusher stats (for the /metrics endpoint)
+type CacheStats struct {
+	NumEntries    int
+	NumDataParts  int
+	NumFlushes    int64
+	NumCommitted  int64
+	FlushErrors   int64
+	LastFlush     FlushStats
+	LastFlushTime time.Time
+}
+
+var flushTotals = struct {
+	lock      sync.Mutex
+	flushes   int64
+	committed int64
+	last      FlushStats
+	lastTime  time.Time
+}{}
+
+func recordFlushStats(stats FlushStats) {
+	flushTotals.lock.Lock()
+	defer flushTotals.lock.Unlock()
+	flushTotals.flushes++
+	flushTotals.committed += int64(stats.NumCommitted)
+	flushTotals.last = stats
+	flushTotals.lastTime = time.Now()
+}
+
+func (s *FileStore) GetCacheStats() CacheStats {
+	s.Lock.Lock()
+	entries := make([]*CacheEntry, 0, len(s.Cache))
+	for _, entry := range s.Cache {
+		entries = append(entries, entry)
+	}
+	s.Lock.Unlock()
+	rtn := CacheStats{NumEntries: len(entries), FlushErrors: int64(flushErrorCount.Load())}
+	for _, entry := range entries {
+		entry.Lock.Lock()
+		rtn.NumDataParts += len(entry.DataEntries)
+		entry.Lock.Unlock()
+	}
+	flushTotals.lock.Lock()
+	defer flushTotals.lock.Unlock()
+	rtn.NumFlushes = flushTotals.flushes
+	rtn.NumCommitted = flushTotals.committed
+	rtn.LastFlush = flushTotals.last
+	rtn.LastFlushTime = flushTotals.lastTime
+	return rtn
+}
+
 func (s *FileStore) FlushCache(ctx context.Context) (stats FlushStats, rtnErr error) {
 	wasFlushing := s.setUnlessFlushing()
 	if wasFlushing {
@@ -515,6 +565,7 @@ func (s *FileStore) runFlusher() {
 	}()
 	for {
 		stats, err := s.runFlushWithNewContext()
+		recordFlushStats(stats)
 		if err != nil || stats.NumDirtyEntries > 0 {
 			log.Printf("filestore flush: %d/%d entries flushed, err:%v\n", stats.NumCommitted, stats.NumDirtyEntries, err)
 		}
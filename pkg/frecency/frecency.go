@@ -0,0 +1,219 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// frecency database of the directories visited in terminal blocks, per connection (used by "wsh z" and
+// the "dir" suggestions).  shell integration reports the cwd with OSC 7 and the terminal stores it in the
+// block's cmd:cwd meta, visits are recorded when wavesrv applies that meta update.
+// ranking follows zoxide: every visit adds 1 to a directory's rank, the score weights the rank by how
+// recent the last visit was, and once the ranks of a connection add up to frecency:maxage they are all
+// aged (scaled down) and directories that drop below 1 are forgotten.
+package frecency
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+const DefaultMaxAge = 10000
+const DefaultQueryItems = 100
+const agingFactor = 0.9
+
+const visitQueueSize = 256
+
+type visitOp struct {
+	BlockId string
+	Cwd     string
+	Ts      int64
+}
+
+var visitQueue = make(chan visitOp, visitQueueSize)
+
+type dirRow struct {
+	Connection string  `db:"connection"`
+	Path       string  `db:"path"`
+	Rank       float64 `db:"rank"`
+	LastTs     int64   `db:"lastts"`
+}
+
+func InitFrecency() {
+	go visitLoop()
+}
+
+func getMaxAge() float64 {
+	settings := wconfig.GetWatcher().GetFullConfig().Settings
+	if settings.FrecencyMaxAge != nil && *settings.FrecencyMaxAge > 0 {
+		return float64(*settings.FrecencyMaxAge)
+	}
+	return DefaultMaxAge
+}
+
+func normalizeConnName(connName string) string {
+	if connName == "local" {
+		return ""
+	}
+	return connName
+}
+
+// HandleMetaUpdate is called with every meta update (after it was applied), it never blocks
+func HandleMetaUpdate(oref waveobj.ORef, meta waveobj.MetaMapType) {
+	if oref.OType != waveobj.OType_Block {
+		return
+	}
+	cwd := meta.GetString(waveobj.MetaKey_CmdCwd, "")
+	if cwd == "" {
+		return
+	}
+	select {
+	case visitQueue <- visitOp{BlockId: oref.OID, Cwd: cwd, Ts: time.Now().UnixMilli()}:
+	default:
+		log.Printf("frecency: queue full, dropping visit for block %s\n", oref.OID)
+	}
+}
+
+func visitLoop() {
+	defer func() {
+		panichandler.PanicHandler("frecency:visitLoop", recover())
+	}()
+	// the shell reports its cwd at every prompt, only a change of directory is a visit
+	lastCwd := make(map[string]string)
+	for op := range visitQueue {
+		if wconfig.GetWatcher().GetFullConfig().Settings.FrecencyDisabled || op.Cwd == lastCwd[op.BlockId] {
+			continue
+		}
+		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+		block, err := wstore.DBGet[*waveobj.Block](ctx, op.BlockId)
+		if err != nil || block == nil {
+			cancelFn()
+			delete(lastCwd, op.BlockId)
+			continue
+		}
+		lastCwd[op.BlockId] = op.Cwd
+		err = AddVisit(ctx, block.Meta.GetString(waveobj.MetaKey_Connection, ""), op.Cwd, op.Ts)
+		cancelFn()
+		if err != nil {
+			log.Printf("frecency: error recording visit: %v\n", err)
+		}
+	}
+}
+
+func AddVisit(ctx context.Context, connName string, path string, ts int64) error {
+	connName = normalizeConnName(connName)
+	maxAge := getMaxAge()
+	return wstore.WithTx(ctx, func(tx *wstore.TxWrap) error {
+		query := `INSERT INTO db_dirfrecency (connection, path, rank, lastts) VALUES (?, ?, 1, ?)
+		          ON CONFLICT (connection, path) DO UPDATE SET rank = rank + 1, lastts = excluded.lastts`
+		tx.Exec(query, connName, path, ts)
+		totalRank := tx.GetFloat64(`SELECT COALESCE(sum(rank), 0) FROM db_dirfrecency WHERE connection = ?`, connName)
+		if totalRank > maxAge {
+			tx.Exec(`UPDATE db_dirfrecency SET rank = rank * ? WHERE connection = ?`, agingFactor, connName)
+			tx.Exec(`DELETE FROM db_dirfrecency WHERE connection = ? AND rank < 1`, connName)
+		}
+		return nil
+	})
+}
+
+func RemoveDir(ctx context.Context, connName string, path string) error {
+	return wstore.WithTx(ctx, func(tx *wstore.TxWrap) error {
+		tx.Exec(`DELETE FROM db_dirfrecency WHERE connection = ? AND path = ?`, normalizeConnName(connName), path)
+		return nil
+	})
+}
+
+// zoxide's weights: visits in the last hour count 4x, last day 2x, last week 0.5x, older 0.25x
+func Score(rank float64, lastTs int64, now time.Time) float64 {
+	age := now.Sub(time.UnixMilli(lastTs))
+	switch {
+	case age < time.Hour:
+		return rank * 4
+	case age < 24*time.Hour:
+		return rank * 2
+	case age < 7*24*time.Hour:
+		return rank / 2
+	default:
+		return rank / 4
+	}
+}
+
+func lastPathComponent(path string) string {
+	path = strings.TrimRight(path, `/\`)
+	if idx := strings.LastIndexAny(path, `/\`); idx >= 0 {
+		return path[idx+1:]
+	}
+	return path
+}
+
+// every keyword must appear in the path in order (case-insensitive), the last one in the last path
+// component (unless it contains a separator itself)
+func MatchKeywords(path string, keywords []string) bool {
+	lowerPath := strings.ToLower(path)
+	pos := 0
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+		idx := strings.Index(lowerPath[pos:], keyword)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(keyword)
+	}
+	if len(keywords) == 0 {
+		return true
+	}
+	lastKeyword := strings.ToLower(keywords[len(keywords)-1])
+	if strings.ContainsAny(lastKeyword, `/\`) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(lastPathComponent(path)), lastKeyword)
+}
+
+func QueryDirs(ctx context.Context, data wshrpc.CommandDirFrecencyQueryData) ([]wshrpc.DirFrecencyEntry, error) {
+	maxItems := data.MaxItems
+	if maxItems <= 0 {
+		maxItems = DefaultQueryItems
+	}
+	connName := normalizeConnName(data.Connection)
+	rows, err := wstore.WithTxRtn(ctx, func(tx *wstore.TxWrap) ([]dirRow, error) {
+		var rows []dirRow
+		tx.Select(&rows, `SELECT connection, path, rank, lastts FROM db_dirfrecency WHERE connection = ?`, connName)
+		return rows, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading directories: %w", err)
+	}
+	now := time.Now()
+	var rtn []wshrpc.DirFrecencyEntry
+	for _, row := range rows {
+		if data.Exclude != "" && strings.TrimRight(row.Path, `/\`) == strings.TrimRight(data.Exclude, `/\`) {
+			continue
+		}
+		if !MatchKeywords(row.Path, data.Keywords) {
+			continue
+		}
+		rtn = append(rtn, wshrpc.DirFrecencyEntry{
+			Connection: row.Connection,
+			Path:       row.Path,
+			Rank:       row.Rank,
+			LastTs:     row.LastTs,
+			Score:      Score(row.Rank, row.LastTs, now),
+		})
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		if rtn[i].Score != rtn[j].Score {
+			return rtn[i].Score > rtn[j].Score
+		}
+		return rtn[i].LastTs > rtn[j].LastTs
+	})
+	if len(rtn) > maxItems {
+		rtn = rtn[:maxItems]
+	}
+	return rtn, nil
+}
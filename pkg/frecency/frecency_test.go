@@ -0,0 +1,58 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package frecency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+)
+
+func TestMatchKeywords(t *testing.T) {
+	tests := []struct {
+		path     string
+		keywords []string
+		want     bool
+	}{
+		{"/home/me/src/slterm", nil, true},
+		{"/home/me/src/slterm", []string{"slt"}, true},
+		{"/home/me/src/slterm", []string{"SLT"}, true},
+		{"/home/me/src/slterm", []string{"src", "term"}, true},
+		{"/home/me/src/slterm", []string{"term", "src"}, false},
+		{"/home/me/src/slterm", []string{"src"}, false}, // last keyword must match the last component
+		{"/home/me/src/slterm", []string{"src/sl"}, true},
+		{`C:\Users\me\Projects`, []string{"proj"}, true},
+		{"/home/me/src/slterm/", []string{"slterm"}, true},
+	}
+	for _, tt := range tests {
+		if got := MatchKeywords(tt.path, tt.keywords); got != tt.want {
+			t.Errorf("MatchKeywords(%q, %q) = %v, want %v", tt.path, tt.keywords, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	now := time.Now()
+	recent := Score(2, now.Add(-10*time.Minute).UnixMilli(), now)
+	old := Score(20, now.Add(-30*24*time.Hour).UnixMilli(), now)
+	if recent != 8 || old != 5 {
+		t.Errorf("scores = %v, %v, want 8, 5", recent, old)
+	}
+}
+
+func TestHandleMetaUpdate(t *testing.T) {
+	blockORef := waveobj.MakeORef(waveobj.OType_Block, "b1")
+	HandleMetaUpdate(blockORef, waveobj.MetaMapType{waveobj.MetaKey_CmdCwd: "/src/app"})
+	HandleMetaUpdate(blockORef, waveobj.MetaMapType{waveobj.MetaKey_View: "term"})
+	HandleMetaUpdate(waveobj.MakeORef(waveobj.OType_Tab, "t1"), waveobj.MetaMapType{waveobj.MetaKey_CmdCwd: "/tmp"})
+	var ops []visitOp
+	for len(visitQueue) > 0 {
+		ops = append(ops, <-visitQueue)
+	}
+	// only the block's cwd update is a visit
+	if len(ops) != 1 || ops[0].BlockId != "b1" || ops[0].Cwd != "/src/app" {
+		t.Errorf("got visits %+v", ops)
+	}
+}
@@ -1367,8 +1367,12 @@ func IsBlockTermDurable(block *waveobj.Block) bool {
 	if conncontroller.IsLocalConnName(connName) || conncontroller.IsWslConnName(connName) {
 		return false
 	}
+	// tls connections have no other way to run a shell
+	if conncontroller.IsTlsConnName(connName) {
+		return true
+	}
 
-	// 3. Check config hierarchy: blockmeta → connection → global (default true)
+	// 3. Check config hierarchy: blockmeta → connection → workspace → global (default true)
 	// Check block meta first
 	if val, exists := block.Meta[waveobj.MetaKey_TermDurable]; exists {
 		if boolVal, ok := val.(bool); ok {
@@ -1384,9 +1388,12 @@ func IsBlockTermDurable(block *waveobj.Block) bool {
 			}
 		}
 	}
-	// Check global settings
-	if fullConfig.Settings.TermDurable != nil {
-		return *fullConfig.Settings.TermDurable
+	// Check workspace + global settings
+	ctx, cancelFn := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelFn()
+	settings := wcore.ResolveBlockSettings(ctx, block)
+	if settings.TermDurable != nil {
+		return *settings.TermDurable
 	}
 	// Default to true for non-local connections
 	return true
@@ -0,0 +1,521 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// layout files describe a set of tabs (splits, sizes, views, connections, cwd and startup commands)
+// in json or yaml so a terminal setup can be checked into a repo and re-created with `wsh workspace apply`.
+//
+// splits alternate direction the same way the layout engine does: the children of a tab's top-level node
+// sit side by side, their children stack vertically, and so on.  sizes are relative weights between siblings.
+package layoutfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"gopkg.in/yaml.v3"
+)
+
+const LayoutsDirName = "layouts"
+
+// must match DefaultNodeSize in frontend/layout/lib/types.ts
+const DefaultNodeSize = 10
+
+var LayoutFileExts = []string{".json", ".yaml", ".yml"}
+
+var paramRe = regexp.MustCompile(`\$\$|\$\{([A-Za-z0-9_.-]+)\}`)
+
+// block meta keys that only describe runtime state, these are not written back out on save
+var runtimeMetaKeys = []string{
+	waveobj.MetaKey_Edit,
+	waveobj.MetaKey_History,
+	waveobj.MetaKey_HistoryForward,
+	waveobj.MetaKey_CmdJwt,
+	waveobj.MetaKey_TermVDomSubBlockId,
+	waveobj.MetaKey_TermVDomToolbarBlockId,
+	waveobj.MetaKey_WaveAiChatId,
+}
+
+var runtimeMetaPrefixes = []string{"vdom:", "aifilediff:"}
+
+type LayoutFile struct {
+	Name   string            `json:"name,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+	Tabs   []*TabDef         `json:"tabs"`
+}
+
+type TabDef struct {
+	Name   string   `json:"name,omitempty"`
+	Layout *NodeDef `json:"layout"`
+}
+
+// a node is either a block (no children) or a split (children)
+type NodeDef struct {
+	Size       float64             `json:"size,omitempty"`
+	View       string              `json:"view,omitempty"`
+	Connection string              `json:"connection,omitempty"`
+	Cwd        string              `json:"cwd,omitempty"`
+	Cmd        string              `json:"cmd,omitempty"`
+	Meta       waveobj.MetaMapType `json:"meta,omitempty"`
+	Focused    bool                `json:"focused,omitempty"`
+	Children   []*NodeDef          `json:"children,omitempty"`
+
+	// the block this node was read from (NodeFromLayoutTree), never serialized
+	BlockId string `json:"-"`
+}
+
+// one InsertAtIndex step, see Flatten
+type Entry struct {
+	IndexArr []int
+	Size     *uint
+	Node     *NodeDef
+}
+
+// parses json or yaml (yaml is a superset of json).  unknown fields are rejected to catch typos.
+func Parse(data []byte) (*LayoutFile, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing layout file: %w", err)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("layout file is empty")
+	}
+	jsonBytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing layout file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.DisallowUnknownFields()
+	var rtn LayoutFile
+	if err := decoder.Decode(&rtn); err != nil {
+		return nil, fmt.Errorf("parsing layout file: %w", err)
+	}
+	if err := rtn.Validate(); err != nil {
+		return nil, err
+	}
+	return &rtn, nil
+}
+
+func (lf *LayoutFile) Validate() error {
+	if len(lf.Tabs) == 0 {
+		return fmt.Errorf("layout file has no tabs")
+	}
+	for idx, tab := range lf.Tabs {
+		if tab == nil || tab.Layout == nil {
+			return fmt.Errorf("tab %d has no layout", idx+1)
+		}
+		if err := tab.Layout.validate(); err != nil {
+			return fmt.Errorf("tab %d: %w", idx+1, err)
+		}
+	}
+	return nil
+}
+
+func (n *NodeDef) validate() error {
+	if n.Size < 0 {
+		return fmt.Errorf("size cannot be negative")
+	}
+	if len(n.Children) == 0 {
+		return nil
+	}
+	if n.View != "" || n.Connection != "" || n.Cwd != "" || n.Cmd != "" || len(n.Meta) > 0 {
+		return fmt.Errorf("a split (node with children) cannot also set view, connection, cwd, cmd or meta")
+	}
+	for _, child := range n.Children {
+		if child == nil {
+			return fmt.Errorf("empty node in children")
+		}
+		if err := child.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ${name} is replaced with the param value, $$ with a literal $.  params override the file's defaults.
+func SubstituteString(s string, params map[string]string, missing map[string]bool) string {
+	return paramRe.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+		name := match[2 : len(match)-1]
+		val, ok := params[name]
+		if !ok {
+			missing[name] = true
+			return match
+		}
+		return val
+	})
+}
+
+func substituteAny(v any, params map[string]string, missing map[string]bool) any {
+	switch tv := v.(type) {
+	case string:
+		return SubstituteString(tv, params, missing)
+	case map[string]any:
+		for key, val := range tv {
+			tv[key] = substituteAny(val, params, missing)
+		}
+		return tv
+	case []any:
+		for idx, val := range tv {
+			tv[idx] = substituteAny(val, params, missing)
+		}
+		return tv
+	default:
+		return v
+	}
+}
+
+func (n *NodeDef) substitute(params map[string]string, missing map[string]bool) {
+	n.View = SubstituteString(n.View, params, missing)
+	n.Connection = SubstituteString(n.Connection, params, missing)
+	n.Cwd = SubstituteString(n.Cwd, params, missing)
+	n.Cmd = SubstituteString(n.Cmd, params, missing)
+	for key, val := range n.Meta {
+		n.Meta[key] = substituteAny(val, params, missing)
+	}
+	for _, child := range n.Children {
+		child.substitute(params, missing)
+	}
+}
+
+// applies params (merged over the file's defaults) to every string in the file
+func (lf *LayoutFile) Substitute(params map[string]string) error {
+	merged := make(map[string]string)
+	for key, val := range lf.Params {
+		merged[key] = val
+	}
+	for key, val := range params {
+		merged[key] = val
+	}
+	missing := make(map[string]bool)
+	lf.Name = SubstituteString(lf.Name, merged, missing)
+	for _, tab := range lf.Tabs {
+		tab.Name = SubstituteString(tab.Name, merged, missing)
+		tab.Layout.substitute(merged, missing)
+	}
+	if len(missing) > 0 {
+		var names []string
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("layout params not set: %s (pass them with --param name=value)", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func (n *NodeDef) BlockMeta() waveobj.MetaMapType {
+	meta := waveobj.MetaMapType{}
+	for key, val := range n.Meta {
+		meta[key] = val
+	}
+	view := n.View
+	if view == "" {
+		view = meta.GetString(waveobj.MetaKey_View, "term")
+	}
+	meta[waveobj.MetaKey_View] = view
+	if view == "term" && meta.GetString(waveobj.MetaKey_Controller, "") == "" {
+		meta[waveobj.MetaKey_Controller] = "shell"
+	}
+	if n.Connection != "" {
+		meta[waveobj.MetaKey_Connection] = n.Connection
+	}
+	if n.Cwd != "" {
+		meta[waveobj.MetaKey_CmdCwd] = n.Cwd
+	}
+	if n.Cmd != "" {
+		meta[waveobj.MetaKey_CmdInitScript] = n.Cmd
+	}
+	return meta
+}
+
+// drops splits with a single child, they would not survive in the layout tree anyway
+func (n *NodeDef) normalize() *NodeDef {
+	for len(n.Children) == 1 {
+		child := n.Children[0]
+		if child.Size == 0 {
+			child.Size = n.Size
+		}
+		child.Focused = child.Focused || n.Focused
+		n = child
+	}
+	for idx, child := range n.Children {
+		n.Children[idx] = child.normalize()
+	}
+	return n
+}
+
+func (n *NodeDef) firstLeaf() *NodeDef {
+	for len(n.Children) > 0 {
+		n = n.Children[0]
+	}
+	return n
+}
+
+// the layout engine always gives the first child of a split the default size (it is the node that got
+// split), so sibling sizes are scaled relative to it.  unset sizes count as 1.
+func childSizes(children []*NodeDef) []*uint {
+	rtn := make([]*uint, len(children))
+	hasSize := false
+	for _, child := range children {
+		if child.Size > 0 {
+			hasSize = true
+		}
+	}
+	if !hasSize {
+		return rtn
+	}
+	weights := make([]float64, len(children))
+	for idx, child := range children {
+		weights[idx] = child.Size
+		if weights[idx] == 0 {
+			weights[idx] = 1
+		}
+	}
+	for idx := range children {
+		size := uint(math.Max(1, math.Round(weights[idx]*DefaultNodeSize/weights[0])))
+		rtn[idx] = &size
+	}
+	return rtn
+}
+
+// converts a tab's tree into InsertAtIndex steps (see findInsertLocationFromIndexArr in layoutNode.ts).
+// an insert at [..., i] lands after the child at i, and an insert into a leaf splits it.  so the first
+// leaf of a split takes the split's own slot, the other children are appended as leaves, and only then
+// is each child split further.
+func (t *TabDef) Flatten() []Entry {
+	root := t.Layout.normalize()
+	first := root.firstLeaf()
+	rtn := []Entry{{IndexArr: []int{0}, Node: first}}
+	rtn = flattenSplit(root, nil, rtn)
+	return rtn
+}
+
+func flattenSplit(n *NodeDef, path []int, rtn []Entry) []Entry {
+	if len(n.Children) == 0 {
+		return rtn
+	}
+	sizes := childSizes(n.Children)
+	for idx := 1; idx < len(n.Children); idx++ {
+		childPath := append(append([]int{}, path...), idx)
+		rtn = append(rtn, Entry{IndexArr: childPath, Size: sizes[idx], Node: n.Children[idx].firstLeaf()})
+	}
+	for idx, child := range n.Children {
+		childPath := append(append([]int{}, path...), idx)
+		rtn = flattenSplit(child, childPath, rtn)
+	}
+	return rtn
+}
+
+func isRuntimeMetaKey(key string) bool {
+	for _, rk := range runtimeMetaKeys {
+		if key == rk {
+			return true
+		}
+	}
+	for _, prefix := range runtimeMetaPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// the inverse of BlockMeta
+func NodeFromBlockMeta(meta waveobj.MetaMapType) *NodeDef {
+	rtn := &NodeDef{
+		View:       meta.GetString(waveobj.MetaKey_View, ""),
+		Connection: meta.GetString(waveobj.MetaKey_Connection, ""),
+		Cwd:        meta.GetString(waveobj.MetaKey_CmdCwd, ""),
+		Cmd:        meta.GetString(waveobj.MetaKey_CmdInitScript, ""),
+	}
+	extra := waveobj.MetaMapType{}
+	for key, val := range meta {
+		if val == nil || isRuntimeMetaKey(key) {
+			continue
+		}
+		switch key {
+		case waveobj.MetaKey_View, waveobj.MetaKey_Connection, waveobj.MetaKey_CmdCwd, waveobj.MetaKey_CmdInitScript:
+			continue
+		case waveobj.MetaKey_Controller:
+			if rtn.View == "term" && val == "shell" {
+				continue
+			}
+		}
+		extra[key] = val
+	}
+	if len(extra) > 0 {
+		rtn.Meta = extra
+	}
+	return rtn
+}
+
+// converts a LayoutState.RootNode (the frontend's LayoutNode tree, as decoded json) into a NodeDef.
+// getMeta returns the meta for a block id, or nil if the block no longer exists.
+func NodeFromLayoutTree(rootNode any, focusedNodeId string, getMeta func(blockId string) waveobj.MetaMapType) *NodeDef {
+	nodeMap, ok := rootNode.(map[string]any)
+	if !ok {
+		return nil
+	}
+	var rtn *NodeDef
+	if children, ok := nodeMap["children"].([]any); ok && len(children) > 0 {
+		rtn = &NodeDef{}
+		for _, child := range children {
+			childNode := NodeFromLayoutTree(child, focusedNodeId, getMeta)
+			if childNode != nil {
+				rtn.Children = append(rtn.Children, childNode)
+			}
+		}
+		if len(rtn.Children) == 0 {
+			return nil
+		}
+	} else {
+		data, _ := nodeMap["data"].(map[string]any)
+		blockId, _ := data["blockId"].(string)
+		if blockId == "" {
+			return nil
+		}
+		meta := getMeta(blockId)
+		if meta == nil {
+			return nil
+		}
+		rtn = NodeFromBlockMeta(meta)
+		rtn.BlockId = blockId
+		if id, _ := nodeMap["id"].(string); id != "" && id == focusedNodeId {
+			rtn.Focused = true
+		}
+	}
+	if size, ok := nodeMap["size"].(float64); ok && size > 0 {
+		rtn.Size = math.Round(size*100) / 100
+	}
+	rtn = rtn.normalize()
+	rtn.clearEqualSizes()
+	return rtn
+}
+
+// like NodeFromLayoutTree, but falls back to placing blockIds side by side when there is no tree (a tab
+// that was never rendered only has pending layout actions).  returns nil if the tab has no blocks.
+func NodeFromTabLayout(rootNode any, focusedNodeId string, blockIds []string, getMeta func(blockId string) waveobj.MetaMapType) *NodeDef {
+	var rtn *NodeDef
+	if rootNode != nil {
+		rtn = NodeFromLayoutTree(rootNode, focusedNodeId, getMeta)
+	}
+	if rtn == nil {
+		rtn = &NodeDef{}
+		for _, blockId := range blockIds {
+			if meta := getMeta(blockId); meta != nil {
+				child := NodeFromBlockMeta(meta)
+				child.BlockId = blockId
+				rtn.Children = append(rtn.Children, child)
+			}
+		}
+		if len(rtn.Children) == 0 {
+			return nil
+		}
+		rtn = rtn.normalize()
+	}
+	rtn.Size = 0
+	return rtn
+}
+
+// sizes only matter relative to siblings, leave them out when a split is evenly divided
+func (n *NodeDef) clearEqualSizes() {
+	if len(n.Children) == 0 {
+		return
+	}
+	equal := true
+	for _, child := range n.Children {
+		if child.Size != n.Children[0].Size {
+			equal = false
+		}
+	}
+	if equal {
+		for _, child := range n.Children {
+			child.Size = 0
+		}
+	}
+}
+
+func replaceParamValues(s string, r *strings.Replacer) string {
+	if s == "" {
+		return s
+	}
+	return r.Replace(s)
+}
+
+func replaceParamValuesAny(v any, r *strings.Replacer) any {
+	switch tv := v.(type) {
+	case string:
+		return replaceParamValues(tv, r)
+	case map[string]any:
+		for key, val := range tv {
+			tv[key] = replaceParamValuesAny(val, r)
+		}
+		return tv
+	case []any:
+		for idx, val := range tv {
+			tv[idx] = replaceParamValuesAny(val, r)
+		}
+		return tv
+	default:
+		return v
+	}
+}
+
+func (n *NodeDef) replaceParamValues(r *strings.Replacer) {
+	n.View = replaceParamValues(n.View, r)
+	n.Connection = replaceParamValues(n.Connection, r)
+	n.Cwd = replaceParamValues(n.Cwd, r)
+	n.Cmd = replaceParamValues(n.Cmd, r)
+	for key, val := range n.Meta {
+		n.Meta[key] = replaceParamValuesAny(val, r)
+	}
+	for _, child := range n.Children {
+		child.replaceParamValues(r)
+	}
+}
+
+// the inverse of Substitute, used when saving: occurrences of each param value become ${name} and the
+// values are recorded as the file's defaults.  literal $ is escaped as $$.  the replacement is a single
+// pass (text produced for one value is never matched again), where values overlap the longest one wins.
+func (lf *LayoutFile) Parameterize(params map[string]string) {
+	names := make(map[string]string)
+	var values []string
+	for name, val := range params {
+		if val == "" {
+			continue
+		}
+		names[val] = name
+		values = append(values, val)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	// strings.Replacer prefers earlier pairs at the same position, "$" goes last so values containing $ match first
+	var pairs []string
+	for _, val := range values {
+		pairs = append(pairs, val, "${"+names[val]+"}")
+	}
+	r := strings.NewReplacer(append(pairs, "$", "$$")...)
+	lf.Name = replaceParamValues(lf.Name, r)
+	for _, tab := range lf.Tabs {
+		tab.Name = replaceParamValues(tab.Name, r)
+		tab.Layout.replaceParamValues(r)
+	}
+	if len(params) > 0 && lf.Params == nil {
+		lf.Params = make(map[string]string)
+	}
+	for name, val := range params {
+		lf.Params[name] = val
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package layoutfile
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+)
+
+// a minimal port of the frontend's insertNodeAtIndex (layoutTree.ts / layoutNode.ts), enough to check
+// that Flatten produces the tree it was given
+type simNode struct {
+	row      bool
+	size     uint
+	blockId  string
+	children []*simNode
+}
+
+func (n *simNode) addChildAt(idx int, child *simNode) {
+	if n.children == nil {
+		n.children = []*simNode{{row: !n.row, size: DefaultNodeSize, blockId: n.blockId}}
+		n.blockId = ""
+	}
+	child.row = !n.row
+	if idx >= len(n.children) {
+		n.children = append(n.children, child)
+	} else {
+		n.children = append(n.children[:idx], append([]*simNode{child}, n.children[idx:]...)...)
+	}
+}
+
+func simInsert(root *simNode, indexArr []int, child *simNode) *simNode {
+	if root == nil {
+		child.row = true
+		return child
+	}
+	node := root
+	for {
+		idx := indexArr[0]
+		indexArr = indexArr[1:]
+		numChildren := len(node.children)
+		if numChildren == 0 {
+			numChildren = 1
+		}
+		if idx > numChildren-1 {
+			idx = numChildren - 1
+		}
+		if len(indexArr) == 0 || node.children == nil {
+			node.addChildAt(idx+1, child)
+			return root
+		}
+		node = node.children[idx]
+	}
+}
+
+func (n *simNode) String() string {
+	if n.children == nil {
+		return fmt.Sprintf("%s:%d", n.blockId, n.size)
+	}
+	var parts []string
+	for _, child := range n.children {
+		parts = append(parts, child.String())
+	}
+	return fmt.Sprintf("(%s):%d", strings.Join(parts, " "), n.size)
+}
+
+func buildSim(tab *TabDef) *simNode {
+	var root *simNode
+	for _, entry := range tab.Flatten() {
+		size := uint(DefaultNodeSize)
+		if entry.Size != nil {
+			size = *entry.Size
+		}
+		root = simInsert(root, entry.IndexArr, &simNode{size: size, blockId: entry.Node.Cmd})
+	}
+	return root
+}
+
+func leaf(name string, size float64) *NodeDef {
+	return &NodeDef{Cmd: name, Size: size}
+}
+
+func split(size float64, children ...*NodeDef) *NodeDef {
+	return &NodeDef{Size: size, Children: children}
+}
+
+func TestFlattenStarterLayout(t *testing.T) {
+	tab := &TabDef{Layout: split(0, leaf("term", 0), split(0, leaf("sysinfo", 0), leaf("web", 0), leaf("preview", 0)))}
+	var got [][]int
+	for _, entry := range tab.Flatten() {
+		got = append(got, entry.IndexArr)
+	}
+	// same index arrays as wcore.GetStarterLayout
+	want := [][]int{{0}, {1}, {1, 1}, {1, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFlattenBuildsTree(t *testing.T) {
+	tests := []struct {
+		layout *NodeDef
+		want   string
+	}{
+		{leaf("a", 0), "a:10"},
+		{split(0, leaf("a", 0), leaf("b", 0), leaf("c", 0)), "(a:10 b:10 c:10):10"},
+		{split(0, leaf("a", 1), leaf("b", 3)), "(a:10 b:30):10"},
+		{split(0, leaf("a", 2), leaf("b", 0), leaf("c", 4)), "(a:10 b:5 c:20):10"},
+		// first child is itself a split, two levels deep
+		{
+			split(0, split(0, split(0, leaf("a", 0), leaf("b", 0)), leaf("c", 0)), leaf("d", 0)),
+			"(((a:10 b:10):10 c:10):10 d:10):10",
+		},
+		{
+			split(0, leaf("a", 0), split(2, leaf("b", 0), split(0, leaf("c", 1), leaf("d", 2))), leaf("e", 0)),
+			"(a:10 (b:10 (c:10 d:20):10):20 e:10):10",
+		},
+		// single-child splits collapse
+		{split(0, split(0, leaf("a", 0)), leaf("b", 0)), "(a:10 b:10):10"},
+	}
+	for _, test := range tests {
+		got := buildSim(&TabDef{Layout: test.layout}).String()
+		if got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
+
+func TestParseAndSubstitute(t *testing.T) {
+	content := `
+params:
+  project: /src/app
+tabs:
+  - name: ${name}
+    layout:
+      children:
+        - cwd: ${project}
+          cmd: echo $$HOME
+        - view: preview
+          meta:
+            file: ${project}/README.md
+`
+	lf, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	err = lf.Substitute(map[string]string{"name": "dev"})
+	if err != nil {
+		t.Fatalf("substitute: %v", err)
+	}
+	if lf.Tabs[0].Name != "dev" {
+		t.Errorf("tab name = %q", lf.Tabs[0].Name)
+	}
+	term := lf.Tabs[0].Layout.Children[0].BlockMeta()
+	if term.GetString(waveobj.MetaKey_View, "") != "term" || term.GetString(waveobj.MetaKey_Controller, "") != "shell" {
+		t.Errorf("term meta = %v", term)
+	}
+	if term.GetString(waveobj.MetaKey_CmdCwd, "") != "/src/app" || term.GetString(waveobj.MetaKey_CmdInitScript, "") != "echo $HOME" {
+		t.Errorf("term meta = %v", term)
+	}
+	preview := lf.Tabs[0].Layout.Children[1].BlockMeta()
+	if preview.GetString(waveobj.MetaKey_File, "") != "/src/app/README.md" {
+		t.Errorf("preview meta = %v", preview)
+	}
+
+	lf, _ = Parse([]byte(content))
+	err = lf.Substitute(nil)
+	if err == nil || !strings.Contains(err.Error(), "name") {
+		t.Errorf("expected missing param error, got %v", err)
+	}
+	if _, err := Parse([]byte(`{"tabs": [{"layuot": {}}]}`)); err == nil {
+		t.Errorf("expected error for unknown field")
+	}
+	if _, err := Parse([]byte(`{"tabs": [{"layout": {"view": "term", "children": [{}]}}]}`)); err == nil {
+		t.Errorf("expected error for split with a view")
+	}
+}
+
+func TestNodeFromLayoutTree(t *testing.T) {
+	blocks := map[string]waveobj.MetaMapType{
+		"b1": {"view": "term", "controller": "shell", "cmd:cwd": "/src/app/web", "history": []any{"x"}},
+		"b2": {"view": "web", "url": "https://example.com"},
+		"b3": {"view": "term", "controller": "shell", "connection": "user@host"},
+	}
+	tree := map[string]any{
+		"id": "root", "size": 10.0,
+		"children": []any{
+			map[string]any{"id": "n1", "size": 10.0, "data": map[string]any{"blockId": "b1"}},
+			map[string]any{"id": "n2", "size": 20.0, "children": []any{
+				map[string]any{"id": "n3", "size": 10.0, "data": map[string]any{"blockId": "b2"}},
+				map[string]any{"id": "n4", "size": 10.0, "data": map[string]any{"blockId": "b3"}},
+				map[string]any{"id": "n5", "size": 10.0, "data": map[string]any{"blockId": "deleted"}},
+			}},
+		},
+	}
+	node := NodeFromLayoutTree(tree, "n4", func(blockId string) waveobj.MetaMapType { return blocks[blockId] })
+	lf := &LayoutFile{Tabs: []*TabDef{{Layout: node}}}
+	lf.Parameterize(map[string]string{"project": "/src/app"})
+
+	want := split(10,
+		&NodeDef{View: "term", Cwd: "${project}/web", Size: 10, BlockId: "b1"},
+		&NodeDef{Size: 20, Children: []*NodeDef{
+			{View: "web", Meta: waveobj.MetaMapType{"url": "https://example.com"}, BlockId: "b2"},
+			{View: "term", Connection: "user@host", Focused: true, BlockId: "b3"},
+		}},
+	)
+	if !reflect.DeepEqual(node, want) {
+		t.Errorf("got %+v, want %+v", node, want)
+	}
+	if lf.Params["project"] != "/src/app" {
+		t.Errorf("params = %v", lf.Params)
+	}
+	if err := lf.Substitute(nil); err != nil || node.Children[0].Cwd != "/src/app/web" {
+		t.Errorf("round trip cwd = %q, err = %v", node.Children[0].Cwd, err)
+	}
+}
+
+func TestParameterizeRoundTrip(t *testing.T) {
+	// "o" appears in the other values and in their ${names}, "$HOME" has to win over the $ escaping
+	params := map[string]string{"root": "/src/root", "o": "o", "home": "$HOME", "price": "$5"}
+	cmd := "cd /src/root/tools && echo $HOME costs $5 $$"
+	lf := &LayoutFile{Name: "root", Tabs: []*TabDef{{Name: "tools", Layout: &NodeDef{
+		View: "term",
+		Cwd:  "/src/root",
+		Cmd:  cmd,
+		Meta: waveobj.MetaMapType{"file": "/src/root/docs/todo.md"},
+	}}}}
+	lf.Parameterize(params)
+	if got := lf.Tabs[0].Layout.Cmd; got != "cd ${root}/t${o}${o}ls && ech${o} ${home} c${o}sts ${price} $$$$" {
+		t.Errorf("parameterized cmd = %q", got)
+	}
+	if err := lf.Substitute(nil); err != nil {
+		t.Fatalf("substitute: %v", err)
+	}
+	node := lf.Tabs[0].Layout
+	if lf.Name != "root" || lf.Tabs[0].Name != "tools" || node.Cwd != "/src/root" || node.Cmd != cmd {
+		t.Errorf("round trip: name %q, tab %q, cwd %q, cmd %q", lf.Name, lf.Tabs[0].Name, node.Cwd, node.Cmd)
+	}
+	if file := node.Meta.GetString("file", ""); file != "/src/root/docs/todo.md" {
+		t.Errorf("round trip meta file = %q", file)
+	}
+}
@@ -0,0 +1,486 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// plugins are external executables in <configdir>/plugins/<name>/ described by a plugin.json manifest.
+// wavesrv launches enabled plugins ("plugins:enabled") with a SLTERM_JWT that authenticates them as a
+// router leaf on the plugin:<name> route.  the token carries the manifest's capabilities, so the router
+// limits what a plugin may call.  once a plugin's route comes up it is subscribed to its manifest events,
+// and wsh dispatches the plugin's subcommands to its route.  stdin is closed when wavesrv exits.
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
+)
+
+const (
+	PluginsDirName    = "plugins"
+	ManifestFileName  = "plugin.json"
+	PluginNameVarName = "SLTERM_PLUGIN_NAME"
+	PluginDirVarName  = "SLTERM_PLUGIN_DIR"
+)
+
+const (
+	Status_Disabled  = "disabled"
+	Status_Starting  = "starting"
+	Status_Running   = "running"   // process started, route not up yet
+	Status_Connected = "connected" // route is up
+	Status_Error     = "error"     // bad manifest, or waiting to restart after a crash
+)
+
+const minRestartBackoff = time.Second
+const maxRestartBackoff = time.Minute
+const stableRunTime = time.Minute // a plugin that ran this long has its backoff reset
+
+var pluginNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+type Manifest struct {
+	Name         string                    `json:"name"`
+	DisplayName  string                    `json:"displayname,omitempty"`
+	Description  string                    `json:"description,omitempty"`
+	Exec         string                    `json:"exec"` // relative to the plugin dir
+	Args         []string                  `json:"args,omitempty"`
+	Commands     []string                  `json:"commands,omitempty"` // rpc commands handled on the plugin's route
+	WshCommands  []wshrpc.PluginWshCommand `json:"wshcommands,omitempty"`
+	Events       []string                  `json:"events,omitempty"`
+	Capabilities []string                  `json:"capabilities,omitempty"` // defaults to core only
+}
+
+type pluginProc struct {
+	Name      string
+	Dir       string
+	Manifest  *Manifest
+	LoadError string
+	Status    string
+	Pid       int
+	Restarts  int
+	LastError string
+	StopCh    chan struct{} // non-nil while the supervisor is running
+	Cmd       *exec.Cmd
+}
+
+type pluginManager struct {
+	lock    *sync.Mutex
+	plugins map[string]*pluginProc
+	enabled map[string]bool
+}
+
+var manager = &pluginManager{
+	lock:    &sync.Mutex{},
+	plugins: make(map[string]*pluginProc),
+	enabled: make(map[string]bool),
+}
+
+func GetPluginsDir() string {
+	return filepath.Join(wavebase.GetWaveConfigDir(), PluginsDirName)
+}
+
+func InitPlugins() {
+	rpcClient := wshclient.GetBareRpcClient()
+	rpcClient.EventListener.On(wps.Event_RouteUp, handleRouteUpEvent)
+	rpcClient.EventListener.On(wps.Event_RouteDown, handleRouteDownEvent)
+	wshclient.EventSubCommand(rpcClient, wps.SubscriptionRequest{
+		Event:     wps.Event_RouteUp,
+		AllScopes: true,
+	}, nil)
+	wshclient.EventSubCommand(rpcClient, wps.SubscriptionRequest{
+		Event:     wps.Event_RouteDown,
+		AllScopes: true,
+	}, nil)
+	watcher := wconfig.GetWatcher()
+	manager.reconcile(watcher.GetFullConfig().Settings.PluginsEnabled)
+	watcher.RegisterUpdateHandler(func(newConfig wconfig.FullConfigType) {
+		manager.reconcile(newConfig.Settings.PluginsEnabled)
+	})
+}
+
+func ValidatePluginName(name string) error {
+	if !pluginNameRe.MatchString(name) {
+		return fmt.Errorf("invalid plugin name %q (lowercase letters, digits, '-' and '_' only)", name)
+	}
+	return nil
+}
+
+func readManifest(dir string, name string) (*Manifest, error) {
+	barr, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ManifestFileName, err)
+	}
+	var manifest Manifest
+	err = json.Unmarshal(barr, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ManifestFileName, err)
+	}
+	if manifest.Name == "" {
+		manifest.Name = name
+	}
+	if manifest.Name != name {
+		return nil, fmt.Errorf("manifest name %q does not match plugin directory %q", manifest.Name, name)
+	}
+	if manifest.Exec == "" {
+		return nil, fmt.Errorf("manifest has no exec")
+	}
+	if filepath.IsAbs(manifest.Exec) || !filepath.IsLocal(manifest.Exec) {
+		return nil, fmt.Errorf("exec %q must be a path inside the plugin directory", manifest.Exec)
+	}
+	for _, wshCmd := range manifest.WshCommands {
+		if wshCmd.Name == "" || wshCmd.Command == "" {
+			return nil, fmt.Errorf("wshcommands entries need a name and a command")
+		}
+		if !slices.Contains(manifest.Commands, wshCmd.Command) {
+			return nil, fmt.Errorf("wsh command %q uses undeclared rpc command %q", wshCmd.Name, wshCmd.Command)
+		}
+	}
+	manifest.Capabilities = wshrpc.NormalizeCapabilities(manifest.Capabilities)
+	if manifest.Capabilities == nil {
+		manifest.Capabilities = []string{wshrpc.Capability_Core}
+	}
+	return &manifest, nil
+}
+
+// scanPlugins_nolock re-reads the manifests in the plugins dir.  running plugins keep the manifest
+// they were started with until they are restarted.
+func (m *pluginManager) scanPlugins_nolock() {
+	entries, err := os.ReadDir(GetPluginsDir())
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("plugins: error reading plugins dir: %v\n", err)
+	}
+	found := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() || ValidatePluginName(entry.Name()) != nil {
+			continue
+		}
+		name := entry.Name()
+		found[name] = true
+		proc := m.plugins[name]
+		if proc == nil {
+			proc = &pluginProc{Name: name, Dir: filepath.Join(GetPluginsDir(), name), Status: Status_Disabled}
+			m.plugins[name] = proc
+		}
+		if proc.StopCh != nil {
+			continue
+		}
+		proc.Manifest, err = readManifest(proc.Dir, name)
+		proc.LoadError = ""
+		if err != nil {
+			proc.LoadError = err.Error()
+		}
+	}
+	for name, proc := range m.plugins {
+		if !found[name] && proc.StopCh == nil {
+			delete(m.plugins, name)
+		}
+	}
+}
+
+func (m *pluginManager) reconcile(enabledList []string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.enabled = make(map[string]bool)
+	for _, name := range enabledList {
+		m.enabled[name] = true
+	}
+	m.scanPlugins_nolock()
+	for name, proc := range m.plugins {
+		if m.enabled[name] {
+			m.start_nolock(proc)
+		} else {
+			m.stop_nolock(proc)
+		}
+	}
+}
+
+func (m *pluginManager) start_nolock(proc *pluginProc) {
+	if proc.StopCh != nil {
+		return
+	}
+	if proc.Manifest == nil {
+		proc.Status = Status_Error
+		return
+	}
+	proc.StopCh = make(chan struct{})
+	proc.Status = Status_Starting
+	proc.LastError = ""
+	go m.superviseLoop(proc, proc.Manifest, proc.StopCh)
+}
+
+func (m *pluginManager) stop_nolock(proc *pluginProc) {
+	if proc.StopCh != nil {
+		close(proc.StopCh)
+		proc.StopCh = nil
+		if proc.Cmd != nil && proc.Cmd.Process != nil {
+			proc.Cmd.Process.Kill()
+		}
+	}
+	proc.Status = Status_Disabled
+	proc.Pid = 0
+}
+
+func (m *pluginManager) superviseLoop(proc *pluginProc, manifest *Manifest, stopCh chan struct{}) {
+	defer func() {
+		panichandler.PanicHandler("plugin:superviseLoop", recover())
+	}()
+	backoff := minRestartBackoff
+	for {
+		startTs := time.Now()
+		err := m.runOnce(proc, manifest, stopCh)
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+		if time.Since(startTs) > stableRunTime {
+			backoff = minRestartBackoff
+		}
+		errStr := "plugin exited"
+		if err != nil {
+			errStr = err.Error()
+		}
+		log.Printf("plugins: %s: %s, restarting in %v\n", proc.Name, errStr, backoff)
+		m.lock.Lock()
+		if proc.StopCh == stopCh {
+			proc.Status = Status_Error
+			proc.LastError = errStr
+			proc.Pid = 0
+			proc.Cmd = nil
+			proc.Restarts++
+		}
+		m.lock.Unlock()
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRestartBackoff)
+	}
+}
+
+func (m *pluginManager) runOnce(proc *pluginProc, manifest *Manifest, stopCh chan struct{}) error {
+	jwtToken, err := wshutil.MakeClientJWTToken(wshrpc.RpcContext{
+		SockName:     wavebase.GetDomainSocketName(),
+		RouteId:      wshutil.MakePluginRouteId(proc.Name),
+		Capabilities: manifest.Capabilities,
+	})
+	if err != nil {
+		return fmt.Errorf("making plugin token: %w", err)
+	}
+	cmd := exec.Command(filepath.Join(proc.Dir, manifest.Exec), manifest.Args...)
+	cmd.Dir = proc.Dir
+	cmd.Env = append(os.Environ(),
+		wshutil.WaveJwtTokenVarName+"="+jwtToken,
+		PluginNameVarName+"="+proc.Name,
+		PluginDirVarName+"="+proc.Dir,
+	)
+	// held open (and never written) so the plugin sees EOF on stdin when wavesrv exits
+	stdinPipe, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("creating stdin pipe: %w", err)
+	}
+	defer stdinPipe.Close()
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("creating stdout pipe: %w", err)
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("creating stderr pipe: %w", err)
+	}
+	m.lock.Lock()
+	if proc.StopCh != stopCh {
+		m.lock.Unlock()
+		return nil
+	}
+	err = cmd.Start()
+	if err != nil {
+		m.lock.Unlock()
+		return fmt.Errorf("starting plugin: %w", err)
+	}
+	proc.Cmd = cmd
+	proc.Pid = cmd.Process.Pid
+	proc.Status = Status_Running
+	m.lock.Unlock()
+	log.Printf("plugins: started %s (pid %d)\n", proc.Name, cmd.Process.Pid)
+	go logPluginOutput(proc.Name, stdoutPipe)
+	go logPluginOutput(proc.Name, stderrPipe)
+	err = cmd.Wait()
+	if err != nil {
+		return fmt.Errorf("plugin exited: %w", err)
+	}
+	return nil
+}
+
+func logPluginOutput(name string, reader io.Reader) {
+	defer func() {
+		panichandler.PanicHandler("plugin:logPluginOutput", recover())
+	}()
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		log.Printf("[plugin:%s] %s\n", name, scanner.Text())
+	}
+}
+
+func getPluginNameFromRoute(event *wps.WaveEvent) string {
+	if len(event.Scopes) == 0 {
+		return ""
+	}
+	name, ok := strings.CutPrefix(event.Scopes[0], wshutil.RoutePrefix_Plugin)
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+func handleRouteUpEvent(event *wps.WaveEvent) {
+	name := getPluginNameFromRoute(event)
+	if name == "" {
+		return
+	}
+	manager.lock.Lock()
+	proc := manager.plugins[name]
+	if proc == nil || proc.StopCh == nil || proc.Manifest == nil {
+		manager.lock.Unlock()
+		return
+	}
+	proc.Status = Status_Connected
+	events := proc.Manifest.Events
+	manager.lock.Unlock()
+	routeId := wshutil.MakePluginRouteId(name)
+	for _, eventName := range events {
+		wps.Broker.Subscribe(routeId, wps.SubscriptionRequest{Event: eventName, AllScopes: true})
+	}
+	log.Printf("plugins: %s connected, subscribed to %d event(s)\n", name, len(events))
+}
+
+func handleRouteDownEvent(event *wps.WaveEvent) {
+	name := getPluginNameFromRoute(event)
+	if name == "" {
+		return
+	}
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	proc := manager.plugins[name]
+	if proc != nil && proc.Status == Status_Connected {
+		proc.Status = Status_Running
+	}
+}
+
+func (proc *pluginProc) toInfo(enabled bool) wshrpc.PluginInfo {
+	info := wshrpc.PluginInfo{
+		Name:     proc.Name,
+		Dir:      proc.Dir,
+		Route:    wshutil.MakePluginRouteId(proc.Name),
+		Enabled:  enabled,
+		Status:   proc.Status,
+		Pid:      proc.Pid,
+		Restarts: proc.Restarts,
+		Error:    proc.LastError,
+	}
+	if proc.LoadError != "" {
+		info.Status = Status_Error
+		info.Error = proc.LoadError
+	}
+	if proc.Manifest != nil {
+		info.DisplayName = proc.Manifest.DisplayName
+		info.Description = proc.Manifest.Description
+		info.Commands = proc.Manifest.Commands
+		info.WshCommands = proc.Manifest.WshCommands
+		info.Events = proc.Manifest.Events
+		info.Capabilities = proc.Manifest.Capabilities
+	}
+	return info
+}
+
+func ListPlugins() []wshrpc.PluginInfo {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	manager.scanPlugins_nolock()
+	rtn := make([]wshrpc.PluginInfo, 0, len(manager.plugins))
+	for name, proc := range manager.plugins {
+		rtn = append(rtn, proc.toInfo(manager.enabled[name]))
+	}
+	sort.Slice(rtn, func(i, j int) bool { return rtn[i].Name < rtn[j].Name })
+	return rtn
+}
+
+// SetPluginEnabled records the choice in "plugins:enabled", the config watcher then starts/stops the plugin
+func SetPluginEnabled(name string, enabled bool) error {
+	if err := ValidatePluginName(name); err != nil {
+		return err
+	}
+	manager.lock.Lock()
+	manager.scanPlugins_nolock()
+	proc := manager.plugins[name]
+	var enabledList []string
+	for enabledName := range manager.enabled {
+		if enabledName != name {
+			enabledList = append(enabledList, enabledName)
+		}
+	}
+	manager.lock.Unlock()
+	if enabled {
+		if proc == nil {
+			return fmt.Errorf("plugin %q not found in %s", name, GetPluginsDir())
+		}
+		if proc.LoadError != "" {
+			return fmt.Errorf("plugin %q: %s", name, proc.LoadError)
+		}
+		enabledList = append(enabledList, name)
+	}
+	if len(enabledList) == 0 {
+		return wconfig.SetBaseConfigValue(map[string]any{"plugins:enabled": nil})
+	}
+	sort.Strings(enabledList)
+	return wconfig.SetBaseConfigValue(map[string]any{"plugins:enabled": enabledList})
+}
+
+func RestartPlugin(name string) error {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	if !manager.enabled[name] {
+		return fmt.Errorf("plugin %q is not enabled", name)
+	}
+	proc := manager.plugins[name]
+	if proc == nil {
+		return fmt.Errorf("plugin %q not found", name)
+	}
+	manager.stop_nolock(proc)
+	manager.scanPlugins_nolock()
+	proc = manager.plugins[name]
+	if proc == nil {
+		return fmt.Errorf("plugin %q was removed", name)
+	}
+	if proc.LoadError != "" {
+		return fmt.Errorf("plugin %q: %s", name, proc.LoadError)
+	}
+	proc.Restarts = 0
+	manager.start_nolock(proc)
+	return nil
+}
+
+func StopAllPlugins() {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	for _, proc := range manager.plugins {
+		manager.stop_nolock(proc)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeManifest(t *testing.T, contents string) string {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(contents), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := writeManifest(t, `{"exec": "bin/hello", "commands": ["hello"], "wshcommands": [{"name": "hello", "command": "hello"}]}`)
+	manifest, err := readManifest(dir, "hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manifest.Name != "hello" {
+		t.Errorf("name should default to the plugin dir, got %q", manifest.Name)
+	}
+	if !slices.Equal(manifest.Capabilities, []string{"core"}) {
+		t.Errorf("capabilities should default to core, got %v", manifest.Capabilities)
+	}
+
+	badManifests := map[string]string{
+		"name mismatch":      `{"name": "other", "exec": "run"}`,
+		"no exec":            `{}`,
+		"exec outside dir":   `{"exec": "../run"}`,
+		"absolute exec":      `{"exec": "/bin/sh"}`,
+		"undeclared command": `{"exec": "run", "wshcommands": [{"name": "x", "command": "x"}]}`,
+	}
+	for desc, contents := range badManifests {
+		if _, err := readManifest(writeManifest(t, contents), "hello"); err == nil {
+			t.Errorf("%s: expected an error", desc)
+		}
+	}
+}
+
+func TestValidatePluginName(t *testing.T) {
+	for _, name := range []string{"hello", "my-plugin", "p_2"} {
+		if err := ValidatePluginName(name); err != nil {
+			t.Errorf("%q should be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "Hello", "-x", "a/b", "a:b"} {
+		if err := ValidatePluginName(name); err == nil {
+			t.Errorf("%q should be invalid", name)
+		}
+	}
+}
@@ -108,6 +108,15 @@ func IsWslConnName(connName string) bool {
 	return strings.HasPrefix(connName, "wsl://")
 }
 
+// capabilities for ssh, tls and wsl connservers (and the default for their blocks), from "wsh:remotecapabilities"
+func GetRemoteWshCapabilities() []string {
+	caps := wconfig.GetWatcher().GetFullConfig().Settings.WshRemoteCapabilities
+	if caps == nil {
+		return wshrpc.DefaultRemoteCapabilities
+	}
+	return wshrpc.NormalizeCapabilities(caps)
+}
+
 func GetAllConnStatus() []wshrpc.ConnStatus {
 	globalLock.Lock()
 	defer globalLock.Unlock()
@@ -116,6 +125,7 @@ func GetAllConnStatus() []wshrpc.ConnStatus {
 	for _, conn := range clientControllerMap {
 		connStatuses = append(connStatuses, conn.DeriveConnStatus())
 	}
+	connStatuses = append(connStatuses, getAllTlsConnStatus_withlock()...)
 	return connStatuses
 }
 
@@ -446,12 +456,16 @@ func (conn *SSHConn) StartConnServer(ctx context.Context, afterUpdate bool, useR
 			IsRouter: true,
 			SockName: sockName,
 			Conn:     conn.GetName(),
+
+			Capabilities: GetRemoteWshCapabilities(),
 		}
 	} else {
 		rpcCtx = wshrpc.RpcContext{
 			RouteId:  wshutil.MakeConnectionRouteId(conn.GetName()),
 			SockName: sockName,
 			Conn:     conn.GetName(),
+
+			Capabilities: GetRemoteWshCapabilities(),
 		}
 	}
 	jwtToken, err := wshutil.MakeClientJWTToken(rpcCtx)
@@ -1095,6 +1109,10 @@ func IsConnected(connName string) (bool, error) {
 	if IsLocalConnName(connName) {
 		return true, nil
 	}
+	if IsTlsConnName(connName) {
+		tlsConn := MaybeGetTlsConn(connName)
+		return tlsConn != nil && tlsConn.GetStatus() == Status_Connected, nil
+	}
 	connOpts, err := remote.ParseOpts(connName)
 	if err != nil {
 		return false, fmt.Errorf("error parsing connection name: %w", err)
@@ -1111,6 +1129,9 @@ func EnsureConnection(ctx context.Context, connName string) error {
 	if IsLocalConnName(connName) {
 		return nil
 	}
+	if IsTlsConnName(connName) {
+		return ensureTlsConnection(ctx, connName)
+	}
 	connOpts, err := remote.ParseOpts(connName)
 	if err != nil {
 		return fmt.Errorf("error parsing connection name: %w", err)
@@ -0,0 +1,420 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package conncontroller
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/blocklogger"
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/telemetry"
+	"github.com/SalyyS1/SLTerm/pkg/telemetry/telemetrydata"
+	"github.com/SalyyS1/SLTerm/pkg/util/tlsutil"
+	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+// tls:// connections talk to a connserver started with `wsh connserver --listen` (containers and vms
+// without sshd).  both ends authenticate with self-signed certificates: wavesrv pins the connserver's
+// fingerprint with "conn:tlsfingerprint", and the connserver only accepts clients listed in its
+// ~/.slterm/tls/authorized_clients.  after the handshake the connserver runs in router mode over the
+// tls stream, so the connection route behaves like an ssh one (file ops, durable jobs, sysinfo).
+// there is no shell access outside of the connserver, so wsh can't be installed or updated from here
+// and terminals on tls connections are always durable.
+
+const TlsConnPrefix = "tls://"
+const TlsConnectTimeout = 15 * time.Second
+
+var tlsConnMap = make(map[string]*TlsConn) // protected by globalLock
+
+type TlsConn struct {
+	lock          *sync.Mutex
+	lifecycleLock *sync.Mutex
+
+	Name            string // "tls://host:port"
+	Status          string
+	Error           string
+	WshVersion      string
+	NetConn         net.Conn
+	LastConnectTime int64
+	ActiveConnNum   int
+}
+
+func IsTlsConnName(connName string) bool {
+	return strings.HasPrefix(connName, TlsConnPrefix)
+}
+
+func getTlsClientCertPaths() (string, string) {
+	tlsDir := filepath.Join(wavebase.GetWaveDataDir(), "tls")
+	return filepath.Join(tlsDir, "client.crt"), filepath.Join(tlsDir, "client.key")
+}
+
+// GetTlsClientCert returns wavesrv's client certificate for tls connections (created on first use)
+func GetTlsClientCert() (tls.Certificate, error) {
+	certPath, keyPath := getTlsClientCertPaths()
+	return tlsutil.LoadOrCreateCert(certPath, keyPath, "slterm-"+wstore.GetClientId())
+}
+
+func GetTlsClientFingerprint() (string, error) {
+	cert, err := GetTlsClientCert()
+	if err != nil {
+		return "", err
+	}
+	return tlsutil.CertFingerprint(cert), nil
+}
+
+func getTlsConnInternal(connName string, createIfNotExists bool) *TlsConn {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+	rtn := tlsConnMap[connName]
+	if rtn == nil && createIfNotExists {
+		rtn = &TlsConn{lock: &sync.Mutex{}, lifecycleLock: &sync.Mutex{}, Name: connName, Status: Status_Init}
+		tlsConnMap[connName] = rtn
+	}
+	return rtn
+}
+
+func GetTlsConn(connName string) *TlsConn {
+	if !IsTlsConnName(connName) {
+		return nil
+	}
+	return getTlsConnInternal(connName, true)
+}
+
+func MaybeGetTlsConn(connName string) *TlsConn {
+	return getTlsConnInternal(connName, false)
+}
+
+func getAllTlsConnStatus_withlock() []wshrpc.ConnStatus {
+	var connStatuses []wshrpc.ConnStatus
+	for _, conn := range tlsConnMap {
+		connStatuses = append(connStatuses, conn.DeriveConnStatus())
+	}
+	return connStatuses
+}
+
+func (conn *TlsConn) WithLock(fn func()) {
+	conn.lock.Lock()
+	defer conn.lock.Unlock()
+	fn()
+}
+
+func (conn *TlsConn) GetName() string {
+	return conn.Name
+}
+
+func (conn *TlsConn) GetStatus() string {
+	conn.lock.Lock()
+	defer conn.lock.Unlock()
+	return conn.Status
+}
+
+func (conn *TlsConn) DeriveConnStatus() wshrpc.ConnStatus {
+	conn.lock.Lock()
+	defer conn.lock.Unlock()
+	return wshrpc.ConnStatus{
+		Status:           conn.Status,
+		Connected:        conn.Status == Status_Connected,
+		Connection:       conn.Name,
+		HasConnected:     (conn.LastConnectTime > 0),
+		ActiveConnNum:    conn.ActiveConnNum,
+		Error:            conn.Error,
+		WshEnabled:       conn.Status == Status_Connected,
+		WshVersion:       conn.WshVersion,
+		ConnHealthStatus: ConnHealthStatus_Good,
+	}
+}
+
+func (conn *TlsConn) Infof(ctx context.Context, format string, args ...any) {
+	log.Print(fmt.Sprintf("[conn:%s] ", conn.GetName()) + fmt.Sprintf(format, args...))
+	blocklogger.Infof(ctx, "[conndebug] "+format, args...)
+}
+
+func (conn *TlsConn) Debugf(ctx context.Context, format string, args ...any) {
+	blocklogger.Debugf(ctx, "[conndebug] "+format, args...)
+}
+
+func (conn *TlsConn) FireConnChangeEvent() {
+	status := conn.DeriveConnStatus()
+	event := wps.WaveEvent{
+		Event: wps.Event_ConnChange,
+		Scopes: []string{
+			fmt.Sprintf("connection:%s", conn.GetName()),
+		},
+		Data: status,
+	}
+	log.Printf("sending event: %+#v", event)
+	wps.Broker.Publish(event)
+}
+
+func (conn *TlsConn) getConnectionConfig() (wconfig.ConnKeywords, bool) {
+	config := wconfig.GetWatcher().GetFullConfig()
+	connSettings, ok := config.Connections[conn.GetName()]
+	if !ok {
+		return wconfig.ConnKeywords{}, false
+	}
+	return connSettings, true
+}
+
+func (conn *TlsConn) GetConfigShellPath() string {
+	config, ok := conn.getConnectionConfig()
+	if !ok {
+		return ""
+	}
+	return config.ConnShellPath
+}
+
+func (conn *TlsConn) WaitForConnect(ctx context.Context) error {
+	for {
+		status := conn.DeriveConnStatus()
+		switch status.Status {
+		case Status_Connected:
+			return nil
+		case Status_Connecting:
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("context timeout")
+			case <-time.After(100 * time.Millisecond):
+				continue
+			}
+		case Status_Init, Status_Disconnected:
+			return fmt.Errorf("disconnected")
+		case Status_Error:
+			return fmt.Errorf("error: %v", status.Error)
+		default:
+			return fmt.Errorf("unknown status: %q", status.Status)
+		}
+	}
+}
+
+func (conn *TlsConn) Connect(ctx context.Context) error {
+	conn.lifecycleLock.Lock()
+	defer conn.lifecycleLock.Unlock()
+
+	var connectAllowed bool
+	conn.WithLock(func() {
+		if conn.Status == Status_Connecting || conn.Status == Status_Connected {
+			connectAllowed = false
+		} else {
+			conn.Status = Status_Connecting
+			conn.Error = ""
+			connectAllowed = true
+		}
+	})
+	if !connectAllowed {
+		return fmt.Errorf("cannot connect to %q when status is %q", conn.GetName(), conn.GetStatus())
+	}
+	conn.Infof(ctx, "trying to connect to %q...\n", conn.GetName())
+	conn.FireConnChangeEvent()
+	err := conn.connectInternal(ctx)
+	if err != nil {
+		conn.Infof(ctx, "ERROR %v\n\n", err)
+		conn.WithLock(func() {
+			conn.Status = Status_Error
+			conn.Error = err.Error()
+		})
+		conn.closeNetConn()
+		telemetry.GoRecordTEventWrap(&telemetrydata.TEvent{
+			Event: "conn:connecterror",
+			Props: telemetrydata.TEventProps{
+				ConnType: "tls",
+			},
+		})
+	} else {
+		conn.Infof(ctx, "successfully connected\n\n")
+		conn.WithLock(func() {
+			conn.Status = Status_Connected
+			conn.LastConnectTime = time.Now().UnixMilli()
+			if conn.ActiveConnNum == 0 {
+				conn.ActiveConnNum = int(activeConnCounter.Add(1))
+			}
+		})
+		telemetry.GoRecordTEventWrap(&telemetrydata.TEvent{
+			Event: "conn:connect",
+			Props: telemetrydata.TEventProps{
+				ConnType: "tls",
+			},
+		})
+	}
+	conn.FireConnChangeEvent()
+	return err
+}
+
+func (conn *TlsConn) makeTlsConfig(host string) (*tls.Config, error) {
+	connConfig, _ := conn.getConnectionConfig()
+	pinnedFp := connConfig.ConnTlsFingerprint
+	if pinnedFp == "" {
+		return nil, fmt.Errorf("conn:tlsfingerprint is not set for %s (`wsh connserver --listen` prints the fingerprint to pin)", conn.GetName())
+	}
+	clientCert, err := GetTlsClientCert()
+	if err != nil {
+		return nil, fmt.Errorf("loading client certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates:          []tls.Certificate{clientCert},
+		ServerName:            host,
+		InsecureSkipVerify:    true, // the certificate is self-signed, VerifyPeerCertificate checks the pinned fingerprint
+		VerifyPeerCertificate: tlsutil.MakePinnedVerifier(func() []string { return []string{pinnedFp} }),
+		MinVersion:            tls.VersionTLS13,
+	}, nil
+}
+
+func (conn *TlsConn) connectInternal(ctx context.Context) error {
+	addr := strings.TrimPrefix(conn.GetName(), TlsConnPrefix)
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid tls connection name %q (want tls://host:port): %w", conn.GetName(), err)
+	}
+	tlsConfig, err := conn.makeTlsConfig(host)
+	if err != nil {
+		return err
+	}
+	dialCtx, cancelFn := context.WithTimeout(ctx, TlsConnectTimeout)
+	defer cancelFn()
+	dialer := &tls.Dialer{Config: tlsConfig}
+	netConn, err := dialer.DialContext(dialCtx, "tcp", addr)
+	if err != nil {
+		var unknownErr *tlsutil.UnknownPeerError
+		if errors.As(err, &unknownErr) {
+			return fmt.Errorf("connserver certificate %s does not match conn:tlsfingerprint", unknownErr.Fingerprint)
+		}
+		return fmt.Errorf("dialing %s: %w", addr, err)
+	}
+	conn.WithLock(func() {
+		conn.NetConn = netConn
+	})
+	netConn.SetDeadline(time.Now().Add(TlsConnectTimeout))
+	helloLine, err := tlsutil.MakeHelloLine(conn.GetName())
+	if err != nil {
+		return err
+	}
+	if _, err := netConn.Write(helloLine); err != nil {
+		return fmt.Errorf("sending hello: %w", err)
+	}
+	// with tls 1.3 a rejected client certificate only shows up on the first read
+	linesChan := utilfn.StreamToLinesChan(netConn)
+	versionLine, err := utilfn.ReadLineWithTimeout(linesChan, TlsConnectTimeout)
+	if err != nil {
+		clientFp, _ := GetTlsClientFingerprint()
+		return fmt.Errorf("connserver closed the connection (%v); if this client is not authorized, add %s to ~/.slterm/tls/authorized_clients on the remote", err, clientFp)
+	}
+	conn.Infof(ctx, "got connserver version: %s\n", strings.TrimSpace(versionLine))
+	isUpToDate, clientVersion, _, err := IsWshVersionUpToDate(ctx, versionLine)
+	if err != nil {
+		return fmt.Errorf("error checking wsh version: %w", err)
+	}
+	if !isUpToDate {
+		return fmt.Errorf("wsh on %s is %s (want v%s), update it and restart `wsh connserver --listen`", conn.GetName(), clientVersion, wavebase.WaveVersion)
+	}
+	netConn.SetDeadline(time.Time{})
+	conn.WithLock(func() {
+		conn.WshVersion = clientVersion
+	})
+	go func() {
+		defer func() {
+			panichandler.PanicHandler("tlsconn:HandleStdIOClient", recover())
+		}()
+		logName := fmt.Sprintf("tlsconn:%s", conn.GetName())
+		wshutil.HandleStdIOClient(logName, linesChan, netConn, GetRemoteWshCapabilities())
+		conn.onLinkClosed(netConn)
+	}()
+	conn.Infof(ctx, "connserver started, waiting for route to be registered\n")
+	regCtx, regCancelFn := context.WithTimeout(ctx, 5*time.Second)
+	defer regCancelFn()
+	connRoute := wshutil.MakeConnectionRouteId(conn.GetName())
+	err = wshutil.DefaultRouter.WaitForRegister(regCtx, connRoute)
+	if err != nil {
+		return fmt.Errorf("timeout waiting for connserver to register")
+	}
+	err = wshclient.ConnServerInitCommand(
+		wshclient.GetBareRpcClient(),
+		wshrpc.CommandConnServerInitData{ClientId: wstore.GetClientId()},
+		&wshrpc.RpcOpts{Route: connRoute},
+	)
+	if err != nil {
+		return fmt.Errorf("connserver init failed: %w", err)
+	}
+	conn.Infof(ctx, "connserver is registered and ready\n")
+	return nil
+}
+
+// called when the tls stream ends (remote closed, network error, or Close)
+func (conn *TlsConn) onLinkClosed(netConn net.Conn) {
+	netConn.Close()
+	var changed bool
+	conn.WithLock(func() {
+		if conn.NetConn != netConn {
+			return
+		}
+		conn.NetConn = nil
+		if conn.Status == Status_Connected {
+			conn.Status = Status_Disconnected
+			changed = true
+		}
+	})
+	if changed {
+		log.Printf("[conn:%s] connserver link closed\n", conn.GetName())
+		conn.FireConnChangeEvent()
+	}
+}
+
+func (conn *TlsConn) closeNetConn() {
+	var netConn net.Conn
+	conn.WithLock(func() {
+		netConn = conn.NetConn
+		conn.NetConn = nil
+	})
+	if netConn != nil {
+		netConn.Close()
+	}
+}
+
+func (conn *TlsConn) Close() error {
+	conn.lifecycleLock.Lock()
+	defer conn.lifecycleLock.Unlock()
+
+	defer conn.FireConnChangeEvent()
+	conn.WithLock(func() {
+		if conn.Status == Status_Connected || conn.Status == Status_Connecting {
+			conn.Status = Status_Disconnected
+		}
+	})
+	conn.closeNetConn()
+	return nil
+}
+
+func ensureTlsConnection(ctx context.Context, connName string) error {
+	conn := GetTlsConn(connName)
+	if conn == nil {
+		return fmt.Errorf("connection not found: %s", connName)
+	}
+	connStatus := conn.DeriveConnStatus()
+	switch connStatus.Status {
+	case Status_Connected:
+		return nil
+	case Status_Connecting:
+		return conn.WaitForConnect(ctx)
+	case Status_Init, Status_Disconnected:
+		return conn.Connect(ctx)
+	case Status_Error:
+		return fmt.Errorf("connection error: %s", connStatus.Error)
+	default:
+		return fmt.Errorf("unknown connection status %q", connStatus.Status)
+	}
+}
@@ -22,6 +22,7 @@ const (
 
 var windowsDriveRegex = regexp.MustCompile(`^[a-zA-Z]:`)
 var wslConnRegex = regexp.MustCompile(`^wsl://[^/]+`)
+var tlsConnRegex = regexp.MustCompile(`^tls://[^/]+`)
 
 type Connection struct {
 	Scheme string
@@ -121,6 +122,9 @@ func ParseURI(uri string) (*Connection, error) {
 		if strings.HasPrefix(rest, "wsl://") {
 			host = wslConnRegex.FindString(rest)
 			remotePath = strings.TrimPrefix(rest, host)
+		} else if strings.HasPrefix(rest, "tls://") {
+			host = tlsConnRegex.FindString(rest)
+			remotePath = strings.TrimPrefix(rest, host)
 		} else {
 			parseGenericPath()
 		}
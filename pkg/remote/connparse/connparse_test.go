@@ -357,6 +357,26 @@ func TestParseURI_WSHWSL(t *testing.T) {
 	testUri()
 }
 
+func TestParseURI_WSHTLS(t *testing.T) {
+	t.Parallel()
+	cstr := "wsh://tls://vm1.internal:7777/path/to/file"
+	c, err := connparse.ParseURI(cstr)
+	if err != nil {
+		t.Fatalf("failed to parse URI: %v", err)
+	}
+	expected := "/path/to/file"
+	if c.Path != expected {
+		t.Fatalf("expected path to be \"%q\", got \"%q\"", expected, c.Path)
+	}
+	expected = "tls://vm1.internal:7777"
+	if c.Host != expected {
+		t.Fatalf("expected host to be \"%q\", got \"%q\"", expected, c.Host)
+	}
+	if cstr != c.GetFullURI() {
+		t.Fatalf("expected full URI to be \"%q\", got \"%q\"", cstr, c.GetFullURI())
+	}
+}
+
 func TestParseUri_LocalWindowsAbsPath(t *testing.T) {
 	t.Parallel()
 	cstr := "wsh://local/C:\\path\\to\\file"
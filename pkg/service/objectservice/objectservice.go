@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/SalyyS1/SLTerm/pkg/frecency"
 	"github.com/SalyyS1/SLTerm/pkg/tsgen/tsgenmeta"
 	"github.com/SalyyS1/SLTerm/pkg/waveobj"
 	"github.com/SalyyS1/SLTerm/pkg/wcore"
@@ -147,6 +148,8 @@ func (svc *ObjectService) UpdateObjectMeta(uiContext waveobj.UIContext, orefStr
 	if err != nil {
 		return nil, fmt.Errorf("error updating %q meta: %w", orefStr, err)
 	}
+	// the terminal reports OSC 7 cwd changes through here
+	frecency.HandleMetaUpdate(*oref, meta)
 	return waveobj.ContextGetUpdatesRtn(ctx), nil
 }
 
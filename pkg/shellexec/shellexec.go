@@ -469,7 +469,15 @@ func StartRemoteShellProc(ctx context.Context, logCtx context.Context, termSize
 	return &ShellProc{Cmd: sessionWrap, ConnName: conn.GetName(), CloseOnce: &sync.Once{}, DoneCh: make(chan any)}, nil
 }
 
-func StartRemoteShellJob(ctx context.Context, logCtx context.Context, termSize waveobj.TermSize, cmdStr string, cmdOpts CommandOptsType, conn *conncontroller.SSHConn, optBlockId string) (string, error) {
+// RemoteJobConn is what StartRemoteShellJob needs from a connection (implemented by ssh and tls conns)
+type RemoteJobConn interface {
+	GetName() string
+	GetConfigShellPath() string
+	Infof(ctx context.Context, format string, args ...any)
+	Debugf(ctx context.Context, format string, args ...any)
+}
+
+func StartRemoteShellJob(ctx context.Context, logCtx context.Context, termSize waveobj.TermSize, cmdStr string, cmdOpts CommandOptsType, conn RemoteJobConn, optBlockId string) (string, error) {
 	connRoute := wshutil.MakeConnectionRouteId(conn.GetName())
 	rpcClient := wshclient.GetBareRpcClient()
 	remoteInfo, err := wshclient.RemoteGetInfoCommand(rpcClient, &wshrpc.RpcOpts{Route: connRoute, Timeout: 2000})
@@ -0,0 +1,445 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// workspace snapshots capture every tab's layout tree and block meta (including the last cwd reported by
+// shell integration, the connection and the durable job id) plus a trimmed copy of each terminal's
+// output.  restoring recreates the tabs, reconnects, starts the shells in their saved directories and
+// shows the old scrollback above the new prompt.  everything is stored in one filestore zone.
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/blockcontroller"
+	"github.com/SalyyS1/SLTerm/pkg/filestore"
+	"github.com/SalyyS1/SLTerm/pkg/jobcontroller"
+	"github.com/SalyyS1/SLTerm/pkg/layoutfile"
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/remote/conncontroller"
+	"github.com/SalyyS1/SLTerm/pkg/util/shellutil"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wcore"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+	"github.com/google/uuid"
+)
+
+const SnapshotZoneId = "snapshots"
+const SnapshotFilePrefix = "snapshot:"
+const ScrollbackFilePrefix = "term:"
+const MaxScrollbackSize = 64 * 1024
+const MaxAutoSnapshots = 10
+const ReconnectTimeout = 60 * time.Second
+
+// the block's meta has its cwd (cmd:cwd) and connection, they are restored with it
+type SnapshotBlock struct {
+	Meta          waveobj.MetaMapType `json:"meta"`
+	JobId         string              `json:"jobid,omitempty"`
+	HasScrollback bool                `json:"hasscrollback,omitempty"`
+}
+
+type SnapshotTab struct {
+	Name          string              `json:"name"`
+	Meta          waveobj.MetaMapType `json:"meta,omitempty"`
+	RootNode      any                 `json:"rootnode,omitempty"`
+	FocusedNodeId string              `json:"focusednodeid,omitempty"`
+	BlockIds      []string            `json:"blockids"`
+}
+
+type Snapshot struct {
+	wshrpc.SnapshotInfo
+	Tabs   []*SnapshotTab            `json:"tabs"`
+	Blocks map[string]*SnapshotBlock `json:"blocks"`
+}
+
+func InitSnapshots() {
+	wcore.BeforeDeleteWorkspaceHook = takeAutoSnapshot
+}
+
+func snapshotFileName(snapshotId string) string {
+	return SnapshotFilePrefix + snapshotId
+}
+
+func scrollbackFileName(snapshotId string, blockId string) string {
+	return ScrollbackFilePrefix + snapshotId + ":" + blockId
+}
+
+func copyMeta(meta waveobj.MetaMapType) waveobj.MetaMapType {
+	rtn := make(waveobj.MetaMapType, len(meta))
+	for key, val := range meta {
+		rtn[key] = val
+	}
+	return rtn
+}
+
+// keeps the tail of a terminal's output, starting on a line boundary so we don't begin in the middle
+// of an escape sequence
+func trimScrollback(data []byte, maxSize int) []byte {
+	if len(data) <= maxSize {
+		return data
+	}
+	data = data[len(data)-maxSize:]
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		data = data[idx+1:]
+	}
+	return data
+}
+
+func writeZoneFile(ctx context.Context, zoneId string, name string, opts wshrpc.FileOpts, data []byte) error {
+	err := filestore.WFS.MakeFile(ctx, zoneId, name, nil, opts)
+	if err != nil && err != fs.ErrExist {
+		return err
+	}
+	return filestore.WFS.WriteFile(ctx, zoneId, name, data)
+}
+
+func captureBlock(ctx context.Context, snapshotId string, blockId string) (*SnapshotBlock, error) {
+	block, err := wstore.DBGet[*waveobj.Block](ctx, blockId)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	rtn := &SnapshotBlock{
+		Meta:  copyMeta(block.Meta),
+		JobId: block.JobId,
+	}
+	if block.Meta.GetString(waveobj.MetaKey_View, "") != "term" {
+		return rtn, nil
+	}
+	_, data, err := filestore.WFS.ReadFile(ctx, blockId, wavebase.BlockFile_Term)
+	if err == fs.ErrNotExist || len(data) == 0 {
+		return rtn, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading terminal output for block %s: %w", blockId, err)
+	}
+	err = writeZoneFile(ctx, SnapshotZoneId, scrollbackFileName(snapshotId, blockId), wshrpc.FileOpts{}, trimScrollback(data, MaxScrollbackSize))
+	if err != nil {
+		return nil, fmt.Errorf("error saving scrollback for block %s: %w", blockId, err)
+	}
+	rtn.HasScrollback = true
+	return rtn, nil
+}
+
+func Create(ctx context.Context, workspaceId string, name string, auto bool) (*wshrpc.SnapshotInfo, error) {
+	ws, err := wcore.GetWorkspace(ctx, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("workspace %s not found: %w", workspaceId, err)
+	}
+	snap := &Snapshot{
+		SnapshotInfo: wshrpc.SnapshotInfo{
+			SnapshotId:    uuid.NewString(),
+			Name:          name,
+			WorkspaceName: ws.Name,
+			CreatedTs:     time.Now().UnixMilli(),
+			Auto:          auto,
+		},
+		Blocks: make(map[string]*SnapshotBlock),
+	}
+	if snap.Name == "" {
+		snap.Name = ws.Name
+	}
+	if snap.Name == "" {
+		snap.Name = time.Now().Format("2006-01-02 15:04")
+	}
+	for _, tabId := range ws.TabIds {
+		tab, err := wstore.DBMustGet[*waveobj.Tab](ctx, tabId)
+		if err != nil {
+			return nil, fmt.Errorf("error getting tab %s: %w", tabId, err)
+		}
+		snapTab := &SnapshotTab{Name: tab.Name, Meta: copyMeta(tab.Meta)}
+		layoutState, _ := wstore.DBGet[*waveobj.LayoutState](ctx, tab.LayoutState)
+		if layoutState != nil {
+			snapTab.RootNode = layoutState.RootNode
+			snapTab.FocusedNodeId = layoutState.FocusedNodeId
+		}
+		for _, blockId := range tab.BlockIds {
+			snapBlock, err := captureBlock(ctx, snap.SnapshotId, blockId)
+			if err != nil {
+				Delete(ctx, snap.SnapshotId)
+				return nil, err
+			}
+			if snapBlock == nil {
+				continue
+			}
+			snap.Blocks[blockId] = snapBlock
+			snapTab.BlockIds = append(snapTab.BlockIds, blockId)
+		}
+		if len(snapTab.BlockIds) == 0 {
+			continue
+		}
+		snap.Tabs = append(snap.Tabs, snapTab)
+	}
+	if len(snap.Tabs) == 0 {
+		return nil, fmt.Errorf("workspace has no blocks to snapshot")
+	}
+	snap.NumTabs = len(snap.Tabs)
+	snap.NumBlocks = len(snap.Blocks)
+	barr, err := json.Marshal(snap)
+	if err != nil {
+		Delete(ctx, snap.SnapshotId)
+		return nil, fmt.Errorf("error serializing snapshot: %w", err)
+	}
+	err = writeZoneFile(ctx, SnapshotZoneId, snapshotFileName(snap.SnapshotId), wshrpc.FileOpts{}, barr)
+	if err != nil {
+		Delete(ctx, snap.SnapshotId)
+		return nil, fmt.Errorf("error saving snapshot: %w", err)
+	}
+	return &snap.SnapshotInfo, nil
+}
+
+func readSnapshot(ctx context.Context, snapshotId string) (*Snapshot, error) {
+	_, data, err := filestore.WFS.ReadFile(ctx, SnapshotZoneId, snapshotFileName(snapshotId))
+	if err != nil {
+		return nil, fmt.Errorf("error reading snapshot %s: %w", snapshotId, err)
+	}
+	var snap Snapshot
+	err = json.Unmarshal(data, &snap)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing snapshot %s: %w", snapshotId, err)
+	}
+	return &snap, nil
+}
+
+// newest first
+func List(ctx context.Context) ([]wshrpc.SnapshotInfo, error) {
+	files, err := filestore.WFS.ListFiles(ctx, SnapshotZoneId)
+	if err != nil {
+		return nil, err
+	}
+	var rtn []wshrpc.SnapshotInfo
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name, SnapshotFilePrefix) {
+			continue
+		}
+		snap, err := readSnapshot(ctx, strings.TrimPrefix(file.Name, SnapshotFilePrefix))
+		if err != nil {
+			log.Printf("snapshot: %v\n", err)
+			continue
+		}
+		rtn = append(rtn, snap.SnapshotInfo)
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		return rtn[i].CreatedTs > rtn[j].CreatedTs
+	})
+	return rtn, nil
+}
+
+// resolves an id, an id prefix or a name (newest match wins)
+func Resolve(ctx context.Context, idOrName string) (string, error) {
+	if idOrName == "" {
+		return "", fmt.Errorf("no snapshot given")
+	}
+	infos, err := List(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, info := range infos {
+		if info.SnapshotId == idOrName {
+			return info.SnapshotId, nil
+		}
+	}
+	for _, info := range infos {
+		if info.Name == idOrName {
+			return info.SnapshotId, nil
+		}
+	}
+	var matches []string
+	for _, info := range infos {
+		if strings.HasPrefix(info.SnapshotId, idOrName) {
+			matches = append(matches, info.SnapshotId)
+		}
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("snapshot id prefix %q is ambiguous", idOrName)
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("snapshot %q not found", idOrName)
+	}
+	return matches[0], nil
+}
+
+func Delete(ctx context.Context, snapshotId string) error {
+	files, err := filestore.WFS.ListFiles(ctx, SnapshotZoneId)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, file := range files {
+		if file.Name == snapshotFileName(snapshotId) {
+			found = true
+		} else if !strings.HasPrefix(file.Name, ScrollbackFilePrefix+snapshotId+":") {
+			continue
+		}
+		err = filestore.WFS.DeleteFile(ctx, SnapshotZoneId, file.Name)
+		if err != nil {
+			return fmt.Errorf("error deleting %s: %w", file.Name, err)
+		}
+	}
+	if !found {
+		return fmt.Errorf("snapshot %s not found", snapshotId)
+	}
+	return nil
+}
+
+func makeTabLayout(snap *Snapshot, snapTab *SnapshotTab) (wcore.PortableLayout, []string) {
+	getMeta := func(blockId string) waveobj.MetaMapType {
+		if snapBlock := snap.Blocks[blockId]; snapBlock != nil {
+			return snapBlock.Meta
+		}
+		return nil
+	}
+	node := layoutfile.NodeFromTabLayout(snapTab.RootNode, snapTab.FocusedNodeId, snapTab.BlockIds, getMeta)
+	if node == nil {
+		return nil, nil
+	}
+	entries := (&layoutfile.TabDef{Layout: node}).Flatten()
+	layout := make(wcore.PortableLayout, len(entries))
+	oldBlockIds := make([]string, len(entries))
+	for idx, entry := range entries {
+		oldBlockIds[idx] = entry.Node.BlockId
+		layout[idx].IndexArr = entry.IndexArr
+		layout[idx].Size = entry.Size
+		layout[idx].Focused = entry.Node.Focused
+		// the full meta is restored (not the trimmed layout-file view of it)
+		layout[idx].BlockDef = &waveobj.BlockDef{Meta: copyMeta(snap.Blocks[entry.Node.BlockId].Meta)}
+	}
+	return layout, oldBlockIds
+}
+
+// a durable job that outlived its block (still running, not attached anywhere) is re-attached to the
+// restored block.  its own output file is the scrollback in that case.
+func reattachJob(ctx context.Context, jobId string, blockId string) bool {
+	job, _ := wstore.DBGet[*waveobj.Job](ctx, jobId)
+	if job == nil || job.AttachedBlockId != "" || job.JobManagerStatus != jobcontroller.JobManagerStatus_Running {
+		return false
+	}
+	_, data, err := filestore.WFS.ReadFile(ctx, jobId, jobcontroller.JobOutputFileName)
+	if err == nil && len(data) > 0 {
+		termOpts := wshrpc.FileOpts{MaxSize: blockcontroller.DefaultTermMaxFileSize, Circular: true}
+		err = writeZoneFile(ctx, blockId, wavebase.BlockFile_Term, termOpts, trimScrollback(data, blockcontroller.DefaultTermMaxFileSize))
+		if err != nil {
+			log.Printf("snapshot: error copying job output to block %s: %v\n", blockId, err)
+		}
+	}
+	err = jobcontroller.AttachJobToBlock(ctx, jobId, blockId)
+	if err != nil {
+		log.Printf("snapshot: error re-attaching job %s: %v\n", jobId, err)
+		return false
+	}
+	return true
+}
+
+// pre-creates the new block's term file with the old output, the shell's own output is appended below it
+func restoreScrollback(ctx context.Context, snap *Snapshot, oldBlockId string, blockId string) error {
+	_, data, err := filestore.WFS.ReadFile(ctx, SnapshotZoneId, scrollbackFileName(snap.SnapshotId, oldBlockId))
+	if err != nil {
+		return err
+	}
+	created := time.UnixMilli(snap.CreatedTs).Format("2006-01-02 15:04")
+	var buf bytes.Buffer
+	buf.Write(data)
+	buf.WriteString(shellutil.GetTerminalResetSeq())
+	buf.WriteString(fmt.Sprintf("\r\n\x1b[90m[restored from snapshot %q, %s]\x1b[0m\r\n", snap.Name, created))
+	termOpts := wshrpc.FileOpts{MaxSize: blockcontroller.DefaultTermMaxFileSize, Circular: true}
+	return writeZoneFile(ctx, blockId, wavebase.BlockFile_Term, termOpts, buf.Bytes())
+}
+
+func reconnectInBackground(connNames map[string]bool) {
+	for connName := range connNames {
+		go func(connName string) {
+			defer func() {
+				panichandler.PanicHandler("snapshot:reconnect", recover())
+			}()
+			ctx, cancelFn := context.WithTimeout(context.Background(), ReconnectTimeout)
+			defer cancelFn()
+			err := conncontroller.EnsureConnection(ctx, connName)
+			if err != nil {
+				log.Printf("snapshot: error reconnecting to %s: %v\n", connName, err)
+			}
+		}(connName)
+	}
+}
+
+// recreates the snapshot's tabs at the end of the workspace and activates the first one.  returns the new tab ids.
+func Restore(ctx context.Context, snapshotId string, workspaceId string) ([]string, error) {
+	snap, err := readSnapshot(ctx, snapshotId)
+	if err != nil {
+		return nil, err
+	}
+	var tabIds []string
+	connNames := make(map[string]bool)
+	for _, snapTab := range snap.Tabs {
+		layout, oldBlockIds := makeTabLayout(snap, snapTab)
+		if len(layout) == 0 {
+			continue
+		}
+		tabId, blockIds, err := wcore.CreateTabWithLayout(ctx, workspaceId, snapTab.Name, copyMeta(snapTab.Meta), layout)
+		if tabId != "" {
+			tabIds = append(tabIds, tabId)
+		}
+		if err != nil {
+			return tabIds, err
+		}
+		for idx, blockId := range blockIds {
+			snapBlock := snap.Blocks[oldBlockIds[idx]]
+			if connName := snapBlock.Meta.GetString(waveobj.MetaKey_Connection, ""); !conncontroller.IsLocalConnName(connName) {
+				connNames[connName] = true
+			}
+			if snapBlock.JobId != "" && reattachJob(ctx, snapBlock.JobId, blockId) {
+				continue
+			}
+			if !snapBlock.HasScrollback {
+				continue
+			}
+			err = restoreScrollback(ctx, snap, oldBlockIds[idx], blockId)
+			if err != nil {
+				log.Printf("snapshot: error restoring scrollback for block %s: %v\n", blockId, err)
+			}
+		}
+	}
+	if len(tabIds) == 0 {
+		return nil, fmt.Errorf("snapshot %s has no tabs to restore", snapshotId)
+	}
+	reconnectInBackground(connNames)
+	return tabIds, wcore.ActivateNewTab(ctx, workspaceId, tabIds)
+}
+
+func pruneAutoSnapshots(ctx context.Context) {
+	infos, err := List(ctx)
+	if err != nil {
+		return
+	}
+	numAuto := 0
+	for _, info := range infos {
+		if !info.Auto {
+			continue
+		}
+		numAuto++
+		if numAuto > MaxAutoSnapshots {
+			Delete(ctx, info.SnapshotId)
+		}
+	}
+}
+
+// a workspace is going away, keep a snapshot of it (the newest MaxAutoSnapshots are kept)
+func takeAutoSnapshot(ctx context.Context, workspaceId string) {
+	defer func() {
+		panichandler.PanicHandler("snapshot:takeAutoSnapshot", recover())
+	}()
+	info, err := Create(ctx, workspaceId, "", true)
+	if err != nil {
+		log.Printf("snapshot: no automatic snapshot for workspace %s: %v\n", workspaceId, err)
+		return
+	}
+	log.Printf("snapshot: took automatic snapshot %s of workspace %s\n", info.SnapshotId, workspaceId)
+	pruneAutoSnapshots(ctx)
+}
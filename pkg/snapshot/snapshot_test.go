@@ -0,0 +1,139 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package snapshot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/filestore"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wcore"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+func TestTrimScrollback(t *testing.T) {
+	data := []byte("line1\r\n\x1b[31mline2\x1b[0m\r\nline3\r\n")
+	if got := trimScrollback(data, 100); string(got) != string(data) {
+		t.Errorf("short output should be kept, got %q", got)
+	}
+	// cut in the middle of line2's escape sequence, so output starts at line3
+	if got := trimScrollback(data, 20); string(got) != "line3\r\n" {
+		t.Errorf("got %q", got)
+	}
+	if got := trimScrollback([]byte("abcdefgh"), 4); string(got) != "efgh" {
+		t.Errorf("without a newline the tail is kept as is, got %q", got)
+	}
+}
+
+// real stores in a temp data dir
+func initTestStores(t *testing.T) {
+	wavebase.DataHome_VarCache = t.TempDir()
+	wavebase.ConfigHome_VarCache = t.TempDir()
+	err := os.MkdirAll(filepath.Join(wavebase.GetWaveDataDir(), wavebase.WaveDBDir), 0700)
+	if err != nil {
+		t.Fatalf("creating db dir: %v", err)
+	}
+	if err := wstore.InitWStore(); err != nil {
+		t.Fatalf("initializing wstore: %v", err)
+	}
+	if err := filestore.InitFilestore(); err != nil {
+		t.Fatalf("initializing filestore: %v", err)
+	}
+}
+
+func TestCreateRestore(t *testing.T) {
+	initTestStores(t)
+	ctx := context.Background()
+	ws, err := wcore.CreateWorkspace(ctx, "dev", "", "", false, false)
+	if err != nil {
+		t.Fatalf("creating workspace: %v", err)
+	}
+	layout := wcore.PortableLayout{
+		{IndexArr: []int{0}, BlockDef: &waveobj.BlockDef{Meta: waveobj.MetaMapType{
+			waveobj.MetaKey_View: "term", waveobj.MetaKey_Controller: "shell", waveobj.MetaKey_CmdCwd: "/src/app", waveobj.MetaKey_Connection: "local",
+		}}},
+		{IndexArr: []int{1}, Focused: true, BlockDef: &waveobj.BlockDef{Meta: waveobj.MetaMapType{
+			waveobj.MetaKey_View: "web", waveobj.MetaKey_Url: "https://example.com",
+		}}},
+	}
+	_, blockIds, err := wcore.CreateTabWithLayout(ctx, ws.OID, "build", waveobj.MetaMapType{"tab:color": "red"}, layout)
+	if err != nil {
+		t.Fatalf("creating tab: %v", err)
+	}
+	termOpts := wshrpc.FileOpts{MaxSize: 256 * 1024, Circular: true}
+	if err := writeZoneFile(ctx, blockIds[0], wavebase.BlockFile_Term, termOpts, []byte("$ make\r\nok\r\n")); err != nil {
+		t.Fatalf("writing term output: %v", err)
+	}
+
+	info, err := Create(ctx, ws.OID, "before", false)
+	if err != nil {
+		t.Fatalf("creating snapshot: %v", err)
+	}
+	// the workspace also has its default tab
+	if info.NumTabs < 1 || info.NumBlocks < 2 {
+		t.Fatalf("snapshot has %d tabs and %d blocks", info.NumTabs, info.NumBlocks)
+	}
+	if snapshotId, err := Resolve(ctx, "before"); err != nil || snapshotId != info.SnapshotId {
+		t.Errorf("Resolve by name = %q, %v", snapshotId, err)
+	}
+
+	tabIds, err := Restore(ctx, info.SnapshotId, ws.OID)
+	if err != nil {
+		t.Fatalf("restoring snapshot: %v", err)
+	}
+	if len(tabIds) != info.NumTabs {
+		t.Fatalf("restored %d tabs, want %d", len(tabIds), info.NumTabs)
+	}
+	var tab *waveobj.Tab
+	for _, tabId := range tabIds {
+		if restored, _ := wstore.DBGet[*waveobj.Tab](ctx, tabId); restored != nil && restored.Name == "build" {
+			tab = restored
+		}
+	}
+	if tab == nil {
+		t.Fatalf("tab \"build\" was not restored")
+	}
+	if tab.Meta.GetString("tab:color", "") != "red" || len(tab.BlockIds) != 2 {
+		t.Fatalf("restored tab meta %v with %d blocks", tab.Meta, len(tab.BlockIds))
+	}
+	var termBlock *waveobj.Block
+	for _, blockId := range tab.BlockIds {
+		if blockId == blockIds[0] || blockId == blockIds[1] {
+			t.Errorf("restore reused block %s", blockId)
+		}
+		block, _ := wstore.DBGet[*waveobj.Block](ctx, blockId)
+		if block != nil && block.Meta.GetString(waveobj.MetaKey_View, "") == "term" {
+			termBlock = block
+		}
+	}
+	if termBlock == nil {
+		t.Fatalf("restored tab has no term block")
+	}
+	if cwd := termBlock.Meta.GetString(waveobj.MetaKey_CmdCwd, ""); cwd != "/src/app" {
+		t.Errorf("restored cwd = %q", cwd)
+	}
+	if conn := termBlock.Meta.GetString(waveobj.MetaKey_Connection, ""); conn != "local" {
+		t.Errorf("restored connection = %q", conn)
+	}
+	_, data, err := filestore.WFS.ReadFile(ctx, termBlock.OID, wavebase.BlockFile_Term)
+	if err != nil {
+		t.Fatalf("reading restored scrollback: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "$ make\r\nok\r\n") || !strings.Contains(string(data), `restored from snapshot "before"`) {
+		t.Errorf("restored scrollback = %q", data)
+	}
+
+	if err := Delete(ctx, info.SnapshotId); err != nil {
+		t.Fatalf("deleting snapshot: %v", err)
+	}
+	if infos, _ := List(ctx); len(infos) != 0 {
+		t.Errorf("snapshots left after delete: %v", infos)
+	}
+}
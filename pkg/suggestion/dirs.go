@@ -0,0 +1,69 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package suggestion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/SalyyS1/SLTerm/pkg/frecency"
+	"github.com/SalyyS1/SLTerm/pkg/util/fuzzyutil"
+	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+)
+
+// number of directories (best frecency first) that are fuzzy matched
+const MaxDirCandidates = 5000
+
+// fuzzy matches the visited directories of the connection, ranked by frecency (then match score)
+func fetchDirSuggestions(ctx context.Context, data wshrpc.FetchSuggestionsData) (*wshrpc.FetchSuggestionsResponse, error) {
+	dirs, err := frecency.QueryDirs(ctx, wshrpc.CommandDirFrecencyQueryData{
+		Connection: data.DirConnection,
+		MaxItems:   MaxDirCandidates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading directories: %w", err)
+	}
+	type scoredEntry struct {
+		dir       wshrpc.DirFrecencyEntry
+		score     int
+		positions []int
+	}
+	matcher := fuzzyutil.MakeMatcher(data.Query)
+	var scoredEntries []scoredEntry
+	for _, dir := range dirs {
+		score, positions, ok := matcher.Match(dir.Path)
+		if !ok {
+			continue
+		}
+		scoredEntries = append(scoredEntries, scoredEntry{dir: dir, score: score, positions: positions})
+	}
+	sort.SliceStable(scoredEntries, func(i, j int) bool {
+		if scoredEntries[i].dir.Score != scoredEntries[j].dir.Score {
+			return scoredEntries[i].dir.Score > scoredEntries[j].dir.Score
+		}
+		return scoredEntries[i].score > scoredEntries[j].score
+	})
+	var suggestions []wshrpc.SuggestionType
+	for _, scored := range scoredEntries {
+		suggestions = append(suggestions, wshrpc.SuggestionType{
+			Type:         "file",
+			SuggestionId: utilfn.QuickHashString(scored.dir.Path),
+			Display:      scored.dir.Path,
+			FilePath:     scored.dir.Path,
+			FileName:     scored.dir.Path,
+			FileMimeType: "directory",
+			MatchPos:     scored.positions,
+			Score:        scored.score,
+		})
+		if len(suggestions) >= MaxSuggestions {
+			break
+		}
+	}
+	return &wshrpc.FetchSuggestionsResponse{
+		Suggestions: suggestions,
+		ReqNum:      data.ReqNum,
+	}, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package suggestion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/SalyyS1/SLTerm/pkg/cmdhistory"
+	"github.com/SalyyS1/SLTerm/pkg/util/fuzzyutil"
+	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+)
+
+// number of unique commands (newest first) that are fuzzy matched
+const MaxHistoryCandidates = 5000
+
+func historySubText(entry wshrpc.HistoryEntry) string {
+	connName := entry.Connection
+	if connName == "" {
+		connName = "local"
+	}
+	if entry.Cwd == "" {
+		return connName
+	}
+	return connName + ":" + entry.Cwd
+}
+
+// matches the unique commands from the history index, ties keep the most recent command first
+func fetchHistorySuggestions(ctx context.Context, data wshrpc.FetchSuggestionsData) (*wshrpc.FetchSuggestionsResponse, error) {
+	entries, err := cmdhistory.QueryHistory(ctx, wshrpc.CommandHistoryQueryData{
+		Cwd:        data.HistoryCwd,
+		Connection: data.HistoryConn,
+		Unique:     true,
+		MaxItems:   MaxHistoryCandidates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+	type scoredEntry struct {
+		entry     wshrpc.HistoryEntry
+		score     int
+		positions []int
+	}
+	matcher := fuzzyutil.MakeMatcher(data.Query)
+	var scoredEntries []scoredEntry
+	for _, entry := range entries {
+		score, positions, ok := matcher.Match(entry.Cmd)
+		if !ok {
+			continue
+		}
+		scoredEntries = append(scoredEntries, scoredEntry{entry: entry, score: score, positions: positions})
+	}
+	sort.SliceStable(scoredEntries, func(i, j int) bool {
+		return scoredEntries[i].score > scoredEntries[j].score
+	})
+	var suggestions []wshrpc.SuggestionType
+	for _, scored := range scoredEntries {
+		suggestions = append(suggestions, wshrpc.SuggestionType{
+			Type:         "history",
+			SuggestionId: utilfn.QuickHashString(scored.entry.Cmd),
+			Display:      scored.entry.Cmd,
+			SubText:      historySubText(scored.entry),
+			Icon:         "clock-rotate-left",
+			MatchPos:     scored.positions,
+			Score:        scored.score,
+			HistoryCmd:   scored.entry.Cmd,
+		})
+		if len(suggestions) >= MaxSuggestions {
+			break
+		}
+	}
+	return &wshrpc.FetchSuggestionsResponse{
+		Suggestions: suggestions,
+		ReqNum:      data.ReqNum,
+	}, nil
+}
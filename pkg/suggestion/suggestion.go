@@ -143,6 +143,12 @@ func FetchSuggestions(ctx context.Context, data wshrpc.FetchSuggestionsData) (*w
 	if data.SuggestionType == "bookmark" {
 		return fetchBookmarkSuggestions(ctx, data)
 	}
+	if data.SuggestionType == "history" {
+		return fetchHistorySuggestions(ctx, data)
+	}
+	if data.SuggestionType == "dir" {
+		return fetchDirSuggestions(ctx, data)
+	}
 	return nil, fmt.Errorf("unsupported suggestion type: %q", data.SuggestionType)
 }
 
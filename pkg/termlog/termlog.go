@@ -0,0 +1,556 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// terminal logging ("term:log") tees a block's output to a file on disk.  the term blockfile is a
+// 256KB circular buffer, the log keeps everything.  like the output triggers, the logger observes the
+// blockfile append events that the shell and durable job controllers publish, so it works for both
+// and the pty read loops only pay for a channel send.  by default escape sequences are stripped and
+// every line gets a timestamp, "term:logstripansi" false keeps the raw output (like script(1)).  logs
+// are rotated by size and/or age and rotated files are gzipped.  log files always live in the logs dir
+// ("term:logdir"), "term:logfile" can only pick a name inside it.
+package termlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/util/termutil"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wcore"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+const LogsDirName = "termlogs"
+const DefaultMaxSizeMb = 10
+const DefaultKeep = 5
+
+const logQueueSize = 1024
+const logConfigCacheTime = 5 * time.Second
+const timestampFormat = "2006-01-02 15:04:05.000"
+const rotatedTimeFormat = "20060102-150405"
+
+type LogConfig struct {
+	Enabled     bool
+	Path        string
+	StripAnsi   bool
+	Timestamps  bool
+	MaxSize     int64         // in bytes, 0 for no size based rotation
+	RotateEvery time.Duration // 0 for no time based rotation
+	Keep        int           // number of rotated files to keep, 0 keeps all of them
+}
+
+type logChunk struct {
+	BlockId  string
+	Data64   string
+	Closed   bool
+	Reconfig bool
+}
+
+type blockLog struct {
+	Config     LogConfig
+	ConfigGen  int64
+	ResolvedTs time.Time
+	File       *os.File
+	Size       int64
+	OpenedTs   time.Time
+	Failed     bool                  // the file couldn't be opened, not retried until the config changes
+	Lines      termutil.LineSplitter // used when stripping ansi
+	MidLine    bool                  // used for raw logs, the last byte written wasn't a newline
+}
+
+type logEngine struct {
+	configGen   atomic.Int64
+	dropped     atomic.Int64 // total number of output chunks dropped because the queue was full
+	queue       chan logChunk
+	blocks      map[string]*blockLog // only used by the runLoop goroutine
+	droppedLock sync.Mutex
+	droppedMap  map[string]int64 // chunks dropped per block since its last write, a gap marker is written to the log
+}
+
+var globalLogEngine = &logEngine{
+	queue:      make(chan logChunk, logQueueSize),
+	blocks:     make(map[string]*blockLog),
+	droppedMap: make(map[string]int64),
+}
+
+func InitTermLog() {
+	wconfig.GetWatcher().RegisterUpdateHandler(func(newConfig wconfig.FullConfigType) {
+		globalLogEngine.configGen.Add(1)
+	})
+	wps.Broker.AddEventObserver(globalLogEngine)
+	go globalLogEngine.runLoop()
+}
+
+func GetDefaultLogsDir() string {
+	return filepath.Join(wavebase.GetWaveDataDir(), LogsDirName)
+}
+
+func getSettingInt(val *int64, def int64) int64 {
+	if val == nil {
+		return def
+	}
+	return *val
+}
+
+func getSettingBool(val *bool, def bool) bool {
+	if val == nil {
+		return def
+	}
+	return *val
+}
+
+// resolves the block's log config, block meta overrides the (workspace and global) settings.  only term
+// blocks can be logged.
+func ResolveLogConfig(ctx context.Context, blockId string) (LogConfig, error) {
+	block, err := wstore.DBMustGet[*waveobj.Block](ctx, blockId)
+	if err != nil {
+		return LogConfig{}, fmt.Errorf("error getting block %s: %w", blockId, err)
+	}
+	settings := wcore.ResolveBlockSettings(ctx, block)
+	logDir := GetDefaultLogsDir()
+	if settings.TermLogDir != "" {
+		logDir = wavebase.ExpandHomeDirSafe(settings.TermLogDir)
+	}
+	logPath := filepath.Join(logDir, blockId+".log")
+	if metaPath := block.Meta.GetString(waveobj.MetaKey_TermLogFile, ""); metaPath != "" {
+		logPath = wavebase.ExpandHomeDirSafe(metaPath)
+		if !filepath.IsAbs(logPath) {
+			logPath = filepath.Join(logDir, logPath)
+		}
+		// block meta can be set over wsh (also from remote connections), don't let it pick any file to append to
+		if !isInDir(logPath, logDir) {
+			return LogConfig{}, fmt.Errorf("term:logfile %q is not in the logs dir (%s)", metaPath, logDir)
+		}
+	}
+	config := LogConfig{
+		Enabled:     block.Meta.GetBool(waveobj.MetaKey_TermLog, getSettingBool(settings.TermLog, false)),
+		Path:        logPath,
+		StripAnsi:   block.Meta.GetBool(waveobj.MetaKey_TermLogStripAnsi, getSettingBool(settings.TermLogStripAnsi, true)),
+		Timestamps:  block.Meta.GetBool(waveobj.MetaKey_TermLogTimestamps, getSettingBool(settings.TermLogTimestamps, true)),
+		MaxSize:     max(getSettingInt(settings.TermLogMaxSizeMb, DefaultMaxSizeMb), 0) * 1024 * 1024,
+		RotateEvery: time.Duration(max(getSettingInt(settings.TermLogRotateHours, 0), 0)) * time.Hour,
+		Keep:        int(max(getSettingInt(settings.TermLogKeep, DefaultKeep), 0)),
+	}
+	if block.Meta.GetString(waveobj.MetaKey_View, "") != "term" {
+		config.Enabled = false
+	}
+	return config, nil
+}
+
+func isInDir(path string, dir string) bool {
+	relPath, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil || relPath == "." {
+		return false
+	}
+	return relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
+
+func GetLogStatus(ctx context.Context, blockId string) (*wshrpc.TermLogStatusData, error) {
+	config, err := ResolveLogConfig(ctx, blockId)
+	if err != nil {
+		return nil, err
+	}
+	rtn := &wshrpc.TermLogStatusData{
+		BlockId:    blockId,
+		Enabled:    config.Enabled,
+		Path:       config.Path,
+		StripAnsi:  config.StripAnsi,
+		Timestamps: config.Timestamps,
+		Dropped:    globalLogEngine.getDropped(blockId),
+	}
+	if finfo, err := os.Stat(config.Path); err == nil {
+		rtn.Size = finfo.Size()
+	}
+	return rtn, nil
+}
+
+// ObserveEvent is called synchronously from wps.Broker.Publish (from the pty read loops for blockfile
+// events), so it only queues the chunk.  all file io happens on the runLoop goroutine.
+func (e *logEngine) ObserveEvent(event wps.WaveEvent) {
+	var chunk logChunk
+	switch event.Event {
+	case wps.Event_BlockFile:
+		fileData, ok := event.Data.(*wps.WSFileEventData)
+		if !ok || fileData.FileOp != wps.FileOp_Append || fileData.FileName != wavebase.BlockFile_Term {
+			return
+		}
+		if !event.HasScope(waveobj.MakeORef(waveobj.OType_Block, fileData.ZoneId).String()) {
+			return
+		}
+		chunk = logChunk{BlockId: fileData.ZoneId, Data64: fileData.Data64}
+	case wps.Event_BlockClose:
+		blockId, ok := event.Data.(string)
+		if !ok {
+			return
+		}
+		chunk = logChunk{BlockId: blockId, Closed: true}
+	case wps.Event_WaveObjUpdate:
+		update, ok := event.Data.(waveobj.WaveObjUpdate)
+		if !ok || update.OType != waveobj.OType_Block {
+			return
+		}
+		chunk = logChunk{BlockId: update.OID, Reconfig: true}
+	default:
+		return
+	}
+	select {
+	case e.queue <- chunk:
+	default:
+		if chunk.Data64 == "" {
+			// a close or reconfig, the config cache picks up the change later
+			return
+		}
+		e.dropped.Add(1)
+		e.droppedLock.Lock()
+		e.droppedMap[chunk.BlockId]++
+		numDropped := e.droppedMap[chunk.BlockId]
+		e.droppedLock.Unlock()
+		if numDropped == 1 {
+			log.Printf("termlog: queue full, dropping terminal output for block %s\n", chunk.BlockId)
+		}
+	}
+}
+
+// returns the number of output chunks dropped for the block that haven't been marked in its log yet
+func (e *logEngine) getDropped(blockId string) int64 {
+	e.droppedLock.Lock()
+	defer e.droppedLock.Unlock()
+	return e.droppedMap[blockId]
+}
+
+func (e *logEngine) takeDropped(blockId string) int64 {
+	e.droppedLock.Lock()
+	defer e.droppedLock.Unlock()
+	numDropped := e.droppedMap[blockId]
+	delete(e.droppedMap, blockId)
+	return numDropped
+}
+
+func (e *logEngine) runLoop() {
+	defer func() {
+		panichandler.PanicHandler("termlog:runLoop", recover())
+	}()
+	for chunk := range e.queue {
+		e.processChunk(chunk)
+	}
+}
+
+func (e *logEngine) processChunk(chunk logChunk) {
+	bl := e.blocks[chunk.BlockId]
+	if chunk.Closed {
+		if bl != nil {
+			bl.close()
+			delete(e.blocks, chunk.BlockId)
+		}
+		e.takeDropped(chunk.BlockId)
+		return
+	}
+	if chunk.Reconfig {
+		// meta changes apply right away (so "wsh term log off" closes the file)
+		if bl != nil {
+			bl.ResolvedTs = time.Time{}
+			e.resolveConfig(chunk.BlockId, bl)
+		}
+		return
+	}
+	if bl == nil {
+		bl = &blockLog{}
+		e.blocks[chunk.BlockId] = bl
+	}
+	e.resolveConfig(chunk.BlockId, bl)
+	if !bl.Config.Enabled || bl.Failed {
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(chunk.Data64)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	if numDropped := e.takeDropped(chunk.BlockId); numDropped > 0 {
+		log.Printf("termlog: dropped %d output chunks for block %s\n", numDropped, chunk.BlockId)
+		bl.writeGapMarker(numDropped, now)
+	}
+	bl.write(data, now)
+}
+
+// re-resolves the block's config when the settings changed or the cache expired.  a changed config
+// closes the current file (the next write opens the new one).
+func (e *logEngine) resolveConfig(blockId string, bl *blockLog) {
+	configGen := e.configGen.Load()
+	if bl.ConfigGen == configGen && time.Since(bl.ResolvedTs) < logConfigCacheTime {
+		return
+	}
+	bl.ConfigGen = configGen
+	bl.ResolvedTs = time.Now()
+	ctx, cancelFn := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelFn()
+	config, err := ResolveLogConfig(ctx, blockId)
+	if err != nil {
+		config = LogConfig{}
+	}
+	if config == bl.Config {
+		return
+	}
+	bl.close()
+	bl.Config = config
+	bl.Failed = false
+}
+
+func (bl *blockLog) write(data []byte, now time.Time) {
+	var out []byte
+	if bl.Config.StripAnsi {
+		for _, line := range bl.Lines.Write(data) {
+			out = bl.appendLine(out, line, now)
+		}
+	} else {
+		out = bl.appendRaw(out, data, now)
+	}
+	if len(out) > 0 {
+		bl.writeOut(out, now)
+	}
+}
+
+// marks the place where output was dropped, on a line of its own
+func (bl *blockLog) writeGapMarker(numDropped int64, now time.Time) {
+	var out []byte
+	if bl.Config.StripAnsi {
+		if partial := bl.Lines.Partial(); partial != "" {
+			out = bl.appendLine(out, partial, now)
+			bl.Lines = termutil.LineSplitter{}
+		}
+	} else if bl.MidLine {
+		out = append(out, '\n')
+		bl.MidLine = false
+	}
+	out = bl.appendLine(out, fmt.Sprintf("[termlog: %d chunks of output were dropped]", numDropped), now)
+	bl.writeOut(out, now)
+}
+
+func (bl *blockLog) appendLine(out []byte, line string, now time.Time) []byte {
+	if bl.Config.Timestamps {
+		out = append(out, now.Format(timestampFormat)...)
+		out = append(out, ' ')
+	}
+	out = append(out, line...)
+	return append(out, '\n')
+}
+
+// raw output is written as is, timestamps go at the start of each line
+func (bl *blockLog) appendRaw(out []byte, data []byte, now time.Time) []byte {
+	for len(data) > 0 {
+		if !bl.MidLine && bl.Config.Timestamps {
+			out = append(out, now.Format(timestampFormat)...)
+			out = append(out, ' ')
+		}
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			out = append(out, data...)
+			bl.MidLine = true
+			break
+		}
+		out = append(out, data[:idx+1]...)
+		bl.MidLine = false
+		data = data[idx+1:]
+	}
+	return out
+}
+
+func (bl *blockLog) writeOut(out []byte, now time.Time) {
+	if bl.File != nil && bl.needsRotate(now) {
+		bl.rotate(now)
+	}
+	if bl.File == nil {
+		err := bl.openFile(now)
+		if err != nil {
+			log.Printf("termlog: cannot open %s: %v\n", bl.Config.Path, err)
+			bl.Failed = true
+			return
+		}
+	}
+	nw, err := bl.File.Write(out)
+	bl.Size += int64(nw)
+	if err != nil {
+		log.Printf("termlog: error writing %s: %v\n", bl.Config.Path, err)
+		bl.closeFile()
+		bl.Failed = true
+	}
+}
+
+func (bl *blockLog) openFile(now time.Time) error {
+	err := os.MkdirAll(filepath.Dir(bl.Config.Path), 0755)
+	if err != nil {
+		return err
+	}
+	// logs can contain anything that was printed to the terminal, keep them private
+	file, err := os.OpenFile(bl.Config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	finfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	bl.File = file
+	bl.Size = finfo.Size()
+	bl.OpenedTs = now
+	return nil
+}
+
+func (bl *blockLog) needsRotate(now time.Time) bool {
+	if bl.Config.MaxSize > 0 && bl.Size >= bl.Config.MaxSize {
+		return true
+	}
+	return bl.Config.RotateEvery > 0 && now.Sub(bl.OpenedTs) >= bl.Config.RotateEvery
+}
+
+// renames the current file to <path>.<time>-<seq> and gzips it in the background.  seq orders the
+// rotations within the same second.
+func (bl *blockLog) rotate(now time.Time) {
+	bl.closeFile()
+	var rotatedPath string
+	for seq := 1; ; seq++ {
+		rotatedPath = fmt.Sprintf("%s.%s-%d", bl.Config.Path, now.Format(rotatedTimeFormat), seq)
+		if !fileExists(rotatedPath) && !fileExists(rotatedPath+".gz") {
+			break
+		}
+	}
+	err := os.Rename(bl.Config.Path, rotatedPath)
+	if err != nil {
+		log.Printf("termlog: cannot rotate %s: %v\n", bl.Config.Path, err)
+		return
+	}
+	go compressRotatedLog(rotatedPath, bl.Config.Path, bl.Config.Keep)
+}
+
+func (bl *blockLog) closeFile() {
+	if bl.File == nil {
+		return
+	}
+	bl.File.Close()
+	bl.File = nil
+}
+
+// writes out the partial line (if any) and closes the file
+func (bl *blockLog) close() {
+	if bl.File != nil && bl.Config.StripAnsi {
+		if partial := bl.Lines.Partial(); partial != "" {
+			bl.writeOut(bl.appendLine(nil, partial, time.Now()), time.Now())
+		}
+	}
+	bl.closeFile()
+	bl.Lines = termutil.LineSplitter{}
+	bl.MidLine = false
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func compressRotatedLog(rotatedPath string, logPath string, keep int) {
+	defer func() {
+		panichandler.PanicHandler("termlog:compressRotatedLog", recover())
+	}()
+	err := gzipFile(rotatedPath, rotatedPath+".gz")
+	if err != nil {
+		log.Printf("termlog: cannot compress %s: %v\n", rotatedPath, err)
+		return
+	}
+	os.Remove(rotatedPath)
+	if keep > 0 {
+		pruneRotatedLogs(logPath, keep)
+	}
+}
+
+func gzipFile(srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmpPath := dstPath + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	gzw := gzip.NewWriter(dst)
+	_, err = io.Copy(gzw, src)
+	if err == nil {
+		err = gzw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, dstPath)
+}
+
+// splits a rotated log's suffix ("<time>-<seq>.gz") into its time and sequence number, files without
+// a sequence number sort first
+func parseRotatedName(suffix string) (string, int) {
+	suffix = strings.TrimSuffix(suffix, ".gz")
+	if len(suffix) <= len(rotatedTimeFormat) || suffix[len(rotatedTimeFormat)] != '-' {
+		return suffix, 0
+	}
+	seq, err := strconv.Atoi(suffix[len(rotatedTimeFormat)+1:])
+	if err != nil {
+		return suffix, 0
+	}
+	return suffix[:len(rotatedTimeFormat)], seq
+}
+
+// returns the rotated (gzipped) files of a log, oldest first
+func listRotatedLogs(logPath string) []string {
+	entries, err := os.ReadDir(filepath.Dir(logPath))
+	if err != nil {
+		return nil
+	}
+	prefix := filepath.Base(logPath) + "."
+	var rtn []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".gz") {
+			continue
+		}
+		rtn = append(rtn, filepath.Join(filepath.Dir(logPath), name))
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		tsI, seqI := parseRotatedName(strings.TrimPrefix(filepath.Base(rtn[i]), prefix))
+		tsJ, seqJ := parseRotatedName(strings.TrimPrefix(filepath.Base(rtn[j]), prefix))
+		if tsI != tsJ {
+			return tsI < tsJ
+		}
+		return seqI < seqJ
+	})
+	return rtn
+}
+
+func pruneRotatedLogs(logPath string, keep int) {
+	rotated := listRotatedLogs(logPath)
+	for len(rotated) > keep {
+		os.Remove(rotated[0])
+		rotated = rotated[1:]
+	}
+}
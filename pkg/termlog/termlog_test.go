@@ -0,0 +1,157 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package termlog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readLog(t *testing.T, path string) string {
+	barr, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(barr)
+}
+
+func TestBlockLogWrite(t *testing.T) {
+	now := time.Date(2025, 3, 4, 10, 20, 30, 0, time.Local)
+	ts := now.Format(timestampFormat) + " "
+	dir := t.TempDir()
+
+	textLog := &blockLog{Config: LogConfig{Enabled: true, Path: filepath.Join(dir, "text.log"), StripAnsi: true, Timestamps: true}}
+	textLog.write([]byte("\x1b[32mok\x1b[0m done\r\nprogress 10%\rprogress 100%\n"), now)
+	textLog.Config.Timestamps = false
+	textLog.write([]byte("prompt$ "), now)
+	textLog.close() // writes out the partial line
+	want := ts + "ok done\n" + ts + "progress 100%\nprompt$ \n"
+	if got := readLog(t, textLog.Config.Path); got != want {
+		t.Errorf("text log = %q, want %q", got, want)
+	}
+
+	rawLog := &blockLog{Config: LogConfig{Enabled: true, Path: filepath.Join(dir, "raw.log"), Timestamps: true}}
+	rawLog.write([]byte("\x1b[32mok\x1b[0m\r\npar"), now)
+	rawLog.write([]byte("tial\nnext"), now)
+	rawLog.close()
+	want = ts + "\x1b[32mok\x1b[0m\r\n" + ts + "partial\n" + ts + "next"
+	if got := readLog(t, rawLog.Config.Path); got != want {
+		t.Errorf("raw log = %q, want %q", got, want)
+	}
+}
+
+func TestBlockLogRotate(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "block.log")
+	bl := &blockLog{Config: LogConfig{Enabled: true, Path: logPath, StripAnsi: true, MaxSize: 10}}
+	now := time.Now()
+	bl.write([]byte("first line\n"), now)
+	bl.write([]byte("second line\n"), now)
+	bl.close()
+	if got := readLog(t, logPath); got != "second line\n" {
+		t.Errorf("current log = %q", got)
+	}
+	// rotated logs are compressed in the background
+	rotatedPath := logPath + "." + now.Format(rotatedTimeFormat) + "-1"
+	for start := time.Now(); fileExists(rotatedPath) && time.Since(start) < 5*time.Second; {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if fileExists(rotatedPath) {
+		t.Fatalf("rotated log was not compressed")
+	}
+	gzFile, err := os.Open(rotatedPath + ".gz")
+	if err != nil {
+		t.Fatalf("opening compressed log: %v", err)
+	}
+	defer gzFile.Close()
+	gzr, err := gzip.NewReader(gzFile)
+	if err != nil {
+		t.Fatalf("reading compressed log: %v", err)
+	}
+	barr, _ := io.ReadAll(gzr)
+	if string(barr) != "first line\n" {
+		t.Errorf("compressed log = %q", barr)
+	}
+
+	for _, name := range []string{"block.log.20250101-000000.gz", "block.log.20250102-000000.gz", "other.log.20250101-000000.gz"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0600)
+	}
+	pruneRotatedLogs(logPath, 2)
+	var names []string
+	for _, path := range listRotatedLogs(logPath) {
+		names = append(names, filepath.Base(path))
+	}
+	want := "block.log.20250102-000000.gz " + filepath.Base(rotatedPath) + ".gz"
+	if strings.Join(names, " ") != want {
+		t.Errorf("rotated logs after prune = %v, want %s", names, want)
+	}
+	if !fileExists(filepath.Join(dir, "other.log.20250101-000000.gz")) {
+		t.Errorf("prune removed another block's log")
+	}
+}
+
+func TestRotatedLogOrder(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "block.log")
+	// rotations within the same second sort by sequence number (numerically), files from older
+	// versions without one sort first
+	for _, name := range []string{"block.log.20250101-000000-10.gz", "block.log.20250101-000000-2.gz", "block.log.20250101-000000.gz", "block.log.20241231-235959-3.gz"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0600)
+	}
+	var names []string
+	for _, path := range listRotatedLogs(logPath) {
+		names = append(names, filepath.Base(path))
+	}
+	want := "block.log.20241231-235959-3.gz block.log.20250101-000000.gz block.log.20250101-000000-2.gz block.log.20250101-000000-10.gz"
+	if strings.Join(names, " ") != want {
+		t.Errorf("rotated logs = %v, want %s", names, want)
+	}
+}
+
+func TestGapMarker(t *testing.T) {
+	now := time.Date(2025, 3, 4, 10, 20, 30, 0, time.Local)
+	dir := t.TempDir()
+	engine := &logEngine{droppedMap: make(map[string]int64)}
+	engine.droppedMap["b1"] = 3
+	bl := &blockLog{Config: LogConfig{Enabled: true, Path: filepath.Join(dir, "gap.log"), StripAnsi: true}}
+	bl.write([]byte("before gap"), now)
+	if numDropped := engine.takeDropped("b1"); numDropped > 0 {
+		bl.writeGapMarker(numDropped, now)
+	}
+	bl.write([]byte("after\n"), now)
+	bl.close()
+	want := "before gap\n[termlog: 3 chunks of output were dropped]\nafter\n"
+	if got := readLog(t, bl.Config.Path); got != want {
+		t.Errorf("log = %q, want %q", got, want)
+	}
+	if engine.getDropped("b1") != 0 {
+		t.Errorf("drop count not reset after the gap marker")
+	}
+}
+
+func TestIsInDir(t *testing.T) {
+	logDir := filepath.Join(t.TempDir(), "termlogs")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(logDir, "block.log"), true},
+		{filepath.Join(logDir, "sub", "block.log"), true},
+		{filepath.Join(logDir, "..", "evil.log"), false},
+		{filepath.Join(logDir, "..", "termlogs2", "block.log"), false},
+		{filepath.Join(logDir, "..foo"), true},
+		{logDir, false},
+		{"/etc/passwd", false},
+	}
+	for _, tc := range tests {
+		if got := isInDir(tc.path, logDir); got != tc.want {
+			t.Errorf("isInDir(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package termtheme
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// alacritty keeps colors in a "colors" table: colors.primary.{foreground,background}, colors.cursor.cursor,
+// colors.selection.background, colors.normal.* and colors.bright.*.  only that table is read, the rest of
+// the config is ignored.
+
+var alacrittyAnsiNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// flat "table.key" => value map of the colors section
+type alacrittyColors map[string]string
+
+func (c alacrittyColors) toTheme() wconfig.TermThemeType {
+	var theme wconfig.TermThemeType
+	ansi := ansiFields(&theme)
+	for idx, colorName := range alacrittyAnsiNames {
+		*ansi[idx] = c["normal."+colorName]
+		*ansi[idx+8] = c["bright."+colorName]
+	}
+	theme.Foreground = c["primary.foreground"]
+	theme.Background = c["primary.background"]
+	theme.Cursor = c["cursor.cursor"]
+	theme.SelectionBackground = c["selection.background"]
+	// alacritty uses "CellForeground"/"CellBackground" to mean "inherit"
+	if strings.HasPrefix(theme.Cursor, "Cell") {
+		theme.Cursor = ""
+	}
+	if strings.HasPrefix(theme.SelectionBackground, "Cell") {
+		theme.SelectionBackground = ""
+	}
+	return theme
+}
+
+// splits off a trailing comment, ignoring '#' inside quoted strings
+func stripTomlComment(line string) string {
+	inQuote := byte(0)
+	for idx := 0; idx < len(line); idx++ {
+		ch := line[idx]
+		switch {
+		case inQuote != 0:
+			if ch == inQuote {
+				inQuote = 0
+			}
+		case ch == '"' || ch == '\'':
+			inQuote = ch
+		case ch == '#':
+			return line[:idx]
+		}
+	}
+	return line
+}
+
+func unquoteTomlValue(val string) string {
+	val = strings.TrimSpace(val)
+	if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+		return val[1 : len(val)-1]
+	}
+	return val
+}
+
+// minimal toml reader for the colors tables: [section] headers, key = "string" and key = { k = "v", ... } inline tables
+func parseAlacrittyToml(data []byte, defaultName string) ([]NamedTheme, error) {
+	colors := make(alacrittyColors)
+	var table string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(stripTomlComment(line))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		val = strings.TrimSpace(val)
+		fullKey := key
+		if table != "" {
+			fullKey = table + "." + key
+		}
+		if !strings.HasPrefix(fullKey, "colors.") {
+			continue
+		}
+		fullKey = strings.TrimPrefix(fullKey, "colors.")
+		if strings.HasPrefix(val, "{") {
+			inner := strings.TrimSuffix(strings.TrimPrefix(val, "{"), "}")
+			for _, part := range strings.Split(inner, ",") {
+				subKey, subVal, ok := strings.Cut(part, "=")
+				if !ok {
+					continue
+				}
+				colors[fullKey+"."+strings.TrimSpace(subKey)] = unquoteTomlValue(subVal)
+			}
+			continue
+		}
+		colors[fullKey] = unquoteTomlValue(val)
+	}
+	if len(colors) == 0 {
+		return nil, fmt.Errorf("no [colors] section found")
+	}
+	return []NamedTheme{{Name: defaultName, Theme: colors.toTheme()}}, nil
+}
+
+func parseAlacrittyYaml(data []byte, defaultName string) ([]NamedTheme, error) {
+	var config struct {
+		Colors map[string]map[string]yaml.Node `yaml:"colors"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing alacritty yaml: %w", err)
+	}
+	if len(config.Colors) == 0 {
+		return nil, fmt.Errorf("no colors section found")
+	}
+	colors := make(alacrittyColors)
+	for table, entries := range config.Colors {
+		for key, val := range entries {
+			// raw scalar, unquoted 0x... values would otherwise decode as ints
+			if val.Kind == yaml.ScalarNode {
+				colors[table+"."+key] = val.Value
+			}
+		}
+	}
+	return []NamedTheme{{Name: defaultName, Theme: colors.toTheme()}}, nil
+}
+
+func formatAlacrittyToml(theme wconfig.TermThemeType) []byte {
+	var sb strings.Builder
+	ansi := ansiFields(&theme)
+	sb.WriteString("[colors.primary]\n")
+	fmt.Fprintf(&sb, "background = %q\nforeground = %q\n", stripAlpha(theme.Background), stripAlpha(theme.Foreground))
+	fmt.Fprintf(&sb, "\n[colors.cursor]\ncursor = %q\ntext = \"CellBackground\"\n", stripAlpha(theme.Cursor))
+	if theme.SelectionBackground != "" {
+		fmt.Fprintf(&sb, "\n[colors.selection]\nbackground = %q\ntext = \"CellForeground\"\n", stripAlpha(theme.SelectionBackground))
+	}
+	for _, section := range []string{"normal", "bright"} {
+		fmt.Fprintf(&sb, "\n[colors.%s]\n", section)
+		for idx, colorName := range alacrittyAnsiNames {
+			if section == "bright" {
+				idx += 8
+			}
+			fmt.Fprintf(&sb, "%s = %q\n", colorName, *ansi[idx])
+		}
+	}
+	return []byte(sb.String())
+}
+
+func formatAlacrittyYaml(theme wconfig.TermThemeType) ([]byte, error) {
+	ansi := ansiFields(&theme)
+	normal := make(map[string]string)
+	bright := make(map[string]string)
+	for idx, colorName := range alacrittyAnsiNames {
+		normal[colorName] = *ansi[idx]
+		bright[colorName] = *ansi[idx+8]
+	}
+	colors := map[string]any{
+		"primary": map[string]string{"background": stripAlpha(theme.Background), "foreground": stripAlpha(theme.Foreground)},
+		"cursor":  map[string]string{"cursor": stripAlpha(theme.Cursor), "text": "CellBackground"},
+		"normal":  normal,
+		"bright":  bright,
+	}
+	if theme.SelectionBackground != "" {
+		colors["selection"] = map[string]string{"background": stripAlpha(theme.SelectionBackground), "text": "CellForeground"}
+	}
+	return yaml.Marshal(map[string]any{"colors": colors})
+}
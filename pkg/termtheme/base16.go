@@ -0,0 +1,118 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package termtheme
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// base16 schemes define 16 colors base00-base0F.  both the classic flat layout (scheme/author/base00...)
+// and the newer tinted-theming layout (name/palette/base00...) are supported.
+
+// colors are read as raw scalars since unquoted values like 000000 would otherwise decode as numbers
+type base16Scheme struct {
+	Scheme  string               `yaml:"scheme"`
+	Name    string               `yaml:"name"`
+	Palette map[string]yaml.Node `yaml:"palette"`
+}
+
+func isBase16Yaml(data []byte) bool {
+	var m map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return false
+	}
+	if _, ok := m["base00"]; ok {
+		return true
+	}
+	var scheme base16Scheme
+	if err := yaml.Unmarshal(data, &scheme); err != nil {
+		return false
+	}
+	_, ok := scheme.Palette["base00"]
+	return ok
+}
+
+func parseBase16(data []byte, defaultName string) ([]NamedTheme, error) {
+	var scheme base16Scheme
+	if err := yaml.Unmarshal(data, &scheme); err != nil {
+		return nil, fmt.Errorf("parsing base16 scheme: %w", err)
+	}
+	nodes := scheme.Palette
+	if nodes == nil {
+		if err := yaml.Unmarshal(data, &nodes); err != nil {
+			return nil, fmt.Errorf("parsing base16 scheme: %w", err)
+		}
+	}
+	colors := make(map[string]string)
+	for k, node := range nodes {
+		if node.Kind == yaml.ScalarNode && strings.HasPrefix(k, "base") {
+			colors[strings.ToLower(k)] = node.Value
+		}
+	}
+	base := func(idx int) string {
+		return colors[fmt.Sprintf("base%02x", idx)]
+	}
+	for idx := 0; idx < 16; idx++ {
+		if base(idx) == "" {
+			return nil, fmt.Errorf("base16 scheme is missing base%02X", idx)
+		}
+	}
+	// standard base16-shell mapping
+	theme := wconfig.TermThemeType{
+		Black:               base(0x00),
+		Red:                 base(0x08),
+		Green:               base(0x0B),
+		Yellow:              base(0x0A),
+		Blue:                base(0x0D),
+		Magenta:             base(0x0E),
+		Cyan:                base(0x0C),
+		White:               base(0x05),
+		BrightBlack:         base(0x03),
+		BrightRed:           base(0x08),
+		BrightGreen:         base(0x0B),
+		BrightYellow:        base(0x0A),
+		BrightBlue:          base(0x0D),
+		BrightMagenta:       base(0x0E),
+		BrightCyan:          base(0x0C),
+		BrightWhite:         base(0x07),
+		Gray:                base(0x04),
+		Foreground:          base(0x05),
+		Background:          base(0x00),
+		Cursor:              base(0x05),
+		SelectionBackground: base(0x02),
+	}
+	name := scheme.Scheme
+	if name == "" {
+		name = scheme.Name
+	}
+	if name == "" {
+		name = defaultName
+	}
+	return []NamedTheme{{Name: name, Theme: theme}}, nil
+}
+
+func formatBase16(name string, theme wconfig.TermThemeType) ([]byte, error) {
+	selection := stripAlpha(theme.SelectionBackground)
+	if selection == "" {
+		selection = theme.BrightBlack
+	}
+	// inverse of the mapping above; slots with no ansi equivalent use the closest color
+	colors := []string{
+		theme.Background, theme.Black, selection, theme.BrightBlack,
+		theme.Gray, theme.Foreground, theme.White, theme.BrightWhite,
+		theme.Red, theme.BrightRed, theme.Yellow, theme.Green,
+		theme.Cyan, theme.Blue, theme.Magenta, theme.BrightMagenta,
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "scheme: %q\n", name)
+	sb.WriteString("author: \"SLTerm export\"\n")
+	for idx, color := range colors {
+		fmt.Fprintf(&sb, "base%02X: %q\n", idx, strings.TrimPrefix(stripAlpha(color), "#"))
+	}
+	return []byte(sb.String()), nil
+}
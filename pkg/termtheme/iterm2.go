@@ -0,0 +1,154 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package termtheme
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+)
+
+// .itermcolors files are xml plists: a top-level dict of "Ansi N Color", "Foreground Color", ...
+// each holding a dict of "Red Component", "Green Component", "Blue Component" (0-1 reals)
+
+// parses a plist value starting at start (a <dict>, <real>, <string>, ...) into map[string]any / float64 / string
+func parsePlistValue(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		rtn := make(map[string]any)
+		var key string
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					var k string
+					if err := dec.DecodeElement(&k, &t); err != nil {
+						return nil, err
+					}
+					key = k
+					continue
+				}
+				val, err := parsePlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				rtn[key] = val
+			case xml.EndElement:
+				return rtn, nil
+			}
+		}
+	case "real", "integer":
+		var s string
+		if err := dec.DecodeElement(&s, &start); err != nil {
+			return nil, err
+		}
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	case "string":
+		var s string
+		if err := dec.DecodeElement(&s, &start); err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+}
+
+func plistColorToHex(val any) (string, bool) {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	var comps [3]int
+	for idx, name := range []string{"Red Component", "Green Component", "Blue Component"} {
+		f, ok := m[name].(float64)
+		if !ok {
+			return "", false
+		}
+		comps[idx] = int(math.Round(math.Max(0, math.Min(1, f)) * 255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", comps[0], comps[1], comps[2]), true
+}
+
+func parseITerm2(data []byte, defaultName string) ([]NamedTheme, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root map[string]any
+	for root == nil {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no dict found in plist")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing plist: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "dict" {
+			val, err := parsePlistValue(dec, start)
+			if err != nil {
+				return nil, fmt.Errorf("parsing plist: %w", err)
+			}
+			root = val.(map[string]any)
+		}
+	}
+	var theme wconfig.TermThemeType
+	for idx, field := range ansiFields(&theme) {
+		if color, ok := plistColorToHex(root[fmt.Sprintf("Ansi %d Color", idx)]); ok {
+			*field = color
+		}
+	}
+	theme.Foreground, _ = plistColorToHex(root["Foreground Color"])
+	theme.Background, _ = plistColorToHex(root["Background Color"])
+	theme.Cursor, _ = plistColorToHex(root["Cursor Color"])
+	theme.SelectionBackground, _ = plistColorToHex(root["Selection Color"])
+	if bold, ok := plistColorToHex(root["Bold Color"]); ok {
+		theme.CmdText = bold
+	}
+	return []NamedTheme{{Name: defaultName, Theme: theme}}, nil
+}
+
+func writePlistColor(buf *bytes.Buffer, key string, color string) {
+	color = stripAlpha(color)
+	if color == "" {
+		return
+	}
+	r, _ := strconv.ParseUint(color[1:3], 16, 8)
+	g, _ := strconv.ParseUint(color[3:5], 16, 8)
+	b, _ := strconv.ParseUint(color[5:7], 16, 8)
+	fmt.Fprintf(buf, "\t<key>%s</key>\n\t<dict>\n", key)
+	fmt.Fprintf(buf, "\t\t<key>Alpha Component</key>\n\t\t<real>1</real>\n")
+	fmt.Fprintf(buf, "\t\t<key>Blue Component</key>\n\t\t<real>%.6f</real>\n", float64(b)/255)
+	fmt.Fprintf(buf, "\t\t<key>Color Space</key>\n\t\t<string>sRGB</string>\n")
+	fmt.Fprintf(buf, "\t\t<key>Green Component</key>\n\t\t<real>%.6f</real>\n", float64(g)/255)
+	fmt.Fprintf(buf, "\t\t<key>Red Component</key>\n\t\t<real>%.6f</real>\n", float64(r)/255)
+	fmt.Fprintf(buf, "\t</dict>\n")
+}
+
+func formatITerm2(theme wconfig.TermThemeType) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	buf.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	for idx, field := range ansiFields(&theme) {
+		writePlistColor(&buf, fmt.Sprintf("Ansi %d Color", idx), *field)
+	}
+	writePlistColor(&buf, "Background Color", theme.Background)
+	writePlistColor(&buf, "Bold Color", theme.CmdText)
+	writePlistColor(&buf, "Cursor Color", theme.Cursor)
+	writePlistColor(&buf, "Foreground Color", theme.Foreground)
+	writePlistColor(&buf, "Selection Color", theme.SelectionBackground)
+	buf.WriteString("</dict>\n</plist>\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package termtheme converts terminal color schemes between wconfig.TermThemeType and
+// the formats used by other terminals (iTerm2, Windows Terminal, base16 and Alacritty).
+package termtheme
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+)
+
+const (
+	Format_ITerm2          = "iterm2"
+	Format_WindowsTerminal = "windowsterminal"
+	Format_Base16          = "base16"
+	Format_Alacritty       = "alacritty"      // toml (alacritty >= 0.13)
+	Format_AlacrittyYaml   = "alacritty-yaml" // legacy yaml config
+)
+
+var AllFormats = []string{Format_ITerm2, Format_WindowsTerminal, Format_Base16, Format_Alacritty, Format_AlacrittyYaml}
+
+type NamedTheme struct {
+	Name  string
+	Theme wconfig.TermThemeType
+}
+
+var hexColorRe = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+var themeKeyRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ansiFields returns pointers to the 16 ansi colors in terminal order (0-15)
+func ansiFields(theme *wconfig.TermThemeType) []*string {
+	return []*string{
+		&theme.Black, &theme.Red, &theme.Green, &theme.Yellow,
+		&theme.Blue, &theme.Magenta, &theme.Cyan, &theme.White,
+		&theme.BrightBlack, &theme.BrightRed, &theme.BrightGreen, &theme.BrightYellow,
+		&theme.BrightBlue, &theme.BrightMagenta, &theme.BrightCyan, &theme.BrightWhite,
+	}
+}
+
+// NormalizeColor converts "#rgb", "#rrggbb", "#rrggbbaa", "0xrrggbb" and bare hex into lowercase "#rrggbb" (or "#rrggbbaa")
+func NormalizeColor(color string) (string, error) {
+	orig := color
+	color = strings.TrimSpace(color)
+	color = strings.Trim(color, `"'`)
+	if color == "" {
+		return "", nil
+	}
+	switch {
+	case strings.HasPrefix(color, "#"):
+		color = color[1:]
+	case strings.HasPrefix(color, "0x"), strings.HasPrefix(color, "0X"):
+		color = color[2:]
+	}
+	if !hexColorRe.MatchString(color) {
+		return "", fmt.Errorf("invalid color %q", orig)
+	}
+	switch len(color) {
+	case 3:
+		color = string([]byte{color[0], color[0], color[1], color[1], color[2], color[2]})
+	case 6, 8:
+	default:
+		return "", fmt.Errorf("invalid color %q", orig)
+	}
+	return "#" + strings.ToLower(color), nil
+}
+
+// Normalize normalizes every color in the theme and fills in fields the source format did not provide.
+// Bright colors fall back to their normal counterparts, gray to bright black, cmdtext and cursor to the foreground.
+func Normalize(theme *wconfig.TermThemeType) error {
+	fields := append(ansiFields(theme), &theme.Gray, &theme.CmdText, &theme.Foreground, &theme.SelectionBackground, &theme.Background, &theme.Cursor)
+	for _, field := range fields {
+		color, err := NormalizeColor(*field)
+		if err != nil {
+			return err
+		}
+		*field = color
+	}
+	if theme.Foreground == "" || theme.Background == "" {
+		return fmt.Errorf("theme must define both foreground and background colors")
+	}
+	ansi := ansiFields(theme)
+	for idx := 0; idx < 8; idx++ {
+		if *ansi[idx+8] == "" {
+			*ansi[idx+8] = *ansi[idx]
+		}
+	}
+	for idx, field := range ansi {
+		if *field == "" {
+			return fmt.Errorf("theme is missing ansi color %d", idx)
+		}
+	}
+	if theme.Gray == "" {
+		theme.Gray = theme.BrightBlack
+	}
+	if theme.CmdText == "" {
+		theme.CmdText = theme.Foreground
+	}
+	if theme.Cursor == "" {
+		theme.Cursor = theme.Foreground
+	}
+	return nil
+}
+
+// MakeThemeKey turns a display name into a termthemes.json key ("Solarized Dark" => "solarized-dark")
+func MakeThemeKey(name string) string {
+	key := themeKeyRe.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(key, "-")
+}
+
+// DetectFormat guesses the format of a theme file from its name and contents
+func DetectFormat(fileName string, data []byte) (string, error) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	switch ext {
+	case ".itermcolors", ".plist":
+		return Format_ITerm2, nil
+	case ".json":
+		return Format_WindowsTerminal, nil
+	case ".toml":
+		return Format_Alacritty, nil
+	case ".yml", ".yaml":
+		if isBase16Yaml(data) {
+			return Format_Base16, nil
+		}
+		return Format_AlacrittyYaml, nil
+	}
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(trimmed, "<?xml") || strings.HasPrefix(trimmed, "<plist"):
+		return Format_ITerm2, nil
+	case strings.HasPrefix(trimmed, "{"):
+		return Format_WindowsTerminal, nil
+	case strings.Contains(trimmed, "[colors"):
+		return Format_Alacritty, nil
+	case isBase16Yaml(data):
+		return Format_Base16, nil
+	}
+	return "", fmt.Errorf("cannot detect theme format of %q, use one of: %s", fileName, strings.Join(AllFormats, ", "))
+}
+
+// Parse reads all themes from data.  defaultName is used for formats that don't carry a scheme name.
+// Returned themes are normalized.
+func Parse(format string, data []byte, defaultName string) ([]NamedTheme, error) {
+	var themes []NamedTheme
+	var err error
+	switch format {
+	case Format_ITerm2:
+		themes, err = parseITerm2(data, defaultName)
+	case Format_WindowsTerminal:
+		themes, err = parseWindowsTerminal(data, defaultName)
+	case Format_Base16:
+		themes, err = parseBase16(data, defaultName)
+	case Format_Alacritty:
+		themes, err = parseAlacrittyToml(data, defaultName)
+	case Format_AlacrittyYaml:
+		themes, err = parseAlacrittyYaml(data, defaultName)
+	default:
+		return nil, fmt.Errorf("unknown theme format %q, use one of: %s", format, strings.Join(AllFormats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(themes) == 0 {
+		return nil, fmt.Errorf("no color scheme found")
+	}
+	for idx := range themes {
+		err = Normalize(&themes[idx].Theme)
+		if err != nil {
+			return nil, fmt.Errorf("theme %q: %w", themes[idx].Name, err)
+		}
+		if themes[idx].Theme.DisplayName == "" {
+			themes[idx].Theme.DisplayName = themes[idx].Name
+		}
+	}
+	return themes, nil
+}
+
+// Format writes theme in the given format.  base16 only has 16 slots, so that export is lossy.
+func Format(format string, name string, theme wconfig.TermThemeType) ([]byte, error) {
+	if err := Normalize(&theme); err != nil {
+		return nil, err
+	}
+	switch format {
+	case Format_ITerm2:
+		return formatITerm2(theme)
+	case Format_WindowsTerminal:
+		return formatWindowsTerminal(name, theme)
+	case Format_Base16:
+		return formatBase16(name, theme)
+	case Format_Alacritty:
+		return formatAlacrittyToml(theme), nil
+	case Format_AlacrittyYaml:
+		return formatAlacrittyYaml(theme)
+	}
+	return nil, fmt.Errorf("unknown theme format %q, use one of: %s", format, strings.Join(AllFormats, ", "))
+}
+
+// rgb hex without the alpha component, for formats that have no alpha
+func stripAlpha(color string) string {
+	if len(color) == 9 {
+		return color[:7]
+	}
+	return color
+}
@@ -0,0 +1,143 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package termtheme
+
+import (
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+)
+
+func TestNormalizeColor(t *testing.T) {
+	tests := map[string]string{
+		"#ABC":      "#aabbcc",
+		"#1D1F21":   "#1d1f21",
+		"0x1d1f21":  "#1d1f21",
+		"1d1f21":    "#1d1f21",
+		"'#1d1f21'": "#1d1f21",
+		"#1d1f2180": "#1d1f2180",
+		"":          "",
+	}
+	for input, expected := range tests {
+		got, err := NormalizeColor(input)
+		if err != nil {
+			t.Errorf("NormalizeColor(%q) unexpected error: %v", input, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("NormalizeColor(%q) = %q, want %q", input, got, expected)
+		}
+	}
+	for _, input := range []string{"#12", "red", "#1234567"} {
+		if _, err := NormalizeColor(input); err == nil {
+			t.Errorf("NormalizeColor(%q) expected error", input)
+		}
+	}
+}
+
+const testBase16 = `scheme: "Test Scheme"
+author: "someone"
+base00: 000000
+base01: "111111"
+base02: "222222"
+base03: "333333"
+base04: "444444"
+base05: "555555"
+base06: "666666"
+base07: "777777"
+base08: "888888"
+base09: "999999"
+base0A: "aaaaaa"
+base0B: "bbbbbb"
+base0C: "cccccc"
+base0D: "dddddd"
+base0E: "eeeeee"
+base0F: "ffffff"
+`
+
+func TestParseBase16(t *testing.T) {
+	format, err := DetectFormat("test.yaml", []byte(testBase16))
+	if err != nil || format != Format_Base16 {
+		t.Fatalf("DetectFormat = %q, %v, want base16", format, err)
+	}
+	themes, err := Parse(format, []byte(testBase16), "fallback")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	theme := themes[0].Theme
+	if themes[0].Name != "Test Scheme" || theme.DisplayName != "Test Scheme" {
+		t.Errorf("unexpected name %q / %q", themes[0].Name, theme.DisplayName)
+	}
+	if theme.Background != "#000000" || theme.Red != "#888888" || theme.BrightWhite != "#777777" {
+		t.Errorf("unexpected colors: bg=%s red=%s brightWhite=%s", theme.Background, theme.Red, theme.BrightWhite)
+	}
+	if theme.Gray != "#444444" || theme.CmdText != "#555555" {
+		t.Errorf("unexpected filled fields: gray=%s cmdtext=%s", theme.Gray, theme.CmdText)
+	}
+}
+
+const testAlacrittyToml = `[window]
+opacity = 0.9
+
+[colors.primary]
+background = "#1d1f21" # comment
+foreground = '0xc5c8c6'
+
+[colors.cursor]
+cursor = "CellForeground"
+
+[colors.normal]
+black = "#282a2e"
+red = "#a54242"
+green = "#8c9440"
+yellow = "#de935f"
+blue = "#5f819d"
+magenta = "#85678f"
+cyan = "#5e8d87"
+white = "#707880"
+`
+
+func TestParseAlacrittyToml(t *testing.T) {
+	themes, err := Parse(Format_Alacritty, []byte(testAlacrittyToml), "tomorrow")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	theme := themes[0].Theme
+	if theme.Background != "#1d1f21" || theme.Foreground != "#c5c8c6" {
+		t.Errorf("unexpected primary colors: %s %s", theme.Background, theme.Foreground)
+	}
+	// missing bright colors fall back to normal ones, "CellForeground" cursor falls back to the foreground
+	if theme.BrightRed != "#a54242" || theme.Cursor != "#c5c8c6" {
+		t.Errorf("unexpected fallbacks: brightRed=%s cursor=%s", theme.BrightRed, theme.Cursor)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	orig := wconfig.TermThemeType{
+		Black: "#000000", Red: "#aa0000", Green: "#00aa00", Yellow: "#aaaa00",
+		Blue: "#0000aa", Magenta: "#aa00aa", Cyan: "#00aaaa", White: "#aaaaaa",
+		BrightBlack: "#555555", BrightRed: "#ff5555", BrightGreen: "#55ff55", BrightYellow: "#ffff55",
+		BrightBlue: "#5555ff", BrightMagenta: "#ff55ff", BrightCyan: "#55ffff", BrightWhite: "#ffffff",
+		Foreground: "#dddddd", Background: "#111111", Cursor: "#eeeeee", SelectionBackground: "#333333",
+	}
+	for _, format := range []string{Format_ITerm2, Format_WindowsTerminal, Format_Alacritty, Format_AlacrittyYaml} {
+		out, err := Format(format, "Round Trip", orig)
+		if err != nil {
+			t.Fatalf("%s: Format error: %v", format, err)
+		}
+		themes, err := Parse(format, out, "Round Trip")
+		if err != nil {
+			t.Fatalf("%s: Parse error: %v\n%s", format, err, out)
+		}
+		got := themes[0].Theme
+		expected := orig
+		if err := Normalize(&expected); err != nil {
+			t.Fatal(err)
+		}
+		got.DisplayName = ""
+		if got != expected {
+			t.Errorf("%s: round trip mismatch\ngot  %+v\nwant %+v", format, got, expected)
+		}
+	}
+}
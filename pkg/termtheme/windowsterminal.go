@@ -0,0 +1,122 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package termtheme
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+)
+
+// a Windows Terminal color scheme, as found in the "schemes" array of its settings.json
+type wtScheme struct {
+	Name                string `json:"name"`
+	Background          string `json:"background"`
+	Foreground          string `json:"foreground"`
+	CursorColor         string `json:"cursorColor,omitempty"`
+	SelectionBackground string `json:"selectionBackground,omitempty"`
+	Black               string `json:"black"`
+	Red                 string `json:"red"`
+	Green               string `json:"green"`
+	Yellow              string `json:"yellow"`
+	Blue                string `json:"blue"`
+	Purple              string `json:"purple"`
+	Cyan                string `json:"cyan"`
+	White               string `json:"white"`
+	BrightBlack         string `json:"brightBlack"`
+	BrightRed           string `json:"brightRed"`
+	BrightGreen         string `json:"brightGreen"`
+	BrightYellow        string `json:"brightYellow"`
+	BrightBlue          string `json:"brightBlue"`
+	BrightPurple        string `json:"brightPurple"`
+	BrightCyan          string `json:"brightCyan"`
+	BrightWhite         string `json:"brightWhite"`
+}
+
+func (s wtScheme) toTheme() wconfig.TermThemeType {
+	return wconfig.TermThemeType{
+		Black:               s.Black,
+		Red:                 s.Red,
+		Green:               s.Green,
+		Yellow:              s.Yellow,
+		Blue:                s.Blue,
+		Magenta:             s.Purple,
+		Cyan:                s.Cyan,
+		White:               s.White,
+		BrightBlack:         s.BrightBlack,
+		BrightRed:           s.BrightRed,
+		BrightGreen:         s.BrightGreen,
+		BrightYellow:        s.BrightYellow,
+		BrightBlue:          s.BrightBlue,
+		BrightMagenta:       s.BrightPurple,
+		BrightCyan:          s.BrightCyan,
+		BrightWhite:         s.BrightWhite,
+		Foreground:          s.Foreground,
+		Background:          s.Background,
+		Cursor:              s.CursorColor,
+		SelectionBackground: s.SelectionBackground,
+	}
+}
+
+// accepts a single scheme object, an array of schemes, or a full settings.json with a "schemes" array
+func parseWindowsTerminal(data []byte, defaultName string) ([]NamedTheme, error) {
+	var schemes []wtScheme
+	if err := json.Unmarshal(data, &schemes); err != nil {
+		var wrapper struct {
+			Schemes []wtScheme `json:"schemes"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, fmt.Errorf("parsing windows terminal scheme: %w", err)
+		}
+		schemes = wrapper.Schemes
+		if len(schemes) == 0 {
+			var single wtScheme
+			if err := json.Unmarshal(data, &single); err != nil {
+				return nil, fmt.Errorf("parsing windows terminal scheme: %w", err)
+			}
+			schemes = []wtScheme{single}
+		}
+	}
+	var rtn []NamedTheme
+	for _, scheme := range schemes {
+		name := scheme.Name
+		if name == "" {
+			name = defaultName
+		}
+		rtn = append(rtn, NamedTheme{Name: name, Theme: scheme.toTheme()})
+	}
+	return rtn, nil
+}
+
+func formatWindowsTerminal(name string, theme wconfig.TermThemeType) ([]byte, error) {
+	scheme := wtScheme{
+		Name:                name,
+		Background:          stripAlpha(theme.Background),
+		Foreground:          stripAlpha(theme.Foreground),
+		CursorColor:         stripAlpha(theme.Cursor),
+		SelectionBackground: stripAlpha(theme.SelectionBackground),
+		Black:               theme.Black,
+		Red:                 theme.Red,
+		Green:               theme.Green,
+		Yellow:              theme.Yellow,
+		Blue:                theme.Blue,
+		Purple:              theme.Magenta,
+		Cyan:                theme.Cyan,
+		White:               theme.White,
+		BrightBlack:         theme.BrightBlack,
+		BrightRed:           theme.BrightRed,
+		BrightGreen:         theme.BrightGreen,
+		BrightYellow:        theme.BrightYellow,
+		BrightBlue:          theme.BrightBlue,
+		BrightPurple:        theme.BrightMagenta,
+		BrightCyan:          theme.BrightCyan,
+		BrightWhite:         theme.BrightWhite,
+	}
+	barr, err := json.MarshalIndent(scheme, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(barr, '\n'), nil
+}
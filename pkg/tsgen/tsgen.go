@@ -54,6 +54,8 @@ var ExtraTypes = []any{
 	wconfig.AIModeConfigUpdate{},
 	wshrpc.TabIndicatorEventData{},
 	wshrpc.BlockJobStatusData{},
+	wshrpc.PetReactionEventData{},
+	wshrpc.ShareStatusEventData{},
 }
 
 // add extra type unions to generate here
@@ -0,0 +1,93 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package userinput
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+)
+
+type cliRouteContextKey struct{}
+
+// ContextWithCliRoute marks ctx as coming from a wsh client (routeId) that can answer prompts on its tty
+func ContextWithCliRoute(ctx context.Context, routeId string) context.Context {
+	if routeId == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, cliRouteContextKey{}, routeId)
+}
+
+func GetCliRoute(ctx context.Context) string {
+	routeId, _ := ctx.Value(cliRouteContextKey{}).(string)
+	return routeId
+}
+
+// CliProvider sends the prompt to the wsh client that started the operation (e.g. `wsh ssh`),
+// which asks the question on its own tty.
+type CliProvider struct{}
+
+func (p *CliProvider) GetUserInput(ctx context.Context, request *UserInputRequest) (*UserInputResponse, error) {
+	routeId := GetCliRoute(ctx)
+	if routeId == "" {
+		return nil, fmt.Errorf("no terminal was offered for prompts: %w", ErrProviderUnavailable)
+	}
+	if request.RequestId == "" {
+		request.RequestId = uuid.New().String()
+	}
+	timeout := utilfn.TimeoutFromContext(ctx, 30*time.Second)
+	request.TimeoutMs = int(timeout.Milliseconds())
+	data := wshrpc.CommandCliUserInputData{
+		QueryText:    request.QueryText,
+		ResponseType: request.ResponseType,
+		Title:        request.Title,
+		Markdown:     request.Markdown,
+		TimeoutMs:    request.TimeoutMs,
+		CheckBoxMsg:  request.CheckBoxMsg,
+		PublicText:   request.PublicText,
+		OkLabel:      request.OkLabel,
+		CancelLabel:  request.CancelLabel,
+	}
+	rtn, err := wshclient.CliUserInputCommand(wshclient.GetBareRpcClient(), data, &wshrpc.RpcOpts{Route: routeId, Timeout: int64(request.TimeoutMs)})
+	if err != nil {
+		return nil, fmt.Errorf("terminal prompt failed: %w", err)
+	}
+	return &UserInputResponse{
+		Type:         request.ResponseType,
+		RequestId:    request.RequestId,
+		Text:         rtn.Text,
+		Confirm:      rtn.Confirm,
+		CheckboxStat: rtn.CheckboxStat,
+	}, nil
+}
+
+// ChainProvider tries each provider in order, moving on when one returns ErrProviderUnavailable.
+// Any other error (e.g. the user cancelled or the prompt timed out) is returned as is.
+type ChainProvider struct {
+	Providers []UserInputProvider
+}
+
+func (p *ChainProvider) GetUserInput(ctx context.Context, request *UserInputRequest) (*UserInputResponse, error) {
+	var reasons []string
+	for _, provider := range p.Providers {
+		resp, err := provider.GetUserInput(ctx, request)
+		if errors.Is(err, ErrProviderUnavailable) {
+			reasons = append(reasons, strings.TrimSuffix(err.Error(), ": "+ErrProviderUnavailable.Error()))
+			continue
+		}
+		return resp, err
+	}
+	title := request.Title
+	if title == "" {
+		title = "user input"
+	}
+	return nil, fmt.Errorf("cannot ask for %s, no window or terminal is available to answer (%s)", title, strings.Join(reasons, "; "))
+}
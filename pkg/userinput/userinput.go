@@ -21,7 +21,11 @@ import (
 
 var MainUserInputHandler = UserInputHandler{Channels: make(map[string](chan *UserInputResponse), 1)}
 
-var defaultProvider UserInputProvider = &FrontendProvider{}
+// prompts go to a frontend window if one is listening, then to the requesting wsh client's tty (see CliProvider)
+var defaultProvider UserInputProvider = &ChainProvider{Providers: []UserInputProvider{&FrontendProvider{}, &CliProvider{}}}
+
+// returned by providers that have no way to reach the user, so the next provider in a ChainProvider is tried
+var ErrProviderUnavailable = errors.New("user input provider unavailable")
 
 type UserInputProvider interface {
 	GetUserInput(ctx context.Context, request *UserInputRequest) (*UserInputResponse, error)
@@ -121,6 +125,9 @@ func (p *FrontendProvider) GetUserInput(ctx context.Context, request *UserInputR
 		}
 		scopes = allWindows
 	}
+	if !wps.Broker.HasSubscribers(wps.WaveEvent{Event: wps.Event_UserInput, Scopes: scopes}) {
+		return nil, fmt.Errorf("no frontend window is listening: %w", ErrProviderUnavailable)
+	}
 
 	MainUserInputHandler.sendRequestToFrontend(request, scopes)
 
@@ -0,0 +1,95 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// fuzzy matching on top of fzf's matcher.  a query is split on spaces and every term must match
+// (fzf's extended search without the special operators), matching is smart-case.
+package fuzzyutil
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/junegunn/fzf/src/algo"
+	"github.com/junegunn/fzf/src/util"
+)
+
+func init() {
+	// builds the character class and bonus tables, without them matching is case sensitive and
+	// word boundaries get no bonus
+	algo.Init("default")
+}
+
+type term struct {
+	runes         []rune
+	caseSensitive bool
+}
+
+type Pattern struct {
+	terms []term
+}
+
+type Matcher struct {
+	pattern Pattern
+	slab    *util.Slab
+}
+
+func ParsePattern(query string) Pattern {
+	var rtn Pattern
+	for _, field := range strings.Fields(query) {
+		caseSensitive := strings.IndexFunc(field, unicode.IsUpper) >= 0
+		runes := []rune(field)
+		if !caseSensitive {
+			runes = []rune(strings.ToLower(field))
+		}
+		runes = algo.NormalizeRunes(runes)
+		rtn.terms = append(rtn.terms, term{runes: runes, caseSensitive: caseSensitive})
+	}
+	return rtn
+}
+
+func (p Pattern) IsEmpty() bool {
+	return len(p.terms) == 0
+}
+
+// a Matcher reuses its scratch space, it is not safe for concurrent use
+func MakeMatcher(query string) *Matcher {
+	return &Matcher{pattern: ParsePattern(query), slab: util.MakeSlab(100*1024, 2048)}
+}
+
+// returns the total score and the (sorted, rune index) positions of the matched characters.
+// an empty pattern matches everything with a score of 0.
+func (m *Matcher) Match(text string) (int, []int, bool) {
+	if m.pattern.IsEmpty() {
+		return 0, nil, true
+	}
+	chars := util.ToChars([]byte(text))
+	var score int
+	var positions []int
+	for _, t := range m.pattern.terms {
+		result, posPtr := algo.FuzzyMatchV2(t.caseSensitive, true, true, &chars, t.runes, true, m.slab)
+		if result.Start < 0 || result.Score <= 0 {
+			return 0, nil, false
+		}
+		score += result.Score
+		if posPtr != nil {
+			positions = append(positions, *posPtr...)
+		}
+	}
+	sort.Ints(positions)
+	positions = dedupSorted(positions)
+	return score, positions, true
+}
+
+func dedupSorted(arr []int) []int {
+	if len(arr) < 2 {
+		return arr
+	}
+	rtn := arr[:1]
+	for _, v := range arr[1:] {
+		if v != rtn[len(rtn)-1] {
+			rtn = append(rtn, v)
+		}
+	}
+	return rtn
+}
@@ -0,0 +1,45 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package fuzzyutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query     string
+		text      string
+		match     bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"gco", "git checkout main", true, []int{0, 4, 9}},
+		{"git main", "git checkout main", true, []int{0, 1, 2, 13, 14, 15, 16}},
+		{"git push", "git checkout main", false, nil},
+		{"Main", "git checkout main", false, nil},
+		{"main", "git checkout MAIN", true, []int{13, 14, 15, 16}},
+		{"ä", "echo ä", true, []int{5}},
+	}
+	for _, test := range tests {
+		_, positions, ok := MakeMatcher(test.query).Match(test.text)
+		if ok != test.match {
+			t.Errorf("%q ~ %q: match=%v, want %v", test.query, test.text, ok, test.match)
+			continue
+		}
+		if ok && !reflect.DeepEqual(positions, test.positions) {
+			t.Errorf("%q ~ %q: positions=%v, want %v", test.query, test.text, positions, test.positions)
+		}
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	m := MakeMatcher("make")
+	exact, _, _ := m.Match("make build")
+	scattered, _, _ := m.Match("mv a/k/e.txt")
+	if exact <= scattered {
+		t.Errorf("contiguous match should score higher: %d <= %d", exact, scattered)
+	}
+}
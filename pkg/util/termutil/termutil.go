@@ -0,0 +1,151 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// helpers for matching against terminal output (pty bytes with escape sequences)
+package termutil
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+const MaxLineLen = 4096
+
+// reassembles terminal output into lines
+type LineSplitter struct {
+	buf []byte
+}
+
+// adds data and returns the lines it completed (cleaned, see CleanOutputLine).  overlong lines are
+// cut at MaxLineLen.
+func (ls *LineSplitter) Write(data []byte) []string {
+	var lines []string
+	for len(data) > 0 {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			ls.buf = append(ls.buf, data...)
+			break
+		}
+		ls.buf = append(ls.buf, data[:idx]...)
+		lines = append(lines, CleanOutputLine(ls.buf))
+		ls.buf = ls.buf[:0]
+		data = data[idx+1:]
+	}
+	if len(ls.buf) > MaxLineLen {
+		lines = append(lines, CleanOutputLine(ls.buf))
+		ls.buf = ls.buf[:0]
+	}
+	return lines
+}
+
+// the current unterminated line (cleaned)
+func (ls *LineSplitter) Partial() string {
+	if len(ls.buf) == 0 {
+		return ""
+	}
+	return CleanOutputLine(ls.buf)
+}
+
+// removes escape sequences and control characters (applying backspaces), and keeps only the text
+// after the last carriage return (what is left visible when a line redraws itself)
+func CleanOutputLine(raw []byte) string {
+	rtn := make([]byte, 0, len(raw))
+	for idx := 0; idx < len(raw); idx++ {
+		ch := raw[idx]
+		switch {
+		case ch == 0x1b:
+			idx = skipEscapeSeq(raw, idx)
+		case ch == '\b':
+			if len(rtn) > 0 {
+				rtn = rtn[:len(rtn)-1]
+			}
+		case ch == '\t' || ch == '\r':
+			rtn = append(rtn, ch)
+		case ch < 0x20 || ch == 0x7f:
+			// other control characters
+		default:
+			rtn = append(rtn, ch)
+		}
+	}
+	line := strings.TrimRight(string(rtn), "\r")
+	if idx := strings.LastIndexByte(line, '\r'); idx >= 0 {
+		line = line[idx+1:]
+	}
+	return strings.ToValidUTF8(line, "")
+}
+
+// returns the index of the last byte of the escape sequence that starts at data[start] (an ESC).
+// an unterminated sequence runs to the end of data.
+func skipEscapeSeq(data []byte, start int) int {
+	if start+1 >= len(data) {
+		return len(data) - 1
+	}
+	switch data[start+1] {
+	case '[':
+		// CSI: parameter and intermediate bytes, then a final byte in 0x40-0x7e
+		for idx := start + 2; idx < len(data); idx++ {
+			if data[idx] >= 0x40 && data[idx] <= 0x7e {
+				return idx
+			}
+		}
+		return len(data) - 1
+	case ']', 'P', '_', '^', 'X':
+		// OSC, DCS, APC, PM, SOS: terminated by BEL or ST (ESC \)
+		for idx := start + 2; idx < len(data); idx++ {
+			if data[idx] == 0x07 {
+				return idx
+			}
+			if data[idx] == 0x1b && idx+1 < len(data) && data[idx+1] == '\\' {
+				return idx + 1
+			}
+		}
+		return len(data) - 1
+	case '(', ')', '*', '+', '#', '%':
+		// charset designation and friends take one more byte
+		return min(start+2, len(data)-1)
+	}
+	return start + 1
+}
+
+// matches regexps against a stream of terminal output, expect-style: each match consumes the output
+// up to the end of the match, so the next regexp only sees what comes after it.  output is matched
+// line by line (completed lines first, then the current partial line).
+type OutputMatcher struct {
+	lines   LineSplitter
+	pending []string // completed lines that have not been consumed
+	skip    int      // consumed bytes at the start of pending[0] (or of the partial line if there are no pending lines)
+}
+
+func (m *OutputMatcher) Write(data []byte) {
+	m.pending = append(m.pending, m.lines.Write(data)...)
+}
+
+// returns the match and its submatches (nil if there is no match yet)
+func (m *OutputMatcher) Match(re *regexp.Regexp) []string {
+	for len(m.pending) > 0 {
+		if rtn := m.matchLine(re, m.pending[0]); rtn != nil {
+			return rtn
+		}
+		m.pending = m.pending[1:]
+		m.skip = 0
+	}
+	return m.matchLine(re, m.lines.Partial())
+}
+
+func (m *OutputMatcher) matchLine(re *regexp.Regexp, line string) []string {
+	skip := min(m.skip, len(line))
+	rest := line[skip:]
+	loc := re.FindStringSubmatchIndex(rest)
+	if loc == nil {
+		return nil
+	}
+	rtn := make([]string, len(loc)/2)
+	for idx := range rtn {
+		if loc[2*idx] >= 0 {
+			rtn[idx] = rest[loc[2*idx]:loc[2*idx+1]]
+		}
+	}
+	m.skip = skip + loc[1]
+	return rtn
+}
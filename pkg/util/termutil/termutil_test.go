@@ -0,0 +1,74 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package termutil
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLineSplitter(t *testing.T) {
+	var ls LineSplitter
+	lines := ls.Write([]byte("\x1b[1;31merr"))
+	if len(lines) != 0 || ls.Partial() != "err" {
+		t.Errorf("got lines %q, partial %q", lines, ls.Partial())
+	}
+	// escape sequence split across chunks, \r\n line endings, OSC title
+	lines = ls.Write([]byte("or\x1b[0"))
+	lines = append(lines, ls.Write([]byte("m: bad\r\n\x1b]0;title\x07ok\r\nContinue? [y/N] "))...)
+	want := []string{"error: bad", "ok"}
+	if len(lines) != len(want) || lines[0] != want[0] || lines[1] != want[1] {
+		t.Errorf("got lines %q, want %q", lines, want)
+	}
+	if ls.Partial() != "Continue? [y/N] " {
+		t.Errorf("got partial %q", ls.Partial())
+	}
+	// progress bars redraw with \r, backspaces erase
+	ls = LineSplitter{}
+	lines = ls.Write([]byte("10%\r50%\r100% done\nab\bc\n"))
+	if len(lines) != 2 || lines[0] != "100% done" || lines[1] != "ac" {
+		t.Errorf("got lines %q", lines)
+	}
+	// an unterminated line is cut once it is longer than MaxLineLen
+	ls = LineSplitter{}
+	long := strings.Repeat("x", MaxLineLen)
+	if lines = ls.Write([]byte(long)); len(lines) != 0 {
+		t.Errorf("line of MaxLineLen was cut early")
+	}
+	lines = ls.Write([]byte("yz"))
+	if len(lines) != 1 || lines[0] != long+"yz" || ls.Partial() != "" {
+		t.Errorf("overlong line not cut, got %d lines, partial %q", len(lines), ls.Partial())
+	}
+}
+
+func TestOutputMatcher(t *testing.T) {
+	var m OutputMatcher
+	prompt := regexp.MustCompile(`>>> $`)
+	if m.Match(prompt) != nil {
+		t.Fatalf("matched empty output")
+	}
+	m.Write([]byte("Python 3.12\r\n>>> "))
+	if m.Match(prompt) == nil {
+		t.Fatalf("expected prompt on the partial line")
+	}
+	// the prompt was consumed, it must not match again until a new one is printed
+	if m.Match(prompt) != nil {
+		t.Errorf("prompt matched twice")
+	}
+	m.Write([]byte("print(6*7)\r\n42\r\n>>> "))
+	if match := m.Match(regexp.MustCompile(`^(\d+)$`)); match == nil || match[1] != "42" {
+		t.Errorf("got %q, want 42", match)
+	}
+	if m.Match(prompt) == nil {
+		t.Errorf("expected second prompt")
+	}
+	// several matches within one line
+	m.Write([]byte("a=1 b=2\n"))
+	for _, want := range []string{"1", "2"} {
+		if match := m.Match(regexp.MustCompile(`=(\d)`)); match == nil || match[1] != want {
+			t.Errorf("got %q, want %s", match, want)
+		}
+	}
+}
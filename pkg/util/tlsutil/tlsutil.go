@@ -0,0 +1,196 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// tlsutil has the certificate handling shared by `wsh connserver --listen` and the tls:// connections in wavesrv.
+// both sides use self-signed certificates and authenticate each other by pinned sha256 fingerprints
+// (like ssh host keys), so there is no CA to manage.
+package tlsutil
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const FingerprintPrefix = "SHA256:"
+const CertValidity = 20 * 365 * 24 * time.Hour
+
+// the first line the dialing side (wavesrv) sends after the handshake
+const HelloPrefix = "#wshtls "
+
+type ConnHello struct {
+	Conn string `json:"conn"`
+}
+
+// Fingerprint returns the ssh-style fingerprint ("SHA256:<base64>") of a DER encoded certificate
+func Fingerprint(certDer []byte) string {
+	sum := sha256.Sum256(certDer)
+	return FingerprintPrefix + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// NormalizeFingerprint accepts fingerprints with or without the "SHA256:" prefix
+func NormalizeFingerprint(fp string) string {
+	fp = strings.TrimSpace(fp)
+	if len(fp) >= len(FingerprintPrefix) && strings.EqualFold(fp[:len(FingerprintPrefix)], FingerprintPrefix) {
+		fp = fp[len(FingerprintPrefix):]
+	}
+	return FingerprintPrefix + strings.TrimRight(fp, "=")
+}
+
+func FingerprintMatches(fp string, allowed []string) bool {
+	fp = NormalizeFingerprint(fp)
+	for _, allowedFp := range allowed {
+		if NormalizeFingerprint(allowedFp) == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadOrCreateCert loads the keypair at certPath/keyPath, generating a self-signed ecdsa keypair on first use
+func LoadOrCreateCert(certPath string, keyPath string, commonName string) (tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err == nil {
+		return cert, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return tls.Certificate{}, fmt.Errorf("loading certificate %s: %w", certPath, err)
+	}
+	certPem, keyPem, err := generateCert(commonName)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(certPath), 0700); err != nil {
+		return tls.Certificate{}, fmt.Errorf("creating certificate dir: %w", err)
+	}
+	if err := os.WriteFile(keyPath, keyPem, 0600); err != nil {
+		return tls.Certificate{}, fmt.Errorf("writing key %s: %w", keyPath, err)
+	}
+	if err := os.WriteFile(certPath, certPem, 0644); err != nil {
+		return tls.Certificate{}, fmt.Errorf("writing certificate %s: %w", certPath, err)
+	}
+	return tls.X509KeyPair(certPem, keyPem)
+}
+
+func generateCert(commonName string) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating serial: %w", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(CertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating certificate: %w", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding key: %w", err)
+	}
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return certPem, keyPem, nil
+}
+
+func CertFingerprint(cert tls.Certificate) string {
+	if len(cert.Certificate) == 0 {
+		return ""
+	}
+	return Fingerprint(cert.Certificate[0])
+}
+
+// MakePinnedVerifier returns a VerifyPeerCertificate func that accepts only a leaf certificate whose
+// fingerprint is in allowedFn().  chain verification is skipped (the certificates are self-signed), so
+// the config using it must set InsecureSkipVerify (client) or RequireAnyClientCert (server).
+func MakePinnedVerifier(allowedFn func() []string) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("peer sent no certificate")
+		}
+		fp := Fingerprint(rawCerts[0])
+		if !FingerprintMatches(fp, allowedFn()) {
+			return &UnknownPeerError{Fingerprint: fp}
+		}
+		return nil
+	}
+}
+
+type UnknownPeerError struct {
+	Fingerprint string
+}
+
+func (e *UnknownPeerError) Error() string {
+	return fmt.Sprintf("certificate %s is not pinned", e.Fingerprint)
+}
+
+// ReadFingerprintFile reads one fingerprint per line ("#" starts a comment).  a missing file is empty.
+func ReadFingerprintFile(fileName string) ([]string, error) {
+	fd, err := os.Open(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	var rtn []string
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		rtn = append(rtn, fields[0])
+	}
+	return rtn, scanner.Err()
+}
+
+func MakeHelloLine(connName string) ([]byte, error) {
+	barr, err := json.Marshal(ConnHello{Conn: connName})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(HelloPrefix + string(barr) + "\n"), nil
+}
+
+func ParseHelloLine(line string) (*ConnHello, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, HelloPrefix) {
+		return nil, fmt.Errorf("bad hello line")
+	}
+	var hello ConnHello
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, HelloPrefix)), &hello); err != nil {
+		return nil, fmt.Errorf("bad hello line: %w", err)
+	}
+	return &hello, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package tlsutil
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func makeTestCert(t *testing.T, name string) tls.Certificate {
+	dir := t.TempDir()
+	cert, err := LoadOrCreateCert(filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key"), name)
+	if err != nil {
+		t.Fatalf("creating cert: %v", err)
+	}
+	return cert
+}
+
+func TestLoadOrCreateCert(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "sub", "server.crt")
+	keyPath := filepath.Join(dir, "sub", "server.key")
+	cert1, err := LoadOrCreateCert(certPath, keyPath, "test")
+	if err != nil {
+		t.Fatalf("creating cert: %v", err)
+	}
+	cert2, err := LoadOrCreateCert(certPath, keyPath, "test")
+	if err != nil {
+		t.Fatalf("loading cert: %v", err)
+	}
+	if CertFingerprint(cert1) != CertFingerprint(cert2) {
+		t.Errorf("reloaded cert has a different fingerprint")
+	}
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatalf("stat key: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestFingerprintMatches(t *testing.T) {
+	fp := CertFingerprint(makeTestCert(t, "a"))
+	bare := fp[len(FingerprintPrefix):]
+	if !FingerprintMatches(fp, []string{"SHA256:other", " sha256:" + bare + " "}) {
+		t.Errorf("expected match with lowercase prefix and spaces")
+	}
+	if !FingerprintMatches(fp, []string{bare + "="}) {
+		t.Errorf("expected match without prefix and with padding")
+	}
+	if FingerprintMatches(fp, []string{"SHA256:" + bare[1:]}) {
+		t.Errorf("unexpected match")
+	}
+}
+
+func TestReadFingerprintFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "authorized_clients")
+	fps, err := ReadFingerprintFile(fileName)
+	if err != nil || len(fps) != 0 {
+		t.Fatalf("missing file: got %v, %v", fps, err)
+	}
+	os.WriteFile(fileName, []byte("# laptop\nSHA256:abc laptop\n\n  SHA256:def # desktop\n"), 0600)
+	fps, err = ReadFingerprintFile(fileName)
+	if err != nil {
+		t.Fatalf("reading file: %v", err)
+	}
+	if len(fps) != 2 || fps[0] != "SHA256:abc" || fps[1] != "SHA256:def" {
+		t.Errorf("got %v", fps)
+	}
+}
+
+func TestHelloLine(t *testing.T) {
+	line, err := MakeHelloLine("tls://vm1:7777")
+	if err != nil {
+		t.Fatalf("making hello: %v", err)
+	}
+	hello, err := ParseHelloLine(string(line))
+	if err != nil {
+		t.Fatalf("parsing hello: %v", err)
+	}
+	if hello.Conn != "tls://vm1:7777" {
+		t.Errorf("conn = %q", hello.Conn)
+	}
+	if _, err := ParseHelloLine("wsh v1.0.0"); err == nil {
+		t.Errorf("expected error for non-hello line")
+	}
+}
+
+// runs a handshake over loopback tcp (net.Pipe is unbuffered, which deadlocks tls)
+func runHandshake(t *testing.T, serverCert tls.Certificate, clientCert tls.Certificate, serverPins []string, clientPins []string) (error, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	serverErrCh := make(chan error, 1)
+	go func() {
+		rawConn, err := listener.Accept()
+		if err != nil {
+			serverErrCh <- err
+			return
+		}
+		defer rawConn.Close()
+		server := tls.Server(rawConn, &tls.Config{
+			Certificates:          []tls.Certificate{serverCert},
+			ClientAuth:            tls.RequireAnyClientCert,
+			VerifyPeerCertificate: MakePinnedVerifier(func() []string { return serverPins }),
+			MinVersion:            tls.VersionTLS13,
+		})
+		err = server.Handshake()
+		if err == nil {
+			_, err = server.Write([]byte{1})
+		}
+		serverErrCh <- err
+	}()
+	rawConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer rawConn.Close()
+	rawConn.SetDeadline(time.Now().Add(5 * time.Second))
+	client := tls.Client(rawConn, &tls.Config{
+		Certificates:          []tls.Certificate{clientCert},
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: MakePinnedVerifier(func() []string { return clientPins }),
+		MinVersion:            tls.VersionTLS13,
+	})
+	clientErr := client.Handshake()
+	if clientErr == nil {
+		// with tls 1.3 the client learns about a rejected certificate on its first read
+		_, clientErr = client.Read(make([]byte, 1))
+	}
+	rawConn.Close()
+	return <-serverErrCh, clientErr
+}
+
+func TestPinnedHandshake(t *testing.T) {
+	serverCert := makeTestCert(t, "server")
+	clientCert := makeTestCert(t, "client")
+	otherCert := makeTestCert(t, "other")
+	serverFp := CertFingerprint(serverCert)
+	clientFp := CertFingerprint(clientCert)
+
+	serverErr, _ := runHandshake(t, serverCert, clientCert, []string{clientFp}, []string{serverFp})
+	if serverErr != nil {
+		t.Errorf("pinned handshake failed: %v", serverErr)
+	}
+
+	_, clientErr := runHandshake(t, otherCert, clientCert, []string{clientFp}, []string{serverFp})
+	var unknownErr *UnknownPeerError
+	if !errors.As(clientErr, &unknownErr) || unknownErr.Fingerprint != CertFingerprint(otherCert) {
+		t.Errorf("expected client to reject unpinned server, got %v", clientErr)
+	}
+
+	serverErr, clientErr = runHandshake(t, serverCert, otherCert, []string{clientFp}, []string{serverFp})
+	if !errors.As(serverErr, &unknownErr) {
+		t.Errorf("expected server to reject unpinned client, got %v", serverErr)
+	}
+	if clientErr == nil {
+		t.Errorf("expected client to see the rejection")
+	}
+}
@@ -34,6 +34,8 @@ type WaveJwtClaims struct {
 	JobId      string `json:"jobid,omitempty"`
 	Conn       string `json:"conn,omitempty"`
 	Router     bool   `json:"router,omitempty"`
+
+	Capabilities []string `json:"caps,omitempty"` // nil means unrestricted, see wshrpc.CapabilitiesAllow
 }
 
 type KeyPair struct {
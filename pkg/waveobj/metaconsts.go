@@ -53,6 +53,7 @@ const (
 	MetaKey_CmdShell                         = "cmd:shell"
 	MetaKey_CmdAllowConnChange               = "cmd:allowconnchange"
 	MetaKey_CmdJwt                           = "cmd:jwt"
+	MetaKey_CmdCapabilities                  = "cmd:capabilities"
 	MetaKey_CmdEnv                           = "cmd:env"
 	MetaKey_CmdCwd                           = "cmd:cwd"
 	MetaKey_CmdInitScript                    = "cmd:initscript"
@@ -120,6 +121,14 @@ const (
 	MetaKey_TermBellSound                    = "term:bellsound"
 	MetaKey_TermBellIndicator                = "term:bellindicator"
 	MetaKey_TermDurable                      = "term:durable"
+	MetaKey_TermInputGroup                   = "term:inputgroup"
+	MetaKey_TermInputGroupDisabled           = "term:inputgroupdisabled"
+	MetaKey_TermTriggers                     = "term:triggers"
+	MetaKey_TermLog                          = "term:log"
+	MetaKey_TermLogFile                      = "term:logfile"
+	MetaKey_TermLogStripAnsi                 = "term:logstripansi"
+	MetaKey_TermLogTimestamps                = "term:logtimestamps"
+	MetaKey_TermCmdNotify                    = "term:cmdnotify"
 
 	MetaKey_WebZoom                          = "web:zoom"
 	MetaKey_WebHideNav                       = "web:hidenav"
@@ -142,6 +151,8 @@ const (
 	MetaKey_VDomRoute                        = "vdom:route"
 	MetaKey_VDomPersist                      = "vdom:persist"
 
+	MetaKey_WorkspaceSettings                = "workspace:settings"
+
 	MetaKey_OnboardingGithubStar             = "onboarding:githubstar"
 	MetaKey_OnboardingLastVersion            = "onboarding:lastversion"
 
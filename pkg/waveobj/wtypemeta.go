@@ -51,7 +51,8 @@ type MetaTSType struct {
 	CmdArgs             []string `json:"cmd:args,omitempty"`  // args for cmd (only if cmd:shell is false)
 	CmdShell            bool     `json:"cmd:shell,omitempty"` // shell expansion for cmd+args (defaults to true)
 	CmdAllowConnChange  bool     `json:"cmd:allowconnchange,omitempty"`
-	CmdJwt              bool     `json:"cmd:jwt,omitempty"` // force adding JWT to environment
+	CmdJwt              bool     `json:"cmd:jwt,omitempty"`          // force adding JWT to environment
+	CmdCapabilities     []string `json:"cmd:capabilities,omitempty"` // wsh capabilities for this block (see wshrpc.CapabilitiesAllow)
 
 	// these can be nested under "[conn]"
 	CmdEnv            map[string]string `json:"cmd:env,omitempty"`
@@ -124,6 +125,14 @@ type MetaTSType struct {
 	TermBellSound           *bool    `json:"term:bellsound,omitempty"`
 	TermBellIndicator       *bool    `json:"term:bellindicator,omitempty"`
 	TermDurable             *bool    `json:"term:durable,omitempty"`
+	TermInputGroup          string   `json:"term:inputgroup,omitempty"`         // input typed into one member is sent to all members
+	TermInputGroupDisabled  *bool    `json:"term:inputgroupdisabled,omitempty"` // member stays in the group but is skipped
+	TermTriggers            []string `json:"term:triggers,omitempty"`           // output triggers to enable (opt-in) or disable ("-name")
+	TermLog                 *bool    `json:"term:log,omitempty"`                // tee the block's output to a log file on disk
+	TermLogFile             string   `json:"term:logfile,omitempty"`            // defaults to <term:logdir>/<blockid>.log, must be in term:logdir
+	TermLogStripAnsi        *bool    `json:"term:logstripansi,omitempty"`       // default true
+	TermLogTimestamps       *bool    `json:"term:logtimestamps,omitempty"`      // default true
+	TermCmdNotify           *bool    `json:"term:cmdnotify,omitempty"`          // notify when a long-running command finishes while unfocused
 
 	WebZoom          float64 `json:"web:zoom,omitempty"`
 	WebHideNav       *bool   `json:"web:hidenav,omitempty"`
@@ -146,6 +155,9 @@ type MetaTSType struct {
 	VDomRoute         string `json:"vdom:route,omitempty"`
 	VDomPersist       bool   `json:"vdom:persist,omitempty"`
 
+	// for workspaces (settings overrides, layered between settings.json and connections.json)
+	WorkspaceSettings map[string]any `json:"workspace:settings,omitempty"`
+
 	OnboardingGithubStar  bool   `json:"onboarding:githubstar,omitempty"`  // for client
 	OnboardingLastVersion string `json:"onboarding:lastversion,omitempty"` // for client (tracks semver of last 'onboarding' shown)
 
@@ -0,0 +1,283 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wconfig
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/filebackup"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+)
+
+const (
+	ConfigHistorySource_Startup  = "startup"
+	ConfigHistorySource_Write    = "write"
+	ConfigHistorySource_External = "external"
+	ConfigHistorySource_Rollback = "rollback"
+)
+
+const DefaultConfigHistoryMaxVersions = 50
+const DefaultConfigHistoryMaxDays = 30
+
+// editors save in several steps (truncate + write, write + rename), external edits are only recorded
+// once the file stopped changing for this long
+const externalEditDebounce = 500 * time.Millisecond
+
+// config files (relative to the config dir) that get versioned snapshots
+var ConfigHistoryFiles = []string{SettingsFile, ConnectionsFile}
+
+type ConfigHistoryEntry struct {
+	File      string `json:"file"`
+	Version   int    `json:"version"`
+	Timestamp string `json:"timestamp"`
+	Source    string `json:"source"`
+	Size      int    `json:"size"`
+}
+
+type ConfigDiffEntry struct {
+	Key      string `json:"key"`
+	OldValue any    `json:"oldvalue,omitempty"`
+	NewValue any    `json:"newvalue,omitempty"`
+}
+
+func isConfigHistoryFile(fileName string) bool {
+	for _, f := range ConfigHistoryFiles {
+		if f == fileName {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeConfigHistoryFile accepts "settings", "connections" or the full file name
+func NormalizeConfigHistoryFile(fileName string) (string, error) {
+	if fileName == "" {
+		return SettingsFile, nil
+	}
+	if filepath.Ext(fileName) == "" {
+		fileName = fileName + ".json"
+	}
+	if !isConfigHistoryFile(fileName) {
+		return "", fmt.Errorf("no history is kept for %q (valid files: %v)", fileName, ConfigHistoryFiles)
+	}
+	return fileName, nil
+}
+
+func getConfigHistoryLimits(settings SettingsType) (int, time.Duration) {
+	maxVersions := DefaultConfigHistoryMaxVersions
+	maxDays := DefaultConfigHistoryMaxDays
+	if settings.ConfigHistoryMaxVersions != nil {
+		maxVersions = int(*settings.ConfigHistoryMaxVersions)
+	}
+	if settings.ConfigHistoryMaxDays != nil {
+		maxDays = int(*settings.ConfigHistoryMaxDays)
+	}
+	return maxVersions, time.Duration(maxDays) * 24 * time.Hour
+}
+
+var externalEditLock sync.Mutex
+var selfWriteHashes = make(map[string][sha256.Size]byte) // contents of our last write of each file
+var externalEditTimers = make(map[string]*time.Timer)
+
+// remembers what we're about to write, so the watcher doesn't record it as an external edit
+func noteSelfWrite(fileName string, barr []byte) {
+	externalEditLock.Lock()
+	defer externalEditLock.Unlock()
+	selfWriteHashes[fileName] = sha256.Sum256(barr)
+}
+
+// half-written files (not valid json yet) and our own writes are not external edits
+func isExternalEdit(fileName string, barr []byte) bool {
+	if len(barr) == 0 || !json.Valid(barr) {
+		return false
+	}
+	externalEditLock.Lock()
+	defer externalEditLock.Unlock()
+	hash, ok := selfWriteHashes[fileName]
+	return !ok || hash != sha256.Sum256(barr)
+}
+
+// called by the watcher when a config file changes, records it (as an external edit) after the debounce
+func scheduleExternalEditRecord(fileName string) {
+	if !isConfigHistoryFile(fileName) {
+		return
+	}
+	externalEditLock.Lock()
+	defer externalEditLock.Unlock()
+	if timer := externalEditTimers[fileName]; timer != nil {
+		timer.Reset(externalEditDebounce)
+		return
+	}
+	externalEditTimers[fileName] = time.AfterFunc(externalEditDebounce, func() {
+		externalEditLock.Lock()
+		delete(externalEditTimers, fileName)
+		externalEditLock.Unlock()
+		barr, err := os.ReadFile(filepath.Join(wavebase.GetWaveConfigDir(), fileName))
+		if err != nil || !isExternalEdit(fileName, barr) {
+			return
+		}
+		recordConfigHistory(fileName, ConfigHistorySource_External)
+	})
+}
+
+func recordConfigHistory(fileName string, source string) {
+	var settings SettingsType
+	if instance != nil {
+		settings = instance.GetFullConfig().Settings
+	}
+	recordConfigHistoryWithSettings(fileName, source, settings)
+}
+
+// snapshots a config file (if it changed since its last snapshot) and applies the retention limits from settings.
+// errors are logged, never returned, so that history problems can't block config writes.
+// the watcher calls this directly (with its lock held) so it must not call GetFullConfig().
+func recordConfigHistoryWithSettings(fileName string, source string, settings SettingsType) {
+	if !isConfigHistoryFile(fileName) {
+		return
+	}
+	fullFileName := filepath.Join(wavebase.GetWaveConfigDir(), fileName)
+	metadata, err := filebackup.RecordHistory(fullFileName, source)
+	if err != nil {
+		log.Printf("error recording config history for %s: %v\n", fileName, err)
+		return
+	}
+	if metadata == nil {
+		return
+	}
+	maxVersions, maxAge := getConfigHistoryLimits(settings)
+	_, err = filebackup.PruneHistory(fullFileName, maxVersions, maxAge)
+	if err != nil {
+		log.Printf("error pruning config history for %s: %v\n", fileName, err)
+	}
+}
+
+func ListConfigHistory(fileName string) ([]ConfigHistoryEntry, error) {
+	fileName, err := NormalizeConfigHistoryFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	history, err := filebackup.ListHistory(filepath.Join(wavebase.GetWaveConfigDir(), fileName))
+	if err != nil {
+		return nil, err
+	}
+	rtn := make([]ConfigHistoryEntry, 0, len(history))
+	for _, metadata := range history {
+		rtn = append(rtn, ConfigHistoryEntry{
+			File:      fileName,
+			Version:   metadata.Version,
+			Timestamp: metadata.Timestamp,
+			Source:    metadata.Source,
+			Size:      metadata.Size,
+		})
+	}
+	return rtn, nil
+}
+
+func readConfigHistoryVersion(fileName string, version int) (waveobj.MetaMapType, error) {
+	barr, _, err := filebackup.ReadHistory(filepath.Join(wavebase.GetWaveConfigDir(), fileName), version)
+	if err != nil {
+		return nil, err
+	}
+	m, cerrs := parseConfigHelper(fileName, barr, nil)
+	if len(cerrs) > 0 {
+		return nil, fmt.Errorf("version %d of %s is not valid: %s", version, fileName, cerrs[0].Err)
+	}
+	return m, nil
+}
+
+// DiffConfigHistory compares a recorded version (old) with the current contents of the file (new).
+// both sides are compared as written, $ENV: references are not resolved (so secrets don't end up in the diff)
+func DiffConfigHistory(fileName string, version int) ([]ConfigDiffEntry, error) {
+	fileName, err := NormalizeConfigHistoryFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	oldMap, err := readConfigHistoryVersion(fileName, version)
+	if err != nil {
+		return nil, err
+	}
+	curMap, cerrs := ReadWaveHomeConfigFileRaw(fileName)
+	if len(cerrs) > 0 {
+		return nil, fmt.Errorf("error reading config file: %v", cerrs[0])
+	}
+	return diffConfigMaps(oldMap, curMap), nil
+}
+
+// RollbackConfigHistory restores a recorded version.  The restore is itself recorded as a new version,
+// so a rollback can be undone by rolling back to the version before it.
+func RollbackConfigHistory(fileName string, version int) error {
+	fileName, err := NormalizeConfigHistoryFile(fileName)
+	if err != nil {
+		return err
+	}
+	fullFileName := filepath.Join(wavebase.GetWaveConfigDir(), fileName)
+	barr, _, err := filebackup.ReadHistory(fullFileName, version)
+	if err != nil {
+		return err
+	}
+	_, cerrs := parseConfigHelper(fileName, barr, nil)
+	if len(cerrs) > 0 {
+		return fmt.Errorf("version %d of %s is not valid: %s", version, fileName, cerrs[0].Err)
+	}
+	recordConfigHistory(fileName, ConfigHistorySource_External)
+	noteSelfWrite(fileName, barr)
+	err = os.WriteFile(fullFileName, barr, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", fileName, err)
+	}
+	recordConfigHistory(fileName, ConfigHistorySource_Rollback)
+	return nil
+}
+
+// flattens nested maps into "a/b" keys (same path syntax as wsh setmeta/setconfig)
+func flattenConfigMap(prefix string, m map[string]any, rtn map[string]any) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "/" + k
+		}
+		if subMap, ok := v.(map[string]any); ok && len(subMap) > 0 {
+			flattenConfigMap(key, subMap, rtn)
+			continue
+		}
+		rtn[key] = v
+	}
+}
+
+func diffConfigMaps(oldMap waveobj.MetaMapType, newMap waveobj.MetaMapType) []ConfigDiffEntry {
+	oldFlat := make(map[string]any)
+	newFlat := make(map[string]any)
+	flattenConfigMap("", oldMap, oldFlat)
+	flattenConfigMap("", newMap, newFlat)
+	var rtn []ConfigDiffEntry
+	for k, oldVal := range oldFlat {
+		newVal, ok := newFlat[k]
+		if !ok {
+			rtn = append(rtn, ConfigDiffEntry{Key: k, OldValue: oldVal})
+			continue
+		}
+		if !reflect.DeepEqual(oldVal, newVal) {
+			rtn = append(rtn, ConfigDiffEntry{Key: k, OldValue: oldVal, NewValue: newVal})
+		}
+	}
+	for k, newVal := range newFlat {
+		if _, ok := oldFlat[k]; !ok {
+			rtn = append(rtn, ConfigDiffEntry{Key: k, NewValue: newVal})
+		}
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		return rtn[i].Key < rtn[j].Key
+	})
+	return rtn
+}
@@ -0,0 +1,73 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+)
+
+func TestIsExternalEdit(t *testing.T) {
+	const fileName = "test-settings.json"
+	written := []byte(`{"term:fontsize": 12}`)
+	noteSelfWrite(fileName, written)
+	defer func() {
+		externalEditLock.Lock()
+		delete(selfWriteHashes, fileName)
+		externalEditLock.Unlock()
+	}()
+
+	tests := []struct {
+		name string
+		barr []byte
+		want bool
+	}{
+		{"own write", written, false},
+		{"truncated", nil, false},
+		{"half written", []byte(`{"term:fontsize": 1`), false},
+		{"edited", []byte(`{"term:fontsize": 14}`), true},
+	}
+	for _, tc := range tests {
+		if got := isExternalEdit(fileName, tc.barr); got != tc.want {
+			t.Errorf("%s: isExternalEdit = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if !isExternalEdit("other.json", written) {
+		t.Errorf("a write to another file hid an external edit")
+	}
+}
+
+func TestDiffConfigHistoryKeepsEnvRefs(t *testing.T) {
+	wavebase.ConfigHome_VarCache = t.TempDir()
+	t.Setenv("SLTERM_TEST_APIKEY", "sk-secret")
+	settingsPath := filepath.Join(wavebase.GetWaveConfigDir(), SettingsFile)
+	writeSettings := func(content string) {
+		if err := os.WriteFile(settingsPath, []byte(content), 0644); err != nil {
+			t.Fatalf("writing settings: %v", err)
+		}
+	}
+	writeSettings(`{"ai:apitoken": "$ENV:SLTERM_TEST_APIKEY", "term:fontsize": 12}`)
+	recordConfigHistoryWithSettings(SettingsFile, ConfigHistorySource_Write, SettingsType{})
+	writeSettings(`{"ai:apitoken": "$ENV:SLTERM_TEST_APIKEY", "term:fontsize": 14}`)
+
+	diff, err := DiffConfigHistory(SettingsFile, 1)
+	if err != nil {
+		t.Fatalf("diffing: %v", err)
+	}
+	if len(diff) != 1 || diff[0].Key != "term:fontsize" {
+		t.Fatalf("got diff %v, want only term:fontsize", diff)
+	}
+
+	writeSettings(`{"term:fontsize": 12}`)
+	diff, err = DiffConfigHistory(SettingsFile, 1)
+	if err != nil {
+		t.Fatalf("diffing: %v", err)
+	}
+	if len(diff) != 1 || diff[0].OldValue != "$ENV:SLTERM_TEST_APIKEY" {
+		t.Errorf("got diff %v, want the unresolved $ENV: reference", diff)
+	}
+}
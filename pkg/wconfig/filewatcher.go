@@ -68,6 +68,9 @@ func (w *Watcher) Start() {
 	log.Printf("starting file watcher\n")
 	w.initialized = true
 	w.sendInitialValues()
+	for _, fileName := range ConfigHistoryFiles {
+		recordConfigHistoryWithSettings(fileName, ConfigHistorySource_Startup, w.fullConfig.Settings)
+	}
 
 	go func() {
 		defer func() {
@@ -166,8 +169,12 @@ func isValidSubSettingsFileName(fileName string) bool {
 	return validFileRe.MatchString(baseName)
 }
 
-func (w *Watcher) handleSettingsFileEvent(_ fsnotify.Event, _ string) {
+func (w *Watcher) handleSettingsFileEvent(event fsnotify.Event, fileName string) {
 	fullConfig := ReadFullConfig()
 	w.fullConfig = fullConfig
 	w.broadcast(WatcherUpdate{FullConfig: w.fullConfig})
+	// fileName is slash separated, compare native paths (for windows)
+	if filepath.Clean(filepath.Dir(event.Name)) == filepath.Clean(wavebase.GetWaveConfigDir()) {
+		scheduleExternalEditRecord(filepath.Base(event.Name))
+	}
 }
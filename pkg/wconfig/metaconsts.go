@@ -54,6 +54,16 @@ const (
 	ConfigKey_TermBellSound                  = "term:bellsound"
 	ConfigKey_TermBellIndicator              = "term:bellindicator"
 	ConfigKey_TermDurable                    = "term:durable"
+	ConfigKey_TermLog                        = "term:log"
+	ConfigKey_TermLogDir                     = "term:logdir"
+	ConfigKey_TermLogStripAnsi               = "term:logstripansi"
+	ConfigKey_TermLogTimestamps              = "term:logtimestamps"
+	ConfigKey_TermLogMaxSizeMb               = "term:logmaxsizemb"
+	ConfigKey_TermLogRotateHours             = "term:logrotatehours"
+	ConfigKey_TermLogKeep                    = "term:logkeep"
+	ConfigKey_TermCmdNotify                  = "term:cmdnotify"
+	ConfigKey_TermCmdNotifySecs              = "term:cmdnotifysecs"
+	ConfigKey_TermCmdNotifyBell              = "term:cmdnotifybell"
 
 	ConfigKey_EditorMinimapEnabled           = "editor:minimapenabled"
 	ConfigKey_EditorStickyScrollEnabled      = "editor:stickyscrollenabled"
@@ -110,10 +120,45 @@ const (
 	ConfigKey_ConnAskBeforeWshInstall        = "conn:askbeforewshinstall"
 	ConfigKey_ConnWshEnabled                 = "conn:wshenabled"
 	ConfigKey_ConnLocalHostnameDisplay       = "conn:localhostdisplayname"
+	ConfigKey_ConnDefault                    = "conn:default"
+
+	ConfigKey_WshClear                       = "wsh:*"
+	ConfigKey_WshLocalCapabilities           = "wsh:localcapabilities"
+	ConfigKey_WshRemoteCapabilities          = "wsh:remotecapabilities"
 
 	ConfigKey_DebugClear                     = "debug:*"
 	ConfigKey_DebugPprofPort                 = "debug:pprofport"
 	ConfigKey_DebugPprofMemProfileRate       = "debug:pprofmemprofilerate"
+	ConfigKey_DebugMetrics                   = "debug:metrics"
+
+	ConfigKey_ConfigClear                    = "config:*"
+	ConfigKey_ConfigHistoryMaxVersions       = "config:historymaxversions"
+	ConfigKey_ConfigHistoryMaxDays           = "config:historymaxdays"
+
+	ConfigKey_EventLogClear                  = "eventlog:*"
+	ConfigKey_EventLogEvents                 = "eventlog:events"
+	ConfigKey_EventLogRetentionDays          = "eventlog:retentiondays"
+	ConfigKey_EventLogMaxPerEvent            = "eventlog:maxperevent"
+	ConfigKey_EventLogRetention              = "eventlog:retention"
+
+	ConfigKey_GatewayClear                   = "gateway:*"
+	ConfigKey_GatewayEnabled                 = "gateway:enabled"
+	ConfigKey_GatewayListen                  = "gateway:listen"
+
+	ConfigKey_HistoryClear                   = "history:*"
+	ConfigKey_HistoryDisabled                = "history:disabled"
+	ConfigKey_HistoryMaxEntries              = "history:maxentries"
+
+	ConfigKey_FrecencyClear                  = "frecency:*"
+	ConfigKey_FrecencyDisabled               = "frecency:disabled"
+	ConfigKey_FrecencyMaxAge                 = "frecency:maxage"
+
+	ConfigKey_ShareClear                     = "share:*"
+	ConfigKey_ShareListen                    = "share:listen"
+	ConfigKey_ShareHost                      = "share:host"
+
+	ConfigKey_PluginsClear                   = "plugins:*"
+	ConfigKey_PluginsEnabled                 = "plugins:enabled"
 
 	ConfigKey_TsunamiClear                   = "tsunami:*"
 	ConfigKey_TsunamiScaffoldPath            = "tsunami:scaffoldpath"
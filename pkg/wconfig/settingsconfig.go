@@ -24,6 +24,9 @@ import (
 const SettingsFile = "settings.json"
 const ConnectionsFile = "connections.json"
 const ProfilesFile = "profiles.json"
+const TermThemesFile = "termthemes.json"
+const AutomationsFile = "automations.json"
+const TriggersFile = "triggers.json"
 
 const AnySchema = `
 {
@@ -101,6 +104,16 @@ type SettingsType struct {
 	TermBellSound           *bool    `json:"term:bellsound,omitempty"`
 	TermBellIndicator       *bool    `json:"term:bellindicator,omitempty"`
 	TermDurable             *bool    `json:"term:durable,omitempty"`
+	TermLog                 *bool    `json:"term:log,omitempty"`
+	TermLogDir              string   `json:"term:logdir,omitempty"`
+	TermLogStripAnsi        *bool    `json:"term:logstripansi,omitempty"`
+	TermLogTimestamps       *bool    `json:"term:logtimestamps,omitempty"`
+	TermLogMaxSizeMb        *int64   `json:"term:logmaxsizemb,omitempty"`
+	TermLogRotateHours      *int64   `json:"term:logrotatehours,omitempty"`
+	TermLogKeep             *int64   `json:"term:logkeep,omitempty"`
+	TermCmdNotify           *bool    `json:"term:cmdnotify,omitempty"`
+	TermCmdNotifySecs       *float64 `json:"term:cmdnotifysecs,omitempty"`
+	TermCmdNotifyBell       *bool    `json:"term:cmdnotifybell,omitempty"`
 
 	EditorMinimapEnabled      bool    `json:"editor:minimapenabled,omitempty"`
 	EditorStickyScrollEnabled bool    `json:"editor:stickyscrollenabled,omitempty"`
@@ -157,10 +170,45 @@ type SettingsType struct {
 	ConnAskBeforeWshInstall  *bool   `json:"conn:askbeforewshinstall,omitempty"`
 	ConnWshEnabled           bool    `json:"conn:wshenabled,omitempty"`
 	ConnLocalHostnameDisplay *string `json:"conn:localhostdisplayname,omitempty"`
+	ConnDefault              string  `json:"conn:default,omitempty"`
+
+	WshClear              bool     `json:"wsh:*,omitempty"`
+	WshLocalCapabilities  []string `json:"wsh:localcapabilities,omitempty"`
+	WshRemoteCapabilities []string `json:"wsh:remotecapabilities,omitempty"`
 
 	DebugClear               bool `json:"debug:*,omitempty"`
 	DebugPprofPort           *int `json:"debug:pprofport,omitempty"`
 	DebugPprofMemProfileRate *int `json:"debug:pprofmemprofilerate,omitempty"`
+	DebugMetrics             bool `json:"debug:metrics,omitempty"` // serve /metrics (prometheus format) on the web server
+
+	ConfigClear              bool   `json:"config:*,omitempty"`
+	ConfigHistoryMaxVersions *int64 `json:"config:historymaxversions,omitempty"`
+	ConfigHistoryMaxDays     *int64 `json:"config:historymaxdays,omitempty"`
+
+	EventLogClear         bool                             `json:"eventlog:*,omitempty"`
+	EventLogEvents        []string                         `json:"eventlog:events,omitempty"`
+	EventLogRetentionDays *int64                           `json:"eventlog:retentiondays,omitempty"`
+	EventLogMaxPerEvent   *int64                           `json:"eventlog:maxperevent,omitempty"`
+	EventLogRetention     map[string]EventLogRetentionType `json:"eventlog:retention,omitempty"` // keyed by event name or pattern (e.g. "user:*")
+
+	GatewayClear   bool   `json:"gateway:*,omitempty"`
+	GatewayEnabled bool   `json:"gateway:enabled,omitempty"`
+	GatewayListen  string `json:"gateway:listen,omitempty"`
+
+	HistoryClear      bool   `json:"history:*,omitempty"`
+	HistoryDisabled   bool   `json:"history:disabled,omitempty"`   // stop recording commands reported by shell integration
+	HistoryMaxEntries *int64 `json:"history:maxentries,omitempty"` // default 50000
+
+	FrecencyClear    bool   `json:"frecency:*,omitempty"`
+	FrecencyDisabled bool   `json:"frecency:disabled,omitempty"` // stop recording visited directories (used by "wsh z")
+	FrecencyMaxAge   *int64 `json:"frecency:maxage,omitempty"`   // total rank per connection before old entries are aged out, default 10000
+
+	ShareClear  bool   `json:"share:*,omitempty"`
+	ShareListen string `json:"share:listen,omitempty"` // host:port for terminal sharing links, default "127.0.0.1:0" (loopback, random port), e.g. ":0" to share on the network (plain http)
+	ShareHost   string `json:"share:host,omitempty"`   // host used in share links, defaults to the first non-loopback address
+
+	PluginsClear   bool     `json:"plugins:*,omitempty"`
+	PluginsEnabled []string `json:"plugins:enabled,omitempty"` // names of plugins (in <configdir>/plugins) that wavesrv may launch
 
 	TsunamiClear          bool   `json:"tsunami:*,omitempty"`
 	TsunamiScaffoldPath   string `json:"tsunami:scaffoldpath,omitempty"`
@@ -169,6 +217,12 @@ type SettingsType struct {
 	TsunamiGoPath         string `json:"tsunami:gopath,omitempty"`
 }
 
+// per event type override of eventlog:retentiondays / eventlog:maxperevent (0 means no limit)
+type EventLogRetentionType struct {
+	RetentionDays *int64 `json:"retentiondays,omitempty"`
+	MaxEvents     *int64 `json:"maxevents,omitempty"`
+}
+
 func (s *SettingsType) GetAiSettings() *AiSettingsType {
 	return &AiSettingsType{
 		AiClear:         s.AiClear,
@@ -268,6 +322,58 @@ type WebBookmark struct {
 	DisplayOrder float64 `json:"display:order,omitempty"`
 }
 
+// automations.json, "when <trigger> happens, run <actions>" (see pkg/automation)
+type AutomationRule struct {
+	DisplayName  string             `json:"display:name,omitempty"`
+	Disabled     bool               `json:"disabled,omitempty"`
+	DryRun       bool               `json:"dryrun,omitempty"`       // log matched actions instead of running them
+	CooldownSecs float64            `json:"cooldownsecs,omitempty"` // minimum time between runs (default 1s)
+	MaxPerHour   int                `json:"maxperhour,omitempty"`
+	Trigger      AutomationTrigger  `json:"trigger"`
+	Actions      []AutomationAction `json:"actions"`
+}
+
+type AutomationTrigger struct {
+	Event      string   `json:"event"`
+	Scopes     []string `json:"scopes,omitempty"`     // event must have one of these scopes ("*" wildcards allowed)
+	Conditions []string `json:"conditions,omitempty"` // and-ed filters, e.g. ".data.status==done" or ".data.values.cpu>90"
+}
+
+// string fields may reference the triggering event with ${.path}, e.g. "${.data.blockid}".  values
+// expanded into cmd are shell-quoted (don't quote them again), control characters are removed from
+// values expanded into cmd and input.  input actions of output triggers cannot use templates.
+type AutomationAction struct {
+	Type       string   `json:"type" jsonschema:"enum=runcommand,enum=notify,enum=tabindicator,enum=input,enum=publish,enum=petreaction"`
+	Cmd        string   `json:"cmd,omitempty"`        // runcommand
+	Cwd        string   `json:"cwd,omitempty"`        // runcommand (new block)
+	Connection string   `json:"connection,omitempty"` // runcommand (new block)
+	BlockId    string   `json:"blockid,omitempty"`    // runcommand (existing block), input
+	TabId      string   `json:"tabid,omitempty"`      // runcommand (new block), tabindicator
+	Input      string   `json:"input,omitempty"`      // input
+	Title      string   `json:"title,omitempty"`      // notify
+	Body       string   `json:"body,omitempty"`       // notify, petreaction
+	Silent     bool     `json:"silent,omitempty"`     // notify
+	Icon       string   `json:"icon,omitempty"`       // tabindicator
+	Color      string   `json:"color,omitempty"`      // tabindicator
+	Event      string   `json:"event,omitempty"`      // publish (must be a "user:" event)
+	Scopes     []string `json:"scopes,omitempty"`     // publish
+	Data       any      `json:"data,omitempty"`       // publish
+}
+
+// triggers.json, regexes matched against terminal output (see pkg/automation/outputtrigger.go).
+// actions are the same as automation actions, templates see a "term:output" event whose data is
+// {blockid, connection, trigger, line, match, groups}.  input actions default to the matching block.
+type OutputTrigger struct {
+	DisplayName  string             `json:"display:name,omitempty"`
+	Disabled     bool               `json:"disabled,omitempty"`
+	OptIn        bool               `json:"optin,omitempty"`        // only active where listed in "term:triggers" (block meta or connection)
+	Pattern      string             `json:"pattern"`                // regexp, matched against each line with escape sequences removed
+	Connections  []string           `json:"connections,omitempty"`  // only for these connections ("*" wildcards, "local" for local shells)
+	CooldownSecs float64            `json:"cooldownsecs,omitempty"` // minimum time between runs per block (default 1s)
+	MaxPerHour   int                `json:"maxperhour,omitempty"`   // per block
+	Actions      []AutomationAction `json:"actions"`
+}
+
 // Wave AI panel mode configuration (NEW)
 type AIModeConfigType struct {
 	DisplayName        string   `json:"display:name"`
@@ -305,6 +411,8 @@ type FullConfigType struct {
 	Connections    map[string]ConnKeywords        `json:"connections"`
 	Bookmarks      map[string]WebBookmark         `json:"bookmarks"`
 	WaveAIModes    map[string]AIModeConfigType    `json:"waveai"`
+	Automations    map[string]AutomationRule      `json:"automations"`
+	Triggers       map[string]OutputTrigger       `json:"triggers"`
 	ConfigErrors   []ConfigError                  `json:"configerrors" configfile:"-"`
 }
 
@@ -314,15 +422,18 @@ type ConnKeywords struct {
 	ConnWshPath             string `json:"conn:wshpath,omitempty"`
 	ConnShellPath           string `json:"conn:shellpath,omitempty"`
 	ConnIgnoreSshConfig     *bool  `json:"conn:ignoresshconfig,omitempty"`
+	ConnTlsFingerprint      string `json:"conn:tlsfingerprint,omitempty"`
 
 	DisplayHidden *bool   `json:"display:hidden,omitempty"`
 	DisplayOrder  float32 `json:"display:order,omitempty"`
 
-	TermClear      bool    `json:"term:*,omitempty"`
-	TermFontSize   float64 `json:"term:fontsize,omitempty"`
-	TermFontFamily string  `json:"term:fontfamily,omitempty"`
-	TermTheme      string  `json:"term:theme,omitempty"`
-	TermDurable    *bool   `json:"term:durable,omitempty"`
+	TermClear      bool     `json:"term:*,omitempty"`
+	TermFontSize   float64  `json:"term:fontsize,omitempty"`
+	TermFontFamily string   `json:"term:fontfamily,omitempty"`
+	TermTheme      string   `json:"term:theme,omitempty"`
+	TermDurable    *bool    `json:"term:durable,omitempty"`
+	TermTriggers   []string `json:"term:triggers,omitempty"`
+	TermCmdNotify  *bool    `json:"term:cmdnotify,omitempty"`
 
 	CmdEnv            map[string]string `json:"cmd:env,omitempty"`
 	CmdInitScript     string            `json:"cmd:initscript,omitempty"`
@@ -444,6 +555,16 @@ func resolveEnvValue(value string) (string, bool) {
 }
 
 func readConfigHelper(fileName string, barr []byte, readErr error) (waveobj.MetaMapType, []ConfigError) {
+	rtn, cerrs := parseConfigHelper(fileName, barr, readErr)
+	// Resolve environment variable replacements
+	if rtn != nil {
+		resolveEnvReplacements(rtn)
+	}
+	return rtn, cerrs
+}
+
+// parses a config file without resolving $ENV: replacements
+func parseConfigHelper(fileName string, barr []byte, readErr error) (waveobj.MetaMapType, []ConfigError) {
 	var cerrs []ConfigError
 	if readErr != nil && !os.IsNotExist(readErr) {
 		cerrs = append(cerrs, ConfigError{File: fileName, Err: readErr.Error()})
@@ -469,12 +590,6 @@ func readConfigHelper(fileName string, barr []byte, readErr error) (waveobj.Meta
 		}
 		cerrs = append(cerrs, ConfigError{File: fileName, Err: err.Error()})
 	}
-
-	// Resolve environment variable replacements
-	if rtn != nil {
-		resolveEnvReplacements(rtn)
-	}
-
 	return rtn, cerrs
 }
 
@@ -497,6 +612,14 @@ func ReadWaveHomeConfigFile(fileName string) (waveobj.MetaMapType, []ConfigError
 	return readConfigFileFS(configDirFsys, "", fileName)
 }
 
+// ReadWaveHomeConfigFileRaw reads a config file as written, keeping $ENV: references unresolved
+// (use when the contents are going to be written back or copied elsewhere)
+func ReadWaveHomeConfigFileRaw(fileName string) (waveobj.MetaMapType, []ConfigError) {
+	fullFileName := filepath.Join(wavebase.GetWaveConfigDir(), fileName)
+	barr, readErr := os.ReadFile(fullFileName)
+	return parseConfigHelper(fileName, barr, readErr)
+}
+
 func WriteWaveHomeConfigFile(fileName string, m waveobj.MetaMapType) error {
 	configDirAbsPath := wavebase.GetWaveConfigDir()
 	fullFileName := filepath.Join(configDirAbsPath, fileName)
@@ -504,7 +627,15 @@ func WriteWaveHomeConfigFile(fileName string, m waveobj.MetaMapType) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(fullFileName, barr, 0644)
+	// capture any unrecorded (external) edits first so the write can be rolled back to them
+	recordConfigHistory(fileName, ConfigHistorySource_External)
+	noteSelfWrite(fileName, barr)
+	err = os.WriteFile(fullFileName, barr, 0644)
+	if err != nil {
+		return err
+	}
+	recordConfigHistory(fileName, ConfigHistorySource_Write)
+	return nil
 }
 
 // simple merge that overwrites
@@ -764,14 +895,9 @@ func convertJsonNumber(num json.Number, ctype reflect.Type) (interface{}, error)
 	return nil, fmt.Errorf("cannot convert number to %s", ctype)
 }
 
-func SetBaseConfigValue(toMerge waveobj.MetaMapType) error {
-	m, cerrs := ReadWaveHomeConfigFile(SettingsFile)
-	if len(cerrs) > 0 {
-		return fmt.Errorf("error reading config file: %v", cerrs[0])
-	}
-	if m == nil {
-		m = make(waveobj.MetaMapType)
-	}
+// applySettingsValues validates the keys in toMerge against SettingsType and merges them into m.
+// json.Number values are converted to the key's type and nil values delete the key.
+func applySettingsValues(m waveobj.MetaMapType, toMerge waveobj.MetaMapType) error {
 	for configKey, val := range toMerge {
 		ctype := getConfigKeyType(configKey)
 		if ctype == nil {
@@ -802,6 +928,21 @@ func SetBaseConfigValue(toMerge waveobj.MetaMapType) error {
 			}
 		}
 	}
+	return nil
+}
+
+func SetBaseConfigValue(toMerge waveobj.MetaMapType) error {
+	m, cerrs := ReadWaveHomeConfigFile(SettingsFile)
+	if len(cerrs) > 0 {
+		return fmt.Errorf("error reading config file: %v", cerrs[0])
+	}
+	if m == nil {
+		m = make(waveobj.MetaMapType)
+	}
+	err := applySettingsValues(m, toMerge)
+	if err != nil {
+		return err
+	}
 	return WriteWaveHomeConfigFile(SettingsFile, m)
 }
 
@@ -824,6 +965,78 @@ func SetConnectionsConfigValue(connName string, toMerge waveobj.MetaMapType) err
 	return WriteWaveHomeConfigFile(ConnectionsFile, m)
 }
 
+// SetTermThemeConfigValue adds (or replaces) a theme in the user's termthemes.json
+func SetTermThemeConfigValue(themeKey string, theme TermThemeType) error {
+	m, cerrs := ReadWaveHomeConfigFileRaw(TermThemesFile)
+	if len(cerrs) > 0 {
+		return fmt.Errorf("error reading config file: %v", cerrs[0])
+	}
+	if m == nil {
+		m = make(waveobj.MetaMapType)
+	}
+	var themeMap map[string]any
+	err := utilfn.ReUnmarshal(&themeMap, theme)
+	if err != nil {
+		return fmt.Errorf("error converting theme: %w", err)
+	}
+	m[themeKey] = themeMap
+	return WriteWaveHomeConfigFile(TermThemesFile, m)
+}
+
+// SetAutomationDisabled toggles "disabled" on a rule in automations.json (rules from automations/*.json are read-only here)
+func SetAutomationDisabled(ruleName string, disabled bool) error {
+	m, cerrs := ReadWaveHomeConfigFileRaw(AutomationsFile)
+	if len(cerrs) > 0 {
+		return fmt.Errorf("error reading config file: %v", cerrs[0])
+	}
+	rule, ok := m[ruleName].(map[string]any)
+	if !ok {
+		return fmt.Errorf("automation %q not found in %s", ruleName, AutomationsFile)
+	}
+	if disabled {
+		rule["disabled"] = true
+	} else {
+		delete(rule, "disabled")
+	}
+	m[ruleName] = rule
+	return WriteWaveHomeConfigFile(AutomationsFile, m)
+}
+
+// MergeSettingsOverrides returns a copy of settings with overrides (e.g. a workspace's settings layer) merged on top.
+// "section:*" keys in overrides clear that section before the remaining keys are applied.
+func MergeSettingsOverrides(settings SettingsType, overrides waveobj.MetaMapType) SettingsType {
+	if len(overrides) == 0 {
+		return settings
+	}
+	var baseMap waveobj.MetaMapType
+	err := utilfn.ReUnmarshal(&baseMap, settings)
+	if err != nil {
+		log.Printf("error converting settings to map: %v\n", err)
+		return settings
+	}
+	var rtn SettingsType
+	err = utilfn.ReUnmarshal(&rtn, waveobj.MergeMeta(baseMap, overrides, true))
+	if err != nil {
+		log.Printf("error applying settings overrides: %v\n", err)
+		return settings
+	}
+	return rtn
+}
+
+// UpdateSettingsOverrides validates toMerge against the settings keys and returns a new overrides map
+// with toMerge applied (nil values remove a key).  overrides is not modified.
+func UpdateSettingsOverrides(overrides waveobj.MetaMapType, toMerge waveobj.MetaMapType) (waveobj.MetaMapType, error) {
+	rtn := make(waveobj.MetaMapType)
+	for k, v := range overrides {
+		rtn[k] = v
+	}
+	err := applySettingsValues(rtn, toMerge)
+	if err != nil {
+		return nil, err
+	}
+	return rtn, nil
+}
+
 type WidgetConfigType struct {
 	DisplayOrder  float64          `json:"display:order,omitempty"`
 	DisplayHidden bool             `json:"display:hidden,omitempty"`
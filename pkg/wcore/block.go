@@ -78,6 +78,7 @@ func CreateBlockWithTelemetry(ctx context.Context, tabId string, blockDef *waveo
 	if blockDef.Meta == nil || blockDef.Meta.GetString(waveobj.MetaKey_View, "") == "" {
 		return nil, fmt.Errorf("no view provided for new block")
 	}
+	applyDefaultConnection(ctx, tabId, blockDef.Meta)
 	blockData, err := createBlockObj(ctx, tabId, blockDef, rtOpts)
 	if err != nil {
 		return nil, fmt.Errorf("error creating block: %w", err)
@@ -105,6 +106,24 @@ func CreateBlockWithTelemetry(ctx context.Context, tabId string, blockDef *waveo
 	return blockData, nil
 }
 
+// new terminal blocks without an explicit connection get conn:default (workspace settings layer, then settings.json)
+func applyDefaultConnection(ctx context.Context, tabId string, meta waveobj.MetaMapType) {
+	if meta.GetString(waveobj.MetaKey_View, "") != "term" || meta.HasKey(waveobj.MetaKey_Connection) {
+		return
+	}
+	workspaceId, err := wstore.DBFindWorkspaceForTabId(ctx, tabId)
+	if err != nil || workspaceId == "" {
+		return
+	}
+	settings, err := ResolveWorkspaceSettings(ctx, workspaceId)
+	if err != nil {
+		log.Printf("error resolving workspace settings for new block: %v\n", err)
+	}
+	if settings.ConnDefault != "" {
+		meta[waveobj.MetaKey_Connection] = settings.ConnDefault
+	}
+}
+
 func recordBlockCreationTelemetry(blockView string, blockController string) {
 	defer func() {
 		panichandler.PanicHandler("CreateBlock:telemetry", recover())
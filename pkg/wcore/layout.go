@@ -114,15 +114,23 @@ func QueueLayoutActionForTab(ctx context.Context, tabId string, actions ...waveo
 }
 
 func ApplyPortableLayout(ctx context.Context, tabId string, layout PortableLayout, recordTelemetry bool) error {
+	_, err := ApplyPortableLayoutBlocks(ctx, tabId, layout, recordTelemetry)
+	return err
+}
+
+// same as ApplyPortableLayout, returns the ids of the created blocks (in layout order)
+func ApplyPortableLayoutBlocks(ctx context.Context, tabId string, layout PortableLayout, recordTelemetry bool) ([]string, error) {
 	actions := make([]waveobj.LayoutActionData, len(layout)+1)
+	blockIds := make([]string, len(layout))
 	actions[0] = waveobj.LayoutActionData{ActionType: LayoutActionDataType_ClearTree}
 	for i := 0; i < len(layout); i++ {
 		layoutAction := layout[i]
 
 		blockData, err := CreateBlockWithTelemetry(ctx, tabId, layoutAction.BlockDef, &waveobj.RuntimeOpts{}, recordTelemetry)
 		if err != nil {
-			return fmt.Errorf("unable to create block to apply portable layout to tab %s: %w", tabId, err)
+			return nil, fmt.Errorf("unable to create block to apply portable layout to tab %s: %w", tabId, err)
 		}
+		blockIds[i] = blockData.OID
 
 		actions[i+1] = waveobj.LayoutActionData{
 			ActionType: LayoutActionDataType_InsertAtIndex,
@@ -135,10 +143,9 @@ func ApplyPortableLayout(ctx context.Context, tabId string, layout PortableLayou
 
 	err := QueueLayoutActionForTab(ctx, tabId, actions...)
 	if err != nil {
-		return fmt.Errorf("unable to queue layout actions for portable layout: %w", err)
+		return nil, fmt.Errorf("unable to queue layout actions for portable layout: %w", err)
 	}
-
-	return nil
+	return blockIds, nil
 }
 
 func BootstrapStarterLayout(ctx context.Context) error {
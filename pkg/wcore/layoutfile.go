@@ -0,0 +1,191 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wcore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SalyyS1/SLTerm/pkg/layoutfile"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+func GetLayoutsDir() string {
+	return filepath.Join(wavebase.GetWaveConfigDir(), layoutfile.LayoutsDirName)
+}
+
+func validateLayoutName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid layout name %q", name)
+	}
+	return nil
+}
+
+// reads <configdir>/layouts/<name>.{json,yaml,yml}
+func ReadConfigLayoutFile(name string) ([]byte, error) {
+	if err := validateLayoutName(name); err != nil {
+		return nil, err
+	}
+	for _, ext := range layoutfile.LayoutFileExts {
+		barr, err := os.ReadFile(filepath.Join(GetLayoutsDir(), name+ext))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return barr, err
+	}
+	return nil, fmt.Errorf("layout %q not found in %s", name, GetLayoutsDir())
+}
+
+func TabDefToPortableLayout(tab *layoutfile.TabDef) PortableLayout {
+	entries := tab.Flatten()
+	rtn := make(PortableLayout, len(entries))
+	hasFocus := false
+	for idx, entry := range entries {
+		rtn[idx].IndexArr = entry.IndexArr
+		rtn[idx].Size = entry.Size
+		rtn[idx].BlockDef = &waveobj.BlockDef{Meta: entry.Node.BlockMeta()}
+		if entry.Node.Focused && !hasFocus {
+			rtn[idx].Focused = true
+			hasFocus = true
+		}
+	}
+	if !hasFocus && len(rtn) > 0 {
+		rtn[0].Focused = true
+	}
+	return rtn
+}
+
+// creates a tab at the end of the workspace and fills it with the layout.  returns the tab id and the
+// created block ids (in layout order).  an empty tabName gets the usual "T<n>" name.
+func CreateTabWithLayout(ctx context.Context, workspaceId string, tabName string, tabMeta waveobj.MetaMapType, layout PortableLayout) (string, []string, error) {
+	if tabName == "" {
+		ws, err := GetWorkspace(ctx, workspaceId)
+		if err != nil {
+			return "", nil, fmt.Errorf("workspace %s not found: %w", workspaceId, err)
+		}
+		tabName = "T" + fmt.Sprint(len(ws.TabIds)+1)
+	}
+	tab, err := createTabObj(ctx, workspaceId, tabName, tabMeta)
+	if err != nil {
+		return "", nil, fmt.Errorf("error creating tab: %w", err)
+	}
+	blockIds, err := ApplyPortableLayoutBlocks(ctx, tab.OID, layout, true)
+	if err != nil {
+		return tab.OID, nil, fmt.Errorf("error applying layout to tab %q: %w", tabName, err)
+	}
+	return tab.OID, blockIds, nil
+}
+
+// creates one new tab per layout-file tab in the workspace and activates the first one.  returns the new tab ids.
+func ApplyLayoutFile(ctx context.Context, workspaceId string, lf *layoutfile.LayoutFile) ([]string, error) {
+	var tabIds []string
+	for _, tabDef := range lf.Tabs {
+		tabId, _, err := CreateTabWithLayout(ctx, workspaceId, tabDef.Name, nil, TabDefToPortableLayout(tabDef))
+		if tabId != "" {
+			tabIds = append(tabIds, tabId)
+		}
+		if err != nil {
+			return tabIds, err
+		}
+	}
+	return tabIds, ActivateNewTab(ctx, workspaceId, tabIds)
+}
+
+// activates the first of the given (newly created) tabs
+func ActivateNewTab(ctx context.Context, workspaceId string, tabIds []string) error {
+	if len(tabIds) == 0 {
+		return nil
+	}
+	err := SetActiveTab(ctx, workspaceId, tabIds[0])
+	if err != nil {
+		return err
+	}
+	SendActiveTabUpdate(ctx, workspaceId, tabIds[0])
+	return nil
+}
+
+func getTabLayoutNode(ctx context.Context, tab *waveobj.Tab) (*layoutfile.NodeDef, error) {
+	getMeta := func(blockId string) waveobj.MetaMapType {
+		block, _ := wstore.DBGet[*waveobj.Block](ctx, blockId)
+		if block == nil {
+			return nil
+		}
+		return block.Meta
+	}
+	layoutState, err := wstore.DBGet[*waveobj.LayoutState](ctx, tab.LayoutState)
+	if err != nil {
+		return nil, fmt.Errorf("error getting layout state for tab %q: %w", tab.Name, err)
+	}
+	var rootNode any
+	var focusedNodeId string
+	if layoutState != nil {
+		rootNode = layoutState.RootNode
+		focusedNodeId = layoutState.FocusedNodeId
+	}
+	return layoutfile.NodeFromTabLayout(rootNode, focusedNodeId, tab.BlockIds, getMeta), nil
+}
+
+// serializes the workspace's tabs (their current layout trees) into a layout file.  empty tabs are skipped.
+func GetWorkspaceLayoutFile(ctx context.Context, workspaceId string) (*layoutfile.LayoutFile, error) {
+	ws, err := GetWorkspace(ctx, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("workspace %s not found: %w", workspaceId, err)
+	}
+	rtn := &layoutfile.LayoutFile{Name: ws.Name}
+	for _, tabId := range ws.TabIds {
+		tab, err := wstore.DBMustGet[*waveobj.Tab](ctx, tabId)
+		if err != nil {
+			return nil, fmt.Errorf("error getting tab %s: %w", tabId, err)
+		}
+		node, err := getTabLayoutNode(ctx, tab)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			continue
+		}
+		rtn.Tabs = append(rtn.Tabs, &layoutfile.TabDef{Name: tab.Name, Layout: node})
+	}
+	if len(rtn.Tabs) == 0 {
+		return nil, fmt.Errorf("workspace has no tabs with blocks to save")
+	}
+	return rtn, nil
+}
+
+// writes the layout file to <configdir>/layouts/<name>.json, returns the path
+func WriteConfigLayoutFile(name string, lf *layoutfile.LayoutFile, overwrite bool) (string, error) {
+	if err := validateLayoutName(name); err != nil {
+		return "", err
+	}
+	if !overwrite {
+		for _, ext := range layoutfile.LayoutFileExts {
+			existingPath := filepath.Join(GetLayoutsDir(), name+ext)
+			if _, err := os.Stat(existingPath); err == nil {
+				return "", fmt.Errorf("layout %s already exists (use --force to overwrite)", existingPath)
+			}
+		}
+	}
+	barr, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error serializing layout: %w", err)
+	}
+	err = os.MkdirAll(GetLayoutsDir(), 0755)
+	if err != nil {
+		return "", fmt.Errorf("error creating layouts dir: %w", err)
+	}
+	fullPath := filepath.Join(GetLayoutsDir(), name+".json")
+	err = os.WriteFile(fullPath, append(barr, '\n'), 0644)
+	if err != nil {
+		return "", fmt.Errorf("error writing layout: %w", err)
+	}
+	return fullPath, nil
+}
@@ -114,6 +114,9 @@ func UpdateWorkspace(ctx context.Context, workspaceId string, name string, icon
 	return ws, updated, nil
 }
 
+// called before a workspace's tabs are deleted (set by the snapshot package to take an automatic snapshot)
+var BeforeDeleteWorkspaceHook func(ctx context.Context, workspaceId string)
+
 // If force is true, it will delete even if workspace is named.
 // If workspace is empty, it will be deleted, even if it is named.
 // Returns true if workspace was deleted, false if it was not deleted.
@@ -134,6 +137,9 @@ func DeleteWorkspace(ctx context.Context, workspaceId string, force bool) (bool,
 		return false, "", nil
 	}
 
+	if BeforeDeleteWorkspaceHook != nil && len(workspace.TabIds) > 0 {
+		BeforeDeleteWorkspaceHook(ctx, workspaceId)
+	}
 	for _, tabId := range workspace.TabIds {
 		log.Printf("deleting tab %s\n", tabId)
 		_, err := DeleteTab(ctx, workspaceId, tabId, false)
@@ -448,3 +454,87 @@ func SetName(workspaceId string, name string) error {
 	}
 	return nil
 }
+
+// GetWorkspaceSettings returns the settings overrides stored in the workspace's meta (nil if none are set).
+func GetWorkspaceSettings(ws *waveobj.Workspace) waveobj.MetaMapType {
+	if ws == nil {
+		return nil
+	}
+	return ws.Meta.GetMap(waveobj.MetaKey_WorkspaceSettings)
+}
+
+// ResolveWorkspaceSettings returns the global settings with the workspace's settings layer applied.
+func ResolveWorkspaceSettings(ctx context.Context, workspaceId string) (wconfig.SettingsType, error) {
+	ws, err := wstore.DBMustGet[*waveobj.Workspace](ctx, workspaceId)
+	if err != nil {
+		return wconfig.GetWatcher().GetFullConfig().Settings, fmt.Errorf("error getting workspace %q: %w", workspaceId, err)
+	}
+	return ResolveSettingsForWorkspace(ws), nil
+}
+
+// ResolveSettingsForWorkspace is ResolveWorkspaceSettings for an already loaded workspace (nil for the global settings).
+func ResolveSettingsForWorkspace(ws *waveobj.Workspace) wconfig.SettingsType {
+	return wconfig.MergeSettingsOverrides(wconfig.GetWatcher().GetFullConfig().Settings, GetWorkspaceSettings(ws))
+}
+
+// ResolveTabSettings returns the settings that apply to the blocks of a tab: defaults, settings.json, then the
+// settings layer of the tab's workspace.  If the tab's workspace cannot be found the global settings are returned.
+func ResolveTabSettings(ctx context.Context, tabId string) wconfig.SettingsType {
+	workspaceId, err := wstore.DBFindWorkspaceForTabId(ctx, tabId)
+	if err != nil || workspaceId == "" {
+		return ResolveSettingsForWorkspace(nil)
+	}
+	ws, err := wstore.DBGet[*waveobj.Workspace](ctx, workspaceId)
+	if err != nil {
+		log.Printf("error getting workspace %q for tab %q: %v\n", workspaceId, tabId, err)
+	}
+	return ResolveSettingsForWorkspace(ws)
+}
+
+// ResolveBlockSettings is ResolveTabSettings for the block's tab.  Connection and block meta overrides are
+// applied by the caller.  Use ResolveTabSettings when the tab is already known.
+func ResolveBlockSettings(ctx context.Context, block *waveobj.Block) wconfig.SettingsType {
+	var tabId string
+	if parentORef, err := waveobj.ParseORef(block.ParentORef); err == nil && parentORef.OType == waveobj.OType_Tab {
+		tabId = parentORef.OID
+	} else if tabId, err = wstore.DBFindTabForBlockId(ctx, block.OID); err != nil {
+		tabId = ""
+	}
+	if tabId == "" {
+		return ResolveSettingsForWorkspace(nil)
+	}
+	return ResolveTabSettings(ctx, tabId)
+}
+
+// SetWorkspaceSettings merges toMerge into the workspace's settings layer (nil values remove a key)
+// and publishes a workspace:config event scoped to the workspace.
+func SetWorkspaceSettings(ctx context.Context, workspaceId string, toMerge waveobj.MetaMapType) error {
+	ws, err := wstore.DBMustGet[*waveobj.Workspace](ctx, workspaceId)
+	if err != nil {
+		return fmt.Errorf("error getting workspace %q: %w", workspaceId, err)
+	}
+	newSettings, err := wconfig.UpdateSettingsOverrides(GetWorkspaceSettings(ws), toMerge)
+	if err != nil {
+		return err
+	}
+	if ws.Meta == nil {
+		ws.Meta = make(waveobj.MetaMapType)
+	}
+	if len(newSettings) == 0 {
+		delete(ws.Meta, waveobj.MetaKey_WorkspaceSettings)
+	} else {
+		ws.Meta[waveobj.MetaKey_WorkspaceSettings] = newSettings
+	}
+	err = wstore.DBUpdate(ctx, ws)
+	if err != nil {
+		return fmt.Errorf("error updating workspace: %w", err)
+	}
+	oref := waveobj.MakeORef(waveobj.OType_Workspace, workspaceId)
+	SendWaveObjUpdate(oref)
+	wps.Broker.Publish(wps.WaveEvent{
+		Event:  wps.Event_WorkspaceConfig,
+		Scopes: []string{oref.String()},
+		Data:   newSettings,
+	})
+	return nil
+}
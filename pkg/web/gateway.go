@@ -0,0 +1,343 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/apitoken"
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/web/sse"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// the gateway is an opt-in ("gateway:enabled") http/json front door to a whitelisted subset of wshrpc
+// for tools outside of SLTerm.  every request needs "Authorization: Bearer <token>" (see `wsh token create`).
+// calls are made as a restricted caller, so protected block meta and settings can't be written.
+//
+//	GET  /api/v1/commands           commands the token may call
+//	POST /api/v1/rpc/{command}      body is the command data, returns {"success":true,"data":...}
+//	POST /api/v1/stream/{command}   streaming commands, responses are sent as SSE
+//	GET  /api/v1/events?event=E     SSE stream of wps events (optional scope=S, repeatable)
+
+const GatewaySocketName = "gateway.sock"
+const GatewayListenUnix = "unix"
+const gatewayRpcTimeoutMs = 30000
+const gatewayMaxBodySize = 10 * 1024 * 1024
+const gatewayEventChSize = 64
+
+type gatewayEventSub struct {
+	Event  string
+	Scopes []string
+	Ch     chan wps.WaveEvent
+}
+
+type gatewayObserverType struct {
+	lock *sync.Mutex
+	subs map[string]*gatewayEventSub
+}
+
+var gatewayObserver = &gatewayObserverType{
+	lock: &sync.Mutex{},
+	subs: make(map[string]*gatewayEventSub),
+}
+
+var gatewayDeclMapOnce = sync.OnceValue(wshrpc.GenerateWshCommandDeclMap)
+
+func GetGatewaySocketName() string {
+	return filepath.Join(wavebase.GetWaveDataDir(), GatewaySocketName)
+}
+
+// listenAddr is "unix" (the default, a socket in the data dir) or a loopback host:port
+func MakeGatewayListener(listenAddr string) (net.Listener, error) {
+	if listenAddr == "" || listenAddr == GatewayListenUnix {
+		sockName := GetGatewaySocketName()
+		os.Remove(sockName) // ignore error
+		rtn, err := net.Listen("unix", sockName)
+		if err != nil {
+			return nil, fmt.Errorf("error creating gateway listener at %v: %v", sockName, err)
+		}
+		os.Chmod(sockName, 0600)
+		log.Printf("Server [gateway] listening on %s\n", sockName)
+		return rtn, nil
+	}
+	host, _, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gateway:listen %q: %v", listenAddr, err)
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return nil, fmt.Errorf("invalid gateway:listen %q: the gateway only listens on loopback addresses", listenAddr)
+		}
+	}
+	rtn, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gateway listener at %v: %v", listenAddr, err)
+	}
+	log.Printf("Server [gateway] listening on %s\n", rtn.Addr())
+	return rtn, nil
+}
+
+func (o *gatewayObserverType) ObserveEvent(event wps.WaveEvent) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	for _, sub := range o.subs {
+		if sub.Event != event.Event || !gatewayScopeMatch(sub.Scopes, event.Scopes) {
+			continue
+		}
+		select {
+		case sub.Ch <- event:
+		default:
+			// slow reader, drop
+		}
+	}
+}
+
+func gatewayScopeMatch(patterns []string, scopes []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		for _, scope := range scopes {
+			if pattern == scope || utilfn.StarMatchString(pattern, scope, ":") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (o *gatewayObserverType) addSub(sub *gatewayEventSub) string {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	id := uuid.New().String()
+	o.subs[id] = sub
+	return id
+}
+
+func (o *gatewayObserverType) removeSub(id string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	delete(o.subs, id)
+}
+
+func writeGatewayError(w http.ResponseWriter, status int, errVal error) {
+	w.Header().Set(ContentTypeHeaderKey, ContentTypeJson)
+	w.WriteHeader(status)
+	barr, _ := json.Marshal(map[string]any{"error": errVal.Error()})
+	w.Write(barr)
+}
+
+type gatewayFnType = func(http.ResponseWriter, *http.Request, *wshrpc.ApiTokenInfo)
+
+func gatewayFnWrap(fn gatewayFnType) WebFnType {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recErr := panichandler.PanicHandler("gatewayFnWrap", recover())
+			if recErr != nil {
+				writeGatewayError(w, http.StatusInternalServerError, recErr)
+			}
+		}()
+		w.Header().Set(CacheControlHeaderKey, CacheControlHeaderNoCache)
+		authHeader := r.Header.Get("Authorization")
+		token, ok := strings.CutPrefix(authHeader, "Bearer ")
+		if !ok || token == "" {
+			writeGatewayError(w, http.StatusUnauthorized, fmt.Errorf("missing api token (Authorization: Bearer <token>)"))
+			return
+		}
+		ctx, cancelFn := context.WithTimeout(r.Context(), 2*time.Second)
+		tokenInfo, err := apitoken.Validate(ctx, token)
+		cancelFn()
+		if err != nil {
+			writeGatewayError(w, http.StatusUnauthorized, err)
+			return
+		}
+		fn(w, r, tokenInfo)
+	}
+}
+
+func checkGatewayCommand(w http.ResponseWriter, tokenInfo *wshrpc.ApiTokenInfo, command string) (*wshrpc.WshRpcMethodDecl, bool) {
+	decl := gatewayDeclMapOnce()[command]
+	if decl == nil || !apitoken.GatewayCommands[command] {
+		writeGatewayError(w, http.StatusNotFound, fmt.Errorf("command %q is not available through the gateway", command))
+		return nil, false
+	}
+	if !apitoken.IsCommandAllowed(tokenInfo, command) {
+		writeGatewayError(w, http.StatusForbidden, fmt.Errorf("token %q is not allowed to call %q", tokenInfo.Name, command))
+		return nil, false
+	}
+	return decl, true
+}
+
+func readGatewayData(r *http.Request, command string) (any, error) {
+	barr, err := io.ReadAll(io.LimitReader(r.Body, gatewayMaxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading request body: %w", err)
+	}
+	var data any
+	if len(strings.TrimSpace(string(barr))) > 0 {
+		if err := json.Unmarshal(barr, &data); err != nil {
+			return nil, fmt.Errorf("request body is not valid json: %w", err)
+		}
+	}
+	if command == "eventpublish" {
+		eventName, _ := utilfn.ConvertMap(data)["event"].(string)
+		if !wps.IsUserEvent(eventName) {
+			return nil, fmt.Errorf("the gateway can only publish custom events (%q namespace)", wps.UserEventPrefix)
+		}
+	}
+	return data, nil
+}
+
+func handleGatewayCommands(w http.ResponseWriter, r *http.Request, tokenInfo *wshrpc.ApiTokenInfo) {
+	var commands []string
+	for _, command := range apitoken.GatewayCommandList() {
+		if apitoken.IsCommandAllowed(tokenInfo, command) {
+			commands = append(commands, command)
+		}
+	}
+	WriteJsonSuccess(w, commands)
+}
+
+func handleGatewayRpc(w http.ResponseWriter, r *http.Request, tokenInfo *wshrpc.ApiTokenInfo) {
+	if r.Method != http.MethodPost {
+		writeGatewayError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+		return
+	}
+	command := mux.Vars(r)["command"]
+	decl, ok := checkGatewayCommand(w, tokenInfo, command)
+	if !ok {
+		return
+	}
+	if decl.CommandType != wshrpc.RpcType_Call {
+		writeGatewayError(w, http.StatusBadRequest, fmt.Errorf("%q is a streaming command, use /api/v1/stream/%s", command, command))
+		return
+	}
+	data, err := readGatewayData(r, command)
+	if err != nil {
+		writeGatewayError(w, http.StatusBadRequest, err)
+		return
+	}
+	// restricted, so the wshserver applies the same checks as for other scoped links (protected meta, etc.)
+	resp, err := wshclient.GetBareRpcClient().SendRpcRequest(command, data, &wshrpc.RpcOpts{Timeout: gatewayRpcTimeoutMs, Restricted: true})
+	if err != nil {
+		writeGatewayError(w, http.StatusBadGateway, err)
+		return
+	}
+	WriteJsonSuccess(w, resp)
+}
+
+func handleGatewayStream(w http.ResponseWriter, r *http.Request, tokenInfo *wshrpc.ApiTokenInfo) {
+	command := mux.Vars(r)["command"]
+	decl, ok := checkGatewayCommand(w, tokenInfo, command)
+	if !ok {
+		return
+	}
+	if decl.CommandType != wshrpc.RpcType_ResponseStream {
+		writeGatewayError(w, http.StatusBadRequest, fmt.Errorf("%q is not a streaming command, use /api/v1/rpc/%s", command, command))
+		return
+	}
+	data, err := readGatewayData(r, command)
+	if err != nil {
+		writeGatewayError(w, http.StatusBadRequest, err)
+		return
+	}
+	reqHandler, err := wshclient.GetBareRpcClient().SendComplexRequest(command, data, &wshrpc.RpcOpts{Timeout: gatewayRpcTimeoutMs, Restricted: true})
+	if err != nil {
+		writeGatewayError(w, http.StatusBadGateway, err)
+		return
+	}
+	sseHandler := sse.MakeSSEHandlerCh(w, r.Context())
+	defer sseHandler.Close()
+	if err := sseHandler.SetupSSE(); err != nil {
+		reqHandler.SendCancel(context.Background())
+		return
+	}
+	for !reqHandler.ResponseDone() {
+		if r.Context().Err() != nil {
+			reqHandler.SendCancel(context.Background())
+			return
+		}
+		resp, err := reqHandler.NextResponse()
+		if err != nil {
+			sseHandler.WriteEvent("error", err.Error())
+			return
+		}
+		if err := sseHandler.WriteJsonData(resp); err != nil {
+			reqHandler.SendCancel(context.Background())
+			return
+		}
+	}
+}
+
+func handleGatewayEvents(w http.ResponseWriter, r *http.Request, tokenInfo *wshrpc.ApiTokenInfo) {
+	if _, ok := checkGatewayCommand(w, tokenInfo, "eventsub"); !ok {
+		return
+	}
+	eventName := r.URL.Query().Get("event")
+	if eventName == "" {
+		writeGatewayError(w, http.StatusBadRequest, errors.New("event query parameter is required"))
+		return
+	}
+	sub := &gatewayEventSub{
+		Event:  eventName,
+		Scopes: r.URL.Query()["scope"],
+		Ch:     make(chan wps.WaveEvent, gatewayEventChSize),
+	}
+	sseHandler := sse.MakeSSEHandlerCh(w, r.Context())
+	defer sseHandler.Close()
+	if err := sseHandler.SetupSSE(); err != nil {
+		return
+	}
+	subId := gatewayObserver.addSub(sub)
+	defer gatewayObserver.removeSub(subId)
+	for {
+		select {
+		case event := <-sub.Ch:
+			if err := sseHandler.WriteJsonData(event); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// blocking
+func RunGatewayServer(listener net.Listener) {
+	wps.Broker.AddEventObserver(gatewayObserver)
+	gr := mux.NewRouter()
+	gr.HandleFunc("/api/v1/commands", gatewayFnWrap(handleGatewayCommands)).Methods(http.MethodGet)
+	gr.HandleFunc("/api/v1/rpc/{command}", gatewayFnWrap(handleGatewayRpc))
+	gr.HandleFunc("/api/v1/stream/{command}", gatewayFnWrap(handleGatewayStream)).Methods(http.MethodGet, http.MethodPost)
+	gr.HandleFunc("/api/v1/events", gatewayFnWrap(handleGatewayEvents)).Methods(http.MethodGet)
+	server := &http.Server{
+		ReadTimeout:    HttpReadTimeout,
+		MaxHeaderBytes: HttpMaxHeaderBytes,
+		Handler:        gr,
+	}
+	err := server.Serve(listener)
+	if err != nil {
+		log.Printf("ERROR [gateway]: %v\n", err)
+	}
+}
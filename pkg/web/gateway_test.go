@@ -0,0 +1,73 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SalyyS1/SLTerm/pkg/apitoken"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshserver"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// a root router with the wshserver on the default route (like wavesrv) and real stores in a temp data dir
+func initTestGateway(t *testing.T) {
+	wavebase.DataHome_VarCache = t.TempDir()
+	wavebase.ConfigHome_VarCache = t.TempDir()
+	err := os.MkdirAll(filepath.Join(wavebase.GetWaveDataDir(), wavebase.WaveDBDir), 0700)
+	if err != nil {
+		t.Fatalf("creating db dir: %v", err)
+	}
+	if err := wstore.InitWStore(); err != nil {
+		t.Fatalf("initializing wstore: %v", err)
+	}
+	wshutil.DefaultRouter = wshutil.NewWshRouter()
+	wshutil.DefaultRouter.SetAsRootRouter()
+	wshutil.DefaultRouter.RegisterTrustedLeaf(wshserver.GetMainRpcClient(), wshutil.DefaultRoute)
+}
+
+func gatewayPost(t *testing.T, token string, command string, body string) *httptest.ResponseRecorder {
+	gr := mux.NewRouter()
+	gr.HandleFunc("/api/v1/rpc/{command}", gatewayFnWrap(handleGatewayRpc))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rpc/"+command, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	gr.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGatewayCallsAreRestricted(t *testing.T) {
+	initTestGateway(t)
+	tokenData, err := apitoken.Create(context.Background(), wshrpc.CommandApiTokenCreateData{Name: "meta", Commands: []string{"setmeta", "setconfig"}})
+	if err != nil {
+		t.Fatalf("creating token: %v", err)
+	}
+	blockORef := "block:" + uuid.New().String()
+	tests := []struct {
+		name    string
+		command string
+		body    string
+	}{
+		{"setmeta cmd", "setmeta", `{"oref": "` + blockORef + `", "meta": {"cmd": "touch /tmp/pwned"}}`},
+		{"setmeta controller", "setmeta", `{"oref": "` + blockORef + `", "meta": {"controller": "cmd"}}`},
+		{"setconfig shell", "setconfig", `{"term:localshellpath": "/tmp/pwned"}`},
+	}
+	for _, tc := range tests {
+		rec := gatewayPost(t, tokenData.Token, tc.command, tc.body)
+		if rec.Code == http.StatusOK || !strings.Contains(rec.Body.String(), "restricted link") {
+			t.Errorf("%s: got %d %s, want a restricted link error", tc.name, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/SalyyS1/SLTerm/pkg/authkey"
+	"github.com/SalyyS1/SLTerm/pkg/blockcontroller"
+	"github.com/SalyyS1/SLTerm/pkg/filestore"
+	"github.com/SalyyS1/SLTerm/pkg/jobcontroller"
+	"github.com/SalyyS1/SLTerm/pkg/remote/conncontroller"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
+	"github.com/SalyyS1/SLTerm/pkg/wslconn"
+)
+
+// GET /metrics serves wavesrv internals in the prometheus text exposition format.  it is off unless
+// "debug:metrics" is set, and needs the server's auth key (X-AuthKey header or "Authorization: Bearer <authkey>").
+
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type promWriter struct {
+	buf      strings.Builder
+	declared map[string]bool
+}
+
+func promEscapeLabel(val string) string {
+	val = strings.ReplaceAll(val, `\`, `\\`)
+	val = strings.ReplaceAll(val, "\n", `\n`)
+	return strings.ReplaceAll(val, `"`, `\"`)
+}
+
+// labels are name/value pairs
+func (pw *promWriter) write(name string, metricType string, help string, value any, labels ...string) {
+	family := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(name, "_bucket"), "_sum"), "_count")
+	if metricType != "histogram" {
+		family = name
+	}
+	if !pw.declared[family] {
+		pw.declared[family] = true
+		fmt.Fprintf(&pw.buf, "# HELP %s %s\n# TYPE %s %s\n", family, help, family, metricType)
+	}
+	pw.buf.WriteString(name)
+	if len(labels) > 0 {
+		var parts []string
+		for i := 0; i+1 < len(labels); i += 2 {
+			parts = append(parts, fmt.Sprintf("%s=\"%s\"", labels[i], promEscapeLabel(labels[i+1])))
+		}
+		pw.buf.WriteString("{" + strings.Join(parts, ",") + "}")
+	}
+	fmt.Fprintf(&pw.buf, " %v\n", value)
+}
+
+func (pw *promWriter) gauge(name string, help string, value any, labels ...string) {
+	pw.write(name, "gauge", help, value, labels...)
+}
+
+func (pw *promWriter) counter(name string, help string, value any, labels ...string) {
+	pw.write(name, "counter", help, value, labels...)
+}
+
+func sortedCountKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeFileStoreMetrics(pw *promWriter) {
+	stats := filestore.WFS.GetCacheStats()
+	pw.gauge("slterm_filestore_cache_entries", "files held in the filestore write cache", stats.NumEntries)
+	pw.gauge("slterm_filestore_cache_parts", "data parts held in the filestore write cache", stats.NumDataParts)
+	pw.counter("slterm_filestore_flushes_total", "filestore flush cycles", stats.NumFlushes)
+	pw.counter("slterm_filestore_flush_committed_total", "cache entries committed to the db by the flusher", stats.NumCommitted)
+	pw.counter("slterm_filestore_flush_errors_total", "cache entry flush errors", stats.FlushErrors)
+	pw.gauge("slterm_filestore_last_flush_seconds", "duration of the last flush", stats.LastFlush.FlushDuration.Seconds())
+	pw.gauge("slterm_filestore_last_flush_dirty_entries", "dirty entries seen by the last flush", stats.LastFlush.NumDirtyEntries)
+}
+
+func writeControllerMetrics(pw *promWriter) {
+	statusCounts := blockcontroller.GetControllerStatusCounts()
+	for _, status := range sortedCountKeys(statusCounts) {
+		pw.gauge("slterm_block_controllers", "block controllers by shell process status", statusCounts[status], "status", status)
+	}
+	pw.gauge("slterm_jobs_running", "durable jobs with a running job manager", jobcontroller.GetNumJobsRunning())
+	pw.gauge("slterm_jobs_connected", "durable jobs with a connected job manager", jobcontroller.GetNumJobsConnected())
+}
+
+func writeConnMetrics(pw *promWriter) {
+	counts := make(map[string]int)
+	for _, status := range conncontroller.GetAllConnStatus() {
+		connType := "ssh"
+		if conncontroller.IsTlsConnName(status.Connection) {
+			connType = "tls"
+		}
+		counts[connType+"\x00"+status.Status]++
+	}
+	for _, status := range wslconn.GetAllConnStatus() {
+		counts["wsl\x00"+status.Status]++
+	}
+	for _, key := range sortedCountKeys(counts) {
+		connType, status, _ := strings.Cut(key, "\x00")
+		pw.gauge("slterm_connections", "remote connections by type and status", counts[key], "type", connType, "status", status)
+	}
+}
+
+func writeWpsMetrics(pw *promWriter) {
+	subCounts := wps.Broker.GetSubscriptionCounts()
+	for _, event := range sortedCountKeys(subCounts) {
+		pw.gauge("slterm_wps_subscriptions", "event subscriptions by event", subCounts[event], "event", event)
+	}
+}
+
+func writeRpcMetrics(pw *promWriter) {
+	// samples of a metric family must be contiguous, so each family gets its own loop
+	stats := wshutil.DefaultRouter.GetRpcStats(false)
+	for _, cs := range stats.Commands {
+		pw.counter("slterm_rpc_errors_total", "rpcs that returned an error", cs.Errors, "command", cs.Command)
+	}
+	for _, cs := range stats.Commands {
+		pw.counter("slterm_rpc_timeouts_total", "rpcs that timed out", cs.Timeouts, "command", cs.Command)
+	}
+	for _, cs := range stats.Commands {
+		pw.gauge("slterm_rpc_inflight", "rpcs waiting for a response", cs.InFlight+cs.Stale, "command", cs.Command)
+	}
+	for _, cs := range stats.Commands {
+		var cumulative int64
+		for idx, count := range cs.Latencies {
+			cumulative += count
+			le := "+Inf"
+			if idx < len(stats.LatencyBucketsMs) {
+				le = fmt.Sprintf("%g", float64(stats.LatencyBucketsMs[idx])/1000)
+			}
+			pw.write("slterm_rpc_duration_seconds_bucket", "histogram", "rpc latency through the router", cumulative, "command", cs.Command, "le", le)
+		}
+		pw.write("slterm_rpc_duration_seconds_sum", "histogram", "", float64(cs.TotalMs)/1000, "command", cs.Command)
+		pw.write("slterm_rpc_duration_seconds_count", "histogram", "", cs.Count, "command", cs.Command)
+	}
+	for _, ls := range stats.Links {
+		pw.counter("slterm_rpc_link_sent_bytes_total", "bytes sent to a router link", ls.BytesSent, "linkid", fmt.Sprint(ls.LinkId), "name", ls.Name)
+	}
+	for _, ls := range stats.Links {
+		pw.counter("slterm_rpc_link_recv_bytes_total", "bytes received from a router link", ls.BytesRecv, "linkid", fmt.Sprint(ls.LinkId), "name", ls.Name)
+	}
+	for _, ls := range stats.Links {
+		pw.gauge("slterm_rpc_link_backlog", "messages queued for a router link", ls.Backlog, "linkid", fmt.Sprint(ls.LinkId), "name", ls.Name)
+	}
+}
+
+func writeRuntimeMetrics(pw *promWriter) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	pw.gauge("go_goroutines", "number of goroutines", runtime.NumGoroutine())
+	pw.gauge("go_memstats_alloc_bytes", "bytes of allocated heap objects", mem.Alloc)
+	pw.gauge("go_memstats_heap_inuse_bytes", "bytes in in-use heap spans", mem.HeapInuse)
+	pw.gauge("go_memstats_heap_objects", "number of allocated heap objects", mem.HeapObjects)
+	pw.gauge("go_memstats_sys_bytes", "bytes obtained from the os", mem.Sys)
+	pw.counter("go_gc_cycles_total", "completed gc cycles", mem.NumGC)
+}
+
+func validateMetricsAuth(r *http.Request) error {
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		expected := authkey.GetAuthKey()
+		if expected == "" || subtle.ConstantTimeCompare([]byte(bearer), []byte(expected)) != 1 {
+			return fmt.Errorf("invalid authorization")
+		}
+		return nil
+	}
+	return authkey.ValidateIncomingRequest(r)
+}
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !wconfig.GetWatcher().GetFullConfig().Settings.DebugMetrics {
+		http.NotFound(w, r)
+		return
+	}
+	if err := validateMetricsAuth(r); err != nil {
+		http.Error(w, fmt.Sprintf("error validating authkey: %v", err), http.StatusUnauthorized)
+		return
+	}
+	pw := &promWriter{declared: make(map[string]bool)}
+	writeRuntimeMetrics(pw)
+	writeFileStoreMetrics(pw)
+	writeControllerMetrics(pw)
+	writeConnMetrics(pw)
+	writeWpsMetrics(pw)
+	writeRpcMetrics(pw)
+	w.Header().Set(ContentTypeHeaderKey, MetricsContentType)
+	w.Header().Set(CacheControlHeaderKey, CacheControlHeaderNoCache)
+	w.Write([]byte(pw.buf.String()))
+}
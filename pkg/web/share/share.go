@@ -0,0 +1,385 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+// terminal sharing: a share exposes a block's live output to viewers through a tokenized link
+// (http://<host>:<port>/share/<shareid>?t=<token>).  the link serves a small bundled viewer page which
+// connects back over a websocket, receives the current contents of the term blockfile and then every
+// append (the same blockfile events the frontend gets).  shares are read-only unless started with write
+// access, even then each viewer has to ask and the request has to be approved through userinput.
+// the share listener only runs while there are active shares.  it listens on loopback unless
+// "share:listen" says otherwise (e.g. ":0" to share on the network), the link is plain http with the
+// token in the url, so anyone who can see the traffic can watch (only open it to networks you trust).
+// shares expire (default one hour) and are stopped when their block is closed.  nothing is persisted,
+// shares end when wavesrv exits.
+package share
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/filestore"
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wconfig"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+	"github.com/google/uuid"
+)
+
+const DefaultListen = "127.0.0.1:0"
+const DefaultExpiry = time.Hour
+const MaxViewersPerShare = 20
+
+const shareTokenBytes = 24
+
+type share struct {
+	lock        *sync.Mutex
+	info        wshrpc.ShareInfo
+	token       string
+	viewers     map[string]*viewer
+	timer       *time.Timer
+	doneCh      chan struct{} // closed when the share is stopped
+	closeReason string
+}
+
+type shareManager struct {
+	lock      *sync.Mutex
+	shares    map[string]*share // keyed by share id
+	numShares atomic.Int32      // so the event observer can return early
+	server    *http.Server
+	baseUrl   string
+}
+
+var globalManager = &shareManager{
+	lock:   &sync.Mutex{},
+	shares: make(map[string]*share),
+}
+
+func InitShares() {
+	wps.Broker.AddEventObserver(globalManager)
+}
+
+func makeShareToken() (string, error) {
+	barr := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(barr); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(barr), nil
+}
+
+// the host put in share links: "share:host", the listen address if it is a specific ip, or the first
+// non-loopback ipv4 address of an interface that is up
+func getShareHost(listenAddr net.Addr, hostOverride string) string {
+	if hostOverride != "" {
+		return hostOverride
+	}
+	if tcpAddr, ok := listenAddr.(*net.TCPAddr); ok && tcpAddr.IP != nil && !tcpAddr.IP.IsUnspecified() {
+		return tcpAddr.IP.String()
+	}
+	ifaces, _ := net.Interfaces()
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if ok && ipNet.IP.To4() != nil && !ipNet.IP.IsLoopback() && !ipNet.IP.IsLinkLocalUnicast() {
+				return ipNet.IP.String()
+			}
+		}
+	}
+	return "localhost"
+}
+
+// starts the share listener if it isn't running, must hold m.lock
+func (m *shareManager) ensureServerLocked() error {
+	if m.server != nil {
+		return nil
+	}
+	settings := wconfig.GetWatcher().GetFullConfig().Settings
+	listenAddr := settings.ShareListen
+	if listenAddr == "" {
+		listenAddr = DefaultListen
+	}
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("error creating share listener at %v: %w", listenAddr, err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	m.baseUrl = "http://" + net.JoinHostPort(getShareHost(listener.Addr(), settings.ShareHost), port)
+	m.server = makeShareServer()
+	log.Printf("Server [share] listening on %s\n", listener.Addr())
+	go func(server *http.Server) {
+		defer func() {
+			panichandler.PanicHandler("share:server", recover())
+		}()
+		err := server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			log.Printf("ERROR [share]: %v\n", err)
+		}
+	}(m.server)
+	return nil
+}
+
+func (m *shareManager) getShare(shareId string) *share {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.shares[shareId]
+}
+
+func (m *shareManager) getBlockShares(blockId string) []*share {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var rtn []*share
+	for _, sh := range m.shares {
+		if sh.info.BlockId == blockId {
+			rtn = append(rtn, sh)
+		}
+	}
+	return rtn
+}
+
+func StartShare(ctx context.Context, data wshrpc.CommandShareStartData) (*wshrpc.ShareInfo, error) {
+	block, err := wstore.DBMustGet[*waveobj.Block](ctx, data.BlockId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block %s: %w", data.BlockId, err)
+	}
+	if block.Meta.GetString(waveobj.MetaKey_View, "") != "term" {
+		return nil, fmt.Errorf("only terminal blocks can be shared")
+	}
+	expiry := DefaultExpiry
+	if data.ExpiresSecs > 0 {
+		expiry = time.Duration(data.ExpiresSecs) * time.Second
+	} else if data.ExpiresSecs < 0 {
+		expiry = 0
+	}
+	token, err := makeShareToken()
+	if err != nil {
+		return nil, fmt.Errorf("error generating share token: %w", err)
+	}
+	now := time.Now()
+	sh := &share{
+		lock: &sync.Mutex{},
+		info: wshrpc.ShareInfo{
+			ShareId:   uuid.New().String(),
+			BlockId:   data.BlockId,
+			Writable:  data.Writable,
+			CreatedTs: now.UnixMilli(),
+		},
+		token:   token,
+		viewers: make(map[string]*viewer),
+		doneCh:  make(chan struct{}),
+	}
+	globalManager.lock.Lock()
+	err = globalManager.ensureServerLocked()
+	if err != nil {
+		globalManager.lock.Unlock()
+		return nil, err
+	}
+	sh.info.Url = fmt.Sprintf("%s/share/%s?t=%s", globalManager.baseUrl, sh.info.ShareId, token)
+	if expiry > 0 {
+		sh.info.ExpiresTs = now.Add(expiry).UnixMilli()
+		shareId := sh.info.ShareId
+		sh.timer = time.AfterFunc(expiry, func() {
+			stopShare(shareId, "the share expired")
+		})
+	}
+	globalManager.shares[sh.info.ShareId] = sh
+	globalManager.numShares.Store(int32(len(globalManager.shares)))
+	globalManager.lock.Unlock()
+	log.Printf("share %s started for block %s (writable:%v expires:%v)\n", sh.info.ShareId, data.BlockId, data.Writable, expiry)
+	publishShareStatus(data.BlockId)
+	rtn := sh.getInfo()
+	return &rtn, nil
+}
+
+// removes the share, disconnects its viewers and stops the listener if it was the last share
+func stopShare(shareId string, reason string) bool {
+	globalManager.lock.Lock()
+	sh := globalManager.shares[shareId]
+	if sh == nil {
+		globalManager.lock.Unlock()
+		return false
+	}
+	delete(globalManager.shares, shareId)
+	globalManager.numShares.Store(int32(len(globalManager.shares)))
+	if len(globalManager.shares) == 0 && globalManager.server != nil {
+		globalManager.server.Close()
+		globalManager.server = nil
+		log.Printf("Server [share] stopped\n")
+	}
+	globalManager.lock.Unlock()
+	sh.stop(reason)
+	log.Printf("share %s stopped: %s\n", shareId, reason)
+	publishShareStatus(sh.info.BlockId)
+	return true
+}
+
+// stops one share (data.ShareId) or all of a block's shares (data.BlockId), returns the number stopped
+func StopShares(data wshrpc.CommandShareStopData) (int, error) {
+	if data.ShareId != "" {
+		if !stopShare(data.ShareId, "the share was stopped") {
+			return 0, fmt.Errorf("share %q not found", data.ShareId)
+		}
+		return 1, nil
+	}
+	if data.BlockId == "" {
+		return 0, fmt.Errorf("no share or block specified")
+	}
+	return stopBlockShares(data.BlockId, "the share was stopped"), nil
+}
+
+func stopBlockShares(blockId string, reason string) int {
+	var numStopped int
+	for _, sh := range globalManager.getBlockShares(blockId) {
+		if stopShare(sh.info.ShareId, reason) {
+			numStopped++
+		}
+	}
+	return numStopped
+}
+
+// lists the active shares (of one block if blockId is set), oldest first
+func ListShares(blockId string) []wshrpc.ShareInfo {
+	globalManager.lock.Lock()
+	var shares []*share
+	for _, sh := range globalManager.shares {
+		if blockId == "" || sh.info.BlockId == blockId {
+			shares = append(shares, sh)
+		}
+	}
+	globalManager.lock.Unlock()
+	rtn := make([]wshrpc.ShareInfo, 0, len(shares))
+	for _, sh := range shares {
+		rtn = append(rtn, sh.getInfo())
+	}
+	sort.Slice(rtn, func(i, j int) bool {
+		return rtn[i].CreatedTs < rtn[j].CreatedTs
+	})
+	return rtn
+}
+
+// tells the frontend how many shares and viewers a block has (shown in the term header)
+func publishShareStatus(blockId string) {
+	status := wshrpc.ShareStatusEventData{BlockId: blockId}
+	for _, sh := range globalManager.getBlockShares(blockId) {
+		status.Shares++
+		status.Viewers += sh.getInfo().Viewers
+	}
+	wps.Broker.Publish(wps.WaveEvent{
+		Event:  wps.Event_ShareStatus,
+		Scopes: []string{waveobj.MakeORef(waveobj.OType_Block, blockId).String()},
+		Data:   status,
+	})
+}
+
+// the term file's size right after an append, -1 if it can't be read.  the term file is only appended
+// to by the block controller, which publishes each append before writing the next one.
+func getTermEndPos(blockId string) int64 {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelFn()
+	file, err := filestore.WFS.Stat(ctx, blockId, wavebase.BlockFile_Term)
+	if err != nil {
+		return -1
+	}
+	return file.Size
+}
+
+func (sh *share) getInfo() wshrpc.ShareInfo {
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	rtn := sh.info
+	rtn.Viewers = len(sh.viewers)
+	return rtn
+}
+
+func (sh *share) addViewer(v *viewer) error {
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	if len(sh.viewers) >= MaxViewersPerShare {
+		return fmt.Errorf("too many viewers (max %d)", MaxViewersPerShare)
+	}
+	sh.viewers[v.Id] = v
+	return nil
+}
+
+func (sh *share) removeViewer(viewerId string) {
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	delete(sh.viewers, viewerId)
+}
+
+func (sh *share) broadcast(msg shareMessage) {
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	for _, v := range sh.viewers {
+		v.send(msg)
+	}
+}
+
+func (sh *share) stop(reason string) {
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	if sh.timer != nil {
+		sh.timer.Stop()
+	}
+	sh.closeReason = reason
+	close(sh.doneCh)
+}
+
+func (sh *share) getCloseReason() string {
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	return sh.closeReason
+}
+
+// ObserveEvent is called synchronously from wps.Broker.Publish (from the pty read loops for blockfile
+// events).  output is handed to the viewers' (buffered) channels, a viewer that falls behind is disconnected.
+func (m *shareManager) ObserveEvent(event wps.WaveEvent) {
+	if m.numShares.Load() == 0 {
+		return
+	}
+	switch event.Event {
+	case wps.Event_BlockFile:
+		fileData, ok := event.Data.(*wps.WSFileEventData)
+		if !ok || fileData.FileName != wavebase.BlockFile_Term {
+			return
+		}
+		var msg shareMessage
+		switch fileData.FileOp {
+		case wps.FileOp_Append:
+			msg = shareMessage{Type: shareMsg_Output, Data64: fileData.Data64, EndPos: getTermEndPos(fileData.ZoneId)}
+		case wps.FileOp_Truncate:
+			msg = shareMessage{Type: shareMsg_Clear}
+		default:
+			return
+		}
+		for _, sh := range m.getBlockShares(fileData.ZoneId) {
+			sh.broadcast(msg)
+		}
+	case wps.Event_BlockClose:
+		blockId, ok := event.Data.(string)
+		if !ok {
+			return
+		}
+		// can't publish (share status) from inside of Publish
+		go func() {
+			defer func() {
+				panichandler.PanicHandler("share:blockClose", recover())
+			}()
+			stopBlockShares(blockId, "the terminal was closed")
+		}()
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package share
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/filestore"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+func TestGetShareHost(t *testing.T) {
+	specificAddr := &net.TCPAddr{IP: net.ParseIP("192.168.1.20"), Port: 4000}
+	anyAddr := &net.TCPAddr{IP: net.IPv4zero, Port: 4000}
+	if host := getShareHost(anyAddr, "devbox.lan"); host != "devbox.lan" {
+		t.Errorf("override: got %q", host)
+	}
+	if host := getShareHost(specificAddr, ""); host != "192.168.1.20" {
+		t.Errorf("specific listen address: got %q", host)
+	}
+	host := getShareHost(anyAddr, "")
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || ip.IsLoopback() || ip.IsUnspecified()) {
+		t.Errorf("unspecified listen address: got %q", host)
+	}
+}
+
+func TestMakeShareToken(t *testing.T) {
+	token1, err := makeShareToken()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token2, _ := makeShareToken()
+	if len(token1) != 32 || token1 == token2 {
+		t.Errorf("bad tokens %q %q", token1, token2)
+	}
+}
+
+func TestTrimSeenOutput(t *testing.T) {
+	makeMsg := func(data string, endPos int64) shareMessage {
+		return shareMessage{Type: shareMsg_Output, Data64: base64.StdEncoding.EncodeToString([]byte(data)), EndPos: endPos}
+	}
+	tests := []struct {
+		name     string
+		msg      shareMessage
+		skipPos  int64
+		wantKeep bool
+		want     string
+	}{
+		{"new", makeMsg("abc", 13), 10, true, "abc"},
+		{"seen", makeMsg("abc", 10), 10, false, ""},
+		{"overlap", makeMsg("abcd", 12), 10, true, "cd"},
+		{"unknown offset", makeMsg("abc", -1), 10, true, "abc"},
+	}
+	for _, tc := range tests {
+		msg := tc.msg
+		keep := trimSeenOutput(&msg, tc.skipPos)
+		data, _ := base64.StdEncoding.DecodeString(msg.Data64)
+		if keep != tc.wantKeep || (keep && string(data) != tc.want) {
+			t.Errorf("%s: got (%v, %q), want (%v, %q)", tc.name, keep, data, tc.wantKeep, tc.want)
+		}
+	}
+}
+
+// real stores in a temp data dir
+func initTestStores(t *testing.T) {
+	wavebase.DataHome_VarCache = t.TempDir()
+	wavebase.ConfigHome_VarCache = t.TempDir()
+	err := os.MkdirAll(filepath.Join(wavebase.GetWaveDataDir(), wavebase.WaveDBDir), 0700)
+	if err != nil {
+		t.Fatalf("creating db dir: %v", err)
+	}
+	if err := wstore.InitWStore(); err != nil {
+		t.Fatalf("initializing wstore: %v", err)
+	}
+	if err := filestore.InitFilestore(); err != nil {
+		t.Fatalf("initializing filestore: %v", err)
+	}
+}
+
+// registers a read-only share without starting the listener (the test serves makeShareServer itself)
+func addTestShare(t *testing.T, blockId string) *share {
+	sh := &share{
+		lock:    &sync.Mutex{},
+		info:    wshrpc.ShareInfo{ShareId: uuid.New().String(), BlockId: blockId},
+		token:   "test-token",
+		viewers: make(map[string]*viewer),
+		doneCh:  make(chan struct{}),
+	}
+	globalManager.lock.Lock()
+	globalManager.shares[sh.info.ShareId] = sh
+	globalManager.numShares.Store(int32(len(globalManager.shares)))
+	globalManager.lock.Unlock()
+	t.Cleanup(func() {
+		stopShare(sh.info.ShareId, "test done")
+	})
+	return sh
+}
+
+// appends to the term file, the event is published separately (so the test controls when it arrives)
+func appendTerm(t *testing.T, blockId string, data string) wps.WaveEvent {
+	err := filestore.WFS.AppendData(context.Background(), blockId, wavebase.BlockFile_Term, []byte(data))
+	if err != nil {
+		t.Fatalf("appending to term file: %v", err)
+	}
+	return wps.WaveEvent{
+		Event: wps.Event_BlockFile,
+		Data: &wps.WSFileEventData{
+			ZoneId:   blockId,
+			FileName: wavebase.BlockFile_Term,
+			FileOp:   wps.FileOp_Append,
+			Data64:   base64.StdEncoding.EncodeToString([]byte(data)),
+		},
+	}
+}
+
+func readShareMessage(t *testing.T, conn *websocket.Conn) shareMessage {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg shareMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("reading share message: %v", err)
+	}
+	return msg
+}
+
+func decodeData(t *testing.T, msg shareMessage) string {
+	data, err := base64.StdEncoding.DecodeString(msg.Data64)
+	if err != nil {
+		t.Fatalf("bad data64 in %s message: %v", msg.Type, err)
+	}
+	return string(data)
+}
+
+func TestShareWsRoundTrip(t *testing.T) {
+	initTestStores(t)
+	blockId := uuid.New().String()
+	err := filestore.WFS.MakeFile(context.Background(), blockId, wavebase.BlockFile_Term, nil, wshrpc.FileOpts{})
+	if err != nil {
+		t.Fatalf("making term file: %v", err)
+	}
+	appendTerm(t, blockId, "hello\r\n")
+	// written before the viewer reads the file, but its event is only delivered once the viewer is registered
+	lateEvent := appendTerm(t, blockId, "late\r\n")
+	sh := addTestShare(t, blockId)
+	srv := httptest.NewServer(makeShareServer().Handler)
+	defer srv.Close()
+
+	wsUrl := "ws" + strings.TrimPrefix(srv.URL, "http") + "/share/" + sh.info.ShareId + "/ws"
+	if _, _, err := websocket.DefaultDialer.Dial(wsUrl+"?t=wrong", nil); err == nil {
+		t.Fatalf("connected with a bad token")
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(wsUrl+"?t="+sh.token, nil)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	defer conn.Close()
+	initMsg := readShareMessage(t, conn)
+	if initMsg.Type != shareMsg_Init || decodeData(t, initMsg) != "hello\r\nlate\r\n" {
+		t.Fatalf("bad init message %q %q", initMsg.Type, decodeData(t, initMsg))
+	}
+
+	globalManager.ObserveEvent(lateEvent)
+	globalManager.ObserveEvent(appendTerm(t, blockId, "live\r\n"))
+	// the late event was in the init contents, so the next output is the live one
+	msg := readShareMessage(t, conn)
+	if msg.Type != shareMsg_Output || decodeData(t, msg) != "live\r\n" {
+		t.Fatalf("got %q %q, want the live output", msg.Type, decodeData(t, msg))
+	}
+
+	err = conn.WriteJSON(shareMessage{Type: viewerMsg_Input, Data64: base64.StdEncoding.EncodeToString([]byte("ls\r"))})
+	if err != nil {
+		t.Fatalf("sending input: %v", err)
+	}
+	if msg := readShareMessage(t, conn); msg.Type != shareMsg_Error {
+		t.Errorf("input to a read-only share got a %q message, want an error", msg.Type)
+	}
+
+	stopShare(sh.info.ShareId, "the share was stopped")
+	if msg := readShareMessage(t, conn); msg.Type != shareMsg_Closed || msg.Text != "the share was stopped" {
+		t.Errorf("got %q %q, want the closed message", msg.Type, msg.Text)
+	}
+}
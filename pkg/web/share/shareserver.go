@@ -0,0 +1,321 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package share
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/blockcontroller"
+	"github.com/SalyyS1/SLTerm/pkg/filestore"
+	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/userinput"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/waveobj"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+//go:embed viewer.html
+var viewerHtml []byte
+
+// server -> viewer messages
+const (
+	shareMsg_Init        = "init"        // data64 is the current terminal contents
+	shareMsg_Output      = "output"      // data64 is new terminal output
+	shareMsg_Clear       = "clear"       // the terminal was cleared
+	shareMsg_WriteAccess = "writeaccess" // answer to a requestwrite, see granted
+	shareMsg_Error       = "error"
+	shareMsg_Closed      = "closed" // the share was stopped (text is the reason)
+)
+
+// viewer -> server messages
+const (
+	viewerMsg_Input        = "input" // data64 is the input, only accepted after write access was granted
+	viewerMsg_RequestWrite = "requestwrite"
+)
+
+const viewerChSize = 256
+const viewerMaxMessageSize = 64 * 1024
+const viewerWriteTimeout = 10 * time.Second
+const viewerPingInterval = 30 * time.Second
+const writeApprovalTimeout = 60 * time.Second
+const shareMaxHeaderBytes = 64 * 1024
+
+type shareMessage struct {
+	Type     string `json:"type"`
+	Data64   string `json:"data64,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Writable bool   `json:"writable,omitempty"` // init: the share allows write access requests
+	Granted  bool   `json:"granted,omitempty"`
+	EndPos   int64  `json:"-"` // output: term file offset at the end of the output (-1 if unknown)
+}
+
+type viewer struct {
+	Id           string
+	RemoteAddr   string
+	Conn         *websocket.Conn
+	Ch           chan shareMessage
+	CloseCh      chan struct{}
+	closeOnce    sync.Once
+	CanWrite     atomic.Bool
+	RequestingWr atomic.Bool
+}
+
+var shareUpgrader = websocket.Upgrader{
+	ReadBufferSize:   4 * 1024,
+	WriteBufferSize:  32 * 1024,
+	HandshakeTimeout: 5 * time.Second,
+	// the default CheckOrigin only accepts the viewer page served by this listener
+}
+
+func makeShareServer() *http.Server {
+	gr := mux.NewRouter()
+	gr.HandleFunc("/share/{shareid}", handleSharePage).Methods(http.MethodGet)
+	gr.HandleFunc("/share/{shareid}/ws", handleShareWs).Methods(http.MethodGet)
+	return &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		MaxHeaderBytes:    shareMaxHeaderBytes,
+		Handler:           gr,
+	}
+}
+
+// returns the share if the request has the share's token, otherwise writes a 404 (an unknown share and
+// a bad token look the same)
+func getRequestShare(w http.ResponseWriter, r *http.Request) *share {
+	sh := globalManager.getShare(mux.Vars(r)["shareid"])
+	token := r.URL.Query().Get("t")
+	if sh == nil || subtle.ConstantTimeCompare([]byte(token), []byte(sh.token)) != 1 {
+		http.Error(w, "share not found (it may have expired or been stopped)", http.StatusNotFound)
+		return nil
+	}
+	return sh
+}
+
+func handleSharePage(w http.ResponseWriter, r *http.Request) {
+	if getRequestShare(w, r) == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	// the token is in the url, don't leak it
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self' ws: wss:")
+	w.Write(viewerHtml)
+}
+
+func handleShareWs(w http.ResponseWriter, r *http.Request) {
+	sh := getRequestShare(w, r)
+	if sh == nil {
+		return
+	}
+	conn, err := shareUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	v := &viewer{
+		Id:         uuid.New().String(),
+		RemoteAddr: r.RemoteAddr,
+		Conn:       conn,
+		Ch:         make(chan shareMessage, viewerChSize),
+		CloseCh:    make(chan struct{}),
+	}
+	// register before reading the terminal contents so no output is missed, output that is already in
+	// the contents is skipped by the writeLoop (using the file offsets)
+	err = sh.addViewer(v)
+	if err != nil {
+		v.writeMessage(shareMessage{Type: shareMsg_Error, Text: err.Error()})
+		return
+	}
+	defer func() {
+		sh.removeViewer(v.Id)
+		publishShareStatus(sh.info.BlockId)
+	}()
+	publishShareStatus(sh.info.BlockId)
+	ctx, cancelFn := context.WithTimeout(r.Context(), 5*time.Second)
+	termOffset, termData, err := filestore.WFS.ReadFile(ctx, sh.info.BlockId, wavebase.BlockFile_Term)
+	cancelFn()
+	if err != nil {
+		log.Printf("share %s: error reading terminal contents: %v\n", sh.info.ShareId, err)
+	}
+	initEndPos := termOffset + int64(len(termData))
+	err = v.writeMessage(shareMessage{
+		Type:     shareMsg_Init,
+		Data64:   base64.StdEncoding.EncodeToString(termData),
+		Text:     getShareTitle(sh.info.BlockId),
+		Writable: sh.info.Writable,
+	})
+	if err != nil {
+		return
+	}
+	go v.writeLoop(sh, initEndPos)
+	v.readLoop(sh)
+}
+
+// drops the part of an output message that ends at or before skipPos (it was in the init contents).
+// returns false if nothing is left.
+func trimSeenOutput(msg *shareMessage, skipPos int64) bool {
+	if msg.EndPos < 0 || msg.EndPos > skipPos+int64(base64.StdEncoding.DecodedLen(len(msg.Data64))) {
+		return true
+	}
+	if msg.EndPos <= skipPos {
+		return false
+	}
+	data, err := base64.StdEncoding.DecodeString(msg.Data64)
+	if err != nil {
+		return true
+	}
+	startPos := msg.EndPos - int64(len(data))
+	if startPos >= skipPos {
+		return true
+	}
+	msg.Data64 = base64.StdEncoding.EncodeToString(data[skipPos-startPos:])
+	return true
+}
+
+func getShareTitle(blockId string) string {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelFn()
+	block, _ := wstore.DBGet[*waveobj.Block](ctx, blockId)
+	connName := "local"
+	if block != nil && block.Meta.GetString(waveobj.MetaKey_Connection, "") != "" {
+		connName = block.Meta.GetString(waveobj.MetaKey_Connection, "")
+	}
+	return fmt.Sprintf("terminal %s (%s)", blockId[:8], connName)
+}
+
+// queues a message, a viewer that can't keep up is disconnected
+func (v *viewer) send(msg shareMessage) {
+	select {
+	case v.Ch <- msg:
+	default:
+		v.close()
+	}
+}
+
+func (v *viewer) close() {
+	v.closeOnce.Do(func() {
+		close(v.CloseCh)
+		v.Conn.Close()
+	})
+}
+
+func (v *viewer) writeMessage(msg shareMessage) error {
+	v.Conn.SetWriteDeadline(time.Now().Add(viewerWriteTimeout))
+	return v.Conn.WriteJSON(msg)
+}
+
+// the only goroutine that writes to the connection (after the init message).  output that ends before
+// skipPos was already sent with the init message.
+func (v *viewer) writeLoop(sh *share, skipPos int64) {
+	defer func() {
+		panichandler.PanicHandler("share:viewerWriteLoop", recover())
+	}()
+	defer v.close()
+	ticker := time.NewTicker(viewerPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg := <-v.Ch:
+			if msg.Type == shareMsg_Clear {
+				skipPos = 0
+			} else if msg.Type == shareMsg_Output && !trimSeenOutput(&msg, skipPos) {
+				continue
+			}
+			if err := v.writeMessage(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			err := v.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(viewerWriteTimeout))
+			if err != nil {
+				return
+			}
+		case <-sh.doneCh:
+			v.writeMessage(shareMessage{Type: shareMsg_Closed, Text: sh.getCloseReason()})
+			v.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+			return
+		case <-v.CloseCh:
+			return
+		}
+	}
+}
+
+func (v *viewer) readLoop(sh *share) {
+	defer v.close()
+	v.Conn.SetReadLimit(viewerMaxMessageSize)
+	for {
+		var msg shareMessage
+		if err := v.Conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case viewerMsg_Input:
+			if !v.CanWrite.Load() {
+				v.send(shareMessage{Type: shareMsg_Error, Text: "you do not have write access"})
+				continue
+			}
+			inputData, err := base64.StdEncoding.DecodeString(msg.Data64)
+			if err != nil || len(inputData) == 0 {
+				continue
+			}
+			// SendInput (not SendUserInput), viewer input isn't copied to the block's input group
+			err = blockcontroller.SendInput(sh.info.BlockId, &blockcontroller.BlockInputUnion{InputData: inputData})
+			if err != nil {
+				v.send(shareMessage{Type: shareMsg_Error, Text: fmt.Sprintf("error sending input: %v", err)})
+			}
+		case viewerMsg_RequestWrite:
+			if !sh.info.Writable {
+				v.send(shareMessage{Type: shareMsg_WriteAccess, Granted: false, Text: "this share is read-only"})
+				continue
+			}
+			if v.CanWrite.Load() || !v.RequestingWr.CompareAndSwap(false, true) {
+				continue
+			}
+			go v.requestWriteAccess(sh)
+		}
+	}
+}
+
+// asks the user (userinput confirm) whether the viewer may send input to the terminal
+func (v *viewer) requestWriteAccess(sh *share) {
+	defer func() {
+		panichandler.PanicHandler("share:requestWriteAccess", recover())
+	}()
+	defer v.RequestingWr.Store(false)
+	viewerHost, _, err := net.SplitHostPort(v.RemoteAddr)
+	if err != nil {
+		viewerHost = v.RemoteAddr
+	}
+	request := &userinput.UserInputRequest{
+		ResponseType: "confirm",
+		Title:        "Shared Terminal Write Access",
+		QueryText:    fmt.Sprintf("A viewer at %s is asking to type into the shared %s.\n\nAllow them to send input?", viewerHost, getShareTitle(sh.info.BlockId)),
+		OkLabel:      "Allow",
+		CancelLabel:  "Deny",
+	}
+	ctx, cancelFn := context.WithTimeout(context.Background(), writeApprovalTimeout)
+	defer cancelFn()
+	resp, err := userinput.GetUserInput(ctx, request)
+	granted := err == nil && resp != nil && resp.Confirm
+	log.Printf("share %s: write access for viewer at %s granted:%v\n", sh.info.ShareId, viewerHost, granted)
+	if granted {
+		v.CanWrite.Store(true)
+		v.send(shareMessage{Type: shareMsg_WriteAccess, Granted: true})
+		return
+	}
+	v.send(shareMessage{Type: shareMsg_WriteAccess, Granted: false, Text: "write access was denied"})
+}
@@ -492,6 +492,7 @@ func RunWebServer(listener net.Listener) {
 	// AI routes removed - SLTerm
 
 	// Other routes without timeout
+	gr.HandleFunc("/metrics", handleMetrics)
 	gr.PathPrefix(schemaPrefix).Handler(http.StripPrefix(schemaPrefix, schema.GetSchemaHandler()))
 
 	handler := http.Handler(gr)
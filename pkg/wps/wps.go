@@ -7,6 +7,7 @@ package wps
 import (
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
 	"github.com/SalyyS1/SLTerm/pkg/waveobj"
@@ -21,6 +22,12 @@ type Client interface {
 	SendEvent(routeId string, event WaveEvent)
 }
 
+// EventObserver sees every published event (e.g. the durable event log, automation rules).
+// ObserveEvent is called synchronously from Publish and must not block.
+type EventObserver interface {
+	ObserveEvent(event WaveEvent)
+}
+
 type BrokerSubscription struct {
 	AllSubs   []string            // routeids subscribed to "all" events
 	ScopeSubs map[string][]string // routeids subscribed to specific scopes
@@ -39,6 +46,7 @@ type persistEventWrap struct {
 type BrokerType struct {
 	Lock       *sync.Mutex
 	Client     Client
+	Observers  []EventObserver
 	SubMap     map[string]*BrokerSubscription
 	PersistMap map[persistKey]*persistEventWrap
 }
@@ -59,6 +67,24 @@ func scopeHasStarMatch(scope string) bool {
 	return false
 }
 
+// number of subscribed routes per event (for the /metrics endpoint)
+func (b *BrokerType) GetSubscriptionCounts() map[string]int {
+	b.Lock.Lock()
+	defer b.Lock.Unlock()
+	rtn := make(map[string]int)
+	for event, bs := range b.SubMap {
+		count := len(bs.AllSubs)
+		for _, routeIds := range bs.ScopeSubs {
+			count += len(routeIds)
+		}
+		for _, routeIds := range bs.StarSubs {
+			count += len(routeIds)
+		}
+		rtn[event] = count
+	}
+	return rtn
+}
+
 func (b *BrokerType) SetClient(client Client) {
 	b.Lock.Lock()
 	defer b.Lock.Unlock()
@@ -71,6 +97,18 @@ func (b *BrokerType) GetClient() Client {
 	return b.Client
 }
 
+func (b *BrokerType) AddEventObserver(observer EventObserver) {
+	b.Lock.Lock()
+	defer b.Lock.Unlock()
+	b.Observers = append(b.Observers, observer)
+}
+
+func (b *BrokerType) getEventObservers() []EventObserver {
+	b.Lock.Lock()
+	defer b.Lock.Unlock()
+	return b.Observers
+}
+
 // if already subscribed, this will *resubscribe* with the new subscription (remove the old one, and replace with this one)
 func (b *BrokerType) Subscribe(subRouteId string, sub SubscriptionRequest) {
 	// log.Printf("[wps] sub %s %s\n", subRouteId, sub.Event)
@@ -225,9 +263,15 @@ func (b *BrokerType) persistEvent(event WaveEvent) {
 
 func (b *BrokerType) Publish(event WaveEvent) {
 	// log.Printf("BrokerType.Publish: %v\n", event)
+	if event.Ts == 0 {
+		event.Ts = time.Now().UnixMilli()
+	}
 	if event.Persist > 0 {
 		b.persistEvent(event)
 	}
+	for _, observer := range b.getEventObservers() {
+		observer.ObserveEvent(event)
+	}
 	client := b.GetClient()
 	if client == nil {
 		return
@@ -248,6 +292,11 @@ func (b *BrokerType) SendUpdateEvents(updates waveobj.UpdatesRtnType) {
 	}
 }
 
+// HasSubscribers returns true if publishing event would deliver it to at least one route
+func (b *BrokerType) HasSubscribers(event WaveEvent) bool {
+	return len(b.getMatchingRouteIds(event)) > 0
+}
+
 func (b *BrokerType) getMatchingRouteIds(event WaveEvent) []string {
 	b.Lock.Lock()
 	defer b.Lock.Unlock()
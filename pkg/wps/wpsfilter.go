@@ -0,0 +1,164 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wps
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EventFilter is a small jq-like condition on an event, e.g.
+//
+//	.data.status==done   .data.exitcode!=0   .data.values.cpu>=90   .scopes[0]==block:123   .data.connected
+//
+// a bare path is a truthy check.  values are parsed as json, falling back to a plain string.
+type EventFilter struct {
+	Expr  string
+	Path  []string
+	Op    string // "", "==", "!=", ">", ">=", "<", "<="
+	Value any
+}
+
+func ParseEventFilter(expr string) (*EventFilter, error) {
+	filter := &EventFilter{Expr: expr}
+	pathStr := expr
+	if idx := strings.IndexAny(expr, "=!<>"); idx >= 0 {
+		op := expr[idx : idx+1]
+		if idx+1 < len(expr) && expr[idx+1] == '=' {
+			op = expr[idx : idx+2]
+		}
+		if op == "=" || op == "!" {
+			return nil, fmt.Errorf("invalid filter %q: unknown operator %q", expr, op)
+		}
+		pathStr = expr[:idx]
+		filter.Op = op
+		valStr := strings.TrimSpace(expr[idx+len(op):])
+		var val any
+		if err := json.Unmarshal([]byte(valStr), &val); err != nil {
+			val = valStr
+		}
+		filter.Value = val
+	}
+	path, err := ParseEventPath(pathStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", expr, err)
+	}
+	filter.Path = path
+	return filter, nil
+}
+
+// ParseEventPath splits ".data.items[0].name" into ["data", "items", "[0]", "name"]
+func ParseEventPath(pathStr string) ([]string, error) {
+	pathStr = strings.TrimSpace(pathStr)
+	if !strings.HasPrefix(pathStr, ".") {
+		return nil, fmt.Errorf("path must start with '.'")
+	}
+	pathStr = strings.ReplaceAll(pathStr, "[", ".[")
+	var path []string
+	for _, part := range strings.Split(pathStr, ".") {
+		if part == "" {
+			continue
+		}
+		path = append(path, part)
+	}
+	return path, nil
+}
+
+// EventToGeneric converts an event to plain json values (map[string]any, []any, float64, ...) for path lookups
+func EventToGeneric(event *WaveEvent) (any, error) {
+	barr, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	var rtn any
+	if err := json.Unmarshal(barr, &rtn); err != nil {
+		return nil, err
+	}
+	return rtn, nil
+}
+
+func LookupEventPath(val any, path []string) (any, bool) {
+	for _, part := range path {
+		if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
+			idx, err := strconv.Atoi(part[1 : len(part)-1])
+			arr, ok := val.([]any)
+			if err != nil || !ok || idx < 0 || idx >= len(arr) {
+				return nil, false
+			}
+			val = arr[idx]
+			continue
+		}
+		m, ok := val.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		val, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return val, true
+}
+
+func isTruthy(val any) bool {
+	switch v := val.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	}
+	return true
+}
+
+// Match evaluates the filter against the output of EventToGeneric
+func (f *EventFilter) Match(eventVal any) bool {
+	val, found := LookupEventPath(eventVal, f.Path)
+	switch f.Op {
+	case "==":
+		return found && reflect.DeepEqual(val, f.Value)
+	case "!=":
+		return !found || !reflect.DeepEqual(val, f.Value)
+	case ">", ">=", "<", "<=":
+		numVal, ok1 := val.(float64)
+		cmpVal, ok2 := f.Value.(float64)
+		if !found || !ok1 || !ok2 {
+			return false
+		}
+		switch f.Op {
+		case ">":
+			return numVal > cmpVal
+		case ">=":
+			return numVal >= cmpVal
+		case "<":
+			return numVal < cmpVal
+		default:
+			return numVal <= cmpVal
+		}
+	}
+	return found && isTruthy(val)
+}
+
+// MatchEventFilters returns true if all filters match (filters are and-ed)
+func MatchEventFilters(event *WaveEvent, filters []*EventFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	eventVal, err := EventToGeneric(event)
+	if err != nil {
+		return false
+	}
+	for _, filter := range filters {
+		if !filter.Match(eventVal) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wps
+
+import (
+	"testing"
+)
+
+func TestEventFilters(t *testing.T) {
+	event := &WaveEvent{
+		Event:  "block:jobstatus",
+		Scopes: []string{"block:123"},
+		Data: map[string]any{
+			"status":    "done",
+			"exitcode":  0,
+			"connected": true,
+			"values":    map[string]any{"cpu": 92.5},
+		},
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{".data.status==done", true},
+		{`.data.status=="done"`, true},
+		{".data.status==running", false},
+		{".data.status!=running", true},
+		{".data.exitcode==0", true},
+		{".data.exitcode!=0", false},
+		{".data.connected", true},
+		{".data.missing", false},
+		{".data.missing!=x", true},
+		{".scopes[0]==block:123", true},
+		{".scopes[1]==block:123", false},
+		{".data.values.cpu>90", true},
+		{".data.values.cpu>=92.5", true},
+		{".data.values.cpu<50", false},
+		{".data.values.cpu<=92.5", true},
+		{".data.status>1", false},
+	}
+	for _, tc := range tests {
+		filter, err := ParseEventFilter(tc.expr)
+		if err != nil {
+			t.Fatalf("ParseEventFilter(%q) error: %v", tc.expr, err)
+		}
+		if got := MatchEventFilters(event, []*EventFilter{filter}); got != tc.want {
+			t.Errorf("filter %q = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+	for _, expr := range []string{"data.status==done", ".data.status=done", ".data.status!done"} {
+		if _, err := ParseEventFilter(expr); err == nil {
+			t.Errorf("expected error for filter %q", expr)
+		}
+	}
+}
@@ -21,19 +21,46 @@ const (
 	Event_RouteDown           = "route:down"
 	Event_RouteUp             = "route:up"
 	Event_WorkspaceUpdate     = "workspace:update"
+	Event_WorkspaceConfig     = "workspace:config" // type: MetaMapType (the workspace settings layer), scoped to the workspace oref
 	Event_WaveAIRateLimit     = "waveai:ratelimit"
 	Event_WaveAppAppGoUpdated = "waveapp:appgoupdated"
 	Event_TsunamiUpdateMeta   = "tsunami:updatemeta"
 	Event_AIModeConfig        = "waveai:modeconfig"
 	Event_TabIndicator        = "tab:indicator"
 	Event_BlockJobStatus      = "block:jobstatus" // type: BlockJobStatusData
+	Event_PetReaction         = "pet:reaction"    // type: PetReactionEventData
+	Event_TermOutput          = "term:output"     // not published, the event output trigger actions are expanded against
+	Event_ShareStatus         = "share:status"    // type: ShareStatusEventData
 )
 
+// custom events published from scripts (wsh events pub) must use this namespace
+const UserEventPrefix = "user:"
+
+func IsUserEvent(eventName string) bool {
+	return len(eventName) > len(UserEventPrefix) && eventName[:len(UserEventPrefix)] == UserEventPrefix
+}
+
+// system events that restricted links (remote connections, scoped tokens) may publish besides custom
+// events, wsh tabindicator works everywhere
+var restrictedPublishEvents = map[string]bool{
+	Event_TabIndicator: true,
+}
+
+// connName is the connection the restricted link serves ("" if none).  connservers report sysinfo,
+// but automation thresholds fire on it, so a link may only publish it scoped to its own connection.
+func CanPublishRestricted(event WaveEvent, connName string) bool {
+	if event.Event == Event_SysInfo {
+		return connName != "" && len(event.Scopes) == 1 && event.Scopes[0] == connName
+	}
+	return IsUserEvent(event.Event) || restrictedPublishEvents[event.Event]
+}
+
 type WaveEvent struct {
 	Event   string   `json:"event"`
 	Scopes  []string `json:"scopes,omitempty"`
 	Sender  string   `json:"sender,omitempty"`
 	Persist int      `json:"persist,omitempty"`
+	Ts      int64    `json:"ts,omitempty"` // set by Publish (unix ms)
 	Data    any      `json:"data,omitempty"`
 }
 
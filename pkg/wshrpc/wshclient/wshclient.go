@@ -27,6 +27,24 @@ func AiSendMessageCommand(w *wshutil.WshRpc, data wshrpc.AiMessageData, opts *ws
 	return err
 }
 
+// command "apitokencreate", wshserver.ApiTokenCreateCommand
+func ApiTokenCreateCommand(w *wshutil.WshRpc, data wshrpc.CommandApiTokenCreateData, opts *wshrpc.RpcOpts) (*wshrpc.ApiTokenCreateRtnData, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.ApiTokenCreateRtnData](w, "apitokencreate", data, opts)
+	return resp, err
+}
+
+// command "apitokenlist", wshserver.ApiTokenListCommand
+func ApiTokenListCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) ([]wshrpc.ApiTokenInfo, error) {
+	resp, err := sendRpcRequestCallHelper[[]wshrpc.ApiTokenInfo](w, "apitokenlist", nil, opts)
+	return resp, err
+}
+
+// command "apitokenrevoke", wshserver.ApiTokenRevokeCommand
+func ApiTokenRevokeCommand(w *wshutil.WshRpc, data string, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "apitokenrevoke", data, opts)
+	return err
+}
+
 // command "authenticate", wshserver.AuthenticateCommand
 func AuthenticateCommand(w *wshutil.WshRpc, data string, opts *wshrpc.RpcOpts) (wshrpc.CommandAuthenticateRtnData, error) {
 	resp, err := sendRpcRequestCallHelper[wshrpc.CommandAuthenticateRtnData](w, "authenticate", data, opts)
@@ -63,6 +81,24 @@ func AuthenticateTokenVerifyCommand(w *wshutil.WshRpc, data wshrpc.CommandAuthen
 	return resp, err
 }
 
+// command "automationlist", wshserver.AutomationListCommand
+func AutomationListCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) ([]wshrpc.AutomationRuleInfo, error) {
+	resp, err := sendRpcRequestCallHelper[[]wshrpc.AutomationRuleInfo](w, "automationlist", nil, opts)
+	return resp, err
+}
+
+// command "automationsetenabled", wshserver.AutomationSetEnabledCommand
+func AutomationSetEnabledCommand(w *wshutil.WshRpc, data wshrpc.CommandAutomationSetEnabledData, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "automationsetenabled", data, opts)
+	return err
+}
+
+// command "automationtest", wshserver.AutomationTestCommand
+func AutomationTestCommand(w *wshutil.WshRpc, data wshrpc.CommandAutomationTestData, opts *wshrpc.RpcOpts) (*wshrpc.AutomationTestResult, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.AutomationTestResult](w, "automationtest", data, opts)
+	return resp, err
+}
+
 // command "blockinfo", wshserver.BlockInfoCommand
 func BlockInfoCommand(w *wshutil.WshRpc, data string, opts *wshrpc.RpcOpts) (*wshrpc.BlockInfoData, error) {
 	resp, err := sendRpcRequestCallHelper[*wshrpc.BlockInfoData](w, "blockinfo", data, opts)
@@ -93,6 +129,42 @@ func CheckGoVersionCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) (*wshrpc.Com
 	return resp, err
 }
 
+// command "cliuserinput", wshserver.CliUserInputCommand
+func CliUserInputCommand(w *wshutil.WshRpc, data wshrpc.CommandCliUserInputData, opts *wshrpc.RpcOpts) (*wshrpc.CliUserInputRtnData, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.CliUserInputRtnData](w, "cliuserinput", data, opts)
+	return resp, err
+}
+
+// command "configexport", wshserver.ConfigExportCommand
+func ConfigExportCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) (*wshrpc.ConfigBundle, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.ConfigBundle](w, "configexport", nil, opts)
+	return resp, err
+}
+
+// command "confighistorydiff", wshserver.ConfigHistoryDiffCommand
+func ConfigHistoryDiffCommand(w *wshutil.WshRpc, data wshrpc.CommandConfigHistoryData, opts *wshrpc.RpcOpts) ([]wconfig.ConfigDiffEntry, error) {
+	resp, err := sendRpcRequestCallHelper[[]wconfig.ConfigDiffEntry](w, "confighistorydiff", data, opts)
+	return resp, err
+}
+
+// command "confighistorylist", wshserver.ConfigHistoryListCommand
+func ConfigHistoryListCommand(w *wshutil.WshRpc, data wshrpc.CommandConfigHistoryData, opts *wshrpc.RpcOpts) ([]wconfig.ConfigHistoryEntry, error) {
+	resp, err := sendRpcRequestCallHelper[[]wconfig.ConfigHistoryEntry](w, "confighistorylist", data, opts)
+	return resp, err
+}
+
+// command "confighistoryrollback", wshserver.ConfigHistoryRollbackCommand
+func ConfigHistoryRollbackCommand(w *wshutil.WshRpc, data wshrpc.CommandConfigHistoryData, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "confighistoryrollback", data, opts)
+	return err
+}
+
+// command "configimport", wshserver.ConfigImportCommand
+func ConfigImportCommand(w *wshutil.WshRpc, data wshrpc.CommandConfigImportData, opts *wshrpc.RpcOpts) (*wshrpc.ConfigImportResult, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.ConfigImportResult](w, "configimport", data, opts)
+	return resp, err
+}
+
 // command "connconnect", wshserver.ConnConnectCommand
 func ConnConnectCommand(w *wshutil.WshRpc, data wshrpc.ConnRequest, opts *wshrpc.RpcOpts) error {
 	_, err := sendRpcRequestCallHelper[any](w, "connconnect", data, opts)
@@ -135,6 +207,12 @@ func ConnStatusCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) ([]wshrpc.ConnSt
 	return resp, err
 }
 
+// command "conntlsfingerprint", wshserver.ConnTlsFingerprintCommand
+func ConnTlsFingerprintCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) (string, error) {
+	resp, err := sendRpcRequestCallHelper[string](w, "conntlsfingerprint", nil, opts)
+	return resp, err
+}
+
 // command "connupdatewsh", wshserver.ConnUpdateWshCommand
 func ConnUpdateWshCommand(w *wshutil.WshRpc, data wshrpc.RemoteInfo, opts *wshrpc.RpcOpts) (bool, error) {
 	resp, err := sendRpcRequestCallHelper[bool](w, "connupdatewsh", data, opts)
@@ -207,6 +285,18 @@ func DeleteSubBlockCommand(w *wshutil.WshRpc, data wshrpc.CommandDeleteBlockData
 	return err
 }
 
+// command "dirfrecencyquery", wshserver.DirFrecencyQueryCommand
+func DirFrecencyQueryCommand(w *wshutil.WshRpc, data wshrpc.CommandDirFrecencyQueryData, opts *wshrpc.RpcOpts) ([]wshrpc.DirFrecencyEntry, error) {
+	resp, err := sendRpcRequestCallHelper[[]wshrpc.DirFrecencyEntry](w, "dirfrecencyquery", data, opts)
+	return resp, err
+}
+
+// command "dirfrecencyremove", wshserver.DirFrecencyRemoveCommand
+func DirFrecencyRemoveCommand(w *wshutil.WshRpc, data wshrpc.CommandDirFrecencyRemoveData, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "dirfrecencyremove", data, opts)
+	return err
+}
+
 // command "dismisswshfail", wshserver.DismissWshFailCommand
 func DismissWshFailCommand(w *wshutil.WshRpc, data string, opts *wshrpc.RpcOpts) error {
 	_, err := sendRpcRequestCallHelper[any](w, "dismisswshfail", data, opts)
@@ -493,6 +583,30 @@ func GetWaveAIRateLimitCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) (interfa
 	return resp, err
 }
 
+// command "historyquery", wshserver.HistoryQueryCommand
+func HistoryQueryCommand(w *wshutil.WshRpc, data wshrpc.CommandHistoryQueryData, opts *wshrpc.RpcOpts) ([]wshrpc.HistoryEntry, error) {
+	resp, err := sendRpcRequestCallHelper[[]wshrpc.HistoryEntry](w, "historyquery", data, opts)
+	return resp, err
+}
+
+// command "inputgroupbroadcast", wshserver.InputGroupBroadcastCommand
+func InputGroupBroadcastCommand(w *wshutil.WshRpc, data wshrpc.CommandInputGroupBroadcastData, opts *wshrpc.RpcOpts) (int, error) {
+	resp, err := sendRpcRequestCallHelper[int](w, "inputgroupbroadcast", data, opts)
+	return resp, err
+}
+
+// command "inputgrouplist", wshserver.InputGroupListCommand
+func InputGroupListCommand(w *wshutil.WshRpc, data wshrpc.CommandInputGroupListData, opts *wshrpc.RpcOpts) ([]wshrpc.InputGroupMember, error) {
+	resp, err := sendRpcRequestCallHelper[[]wshrpc.InputGroupMember](w, "inputgrouplist", data, opts)
+	return resp, err
+}
+
+// command "inputgroupset", wshserver.InputGroupSetCommand
+func InputGroupSetCommand(w *wshutil.WshRpc, data wshrpc.CommandInputGroupSetData, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "inputgroupset", data, opts)
+	return err
+}
+
 // command "jobcmdexited", wshserver.JobCmdExitedCommand
 func JobCmdExitedCommand(w *wshutil.WshRpc, data wshrpc.CommandJobCmdExitedData, opts *wshrpc.RpcOpts) error {
 	_, err := sendRpcRequestCallHelper[any](w, "jobcmdexited", data, opts)
@@ -637,6 +751,24 @@ func PathCommand(w *wshutil.WshRpc, data wshrpc.PathCommandData, opts *wshrpc.Rp
 	return resp, err
 }
 
+// command "pluginlist", wshserver.PluginListCommand
+func PluginListCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) ([]wshrpc.PluginInfo, error) {
+	resp, err := sendRpcRequestCallHelper[[]wshrpc.PluginInfo](w, "pluginlist", nil, opts)
+	return resp, err
+}
+
+// command "pluginrestart", wshserver.PluginRestartCommand
+func PluginRestartCommand(w *wshutil.WshRpc, data string, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "pluginrestart", data, opts)
+	return err
+}
+
+// command "pluginsetenabled", wshserver.PluginSetEnabledCommand
+func PluginSetEnabledCommand(w *wshutil.WshRpc, data wshrpc.CommandPluginSetEnabledData, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "pluginsetenabled", data, opts)
+	return err
+}
+
 // command "publishapp", wshserver.PublishAppCommand
 func PublishAppCommand(w *wshutil.WshRpc, data wshrpc.CommandPublishAppData, opts *wshrpc.RpcOpts) (*wshrpc.CommandPublishAppRtnData, error) {
 	resp, err := sendRpcRequestCallHelper[*wshrpc.CommandPublishAppRtnData](w, "publishapp", data, opts)
@@ -784,6 +916,18 @@ func RouteUnannounceCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) error {
 	return err
 }
 
+// command "rpcstats", wshserver.RpcStatsCommand
+func RpcStatsCommand(w *wshutil.WshRpc, data wshrpc.CommandRpcStatsData, opts *wshrpc.RpcOpts) (*wshrpc.RpcStatsData, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.RpcStatsData](w, "rpcstats", data, opts)
+	return resp, err
+}
+
+// command "rpctrace", wshserver.RpcTraceCommand
+func RpcTraceCommand(w *wshutil.WshRpc, data wshrpc.CommandRpcTraceData, opts *wshrpc.RpcOpts) (*wshrpc.RpcTraceData, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.RpcTraceData](w, "rpctrace", data, opts)
+	return resp, err
+}
+
 // command "sendtelemetry", wshserver.SendTelemetryCommand
 func SendTelemetryCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) error {
 	_, err := sendRpcRequestCallHelper[any](w, "sendtelemetry", nil, opts)
@@ -832,12 +976,66 @@ func SetSecretsCommand(w *wshutil.WshRpc, data map[string]*string, opts *wshrpc.
 	return err
 }
 
+// command "settermtheme", wshserver.SetTermThemeCommand
+func SetTermThemeCommand(w *wshutil.WshRpc, data wshrpc.CommandSetTermThemeData, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "settermtheme", data, opts)
+	return err
+}
+
 // command "setvar", wshserver.SetVarCommand
 func SetVarCommand(w *wshutil.WshRpc, data wshrpc.CommandVarData, opts *wshrpc.RpcOpts) error {
 	_, err := sendRpcRequestCallHelper[any](w, "setvar", data, opts)
 	return err
 }
 
+// command "setworkspaceconfig", wshserver.SetWorkspaceConfigCommand
+func SetWorkspaceConfigCommand(w *wshutil.WshRpc, data wshrpc.CommandSetWorkspaceConfigData, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "setworkspaceconfig", data, opts)
+	return err
+}
+
+// command "sharelist", wshserver.ShareListCommand
+func ShareListCommand(w *wshutil.WshRpc, data wshrpc.CommandShareListData, opts *wshrpc.RpcOpts) ([]wshrpc.ShareInfo, error) {
+	resp, err := sendRpcRequestCallHelper[[]wshrpc.ShareInfo](w, "sharelist", data, opts)
+	return resp, err
+}
+
+// command "sharestart", wshserver.ShareStartCommand
+func ShareStartCommand(w *wshutil.WshRpc, data wshrpc.CommandShareStartData, opts *wshrpc.RpcOpts) (*wshrpc.ShareInfo, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.ShareInfo](w, "sharestart", data, opts)
+	return resp, err
+}
+
+// command "sharestop", wshserver.ShareStopCommand
+func ShareStopCommand(w *wshutil.WshRpc, data wshrpc.CommandShareStopData, opts *wshrpc.RpcOpts) (int, error) {
+	resp, err := sendRpcRequestCallHelper[int](w, "sharestop", data, opts)
+	return resp, err
+}
+
+// command "snapshotcreate", wshserver.SnapshotCreateCommand
+func SnapshotCreateCommand(w *wshutil.WshRpc, data wshrpc.CommandSnapshotData, opts *wshrpc.RpcOpts) (*wshrpc.SnapshotInfo, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.SnapshotInfo](w, "snapshotcreate", data, opts)
+	return resp, err
+}
+
+// command "snapshotdelete", wshserver.SnapshotDeleteCommand
+func SnapshotDeleteCommand(w *wshutil.WshRpc, data wshrpc.CommandSnapshotData, opts *wshrpc.RpcOpts) error {
+	_, err := sendRpcRequestCallHelper[any](w, "snapshotdelete", data, opts)
+	return err
+}
+
+// command "snapshotlist", wshserver.SnapshotListCommand
+func SnapshotListCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) ([]wshrpc.SnapshotInfo, error) {
+	resp, err := sendRpcRequestCallHelper[[]wshrpc.SnapshotInfo](w, "snapshotlist", nil, opts)
+	return resp, err
+}
+
+// command "snapshotrestore", wshserver.SnapshotRestoreCommand
+func SnapshotRestoreCommand(w *wshutil.WshRpc, data wshrpc.CommandSnapshotData, opts *wshrpc.RpcOpts) ([]string, error) {
+	resp, err := sendRpcRequestCallHelper[[]string](w, "snapshotrestore", data, opts)
+	return resp, err
+}
+
 // command "startbuilder", wshserver.StartBuilderCommand
 func StartBuilderCommand(w *wshutil.WshRpc, data wshrpc.CommandStartBuilderData, opts *wshrpc.RpcOpts) error {
 	_, err := sendRpcRequestCallHelper[any](w, "startbuilder", data, opts)
@@ -889,6 +1087,12 @@ func TermGetScrollbackLinesCommand(w *wshutil.WshRpc, data wshrpc.CommandTermGet
 	return resp, err
 }
 
+// command "termlogstatus", wshserver.TermLogStatusCommand
+func TermLogStatusCommand(w *wshutil.WshRpc, data string, opts *wshrpc.RpcOpts) (*wshrpc.TermLogStatusData, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.TermLogStatusData](w, "termlogstatus", data, opts)
+	return resp, err
+}
+
 // command "test", wshserver.TestCommand
 func TestCommand(w *wshutil.WshRpc, data string, opts *wshrpc.RpcOpts) error {
 	_, err := sendRpcRequestCallHelper[any](w, "test", data, opts)
@@ -965,12 +1169,24 @@ func WebSelectorCommand(w *wshutil.WshRpc, data wshrpc.CommandWebSelectorData, o
 	return resp, err
 }
 
+// command "workspaceapplylayout", wshserver.WorkspaceApplyLayoutCommand
+func WorkspaceApplyLayoutCommand(w *wshutil.WshRpc, data wshrpc.CommandWorkspaceApplyLayoutData, opts *wshrpc.RpcOpts) ([]string, error) {
+	resp, err := sendRpcRequestCallHelper[[]string](w, "workspaceapplylayout", data, opts)
+	return resp, err
+}
+
 // command "workspacelist", wshserver.WorkspaceListCommand
 func WorkspaceListCommand(w *wshutil.WshRpc, opts *wshrpc.RpcOpts) ([]wshrpc.WorkspaceInfoData, error) {
 	resp, err := sendRpcRequestCallHelper[[]wshrpc.WorkspaceInfoData](w, "workspacelist", nil, opts)
 	return resp, err
 }
 
+// command "workspacesavelayout", wshserver.WorkspaceSaveLayoutCommand
+func WorkspaceSaveLayoutCommand(w *wshutil.WshRpc, data wshrpc.CommandWorkspaceSaveLayoutData, opts *wshrpc.RpcOpts) (*wshrpc.CommandWorkspaceSaveLayoutRtnData, error) {
+	resp, err := sendRpcRequestCallHelper[*wshrpc.CommandWorkspaceSaveLayoutRtnData](w, "workspacesavelayout", data, opts)
+	return resp, err
+}
+
 // command "writeappfile", wshserver.WriteAppFileCommand
 func WriteAppFileCommand(w *wshutil.WshRpc, data wshrpc.CommandWriteAppFileData, opts *wshrpc.RpcOpts) error {
 	_, err := sendRpcRequestCallHelper[any](w, "writeappfile", data, opts)
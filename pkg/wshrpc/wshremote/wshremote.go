@@ -84,6 +84,18 @@ func (*ServerImpl) RemoteGetInfoCommand(ctx context.Context) (wshrpc.RemoteInfo,
 	return wshutil.GetInfo(), nil
 }
 
+// stats for the connserver's router (lets "wsh debug rpcstats --conn" look at both ends of a connection)
+func (impl *ServerImpl) RpcStatsCommand(ctx context.Context, data wshrpc.CommandRpcStatsData) (*wshrpc.RpcStatsData, error) {
+	if impl.Router == nil {
+		return nil, fmt.Errorf("connserver is not running a router")
+	}
+	return impl.Router.GetRpcStats(data.Reset), nil
+}
+
+func (*ServerImpl) RpcTraceCommand(ctx context.Context, data wshrpc.CommandRpcTraceData) (*wshrpc.RpcTraceData, error) {
+	return wshutil.GetRpcTrace(data), nil
+}
+
 func (*ServerImpl) RemoteInstallRcFilesCommand(ctx context.Context) error {
 	return wshutil.InstallRcFiles()
 }
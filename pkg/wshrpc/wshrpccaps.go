@@ -0,0 +1,379 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wshrpc
+
+import "strings"
+
+// capabilities limit which commands a wsh token (and the link it authenticates) may send.
+// a capability list is a set of grants and denials:
+//
+//	"*"        all commands
+//	"files"    a capability group (see CommandCapabilities)
+//	"setmeta"  a single command
+//	"-secrets" deny a group or command (denials always win)
+//
+// "core" commands (routing, events, metadata reads, etc.) are allowed unless explicitly denied.
+// commands missing from CommandCapabilities are denied unless granted by name (every registered
+// command has an entry, see the tests).  a nil list means the token is unrestricted.
+const (
+	Capability_All        = "*"
+	Capability_Core       = "core"
+	Capability_Secrets    = "secrets"
+	Capability_Config     = "config"
+	Capability_Blocks     = "blocks"
+	Capability_Files      = "files"
+	Capability_Conns      = "conns"
+	Capability_AI         = "ai"
+	Capability_Automation = "automation"
+	Capability_History    = "history"
+	Capability_Share      = "share"
+	Capability_Apps       = "apps"
+)
+
+// used for ssh/wsl/tls blocks and connservers when "wsh:remotecapabilities" is not set.  a remote can
+// open and edit blocks but not change config, read secrets or history, type into other blocks, or
+// write ProtectedMetaKeys.  it can read its own connection's visited directories (wsh z), the wshserver
+// limits the dirfrecency commands to the caller's connection.
+var DefaultRemoteCapabilities = []string{
+	Capability_Core,
+	Capability_Files,
+	Capability_AI,
+	"createblock",
+	"deleteblock",
+	"setmeta",
+	"setvar",
+	"setblockfocus",
+	"focuswindow",
+	"controllerresync",
+	"dirfrecencyquery",
+	"dirfrecencyremove",
+}
+
+// meta keys that decide what a block runs and with which capabilities, only unrestricted links may
+// write them (see WshServer.SetMetaCommand / CreateBlockCommand).  workspace settings can override
+// ProtectedSettingsKeys, they are written through SetWorkspaceConfigCommand.
+var ProtectedMetaKeys = map[string]bool{
+	"cmd":                 true,
+	"cmd:shell":           true,
+	"cmd:env":             true,
+	"cmd:args":            true,
+	"cmd:initscript":      true,
+	"cmd:initscript.sh":   true,
+	"cmd:initscript.bash": true,
+	"cmd:initscript.zsh":  true,
+	"cmd:initscript.pwsh": true,
+	"cmd:initscript.fish": true,
+	"cmd:capabilities":    true,
+	"controller":          true,
+	"connection":          true,
+	"term:localshellpath": true,
+	"term:localshellopts": true,
+	"term:gitbashpath":    true,
+	"workspace:settings":  true,
+}
+
+// the protected keys restricted links may still set on new blocks that run on a remote connection
+// (wsh run, wsh ssh)
+var NewRemoteBlockMetaKeys = map[string]bool{
+	"cmd":        true,
+	"cmd:shell":  true,
+	"cmd:env":    true,
+	"cmd:args":   true,
+	"controller": true,
+	"connection": true,
+}
+
+func IsProtectedMetaKey(key string) bool {
+	return ProtectedMetaKeys[key]
+}
+
+// settings that decide what runs locally or which capabilities links get, only unrestricted links may
+// write them (see WshServer.SetConfigCommand / SetWorkspaceConfigCommand)
+var ProtectedSettingsKeys = map[string]bool{
+	"term:localshellpath":    true,
+	"term:localshellopts":    true,
+	"term:gitbashpath":       true,
+	"wsh:*":                  true,
+	"wsh:localcapabilities":  true,
+	"wsh:remotecapabilities": true,
+	"plugins:*":              true,
+	"plugins:enabled":        true,
+	"tsunami:*":              true,
+	"tsunami:scaffoldpath":   true,
+	"tsunami:sdkreplacepath": true,
+	"tsunami:gopath":         true,
+}
+
+func IsProtectedSettingsKey(key string) bool {
+	return ProtectedSettingsKeys[key]
+}
+
+var CommandCapabilities = map[string]string{
+	"activity":                     Capability_Core,
+	"authenticate":                 Capability_Core,
+	"authenticatejobmanager":       Capability_Core,
+	"authenticatejobmanagerverify": Capability_Core,
+	"authenticatetojobmanager":     Capability_Core,
+	"authenticatetoken":            Capability_Core,
+	"authenticatetokenverify":      Capability_Core,
+	"blockinfo":                    Capability_Core,
+	"blockjobstatus":               Capability_Core,
+	"blockslist":                   Capability_Core,
+	"connlist":                     Capability_Core,
+	"connserverinit":               Capability_Core,
+	"connstatus":                   Capability_Core,
+	"controlgetrouteid":            Capability_Core,
+	"dismisswshfail":               Capability_Core,
+	"dispose":                      Capability_Core,
+	"disposesuggestions":           Capability_Core,
+	"electronsystembell":           Capability_Core,
+	"eventpublish":                 Capability_Core,
+	"eventreadhistory":             Capability_Core,
+	"eventrecv":                    Capability_Core,
+	"eventsub":                     Capability_Core,
+	"eventunsub":                   Capability_Core,
+	"eventunsuball":                Capability_Core,
+	"fetchsuggestions":             Capability_Core,
+	"findgitbash":                  Capability_Core,
+	"getalltabindicators":          Capability_Core,
+	"getallvars":                   Capability_Core,
+	"getfocusedblockdata":          Capability_Core,
+	"getfullconfig":                Capability_Core,
+	"getjwtpublickey":              Capability_Core,
+	"getmeta":                      Capability_Core,
+	"getrtinfo":                    Capability_Core,
+	"gettab":                       Capability_Core,
+	"gettempdir":                   Capability_Core,
+	"getupdatechannel":             Capability_Core,
+	"getvar":                       Capability_Core,
+	"inputgrouplist":               Capability_Core,
+	"jobcmdexited":                 Capability_Core,
+	"message":                      Capability_Core,
+	"networkonline":                Capability_Core,
+	"notify":                       Capability_Core,
+	"notifysystemresume":           Capability_Core,
+	"path":                         Capability_Core,
+	"petaddxp":                     Capability_Core,
+	"petgetcatalogue":              Capability_Core,
+	"petgetdialogue":               Capability_Core,
+	"petgetprofile":                Capability_Core,
+	"petgetsession":                Capability_Core,
+	"petgetstate":                  Capability_Core,
+	"petinteract":                  Capability_Core,
+	"petselectpet":                 Capability_Core,
+	"recordtevent":                 Capability_Core,
+	"remotegetinfo":                Capability_Core,
+	"remotestreamcpudata":          Capability_Core,
+	"resolveids":                   Capability_Core,
+	"routeannounce":                Capability_Core,
+	"routeunannounce":              Capability_Core,
+	"sendtelemetry":                Capability_Core,
+	"setpeerinfo":                  Capability_Core,
+	"streamcpudata":                Capability_Core,
+	"streamdata":                   Capability_Core,
+	"streamdataack":                Capability_Core,
+	"streamtest":                   Capability_Core,
+	"test":                         Capability_Core,
+	"vdomasyncinitiation":          Capability_Core,
+	"vdomcreatecontext":            Capability_Core,
+	"vdomrender":                   Capability_Core,
+	"vdomurlrequest":               Capability_Core,
+	"waitforroute":                 Capability_Core,
+	"waveinfo":                     Capability_Core,
+	"workspacelist":                Capability_Core,
+	"wshactivity":                  Capability_Core,
+	"wsldefaultdistro":             Capability_Core,
+	"wsllist":                      Capability_Core,
+	"wslstatus":                    Capability_Core,
+
+	"getsecrets":                    Capability_Secrets,
+	"getsecretsnames":               Capability_Secrets,
+	"setsecrets":                    Capability_Secrets,
+	"getsecretslinuxstoragebackend": Capability_Secrets,
+	"electronencrypt":               Capability_Secrets,
+	"electrondecrypt":               Capability_Secrets,
+	"writeappsecretbindings":        Capability_Secrets,
+
+	"setconfig":             Capability_Config,
+	"setconnectionsconfig":  Capability_Config,
+	"setworkspaceconfig":    Capability_Config,
+	"confighistoryrollback": Capability_Config,
+	"configimport":          Capability_Config,
+	"settermtheme":          Capability_Config,
+	"automationsetenabled":  Capability_Config,
+	"apitokencreate":        Capability_Config,
+	"apitokenlist":          Capability_Config,
+	"apitokenrevoke":        Capability_Config,
+	"pluginsetenabled":      Capability_Config,
+	"pluginrestart":         Capability_Config,
+	"workspacesavelayout":   Capability_Config,
+	"configexport":          Capability_Config,
+	"confighistorydiff":     Capability_Config,
+	"confighistorylist":     Capability_Config,
+	"pluginlist":            Capability_Config,
+	"rpcstats":              Capability_Config,
+	"rpctrace":              Capability_Config,
+
+	"createblock":            Capability_Blocks,
+	"createsubblock":         Capability_Blocks,
+	"deleteblock":            Capability_Blocks,
+	"deletesubblock":         Capability_Blocks,
+	"setmeta":                Capability_Blocks,
+	"controllerinput":        Capability_Blocks,
+	"controllerdestroy":      Capability_Blocks,
+	"controllerresync":       Capability_Blocks,
+	"setblockfocus":          Capability_Blocks,
+	"focuswindow":            Capability_Blocks,
+	"workspaceapplylayout":   Capability_Blocks,
+	"snapshotcreate":         Capability_Blocks,
+	"snapshotrestore":        Capability_Blocks,
+	"snapshotdelete":         Capability_Blocks,
+	"inputgroupset":          Capability_Blocks,
+	"inputgroupbroadcast":    Capability_Blocks,
+	"snapshotlist":           Capability_Blocks,
+	"setvar":                 Capability_Blocks,
+	"setrtinfo":              Capability_Blocks,
+	"controllerappendoutput": Capability_Blocks,
+	"captureblockscreenshot": Capability_Blocks,
+	"termgetscrollbacklines": Capability_Blocks,
+	"termlogstatus":          Capability_Blocks,
+	"webselector":            Capability_Blocks,
+	"cliuserinput":           Capability_Blocks,
+
+	"sharestart": Capability_Share,
+	"sharestop":  Capability_Share,
+	"sharelist":  Capability_Share,
+
+	"filemkdir":          Capability_Files,
+	"filecreate":         Capability_Files,
+	"filedelete":         Capability_Files,
+	"fileappend":         Capability_Files,
+	"filewrite":          Capability_Files,
+	"fileread":           Capability_Files,
+	"filereadstream":     Capability_Files,
+	"filemove":           Capability_Files,
+	"filecopy":           Capability_Files,
+	"fileinfo":           Capability_Files,
+	"filelist":           Capability_Files,
+	"filejoin":           Capability_Files,
+	"fileliststream":     Capability_Files,
+	"filerestorebackup":  Capability_Files,
+	"writetempfile":      Capability_Files,
+	"wavefilereadstream": Capability_Files,
+	"remotestreamfile":   Capability_Files,
+	"remotefilecopy":     Capability_Files,
+	"remotelistentries":  Capability_Files,
+	"remotefileinfo":     Capability_Files,
+	"remotefiletouch":    Capability_Files,
+	"remotefilemove":     Capability_Files,
+	"remotefiledelete":   Capability_Files,
+	"remotewritefile":    Capability_Files,
+	"remotefilejoin":     Capability_Files,
+	"remotemkdir":        Capability_Files,
+
+	"connensure":                          Capability_Conns,
+	"connreinstallwsh":                    Capability_Conns,
+	"connconnect":                         Capability_Conns,
+	"conndisconnect":                      Capability_Conns,
+	"connupdatewsh":                       Capability_Conns,
+	"conntlsfingerprint":                  Capability_Conns,
+	"remotestartjob":                      Capability_Conns,
+	"jobcontrollerstartjob":               Capability_Conns,
+	"jobcontrollerexitjob":                Capability_Conns,
+	"jobcontrollerdeletejob":              Capability_Conns,
+	"jobcontrollerattachjob":              Capability_Conns,
+	"jobcontrollerconnectedjobs":          Capability_Conns,
+	"jobcontrollerdetachjob":              Capability_Conns,
+	"jobcontrollerdisconnectjob":          Capability_Conns,
+	"jobcontrollergetalljobmanagerstatus": Capability_Conns,
+	"jobcontrollerlist":                   Capability_Conns,
+	"jobcontrollerreconnectjob":           Capability_Conns,
+	"jobcontrollerreconnectjobsforconn":   Capability_Conns,
+	"jobinput":                            Capability_Conns,
+	"jobprepareconnect":                   Capability_Conns,
+	"jobstartstream":                      Capability_Conns,
+	"startjob":                            Capability_Conns,
+	"remotedisconnectfromjobmanager":      Capability_Conns,
+	"remotereconnecttojobmanager":         Capability_Conns,
+	"remoteterminatejobmanager":           Capability_Conns,
+	"remoteinstallrcfiles":                Capability_Conns,
+
+	"automationlist": Capability_Automation,
+	"automationtest": Capability_Automation,
+
+	"historyquery":      Capability_History,
+	"dirfrecencyquery":  Capability_History,
+	"dirfrecencyremove": Capability_History,
+
+	"checkgoversion":        Capability_Apps,
+	"deletebuilder":         Capability_Apps,
+	"getbuilderoutput":      Capability_Apps,
+	"getbuilderstatus":      Capability_Apps,
+	"restartbuilderandwait": Capability_Apps,
+	"startbuilder":          Capability_Apps,
+	"stopbuilder":           Capability_Apps,
+	"listallappfiles":       Capability_Apps,
+	"listallapps":           Capability_Apps,
+	"listalleditableapps":   Capability_Apps,
+	"makedraftfromlocal":    Capability_Apps,
+	"publishapp":            Capability_Apps,
+	"readappfile":           Capability_Apps,
+	"renameappfile":         Capability_Apps,
+	"deleteappfile":         Capability_Apps,
+	"writeappfile":          Capability_Apps,
+	"writeappgofile":        Capability_Apps,
+
+	"aisendmessage":         Capability_AI,
+	"streamwaveai":          Capability_AI,
+	"waveaitoolapprove":     Capability_AI,
+	"waveaiaddcontext":      Capability_AI,
+	"waveaienabletelemetry": Capability_AI,
+	"getwaveaichat":         Capability_AI,
+	"getwaveaimodeconfig":   Capability_AI,
+	"getwaveairatelimit":    Capability_AI,
+	"waveaigettooldiff":     Capability_AI,
+}
+
+// returns "" for commands that aren't classified
+func GetCommandCapability(command string) string {
+	return CommandCapabilities[command]
+}
+
+// NormalizeCapabilities turns an explicitly empty list into a core-only list (nil stays unrestricted)
+func NormalizeCapabilities(caps []string) []string {
+	if caps == nil {
+		return nil
+	}
+	var rtn []string
+	for _, c := range caps {
+		c = strings.TrimSpace(c)
+		if c != "" {
+			rtn = append(rtn, c)
+		}
+	}
+	if len(rtn) == 0 {
+		return []string{Capability_Core}
+	}
+	return rtn
+}
+
+func CapabilitiesAllow(caps []string, command string) bool {
+	if caps == nil {
+		return true
+	}
+	capability := GetCommandCapability(command)
+	allowed := capability == Capability_Core
+	for _, c := range caps {
+		if denied, ok := strings.CutPrefix(c, "-"); ok {
+			if denied == Capability_All || denied == capability || denied == command {
+				return false
+			}
+			continue
+		}
+		if c == command || (capability != "" && (c == Capability_All || c == capability)) {
+			allowed = true
+		}
+	}
+	return allowed
+}
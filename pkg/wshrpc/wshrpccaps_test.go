@@ -0,0 +1,60 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wshrpc
+
+import "testing"
+
+func TestCapabilitiesAllow(t *testing.T) {
+	tests := []struct {
+		caps    []string
+		command string
+		want    bool
+	}{
+		{nil, "getsecrets", true},
+		{DefaultRemoteCapabilities, "getsecrets", false},
+		{DefaultRemoteCapabilities, "setconfig", false},
+		{DefaultRemoteCapabilities, "setmeta", true},
+		{DefaultRemoteCapabilities, "controllerinput", false},
+		{DefaultRemoteCapabilities, "sharelist", false},
+		{DefaultRemoteCapabilities, "fileread", true},
+		{[]string{"*"}, "notacommand", false},
+		{[]string{"*", "notacommand"}, "notacommand", true},
+		{[]string{}, "getmeta", true},
+		{[]string{}, "fileread", false},
+		{[]string{"files"}, "fileread", true},
+		{[]string{"files"}, "setmeta", false},
+		{[]string{"files"}, "getmeta", true},
+		{[]string{"files", "setmeta"}, "setmeta", true},
+		{[]string{"*", "-deleteblock"}, "deleteblock", false},
+		{[]string{"*", "-deleteblock"}, "createblock", true},
+		{[]string{"-eventpublish"}, "eventpublish", false},
+		{DefaultRemoteCapabilities, "automationtest", false},
+		{NormalizeCapabilities([]string{}), "automationtest", false},
+		{[]string{"core", "automation"}, "automationtest", true},
+		{DefaultRemoteCapabilities, "historyquery", false},
+		{NormalizeCapabilities([]string{}), "historyquery", false},
+		{[]string{"core", "history"}, "historyquery", true},
+		{NormalizeCapabilities([]string{}), "getmeta", true},
+		{NormalizeCapabilities([]string{}), "fileread", false},
+	}
+	for _, tc := range tests {
+		if got := CapabilitiesAllow(tc.caps, tc.command); got != tc.want {
+			t.Errorf("CapabilitiesAllow(%v, %q) = %v, want %v", tc.caps, tc.command, got, tc.want)
+		}
+	}
+}
+
+func TestAllCommandsClassified(t *testing.T) {
+	declMap := GenerateWshCommandDeclMap()
+	for command := range declMap {
+		if GetCommandCapability(command) == "" {
+			t.Errorf("command %q has no entry in CommandCapabilities", command)
+		}
+	}
+	for command := range CommandCapabilities {
+		if _, ok := declMap[command]; !ok {
+			t.Errorf("CommandCapabilities has an entry for unknown command %q", command)
+		}
+	}
+}
@@ -70,6 +70,22 @@ type WshRpcInterface interface {
 	TestCommand(ctx context.Context, data string) error
 	SetConfigCommand(ctx context.Context, data MetaSettingsType) error
 	SetConnectionsConfigCommand(ctx context.Context, data ConnConfigRequest) error
+	SetWorkspaceConfigCommand(ctx context.Context, data CommandSetWorkspaceConfigData) error
+	ConfigHistoryListCommand(ctx context.Context, data CommandConfigHistoryData) ([]wconfig.ConfigHistoryEntry, error)
+	ConfigHistoryDiffCommand(ctx context.Context, data CommandConfigHistoryData) ([]wconfig.ConfigDiffEntry, error)
+	ConfigHistoryRollbackCommand(ctx context.Context, data CommandConfigHistoryData) error
+	ConfigExportCommand(ctx context.Context) (*ConfigBundle, error)
+	ConfigImportCommand(ctx context.Context, data CommandConfigImportData) (*ConfigImportResult, error)
+	SetTermThemeCommand(ctx context.Context, data CommandSetTermThemeData) error
+	AutomationListCommand(ctx context.Context) ([]AutomationRuleInfo, error)
+	AutomationTestCommand(ctx context.Context, data CommandAutomationTestData) (*AutomationTestResult, error)
+	AutomationSetEnabledCommand(ctx context.Context, data CommandAutomationSetEnabledData) error
+	ApiTokenCreateCommand(ctx context.Context, data CommandApiTokenCreateData) (*ApiTokenCreateRtnData, error)
+	ApiTokenListCommand(ctx context.Context) ([]ApiTokenInfo, error)
+	ApiTokenRevokeCommand(ctx context.Context, idOrName string) error
+	PluginListCommand(ctx context.Context) ([]PluginInfo, error)
+	PluginSetEnabledCommand(ctx context.Context, data CommandPluginSetEnabledData) error
+	PluginRestartCommand(ctx context.Context, name string) error
 	GetFullConfigCommand(ctx context.Context) (wconfig.FullConfigType, error)
 	GetWaveAIModeConfigCommand(ctx context.Context) (wconfig.AIModeConfigUpdate, error)
 	BlockInfoCommand(ctx context.Context, blockId string) (*BlockInfoData, error)
@@ -87,6 +103,8 @@ type WshRpcInterface interface {
 	DisposeSuggestionsCommand(ctx context.Context, widgetId string) error
 	GetTabCommand(ctx context.Context, tabId string) (*waveobj.Tab, error)
 	GetAllTabIndicatorsCommand(ctx context.Context) (map[string]*TabIndicator, error)
+	RpcStatsCommand(ctx context.Context, data CommandRpcStatsData) (*RpcStatsData, error)
+	RpcTraceCommand(ctx context.Context, data CommandRpcTraceData) (*RpcTraceData, error)
 
 	// connection functions
 	ConnStatusCommand(ctx context.Context) ([]ConnStatus, error)
@@ -96,6 +114,7 @@ type WshRpcInterface interface {
 	ConnConnectCommand(ctx context.Context, connRequest ConnRequest) error
 	ConnDisconnectCommand(ctx context.Context, connName string) error
 	ConnListCommand(ctx context.Context) ([]string, error)
+	ConnTlsFingerprintCommand(ctx context.Context) (string, error)
 	WslListCommand(ctx context.Context) ([]string, error)
 	WslDefaultDistroCommand(ctx context.Context) (string, error)
 	DismissWshFailCommand(ctx context.Context, connName string) error
@@ -117,6 +136,9 @@ type WshRpcInterface interface {
 	RemoteDisconnectFromJobManagerCommand(ctx context.Context, data CommandRemoteDisconnectFromJobManagerData) error
 	RemoteTerminateJobManagerCommand(ctx context.Context, data CommandRemoteTerminateJobManagerData) error
 
+	// wsh cli (served by a wsh process that offered to answer prompts on its tty)
+	CliUserInputCommand(ctx context.Context, data CommandCliUserInputData) (*CliUserInputRtnData, error)
+
 	// emain
 	WebSelectorCommand(ctx context.Context, data CommandWebSelectorData) ([]string, error)
 	NotifyCommand(ctx context.Context, notificationOptions WaveNotificationOptions) error
@@ -133,6 +155,22 @@ type WshRpcInterface interface {
 	GetSecretsLinuxStorageBackendCommand(ctx context.Context) (string, error)
 
 	WorkspaceListCommand(ctx context.Context) ([]WorkspaceInfoData, error)
+	WorkspaceApplyLayoutCommand(ctx context.Context, data CommandWorkspaceApplyLayoutData) ([]string, error)
+	WorkspaceSaveLayoutCommand(ctx context.Context, data CommandWorkspaceSaveLayoutData) (*CommandWorkspaceSaveLayoutRtnData, error)
+	SnapshotListCommand(ctx context.Context) ([]SnapshotInfo, error)
+	SnapshotCreateCommand(ctx context.Context, data CommandSnapshotData) (*SnapshotInfo, error)
+	SnapshotRestoreCommand(ctx context.Context, data CommandSnapshotData) ([]string, error)
+	SnapshotDeleteCommand(ctx context.Context, data CommandSnapshotData) error
+	InputGroupListCommand(ctx context.Context, data CommandInputGroupListData) ([]InputGroupMember, error)
+	InputGroupSetCommand(ctx context.Context, data CommandInputGroupSetData) error
+	InputGroupBroadcastCommand(ctx context.Context, data CommandInputGroupBroadcastData) (int, error)
+	TermLogStatusCommand(ctx context.Context, blockId string) (*TermLogStatusData, error)
+	ShareStartCommand(ctx context.Context, data CommandShareStartData) (*ShareInfo, error)
+	ShareStopCommand(ctx context.Context, data CommandShareStopData) (int, error)
+	ShareListCommand(ctx context.Context, data CommandShareListData) ([]ShareInfo, error)
+	HistoryQueryCommand(ctx context.Context, data CommandHistoryQueryData) ([]HistoryEntry, error)
+	DirFrecencyQueryCommand(ctx context.Context, data CommandDirFrecencyQueryData) ([]DirFrecencyEntry, error)
+	DirFrecencyRemoveCommand(ctx context.Context, data CommandDirFrecencyRemoveData) error
 	GetUpdateChannelCommand(ctx context.Context) (string, error)
 
 	// terminal
@@ -218,6 +256,7 @@ type RpcOpts struct {
 	Timeout    int64  `json:"timeout,omitempty"`
 	NoResponse bool   `json:"noresponse,omitempty"`
 	Route      string `json:"route,omitempty"`
+	Restricted bool   `json:"-"` // send as a restricted caller (for requests made on behalf of an outside client)
 
 	StreamCancelFn func(context.Context) error `json:"-"` // this is an *output* parameter, set by the handler
 }
@@ -229,6 +268,8 @@ type RpcContext struct {
 	BlockId   string `json:"blockid,omitempty"`   // blockid for this rpc
 	Conn      string `json:"conn,omitempty"`      // the conn name
 	IsRouter  bool   `json:"isrouter,omitempty"`  // if this is for a sub-router
+
+	Capabilities []string `json:"capabilities,omitempty"` // commands this context may call (nil is unrestricted)
 }
 
 func (rc RpcContext) GenerateRouteId() string {
@@ -331,10 +372,14 @@ type CommandDeleteBlockData struct {
 	BlockId string `json:"blockid"`
 }
 
+// setting Durable (or a time range) reads from the sqlite event log instead of the in-memory ring
 type CommandEventReadHistoryData struct {
 	Event    string `json:"event"`
 	Scope    string `json:"scope"`
 	MaxItems int    `json:"maxitems"`
+	StartTs  int64  `json:"startts,omitempty"`
+	EndTs    int64  `json:"endts,omitempty"`
+	Durable  bool   `json:"durable,omitempty"`
 }
 
 type WaveAIStreamRequest struct {
@@ -407,6 +452,25 @@ type ConnRequest struct {
 	Host       string               `json:"host"`
 	Keywords   wconfig.ConnKeywords `json:"keywords,omitempty"`
 	LogBlockId string               `json:"logblockid,omitempty"`
+	CliPrompts bool                 `json:"cliprompts,omitempty"` // the calling wsh client can answer prompts on its tty (CliUserInputCommand)
+}
+
+type CommandCliUserInputData struct {
+	QueryText    string `json:"querytext"`
+	ResponseType string `json:"responsetype"`
+	Title        string `json:"title,omitempty"`
+	Markdown     bool   `json:"markdown,omitempty"`
+	TimeoutMs    int    `json:"timeoutms,omitempty"`
+	CheckBoxMsg  string `json:"checkboxmsg,omitempty"`
+	PublicText   bool   `json:"publictext,omitempty"`
+	OkLabel      string `json:"oklabel,omitempty"`
+	CancelLabel  string `json:"cancellabel,omitempty"`
+}
+
+type CliUserInputRtnData struct {
+	Text         string `json:"text,omitempty"`
+	Confirm      bool   `json:"confirm,omitempty"`
+	CheckboxStat bool   `json:"checkboxstat,omitempty"`
 }
 
 type RemoteInfo struct {
@@ -450,6 +514,240 @@ type ConnConfigRequest struct {
 	MetaMapType waveobj.MetaMapType `json:"metamaptype"`
 }
 
+type CommandSetWorkspaceConfigData struct {
+	WorkspaceId string           `json:"workspaceid"`
+	Meta        MetaSettingsType `json:"meta"`
+}
+
+type CommandConfigHistoryData struct {
+	File    string `json:"file,omitempty"` // "settings" (default) or "connections"
+	Version int    `json:"version,omitempty"`
+}
+
+type CommandSetTermThemeData struct {
+	ThemeKey string                `json:"themekey"`
+	Theme    wconfig.TermThemeType `json:"theme"`
+}
+
+type AutomationRuleInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayname,omitempty"`
+	Event       string `json:"event"`
+	Disabled    bool   `json:"disabled,omitempty"`
+	DryRun      bool   `json:"dryrun,omitempty"`
+	NumActions  int    `json:"numactions"`
+	FireCount   int    `json:"firecount"`
+	LastFireTs  int64  `json:"lastfirets,omitempty"`
+	LastError   string `json:"lasterror,omitempty"`
+	ConfigError string `json:"configerror,omitempty"`
+}
+
+// if Event is nil the rule is tested against the last event seen for its trigger.
+// actions are only executed when Run is set.
+type CommandAutomationTestData struct {
+	Name  string         `json:"name"`
+	Event *wps.WaveEvent `json:"event,omitempty"`
+	Run   bool           `json:"run,omitempty"`
+}
+
+type AutomationTestResult struct {
+	Name       string                      `json:"name"`
+	Event      *wps.WaveEvent              `json:"event,omitempty"`
+	Matched    bool                        `json:"matched"`
+	Conditions []AutomationConditionResult `json:"conditions,omitempty"`
+	Actions    []AutomationActionResult    `json:"actions,omitempty"`
+}
+
+type AutomationConditionResult struct {
+	Condition string `json:"condition"`
+	Matched   bool   `json:"matched"`
+	Error     string `json:"error,omitempty"`
+}
+
+type AutomationActionResult struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Ran         bool   `json:"ran,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type CommandAutomationSetEnabledData struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+type CommandApiTokenCreateData struct {
+	Name     string   `json:"name"`
+	Commands []string `json:"commands"` // gateway commands this token may call, "*" for all
+}
+
+type ApiTokenCreateRtnData struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+type ApiTokenInfo struct {
+	Id         string   `json:"id"`
+	Name       string   `json:"name"`
+	Commands   []string `json:"commands"`
+	CreatedTs  int64    `json:"createdts"`
+	LastUsedTs int64    `json:"lastusedts,omitempty"`
+}
+
+type PluginWshCommand struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Command     string `json:"command"` // rpc command sent to the plugin's route
+}
+
+type PluginInfo struct {
+	Name         string             `json:"name"`
+	DisplayName  string             `json:"displayname,omitempty"`
+	Description  string             `json:"description,omitempty"`
+	Dir          string             `json:"dir"`
+	Route        string             `json:"route"`
+	Enabled      bool               `json:"enabled,omitempty"`
+	Status       string             `json:"status"`
+	Pid          int                `json:"pid,omitempty"`
+	Restarts     int                `json:"restarts,omitempty"`
+	Error        string             `json:"error,omitempty"`
+	Commands     []string           `json:"commands,omitempty"`
+	WshCommands  []PluginWshCommand `json:"wshcommands,omitempty"`
+	Events       []string           `json:"events,omitempty"`
+	Capabilities []string           `json:"capabilities"`
+}
+
+type CommandPluginSetEnabledData struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// sent to a plugin's route when wsh dispatches one of its subcommands (a streaming request)
+type PluginWshCommandData struct {
+	Args    []string `json:"args"`
+	Cwd     string   `json:"cwd,omitempty"`
+	BlockId string   `json:"blockid,omitempty"`
+}
+
+// streamed back by the plugin.  the last response should set ExitCode.
+type PluginWshCommandOutput struct {
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode *int   `json:"exitcode,omitempty"`
+}
+
+type CommandRpcStatsData struct {
+	Reset bool `json:"reset,omitempty"`
+}
+
+// RpcCommandStats.Latencies[i] counts calls that finished within LatencyBucketsMs[i] (the extra last entry counts slower calls)
+type RpcStatsData struct {
+	Ts               int64             `json:"ts"`
+	SinceTs          int64             `json:"sincets"`
+	LatencyBucketsMs []int64           `json:"latencybucketsms"`
+	Commands         []RpcCommandStats `json:"commands"`
+	Links            []RpcLinkStats    `json:"links"`
+	TraceSampleRate  float64           `json:"tracesamplerate,omitempty"`
+}
+
+type RpcCommandStats struct {
+	Command   string  `json:"command"`
+	Count     int64   `json:"count"`
+	Errors    int64   `json:"errors,omitempty"`
+	Timeouts  int64   `json:"timeouts,omitempty"`
+	InFlight  int     `json:"inflight,omitempty"`
+	Stale     int     `json:"stale,omitempty"` // still waiting for a response past the caller's timeout
+	TotalMs   int64   `json:"totalms"`
+	MaxMs     int64   `json:"maxms"`
+	Latencies []int64 `json:"latencies"`
+}
+
+type RpcLinkStats struct {
+	LinkId           int      `json:"linkid"`
+	Name             string   `json:"name"`
+	Kind             string   `json:"kind,omitempty"`
+	Routes           []string `json:"routes,omitempty"`
+	MsgsSent         int64    `json:"msgssent"`
+	MsgsRecv         int64    `json:"msgsrecv"`
+	BytesSent        int64    `json:"bytessent"`
+	BytesRecv        int64    `json:"bytesrecv"`
+	Backlog          int      `json:"backlog,omitempty"`
+	BacklogHighWater int      `json:"backloghighwater,omitempty"`
+}
+
+type CommandRpcTraceData struct {
+	SampleRate *float64 `json:"samplerate,omitempty"` // set the sample rate (0 disables tracing)
+	Clear      bool     `json:"clear,omitempty"`
+	MaxEntries int      `json:"maxentries,omitempty"`
+}
+
+type RpcTraceData struct {
+	SampleRate float64         `json:"samplerate"`
+	Entries    []RpcTraceEntry `json:"entries"`
+}
+
+type RpcTraceEntry struct {
+	ReqId      string   `json:"reqid"`
+	Command    string   `json:"command"`
+	Source     string   `json:"source,omitempty"`
+	Route      string   `json:"route"`
+	Hops       []string `json:"hops"`
+	StartTs    int64    `json:"startts"`
+	DurationMs int64    `json:"durationms"`
+	Responses  int      `json:"responses"`
+	Error      string   `json:"error,omitempty"`
+}
+
+const (
+	ConfigBundleSection_Config  = "config"
+	ConfigBundleSection_Pet     = "pet"
+	ConfigBundleSection_Tsunami = "tsunami"
+)
+
+const (
+	ConfigImportStrategy_Merge     = "merge"
+	ConfigImportStrategy_Overwrite = "overwrite"
+)
+
+type ConfigBundle struct {
+	Version     int                `json:"version"`
+	CreatedTs   int64              `json:"createdts"`
+	Files       []ConfigBundleFile `json:"files"`
+	SecretNames []string           `json:"secretnames,omitempty"`
+	Stripped    []string           `json:"stripped,omitempty"`
+}
+
+// json config files are carried as Config, everything else (pet data, app sources) as Data64
+type ConfigBundleFile struct {
+	Section string              `json:"section"`
+	Path    string              `json:"path"`
+	Config  waveobj.MetaMapType `json:"config,omitempty"`
+	Data64  string              `json:"data64,omitempty"`
+}
+
+type CommandConfigImportData struct {
+	Bundle   ConfigBundle `json:"bundle"`
+	Strategy string       `json:"strategy,omitempty"` // "merge" (default) or "overwrite"
+	DryRun   bool         `json:"dryrun,omitempty"`
+}
+
+type ConfigImportConflict struct {
+	Section    string `json:"section"`
+	Path       string `json:"path"`
+	Key        string `json:"key,omitempty"`
+	Resolution string `json:"resolution"` // "kept" or "overwritten"
+}
+
+type ConfigImportResult struct {
+	DryRun         bool                   `json:"dryrun,omitempty"`
+	Written        []string               `json:"written,omitempty"`
+	Unchanged      []string               `json:"unchanged,omitempty"`
+	Conflicts      []ConfigImportConflict `json:"conflicts,omitempty"`
+	MissingSecrets []string               `json:"missingsecrets,omitempty"`
+	Warnings       []string               `json:"warnings,omitempty"`
+}
+
 type ConnStatus struct {
 	Status                        string `json:"status"`
 	ConnHealthStatus              string `json:"connhealthstatus,omitempty"`
@@ -488,9 +786,12 @@ type BlockInfoData struct {
 }
 
 type WaveNotificationOptions struct {
-	Title  string `json:"title,omitempty"`
-	Body   string `json:"body,omitempty"`
-	Silent bool   `json:"silent,omitempty"`
+	Title           string `json:"title,omitempty"`
+	Body            string `json:"body,omitempty"`
+	Silent          bool   `json:"silent,omitempty"`
+	TabId           string `json:"tabid,omitempty"`           // clicking the notification switches to this tab
+	BlockId         string `json:"blockid,omitempty"`         // and focuses this block
+	OnlyIfUnfocused bool   `json:"onlyifunfocused,omitempty"` // not shown while the tab's window has focus
 }
 
 type VDomUrlRequestData struct {
@@ -519,6 +820,167 @@ type WorkspaceInfoData struct {
 	WorkspaceData *waveobj.Workspace `json:"workspacedata"`
 }
 
+// Content is the layout file itself (json or yaml).  when empty, Name is looked up in <configdir>/layouts.
+type CommandWorkspaceApplyLayoutData struct {
+	WorkspaceId string            `json:"workspaceid"`
+	Name        string            `json:"name,omitempty"`
+	Content     string            `json:"content,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+}
+
+// when Name is empty the layout is only returned, not written to <configdir>/layouts
+type CommandWorkspaceSaveLayoutData struct {
+	WorkspaceId string            `json:"workspaceid"`
+	Name        string            `json:"name,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+	Overwrite   bool              `json:"overwrite,omitempty"`
+}
+
+type CommandWorkspaceSaveLayoutRtnData struct {
+	Path    string `json:"path,omitempty"`
+	Content string `json:"content"`
+}
+
+type SnapshotInfo struct {
+	SnapshotId    string `json:"snapshotid"`
+	Name          string `json:"name"`
+	WorkspaceName string `json:"workspacename,omitempty"`
+	CreatedTs     int64  `json:"createdts"`
+	Auto          bool   `json:"auto,omitempty"` // taken automatically when the workspace was closed
+	NumTabs       int    `json:"numtabs"`
+	NumBlocks     int    `json:"numblocks"`
+}
+
+// SnapshotId may be a snapshot id, an id prefix or a snapshot name (the newest snapshot with that name)
+type CommandSnapshotData struct {
+	WorkspaceId string `json:"workspaceid,omitempty"`
+	SnapshotId  string `json:"snapshotid,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+const (
+	InputGroupAction_Join    = "join"
+	InputGroupAction_Leave   = "leave"
+	InputGroupAction_Enable  = "enable"
+	InputGroupAction_Disable = "disable"
+)
+
+type InputGroupMember struct {
+	Group    string `json:"group"`
+	BlockId  string `json:"blockid"`
+	View     string `json:"view,omitempty"`
+	ConnName string `json:"connname,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+	Running  bool   `json:"running,omitempty"` // has a running shell that can receive input
+}
+
+// an empty Group lists the members of all groups
+type CommandInputGroupListData struct {
+	Group string `json:"group,omitempty"`
+}
+
+// Group is required for join
+type CommandInputGroupSetData struct {
+	Action   string   `json:"action"`
+	BlockIds []string `json:"blockids"`
+	Group    string   `json:"group,omitempty"`
+}
+
+type CommandInputGroupBroadcastData struct {
+	Group       string `json:"group"`
+	InputData64 string `json:"inputdata64"`
+}
+
+type TermLogStatusData struct {
+	BlockId    string `json:"blockid"`
+	Enabled    bool   `json:"enabled"`
+	Path       string `json:"path"`
+	Size       int64  `json:"size"` // size of the current log file (0 if it doesn't exist)
+	StripAnsi  bool   `json:"stripansi"`
+	Timestamps bool   `json:"timestamps"`
+	Dropped    int64  `json:"dropped,omitempty"` // output chunks dropped since the last write (the log gets a gap marker)
+}
+
+type ShareInfo struct {
+	ShareId   string `json:"shareid"`
+	BlockId   string `json:"blockid"`
+	Url       string `json:"url"`
+	Writable  bool   `json:"writable,omitempty"` // viewers may ask for write access (each request must be approved)
+	Viewers   int    `json:"viewers"`
+	CreatedTs int64  `json:"createdts"`
+	ExpiresTs int64  `json:"expirests,omitempty"` // 0 if the share doesn't expire
+}
+
+// ExpiresSecs 0 uses the default expiry, -1 never expires
+type CommandShareStartData struct {
+	BlockId     string `json:"blockid"`
+	Writable    bool   `json:"writable,omitempty"`
+	ExpiresSecs int    `json:"expiressecs,omitempty"`
+}
+
+// stops one share (ShareId) or all shares of a block (BlockId)
+type CommandShareStopData struct {
+	ShareId string `json:"shareid,omitempty"`
+	BlockId string `json:"blockid,omitempty"`
+}
+
+// an empty BlockId lists all shares
+type CommandShareListData struct {
+	BlockId string `json:"blockid,omitempty"`
+}
+
+type ShareStatusEventData struct {
+	BlockId string `json:"blockid"`
+	Shares  int    `json:"shares"`
+	Viewers int    `json:"viewers"`
+}
+
+type HistoryEntry struct {
+	Id         int64  `json:"id"`
+	Ts         int64  `json:"ts"` // command start
+	Cmd        string `json:"cmd"`
+	BlockId    string `json:"blockid"`
+	Connection string `json:"connection,omitempty"` // empty for local
+	ShellType  string `json:"shelltype,omitempty"`
+	Cwd        string `json:"cwd,omitempty"`
+	ExitCode   *int   `json:"exitcode,omitempty"` // nil if the command is still running (or never reported one)
+	DurationMs int64  `json:"durationms,omitempty"`
+	Count      int    `json:"count,omitempty"` // unique queries: how many times the command was run
+}
+
+// empty fields don't filter.  Connection "local" matches local commands.  results are newest first.
+type CommandHistoryQueryData struct {
+	Cwd        string `json:"cwd,omitempty"`
+	Connection string `json:"connection,omitempty"`
+	BlockId    string `json:"blockid,omitempty"`
+	ExitStatus string `json:"exitstatus,omitempty" tstype:"\"success\" | \"failed\""`
+	Unique     bool   `json:"unique,omitempty"` // one entry per command (its latest run)
+	MaxItems   int    `json:"maxitems,omitempty"`
+}
+
+type DirFrecencyEntry struct {
+	Connection string  `json:"connection,omitempty"`
+	Path       string  `json:"path"`
+	Rank       float64 `json:"rank"`   // number of visits (aged)
+	LastTs     int64   `json:"lastts"` // last visit
+	Score      float64 `json:"score"`  // rank weighted by how recent the last visit was
+}
+
+// directories are tracked per connection, an empty Connection (or "local") is local.  every keyword must
+// appear in the path (in order, case-insensitive) and the last one must match the last path component.
+// results are best first.
+type CommandDirFrecencyQueryData struct {
+	Connection string   `json:"connection,omitempty"`
+	Keywords   []string `json:"keywords,omitempty"`
+	Exclude    string   `json:"exclude,omitempty"` // e.g. the current directory
+	MaxItems   int      `json:"maxitems,omitempty"`
+}
+
+type CommandDirFrecencyRemoveData struct {
+	Connection string `json:"connection,omitempty"`
+	Path       string `json:"path"`
+}
+
 type BlocksListRequest struct {
 	WindowId    string `json:"windowid,omitempty"`
 	WorkspaceId string `json:"workspaceid,omitempty"`
@@ -647,6 +1109,9 @@ type FetchSuggestionsData struct {
 	FileCwd        string `json:"file:cwd,omitempty"`
 	FileDirOnly    bool   `json:"file:dironly,omitempty"`
 	FileConnection string `json:"file:connection,omitempty"`
+	HistoryCwd     string `json:"history:cwd,omitempty"`
+	HistoryConn    string `json:"history:connection,omitempty"`
+	DirConnection  string `json:"dir:connection,omitempty"`
 }
 
 type FetchSuggestionsResponse struct {
@@ -669,6 +1134,7 @@ type SuggestionType struct {
 	FilePath     string `json:"file:path,omitempty"`
 	FileName     string `json:"file:name,omitempty"`
 	UrlUrl       string `json:"url:url,omitempty"`
+	HistoryCmd   string `json:"history:cmd,omitempty"`
 }
 
 type CommandGetRTInfoData struct {
@@ -879,6 +1345,10 @@ type TabIndicatorEventData struct {
 	Indicator *TabIndicator `json:"indicator"`
 }
 
+type PetReactionEventData struct {
+	Text string `json:"text"`
+}
+
 type BlockJobStatusData struct {
 	BlockId       string `json:"blockid"`
 	JobId         string `json:"jobid"`
@@ -20,21 +20,33 @@ import (
 	"strings"
 	"time"
 
+	"github.com/SalyyS1/SLTerm/pkg/apitoken"
+	"github.com/SalyyS1/SLTerm/pkg/automation"
 	"github.com/SalyyS1/SLTerm/pkg/blockcontroller"
 	"github.com/SalyyS1/SLTerm/pkg/blocklogger"
+	"github.com/SalyyS1/SLTerm/pkg/cmdhistory"
+	"github.com/SalyyS1/SLTerm/pkg/cmdnotify"
 	"github.com/SalyyS1/SLTerm/pkg/buildercontroller"
+	"github.com/SalyyS1/SLTerm/pkg/configbundle"
+	"github.com/SalyyS1/SLTerm/pkg/eventlog"
 	"github.com/SalyyS1/SLTerm/pkg/filebackup"
 	"github.com/SalyyS1/SLTerm/pkg/filestore"
+	"github.com/SalyyS1/SLTerm/pkg/frecency"
 	"github.com/SalyyS1/SLTerm/pkg/genconn"
 	"github.com/SalyyS1/SLTerm/pkg/jobcontroller"
+	"github.com/SalyyS1/SLTerm/pkg/layoutfile"
 	"github.com/SalyyS1/SLTerm/pkg/panichandler"
+	"github.com/SalyyS1/SLTerm/pkg/plugin"
 	"github.com/SalyyS1/SLTerm/pkg/remote"
 	"github.com/SalyyS1/SLTerm/pkg/remote/conncontroller"
 	"github.com/SalyyS1/SLTerm/pkg/remote/fileshare/wshfs"
 	"github.com/SalyyS1/SLTerm/pkg/secretstore"
+	"github.com/SalyyS1/SLTerm/pkg/snapshot"
 	"github.com/SalyyS1/SLTerm/pkg/suggestion"
 	"github.com/SalyyS1/SLTerm/pkg/telemetry"
 	"github.com/SalyyS1/SLTerm/pkg/telemetry/telemetrydata"
+	"github.com/SalyyS1/SLTerm/pkg/termlog"
+	"github.com/SalyyS1/SLTerm/pkg/userinput"
 	"github.com/SalyyS1/SLTerm/pkg/util/envutil"
 	"github.com/SalyyS1/SLTerm/pkg/util/shellutil"
 	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
@@ -46,6 +58,7 @@ import (
 	"github.com/SalyyS1/SLTerm/pkg/wcloud"
 	"github.com/SalyyS1/SLTerm/pkg/wconfig"
 	"github.com/SalyyS1/SLTerm/pkg/wcore"
+	"github.com/SalyyS1/SLTerm/pkg/web/share"
 	"github.com/SalyyS1/SLTerm/pkg/wps"
 	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
 	"github.com/SalyyS1/SLTerm/pkg/wshutil"
@@ -151,13 +164,37 @@ func (ws *WshServer) GetMetaCommand(ctx context.Context, data wshrpc.CommandGetM
 	return waveobj.GetMeta(obj), nil
 }
 
+// restricted links (remote connections, scoped tokens) can't change what a block runs or its
+// capabilities, except when creating a block that runs on a remote connection
+func checkProtectedMeta(ctx context.Context, meta waveobj.MetaMapType, newBlock bool) error {
+	if wshutil.IsUnrestrictedCaller(ctx) {
+		return nil
+	}
+	connName := meta.GetString(waveobj.MetaKey_Connection, "")
+	remoteBlock := newBlock && !conncontroller.IsLocalConnName(connName) && !conncontroller.IsWslConnName(connName)
+	for key := range meta {
+		if !wshrpc.IsProtectedMetaKey(key) {
+			continue
+		}
+		if remoteBlock && wshrpc.NewRemoteBlockMetaKeys[key] {
+			continue
+		}
+		return fmt.Errorf("%q cannot be set from a restricted link", key)
+	}
+	return nil
+}
+
 func (ws *WshServer) SetMetaCommand(ctx context.Context, data wshrpc.CommandSetMetaData) error {
 	log.Printf("SetMetaCommand: %s | %v\n", data.ORef, data.Meta)
+	if err := checkProtectedMeta(ctx, data.Meta, false); err != nil {
+		return err
+	}
 	oref := data.ORef
 	err := wstore.UpdateObjectMeta(ctx, oref, data.Meta, false)
 	if err != nil {
 		return fmt.Errorf("error updating object meta: %w", err)
 	}
+	frecency.HandleMetaUpdate(oref, data.Meta)
 	wcore.SendWaveObjUpdate(oref)
 	return nil
 }
@@ -172,6 +209,8 @@ func (ws *WshServer) SetRTInfoCommand(ctx context.Context, data wshrpc.CommandSe
 		return nil
 	}
 	wstore.SetRTInfo(data.ORef, data.Data)
+	cmdhistory.HandleRTInfoUpdate(data.ORef, data.Data)
+	cmdnotify.HandleRTInfoUpdate(data.ORef, data.Data)
 	return nil
 }
 
@@ -199,6 +238,11 @@ func (ws *WshServer) ResolveIdsCommand(ctx context.Context, data wshrpc.CommandR
 }
 
 func (ws *WshServer) CreateBlockCommand(ctx context.Context, data wshrpc.CommandCreateBlockData) (*waveobj.ORef, error) {
+	if data.BlockDef != nil {
+		if err := checkProtectedMeta(ctx, data.BlockDef.Meta, true); err != nil {
+			return nil, err
+		}
+	}
 	ctx = waveobj.ContextWithUpdates(ctx)
 	tabId := data.TabId
 	blockData, err := wcore.CreateBlock(ctx, tabId, data.BlockDef, data.RtOpts)
@@ -273,6 +317,11 @@ func (ws *WshServer) CreateBlockCommand(ctx context.Context, data wshrpc.Command
 }
 
 func (ws *WshServer) CreateSubBlockCommand(ctx context.Context, data wshrpc.CommandCreateSubBlockData) (*waveobj.ORef, error) {
+	if data.BlockDef != nil {
+		if err := checkProtectedMeta(ctx, data.BlockDef.Meta, true); err != nil {
+			return nil, err
+		}
+	}
 	parentBlockId := data.ParentBlockId
 	blockData, err := wcore.CreateSubBlock(ctx, parentBlockId, data.BlockDef)
 	if err != nil {
@@ -306,6 +355,10 @@ func (ws *WshServer) ControllerInputCommand(ctx context.Context, data wshrpc.Com
 		}
 		inputUnion.InputData = inputBuf[:nw]
 	}
+	// keystrokes from the frontend go to the block's input group too, input sent by wsh (expect, z, ...) doesn't
+	if strings.HasPrefix(wshutil.GetRpcSourceFromContext(ctx), wshutil.RoutePrefix_Tab) {
+		return blockcontroller.SendUserInput(data.BlockId, inputUnion)
+	}
 	return blockcontroller.SendInput(data.BlockId, inputUnion)
 }
 
@@ -488,6 +541,18 @@ func (ws *WshServer) EventPublishCommand(ctx context.Context, data wps.WaveEvent
 	if rpcSource == "" {
 		return fmt.Errorf("no rpc source set")
 	}
+	// system events drive automations and the ui, only unrestricted links may publish them (or set
+	// the sender)
+	if !wshutil.IsUnrestrictedCaller(ctx) {
+		callerConn := wshutil.GetCallerConnName(ctx)
+		if !wps.CanPublishRestricted(data, callerConn) {
+			if data.Event == wps.Event_SysInfo {
+				return fmt.Errorf("cannot publish %q for %v, only for this link's own connection", data.Event, data.Scopes)
+			}
+			return fmt.Errorf("cannot publish %q, custom events must be in the %q namespace", data.Event, wps.UserEventPrefix)
+		}
+		data.Sender = rpcSource
+	}
 	if data.Sender == "" {
 		data.Sender = rpcSource
 	}
@@ -523,11 +588,36 @@ func (ws *WshServer) EventUnsubAllCommand(ctx context.Context) error {
 }
 
 func (ws *WshServer) EventReadHistoryCommand(ctx context.Context, data wshrpc.CommandEventReadHistoryData) ([]*wps.WaveEvent, error) {
+	if data.Durable || data.StartTs > 0 || data.EndTs > 0 {
+		return eventlog.ReadEvents(ctx, eventlog.ReadOpts{
+			Event:    data.Event,
+			Scope:    data.Scope,
+			StartTs:  data.StartTs,
+			EndTs:    data.EndTs,
+			MaxItems: data.MaxItems,
+		})
+	}
 	events := wps.Broker.ReadEventHistory(data.Event, data.Scope, data.MaxItems)
 	return events, nil
 }
 
+// restricted links (scoped tokens, plugins) can't change what runs locally or widen capabilities
+func checkProtectedSettings(ctx context.Context, settings waveobj.MetaMapType) error {
+	if wshutil.IsUnrestrictedCaller(ctx) {
+		return nil
+	}
+	for key := range settings {
+		if wshrpc.IsProtectedSettingsKey(key) {
+			return fmt.Errorf("%q cannot be set from a restricted link", key)
+		}
+	}
+	return nil
+}
+
 func (ws *WshServer) SetConfigCommand(ctx context.Context, data wshrpc.MetaSettingsType) error {
+	if err := checkProtectedSettings(ctx, data.MetaMapType); err != nil {
+		return err
+	}
 	return wconfig.SetBaseConfigValue(data.MetaMapType)
 }
 
@@ -535,6 +625,86 @@ func (ws *WshServer) SetConnectionsConfigCommand(ctx context.Context, data wshrp
 	return wconfig.SetConnectionsConfigValue(data.Host, data.MetaMapType)
 }
 
+func (ws *WshServer) SetWorkspaceConfigCommand(ctx context.Context, data wshrpc.CommandSetWorkspaceConfigData) error {
+	if data.WorkspaceId == "" {
+		return fmt.Errorf("workspaceid is required")
+	}
+	if err := checkProtectedSettings(ctx, data.Meta.MetaMapType); err != nil {
+		return err
+	}
+	return wcore.SetWorkspaceSettings(ctx, data.WorkspaceId, data.Meta.MetaMapType)
+}
+
+func (ws *WshServer) ConfigHistoryListCommand(ctx context.Context, data wshrpc.CommandConfigHistoryData) ([]wconfig.ConfigHistoryEntry, error) {
+	return wconfig.ListConfigHistory(data.File)
+}
+
+func (ws *WshServer) ConfigHistoryDiffCommand(ctx context.Context, data wshrpc.CommandConfigHistoryData) ([]wconfig.ConfigDiffEntry, error) {
+	return wconfig.DiffConfigHistory(data.File, data.Version)
+}
+
+func (ws *WshServer) ConfigHistoryRollbackCommand(ctx context.Context, data wshrpc.CommandConfigHistoryData) error {
+	return wconfig.RollbackConfigHistory(data.File, data.Version)
+}
+
+func (ws *WshServer) ConfigExportCommand(ctx context.Context) (*wshrpc.ConfigBundle, error) {
+	return configbundle.Export()
+}
+
+func (ws *WshServer) ConfigImportCommand(ctx context.Context, data wshrpc.CommandConfigImportData) (*wshrpc.ConfigImportResult, error) {
+	return configbundle.Import(data)
+}
+
+func (ws *WshServer) SetTermThemeCommand(ctx context.Context, data wshrpc.CommandSetTermThemeData) error {
+	if data.ThemeKey == "" {
+		return fmt.Errorf("theme key is required")
+	}
+	return wconfig.SetTermThemeConfigValue(data.ThemeKey, data.Theme)
+}
+
+func (ws *WshServer) AutomationListCommand(ctx context.Context) ([]wshrpc.AutomationRuleInfo, error) {
+	return automation.ListRules(), nil
+}
+
+func (ws *WshServer) AutomationTestCommand(ctx context.Context, data wshrpc.CommandAutomationTestData) (*wshrpc.AutomationTestResult, error) {
+	// the caller picks the event, running the actions is only for unrestricted (local) callers
+	if data.Run && !wshutil.IsUnrestrictedCaller(ctx) {
+		return nil, fmt.Errorf("actions can only be run from an unrestricted local link, use a dry run")
+	}
+	return automation.TestRule(ctx, data)
+}
+
+func (ws *WshServer) AutomationSetEnabledCommand(ctx context.Context, data wshrpc.CommandAutomationSetEnabledData) error {
+	if data.Name == "" {
+		return fmt.Errorf("automation name is required")
+	}
+	return wconfig.SetAutomationDisabled(data.Name, !data.Enabled)
+}
+
+func (ws *WshServer) ApiTokenCreateCommand(ctx context.Context, data wshrpc.CommandApiTokenCreateData) (*wshrpc.ApiTokenCreateRtnData, error) {
+	return apitoken.Create(ctx, data)
+}
+
+func (ws *WshServer) ApiTokenListCommand(ctx context.Context) ([]wshrpc.ApiTokenInfo, error) {
+	return apitoken.List(ctx)
+}
+
+func (ws *WshServer) ApiTokenRevokeCommand(ctx context.Context, idOrName string) error {
+	return apitoken.Revoke(ctx, idOrName)
+}
+
+func (ws *WshServer) PluginListCommand(ctx context.Context) ([]wshrpc.PluginInfo, error) {
+	return plugin.ListPlugins(), nil
+}
+
+func (ws *WshServer) PluginSetEnabledCommand(ctx context.Context, data wshrpc.CommandPluginSetEnabledData) error {
+	return plugin.SetPluginEnabled(data.Name, data.Enabled)
+}
+
+func (ws *WshServer) PluginRestartCommand(ctx context.Context, name string) error {
+	return plugin.RestartPlugin(name)
+}
+
 func (ws *WshServer) GetFullConfigCommand(ctx context.Context) (wconfig.FullConfigType, error) {
 	watcher := wconfig.GetWatcher()
 	return watcher.GetFullConfig(), nil
@@ -592,6 +762,13 @@ func (ws *WshServer) ConnDisconnectCommand(ctx context.Context, connName string)
 		}
 		return conn.Close()
 	}
+	if conncontroller.IsTlsConnName(connName) {
+		conn := conncontroller.MaybeGetTlsConn(connName)
+		if conn == nil {
+			return fmt.Errorf("connection not found: %s", connName)
+		}
+		return conn.Close()
+	}
 	connOpts, err := remote.ParseOpts(connName)
 	if err != nil {
 		return fmt.Errorf("error parsing connection name: %w", err)
@@ -609,6 +786,9 @@ func (ws *WshServer) ConnConnectCommand(ctx context.Context, connRequest wshrpc.
 	}
 	ctx = genconn.ContextWithConnData(ctx, connRequest.LogBlockId)
 	ctx = termCtxWithLogBlockId(ctx, connRequest.LogBlockId)
+	if connRequest.CliPrompts {
+		ctx = userinput.ContextWithCliRoute(ctx, wshutil.GetRpcSourceFromContext(ctx))
+	}
 	connName := connRequest.Host
 	if strings.HasPrefix(connName, "wsl://") {
 		distroName := strings.TrimPrefix(connName, "wsl://")
@@ -618,6 +798,13 @@ func (ws *WshServer) ConnConnectCommand(ctx context.Context, connRequest wshrpc.
 		}
 		return conn.Connect(ctx)
 	}
+	if conncontroller.IsTlsConnName(connName) {
+		conn := conncontroller.GetTlsConn(connName)
+		if conn == nil {
+			return fmt.Errorf("connection not found: %s", connName)
+		}
+		return conn.Connect(ctx)
+	}
 	connOpts, err := remote.ParseOpts(connName)
 	if err != nil {
 		return fmt.Errorf("error parsing connection name: %w", err)
@@ -644,6 +831,9 @@ func (ws *WshServer) ConnReinstallWshCommand(ctx context.Context, data wshrpc.Co
 		}
 		return conn.InstallWsh(ctx, "")
 	}
+	if conncontroller.IsTlsConnName(connName) {
+		return fmt.Errorf("wsh can't be installed over a tls connection, update wsh on the remote and restart `wsh connserver --listen`")
+	}
 	connOpts, err := remote.ParseOpts(connName)
 	if err != nil {
 		return fmt.Errorf("error parsing connection name: %w", err)
@@ -681,6 +871,9 @@ func (ws *WshServer) ConnUpdateWshCommand(ctx context.Context, remoteInfo wshrpc
 	if strings.HasPrefix(connName, "wsl://") {
 		return false, fmt.Errorf("connupdatewshcommand is not supported for wsl connections")
 	}
+	if conncontroller.IsTlsConnName(connName) {
+		return false, fmt.Errorf("connupdatewshcommand is not supported for tls connections")
+	}
 	connOpts, err := remote.ParseOpts(connName)
 	if err != nil {
 		return false, fmt.Errorf("error parsing connection name: %w", err)
@@ -698,6 +891,10 @@ func (ws *WshServer) ConnUpdateWshCommand(ctx context.Context, remoteInfo wshrpc
 	return true, nil
 }
 
+func (ws *WshServer) ConnTlsFingerprintCommand(ctx context.Context) (string, error) {
+	return conncontroller.GetTlsClientFingerprint()
+}
+
 func (ws *WshServer) ConnListCommand(ctx context.Context) ([]string, error) {
 	return conncontroller.GetConnectionsList()
 }
@@ -743,6 +940,9 @@ func (ws *WshServer) DismissWshFailCommand(ctx context.Context, connName string)
 		conn.FireConnChangeEvent()
 		return nil
 	}
+	if conncontroller.IsTlsConnName(connName) {
+		return nil
+	}
 	opts, err := remote.ParseOpts(connName)
 	if err != nil {
 		return err
@@ -904,6 +1104,216 @@ func (ws *WshServer) WorkspaceListCommand(ctx context.Context) ([]wshrpc.Workspa
 	return rtn, nil
 }
 
+func (ws *WshServer) WorkspaceApplyLayoutCommand(ctx context.Context, data wshrpc.CommandWorkspaceApplyLayoutData) ([]string, error) {
+	if data.WorkspaceId == "" {
+		return nil, fmt.Errorf("workspaceid is required")
+	}
+	content := []byte(data.Content)
+	if data.Content == "" {
+		var err error
+		content, err = wcore.ReadConfigLayoutFile(data.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	lf, err := layoutfile.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	err = lf.Substitute(data.Params)
+	if err != nil {
+		return nil, err
+	}
+	for _, tab := range lf.Tabs {
+		if err := checkLayoutProtectedMeta(ctx, tab.Layout); err != nil {
+			return nil, fmt.Errorf("tab %q: %w", tab.Name, err)
+		}
+	}
+	ctx = waveobj.ContextWithUpdates(ctx)
+	tabIds, err := wcore.ApplyLayoutFile(ctx, data.WorkspaceId, lf)
+	updates := waveobj.ContextGetUpdatesRtn(ctx)
+	wps.Broker.SendUpdateEvents(updates)
+	if err != nil {
+		return tabIds, fmt.Errorf("error applying layout: %w", err)
+	}
+	return tabIds, nil
+}
+
+// the blocks of a layout get the same protected meta check as CreateBlockCommand.  the "shell" controller
+// BlockMeta gives plain terminals is a default, not something the layout asked for.
+func checkLayoutProtectedMeta(ctx context.Context, node *layoutfile.NodeDef) error {
+	if node == nil {
+		return nil
+	}
+	if len(node.Children) > 0 {
+		for _, child := range node.Children {
+			if err := checkLayoutProtectedMeta(ctx, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	meta := node.BlockMeta()
+	if _, ok := node.Meta[waveobj.MetaKey_Controller]; !ok {
+		delete(meta, waveobj.MetaKey_Controller)
+	}
+	return checkProtectedMeta(ctx, meta, true)
+}
+
+func (ws *WshServer) WorkspaceSaveLayoutCommand(ctx context.Context, data wshrpc.CommandWorkspaceSaveLayoutData) (*wshrpc.CommandWorkspaceSaveLayoutRtnData, error) {
+	if data.WorkspaceId == "" {
+		return nil, fmt.Errorf("workspaceid is required")
+	}
+	lf, err := wcore.GetWorkspaceLayoutFile(ctx, data.WorkspaceId)
+	if err != nil {
+		return nil, err
+	}
+	lf.Parameterize(data.Params)
+	barr, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error serializing layout: %w", err)
+	}
+	rtn := &wshrpc.CommandWorkspaceSaveLayoutRtnData{Content: string(barr) + "\n"}
+	if data.Name != "" {
+		rtn.Path, err = wcore.WriteConfigLayoutFile(data.Name, lf, data.Overwrite)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rtn, nil
+}
+
+func (ws *WshServer) SnapshotListCommand(ctx context.Context) ([]wshrpc.SnapshotInfo, error) {
+	return snapshot.List(ctx)
+}
+
+func (ws *WshServer) SnapshotCreateCommand(ctx context.Context, data wshrpc.CommandSnapshotData) (*wshrpc.SnapshotInfo, error) {
+	if data.WorkspaceId == "" {
+		return nil, fmt.Errorf("workspaceid is required")
+	}
+	return snapshot.Create(ctx, data.WorkspaceId, data.Name, false)
+}
+
+func (ws *WshServer) SnapshotRestoreCommand(ctx context.Context, data wshrpc.CommandSnapshotData) ([]string, error) {
+	if data.WorkspaceId == "" {
+		return nil, fmt.Errorf("workspaceid is required")
+	}
+	snapshotId, err := snapshot.Resolve(ctx, data.SnapshotId)
+	if err != nil {
+		return nil, err
+	}
+	ctx = waveobj.ContextWithUpdates(ctx)
+	tabIds, err := snapshot.Restore(ctx, snapshotId, data.WorkspaceId)
+	updates := waveobj.ContextGetUpdatesRtn(ctx)
+	wps.Broker.SendUpdateEvents(updates)
+	if err != nil {
+		return tabIds, fmt.Errorf("error restoring snapshot: %w", err)
+	}
+	return tabIds, nil
+}
+
+func (ws *WshServer) SnapshotDeleteCommand(ctx context.Context, data wshrpc.CommandSnapshotData) error {
+	snapshotId, err := snapshot.Resolve(ctx, data.SnapshotId)
+	if err != nil {
+		return err
+	}
+	return snapshot.Delete(ctx, snapshotId)
+}
+
+func (ws *WshServer) InputGroupListCommand(ctx context.Context, data wshrpc.CommandInputGroupListData) ([]wshrpc.InputGroupMember, error) {
+	return blockcontroller.ListInputGroupMembers(ctx, data.Group)
+}
+
+func (ws *WshServer) InputGroupSetCommand(ctx context.Context, data wshrpc.CommandInputGroupSetData) error {
+	var meta waveobj.MetaMapType
+	switch data.Action {
+	case wshrpc.InputGroupAction_Join:
+		if data.Group == "" {
+			return fmt.Errorf("no input group specified")
+		}
+		meta = waveobj.MetaMapType{waveobj.MetaKey_TermInputGroup: data.Group, waveobj.MetaKey_TermInputGroupDisabled: nil}
+	case wshrpc.InputGroupAction_Leave:
+		meta = waveobj.MetaMapType{waveobj.MetaKey_TermInputGroup: nil, waveobj.MetaKey_TermInputGroupDisabled: nil}
+	case wshrpc.InputGroupAction_Enable:
+		meta = waveobj.MetaMapType{waveobj.MetaKey_TermInputGroupDisabled: nil}
+	case wshrpc.InputGroupAction_Disable:
+		meta = waveobj.MetaMapType{waveobj.MetaKey_TermInputGroupDisabled: true}
+	default:
+		return fmt.Errorf("invalid input group action %q", data.Action)
+	}
+	for _, blockId := range data.BlockIds {
+		block, err := wstore.DBMustGet[*waveobj.Block](ctx, blockId)
+		if err != nil {
+			return fmt.Errorf("error getting block %s: %w", blockId, err)
+		}
+		if data.Action != wshrpc.InputGroupAction_Join && block.Meta.GetString(waveobj.MetaKey_TermInputGroup, "") == "" {
+			if data.Action == wshrpc.InputGroupAction_Leave {
+				continue
+			}
+			return fmt.Errorf("block %s is not in an input group", blockId)
+		}
+		oref := waveobj.MakeORef(waveobj.OType_Block, blockId)
+		err = wstore.UpdateObjectMeta(ctx, oref, meta, false)
+		if err != nil {
+			return fmt.Errorf("error updating block %s: %w", blockId, err)
+		}
+		wcore.SendWaveObjUpdate(oref)
+	}
+	return nil
+}
+
+func (ws *WshServer) InputGroupBroadcastCommand(ctx context.Context, data wshrpc.CommandInputGroupBroadcastData) (int, error) {
+	inputData, err := base64.StdEncoding.DecodeString(data.InputData64)
+	if err != nil {
+		return 0, fmt.Errorf("error decoding input data: %w", err)
+	}
+	return blockcontroller.BroadcastGroupInput(ctx, data.Group, inputData)
+}
+
+func (ws *WshServer) TermLogStatusCommand(ctx context.Context, blockId string) (*wshrpc.TermLogStatusData, error) {
+	return termlog.GetLogStatus(ctx, blockId)
+}
+
+func (ws *WshServer) ShareStartCommand(ctx context.Context, data wshrpc.CommandShareStartData) (*wshrpc.ShareInfo, error) {
+	return share.StartShare(ctx, data)
+}
+
+func (ws *WshServer) ShareStopCommand(ctx context.Context, data wshrpc.CommandShareStopData) (int, error) {
+	return share.StopShares(data)
+}
+
+func (ws *WshServer) ShareListCommand(ctx context.Context, data wshrpc.CommandShareListData) ([]wshrpc.ShareInfo, error) {
+	return share.ListShares(data.BlockId), nil
+}
+
+func (ws *WshServer) HistoryQueryCommand(ctx context.Context, data wshrpc.CommandHistoryQueryData) ([]wshrpc.HistoryEntry, error) {
+	return cmdhistory.QueryHistory(ctx, data)
+}
+
+// a restricted link on a connection (a remote's default capabilities include the dirfrecency commands)
+// only gets that connection's directories.  other restricted links were granted the history capability.
+func checkDirFrecencyConn(ctx context.Context, connName string) error {
+	callerConn := wshutil.GetCallerConnName(ctx)
+	if callerConn != "" && connName != callerConn {
+		return fmt.Errorf("a connection can only read its own directories (%q)", callerConn)
+	}
+	return nil
+}
+
+func (ws *WshServer) DirFrecencyQueryCommand(ctx context.Context, data wshrpc.CommandDirFrecencyQueryData) ([]wshrpc.DirFrecencyEntry, error) {
+	if err := checkDirFrecencyConn(ctx, data.Connection); err != nil {
+		return nil, err
+	}
+	return frecency.QueryDirs(ctx, data)
+}
+
+func (ws *WshServer) DirFrecencyRemoveCommand(ctx context.Context, data wshrpc.CommandDirFrecencyRemoveData) error {
+	if err := checkDirFrecencyConn(ctx, data.Connection); err != nil {
+		return err
+	}
+	return frecency.RemoveDir(ctx, data.Connection, data.Path)
+}
+
 func (ws *WshServer) ListAllAppsCommand(ctx context.Context) ([]wshrpc.AppInfo, error) {
 	return waveappstore.ListAllApps()
 }
@@ -1380,6 +1790,18 @@ func (ws *WshServer) PathCommand(ctx context.Context, data wshrpc.PathCommandDat
 }
 
 func (ws *WshServer) FetchSuggestionsCommand(ctx context.Context, data wshrpc.FetchSuggestionsData) (*wshrpc.FetchSuggestionsResponse, error) {
+	// history suggestions expose what was typed in every block, same as historyquery.  dir suggestions
+	// are the visited directories, a restricted link only gets its own connection's (fetchsuggestions is
+	// a core command, so this can't rely on the history capability).
+	if !wshutil.IsUnrestrictedCaller(ctx) {
+		if data.SuggestionType == "history" {
+			return nil, fmt.Errorf("history suggestions are not available to restricted links")
+		}
+		callerConn := wshutil.GetCallerConnName(ctx)
+		if data.SuggestionType == "dir" && (callerConn == "" || data.DirConnection != callerConn) {
+			return nil, fmt.Errorf("directory suggestions are only available for the caller's own connection")
+		}
+	}
 	return suggestion.FetchSuggestions(ctx, data)
 }
 
@@ -1400,6 +1822,14 @@ func (ws *WshServer) GetAllTabIndicatorsCommand(ctx context.Context) (map[string
 	return wcore.GetAllTabIndicators(), nil
 }
 
+func (ws *WshServer) RpcStatsCommand(ctx context.Context, data wshrpc.CommandRpcStatsData) (*wshrpc.RpcStatsData, error) {
+	return wshutil.DefaultRouter.GetRpcStats(data.Reset), nil
+}
+
+func (ws *WshServer) RpcTraceCommand(ctx context.Context, data wshrpc.CommandRpcTraceData) (*wshrpc.RpcTraceData, error) {
+	return wshutil.GetRpcTrace(data), nil
+}
+
 func (ws *WshServer) GetSecretsCommand(ctx context.Context, names []string) (map[string]string, error) {
 	result := make(map[string]string)
 	for _, name := range names {
@@ -0,0 +1,175 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wshserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/filestore"
+	"github.com/SalyyS1/SLTerm/pkg/frecency"
+	"github.com/SalyyS1/SLTerm/pkg/wavebase"
+	"github.com/SalyyS1/SLTerm/pkg/wcore"
+	"github.com/SalyyS1/SLTerm/pkg/wps"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc/wshclient"
+	"github.com/SalyyS1/SLTerm/pkg/wshutil"
+	"github.com/SalyyS1/SLTerm/pkg/wstore"
+)
+
+var testRouterOnce sync.Once
+
+// real stores in a temp data dir and a root router with the wshserver on the default route (like wavesrv).
+// restricted calls are sent through the bare client with RpcOpts.Restricted.
+// the router is shared across tests, the main and bare clients are singletons bound to it.
+func initTestServer(t *testing.T) {
+	wavebase.DataHome_VarCache = t.TempDir()
+	wavebase.ConfigHome_VarCache = t.TempDir()
+	err := os.MkdirAll(filepath.Join(wavebase.GetWaveDataDir(), wavebase.WaveDBDir), 0700)
+	if err != nil {
+		t.Fatalf("creating db dir: %v", err)
+	}
+	if err := wstore.InitWStore(); err != nil {
+		t.Fatalf("initializing wstore: %v", err)
+	}
+	if err := filestore.InitFilestore(); err != nil {
+		t.Fatalf("initializing filestore: %v", err)
+	}
+	testRouterOnce.Do(func() {
+		wshutil.DefaultRouter = wshutil.NewWshRouter()
+		wshutil.DefaultRouter.SetAsRootRouter()
+		wshutil.DefaultRouter.RegisterTrustedLeaf(GetMainRpcClient(), wshutil.DefaultRoute)
+	})
+}
+
+func sendRestricted(command string, data any) (any, error) {
+	return wshclient.GetBareRpcClient().SendRpcRequest(command, data, &wshrpc.RpcOpts{Restricted: true})
+}
+
+// returns a sender for restricted calls from a leaf with a conn: route, like the link of a connserver
+func makeConnSender(t *testing.T, connName string) func(string, any) (any, error) {
+	connRpc := wshutil.MakeWshRpc(wshrpc.RpcContext{}, &wshclient.WshServerImpl, "conn-test")
+	if _, err := wshutil.DefaultRouter.RegisterTrustedLeaf(connRpc, wshutil.MakeConnectionRouteId(connName)); err != nil {
+		t.Fatalf("registering conn link: %v", err)
+	}
+	return func(command string, data any) (any, error) {
+		return connRpc.SendRpcRequest(command, data, &wshrpc.RpcOpts{Restricted: true})
+	}
+}
+
+func TestApplyLayoutProtectedMeta(t *testing.T) {
+	initTestServer(t)
+	ws, err := wcore.CreateWorkspace(context.Background(), "dev", "", "", false, false)
+	if err != nil {
+		t.Fatalf("creating workspace: %v", err)
+	}
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"plain terminal", `{"tabs": [{"name": "t", "layout": {"cwd": "/tmp"}}]}`, false},
+		{"cmd", `{"tabs": [{"name": "t", "layout": {"cmd": "touch /tmp/pwned"}}]}`, true},
+		{"nested controller", `{"tabs": [{"name": "t", "layout": {"children": [{}, {"meta": {"controller": "cmd", "cmd": "sh"}}]}}]}`, true},
+		{"capabilities", `{"tabs": [{"name": "t", "layout": {"meta": {"cmd:capabilities": ["*"]}}}]}`, true},
+	}
+	for _, tc := range tests {
+		_, err := sendRestricted("workspaceapplylayout", wshrpc.CommandWorkspaceApplyLayoutData{WorkspaceId: ws.OID, Content: tc.content})
+		if tc.wantErr && (err == nil || !strings.Contains(err.Error(), "restricted link")) {
+			t.Errorf("%s: got %v, want a restricted link error", tc.name, err)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+	}
+}
+
+func TestWorkspaceSettingsProtected(t *testing.T) {
+	initTestServer(t)
+	ws, err := wcore.CreateWorkspace(context.Background(), "dev", "", "", false, false)
+	if err != nil {
+		t.Fatalf("creating workspace: %v", err)
+	}
+	wsORef := "workspace:" + ws.OID
+	// workspace settings are only written through setworkspaceconfig, and never the local shell
+	_, err = sendRestricted("setmeta", map[string]any{"oref": wsORef, "meta": map[string]any{
+		"workspace:settings": map[string]any{"term:localshellpath": "/tmp/pwned"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "restricted link") {
+		t.Errorf("setmeta workspace:settings: got %v, want a restricted link error", err)
+	}
+	_, err = sendRestricted("setworkspaceconfig", map[string]any{"workspaceid": ws.OID, "meta": map[string]any{"term:localshellpath": "/tmp/pwned"}})
+	if err == nil || !strings.Contains(err.Error(), "restricted link") {
+		t.Errorf("setworkspaceconfig term:localshellpath: got %v, want a restricted link error", err)
+	}
+	_, err = sendRestricted("setworkspaceconfig", map[string]any{"workspaceid": ws.OID, "meta": map[string]any{"term:fontsize": 14}})
+	if err != nil {
+		t.Errorf("setworkspaceconfig term:fontsize: %v", err)
+	}
+	settings := wcore.ResolveTabSettings(context.Background(), ws.ActiveTabId)
+	if settings.TermFontSize != 14 || settings.TermLocalShellPath != "" {
+		t.Errorf("resolved settings fontsize %v shell %q", settings.TermFontSize, settings.TermLocalShellPath)
+	}
+}
+
+func TestDirFrecencyRestricted(t *testing.T) {
+	initTestServer(t)
+	ctx := context.Background()
+	frecency.AddVisit(ctx, "user@host", "/srv/app", time.Now().UnixMilli())
+	frecency.AddVisit(ctx, "local", "/home/me/secret", time.Now().UnixMilli())
+	sendFromConn := makeConnSender(t, "user@host")
+
+	rtn, err := sendFromConn("dirfrecencyquery", wshrpc.CommandDirFrecencyQueryData{Connection: "user@host"})
+	if err != nil || !strings.Contains(fmt.Sprint(rtn), "/srv/app") {
+		t.Errorf("own connection: got %v, %v", rtn, err)
+	}
+	tests := []struct {
+		name    string
+		send    func(string, any) (any, error)
+		command string
+		data    any
+	}{
+		{"query local dirs", sendFromConn, "dirfrecencyquery", wshrpc.CommandDirFrecencyQueryData{Connection: "local"}},
+		{"remove local dir", sendFromConn, "dirfrecencyremove", wshrpc.CommandDirFrecencyRemoveData{Connection: "local", Path: "/home/me/secret"}},
+		{"local dir suggestions", sendFromConn, "fetchsuggestions", wshrpc.FetchSuggestionsData{SuggestionType: "dir", DirConnection: "local"}},
+		{"dir suggestions without a connection", sendRestricted, "fetchsuggestions", wshrpc.FetchSuggestionsData{SuggestionType: "dir", DirConnection: "user@host"}},
+	}
+	for _, tc := range tests {
+		if _, err := tc.send(tc.command, tc.data); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+	if _, err := sendFromConn("fetchsuggestions", wshrpc.FetchSuggestionsData{SuggestionType: "dir", DirConnection: "user@host", Query: "app"}); err != nil {
+		t.Errorf("own connection dir suggestions: %v", err)
+	}
+}
+
+func TestEventPublishSysInfoRestricted(t *testing.T) {
+	initTestServer(t)
+	sendFromConn := makeConnSender(t, "user@sysinfo")
+	tests := []struct {
+		name    string
+		send    func(string, any) (any, error)
+		event   wps.WaveEvent
+		wantErr bool
+	}{
+		{"own connection", sendFromConn, wps.WaveEvent{Event: wps.Event_SysInfo, Scopes: []string{"user@sysinfo"}}, false},
+		{"local", sendFromConn, wps.WaveEvent{Event: wps.Event_SysInfo, Scopes: []string{"local"}}, true},
+		{"extra scope", sendFromConn, wps.WaveEvent{Event: wps.Event_SysInfo, Scopes: []string{"user@sysinfo", "local"}}, true},
+		{"no connection", sendRestricted, wps.WaveEvent{Event: wps.Event_SysInfo, Scopes: []string{"local"}}, true},
+		{"tab indicator", sendRestricted, wps.WaveEvent{Event: wps.Event_TabIndicator}, false},
+	}
+	for _, tc := range tests {
+		_, err := tc.send("eventpublish", tc.event)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
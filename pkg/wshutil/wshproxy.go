@@ -13,11 +13,12 @@ import (
 )
 
 type WshRpcProxy struct {
-	Lock         *sync.Mutex
-	RpcContext   *wshrpc.RpcContext
-	ToRemoteCh   chan []byte
-	FromRemoteCh chan baseds.RpcInputChType
-	PeerInfo     string
+	Lock           *sync.Mutex
+	RpcContext     *wshrpc.RpcContext
+	ToRemoteCh     chan []byte
+	ToRemoteHighCh chan []byte // nil unless EnablePriorityLane was called
+	FromRemoteCh   chan baseds.RpcInputChType
+	PeerInfo       string
 }
 
 func MakeRpcProxy(peerInfo string) *WshRpcProxy {
@@ -59,6 +60,27 @@ func (p *WshRpcProxy) SendRpcMessage(msg []byte, ingressLinkId baseds.LinkId, de
 	}
 }
 
+// EnablePriorityLane routes high priority messages to ToRemoteHighCh.  only call it (before registering
+// the proxy with a router) if the writer drains ToRemoteHighCh as well, see LinkStream.WriteLoop.
+func (p *WshRpcProxy) EnablePriorityLane() {
+	p.ToRemoteHighCh = make(chan []byte, cap(p.ToRemoteCh))
+}
+
+func (p *WshRpcProxy) SendRpcMessagePriority(msg []byte, ingressLinkId baseds.LinkId, priority int, debugStr string) bool {
+	if priority != RpcPriority_High || p.ToRemoteHighCh == nil {
+		return p.SendRpcMessage(msg, ingressLinkId, debugStr)
+	}
+	defer func() {
+		panichandler.PanicHandler("WshRpcProxy.SendRpcMessagePriority", recover())
+	}()
+	select {
+	case p.ToRemoteHighCh <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *WshRpcProxy) RecvRpcMessage() ([]byte, bool) {
 	inputVal, more := <-p.FromRemoteCh
 	return inputVal.MsgBytes, more
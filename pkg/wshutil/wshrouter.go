@@ -38,6 +38,7 @@ const (
 	RoutePrefix_Link       = "link:"
 	RoutePrefix_Job        = "job:"
 	RoutePrefix_Bare       = "bare:"
+	RoutePrefix_Plugin     = "plugin:"
 )
 
 const RouterInputChQueueSize = 100
@@ -62,7 +63,9 @@ type linkMeta struct {
 	trusted       bool
 	linkKind      string
 	sourceRouteId string
+	capabilities  []string // from the link's auth token, nil is unrestricted
 	client        AbstractRpcClient
+	stats         *linkStats
 }
 
 func (lm *linkMeta) Name() string {
@@ -73,6 +76,11 @@ type rpcRoutingInfo struct {
 	rpcId        string
 	sourceLinkId baseds.LinkId
 	destRouteId  string
+	command      string
+	startTs      time.Time
+	timeoutMs    int64
+	priority     int
+	trace        *wshrpc.RpcTraceEntry // nil unless sampled
 }
 
 type messageWrap struct {
@@ -83,6 +91,7 @@ type messageWrap struct {
 type backlogMessageWrap struct {
 	msgBytes      []byte
 	ingressLinkId baseds.LinkId
+	priority      int
 	debugStr      string
 }
 
@@ -140,6 +149,10 @@ func MakeJobRouteId(jobId string) string {
 	return "job:" + jobId
 }
 
+func MakePluginRouteId(pluginName string) string {
+	return "plugin:" + pluginName
+}
+
 func MakeLinkRouteId(linkId baseds.LinkId) string {
 	return fmt.Sprintf("%s%d", RoutePrefix_Link, linkId)
 }
@@ -214,7 +227,7 @@ func (router *WshRouter) SendEvent(routeId string, event wps.WaveEvent) {
 		// nothing to do
 		return
 	}
-	router.sendRpcMessageToLink(lm.linkId, lm.client, msgBytes, baseds.NoLinkId, "eventrecv")
+	router.sendRpcMessageToLink(lm.linkId, lm.client, msgBytes, baseds.NoLinkId, RpcPriority_Normal, "eventrecv")
 }
 
 func (router *WshRouter) handleNoRoute(msg RpcMessage, ingressLinkId baseds.LinkId) {
@@ -236,7 +249,7 @@ func (router *WshRouter) handleNoRoute(msg RpcMessage, ingressLinkId baseds.Link
 			Data:    wshrpc.CommandMessageData{Message: nrErr.Error()},
 		}
 		respBytes, _ := json.Marshal(respMsg)
-		router.sendRpcMessageToLink(lm.linkId, lm.client, respBytes, baseds.NoLinkId, "no-route-err")
+		router.sendRpcMessageToLink(lm.linkId, lm.client, respBytes, baseds.NoLinkId, RpcPriority_High, "no-route-err")
 		return
 	}
 	// send error response
@@ -245,19 +258,60 @@ func (router *WshRouter) handleNoRoute(msg RpcMessage, ingressLinkId baseds.Link
 		Error: nrErr.Error(),
 	}
 	respBytes, _ := json.Marshal(response)
-	router.sendRoutedMessage(respBytes, msg.Source, msg.Command, baseds.NoLinkId)
+	router.sendRoutedMessage(respBytes, msg.Source, msg.Command, baseds.NoLinkId, RpcPriority_High)
 }
 
-func (router *WshRouter) registerRouteInfo(rpcId string, sourceLinkId baseds.LinkId, destRouteId string) {
-	if rpcId == "" {
+func (router *WshRouter) registerRouteInfo(msg RpcMessage, sourceLinkId baseds.LinkId, destRouteId string) {
+	if msg.ReqId == "" {
 		return
 	}
-	router.lock.Lock()
-	defer router.lock.Unlock()
-	router.rpcMap[rpcId] = rpcRoutingInfo{
-		rpcId:        rpcId,
+	info := rpcRoutingInfo{
+		rpcId:        msg.ReqId,
 		sourceLinkId: sourceLinkId,
 		destRouteId:  destRouteId,
+		command:      msg.Command,
+		startTs:      time.Now(),
+		timeoutMs:    msg.Timeout,
+		priority:     GetCommandPriority(msg.Command, destRouteId),
+	}
+	if rpcMetrics.shouldTrace() {
+		info.trace = router.makeTraceEntry(msg, sourceLinkId, destRouteId, info.startTs)
+	}
+	router.lock.Lock()
+	defer router.lock.Unlock()
+	router.rpcMap[msg.ReqId] = info
+}
+
+func (router *WshRouter) makeTraceEntry(msg RpcMessage, sourceLinkId baseds.LinkId, destRouteId string, startTs time.Time) *wshrpc.RpcTraceEntry {
+	hops := []string{"unknown"}
+	if lm := router.getLinkMeta(sourceLinkId); lm != nil {
+		hops[0] = lm.Name()
+	}
+	if lm := router.getLinkForRoute(destRouteId); lm != nil {
+		hops = append(hops, lm.Name())
+	} else if upstreamLinkId, _ := router.getUpstreamClient(); upstreamLinkId != baseds.NoLinkId {
+		hops = append(hops, "upstream")
+	}
+	return &wshrpc.RpcTraceEntry{
+		ReqId:   msg.ReqId,
+		Command: msg.Command,
+		Source:  msg.Source,
+		Route:   destRouteId,
+		Hops:    hops,
+		StartTs: startTs.UnixMilli(),
+	}
+}
+
+// called for the final response of an rpc
+func (router *WshRouter) recordRpcDone(info *rpcRoutingInfo, errStr string) {
+	dur := time.Since(info.startTs)
+	timedOut := isTimeoutError(errStr) || (info.timeoutMs > 0 && dur.Milliseconds() > info.timeoutMs)
+	rpcMetrics.recordCall(info.command, dur, errStr, timedOut)
+	if info.trace != nil {
+		info.trace.DurationMs = dur.Milliseconds()
+		info.trace.Responses++
+		info.trace.Error = errStr
+		rpcMetrics.recordTrace(*info.trace)
 	}
 }
 
@@ -278,22 +332,22 @@ func (router *WshRouter) getRouteInfo(rpcId string) *rpcRoutingInfo {
 }
 
 // returns true if message was sent, false if failed
-func (router *WshRouter) sendRoutedMessage(msgBytes []byte, routeId string, commandName string, ingressLinkId baseds.LinkId) bool {
+func (router *WshRouter) sendRoutedMessage(msgBytes []byte, routeId string, commandName string, ingressLinkId baseds.LinkId, priority int) bool {
 	if strings.HasPrefix(routeId, RoutePrefix_Link) {
 		linkIdStr := strings.TrimPrefix(routeId, RoutePrefix_Link)
 		linkIdInt, err := strconv.ParseInt(linkIdStr, 10, 32)
 		if err == nil {
-			return router.sendMessageToLink(msgBytes, baseds.LinkId(linkIdInt), ingressLinkId)
+			return router.sendMessageToLink(msgBytes, baseds.LinkId(linkIdInt), ingressLinkId, priority)
 		}
 	}
 	lm := router.getLinkForRoute(routeId)
 	if lm != nil {
-		router.sendRpcMessageToLink(lm.linkId, lm.client, msgBytes, ingressLinkId, "route")
+		router.sendRpcMessageToLink(lm.linkId, lm.client, msgBytes, ingressLinkId, priority, "route")
 		return true
 	}
 	upstreamLinkId, upstream := router.getUpstreamClient()
 	if upstream != nil {
-		router.sendRpcMessageToLink(upstreamLinkId, upstream, msgBytes, ingressLinkId, "route-upstream")
+		router.sendRpcMessageToLink(upstreamLinkId, upstream, msgBytes, ingressLinkId, priority, "route-upstream")
 		return true
 	}
 	if commandName != "" {
@@ -304,19 +358,19 @@ func (router *WshRouter) sendRoutedMessage(msgBytes []byte, routeId string, comm
 	return false
 }
 
-func (router *WshRouter) sendMessageToLink(msgBytes []byte, linkId baseds.LinkId, ingressLinkId baseds.LinkId) bool {
+func (router *WshRouter) sendMessageToLink(msgBytes []byte, linkId baseds.LinkId, ingressLinkId baseds.LinkId, priority int) bool {
 	lm := router.getLinkMeta(linkId)
 	if lm == nil {
 		return false
 	}
-	router.sendRpcMessageToLink(lm.linkId, lm.client, msgBytes, ingressLinkId, "link")
+	router.sendRpcMessageToLink(lm.linkId, lm.client, msgBytes, ingressLinkId, priority, "link")
 	return true
 }
 
-func (router *WshRouter) addToBacklog_withlock(linkId baseds.LinkId, msgBytes []byte, ingressLinkId baseds.LinkId, debugStr string) {
+func (router *WshRouter) addToBacklog_withlock(linkId baseds.LinkId, msgBytes []byte, ingressLinkId baseds.LinkId, priority int, debugStr string) {
 	mapWasEmpty := len(router.linkMsgBacklog) == 0
 	backlog := router.linkMsgBacklog[linkId]
-	backlog = append(backlog, backlogMessageWrap{msgBytes: msgBytes, ingressLinkId: ingressLinkId, debugStr: debugStr})
+	backlog = insertByPriority(backlog, backlogMessageWrap{msgBytes: msgBytes, ingressLinkId: ingressLinkId, priority: priority, debugStr: debugStr})
 	router.linkMsgBacklog[linkId] = backlog
 
 	newLen := len(backlog)
@@ -335,16 +389,21 @@ func (router *WshRouter) addToBacklog_withlock(linkId baseds.LinkId, msgBytes []
 	}
 }
 
-func (router *WshRouter) sendRpcMessageToLink(linkId baseds.LinkId, client AbstractRpcClient, msgBytes []byte, ingressLinkId baseds.LinkId, debugStr string) {
+func (router *WshRouter) sendRpcMessageToLink(linkId baseds.LinkId, client AbstractRpcClient, msgBytes []byte, ingressLinkId baseds.LinkId, priority int, debugStr string) {
 	router.lock.Lock()
 	defer router.lock.Unlock()
+	if lm := router.linkMap[linkId]; lm != nil && lm.stats != nil {
+		lm.stats.MsgsSent.Add(1)
+		lm.stats.BytesSent.Add(int64(len(msgBytes)))
+	}
 	sent := false
 	backlog := router.linkMsgBacklog[linkId]
-	if len(backlog) == 0 {
-		sent = client.SendRpcMessage(msgBytes, ingressLinkId, debugStr)
+	// the backlog is sorted, so if its head is lower priority we can skip ahead of all of it
+	if len(backlog) == 0 || backlog[0].priority > priority {
+		sent = sendToClientWithPriority(client, msgBytes, ingressLinkId, priority, debugStr)
 	}
 	if !sent {
-		router.addToBacklog_withlock(linkId, msgBytes, ingressLinkId, debugStr)
+		router.addToBacklog_withlock(linkId, msgBytes, ingressLinkId, priority, debugStr)
 	}
 }
 
@@ -360,12 +419,12 @@ func (router *WshRouter) runServer() {
 		routeId := msg.Route
 		if msg.Command != "" {
 			// new comand, setup new rpc
-			ok := router.sendRoutedMessage(msgBytes, routeId, msg.Command, input.IngressLinkId)
+			ok := router.sendRoutedMessage(msgBytes, routeId, msg.Command, input.IngressLinkId, GetCommandPriority(msg.Command, routeId))
 			if !ok {
 				router.handleNoRoute(msg, input.IngressLinkId)
 				continue
 			}
-			router.registerRouteInfo(msg.ReqId, input.IngressLinkId, routeId)
+			router.registerRouteInfo(msg, input.IngressLinkId, routeId)
 			continue
 		}
 		// look at reqid or resid to route correctly
@@ -376,7 +435,7 @@ func (router *WshRouter) runServer() {
 				continue
 			}
 			// no need to check the return value here (noop if failed)
-			router.sendRoutedMessage(msgBytes, routeInfo.destRouteId, "", input.IngressLinkId)
+			router.sendRoutedMessage(msgBytes, routeInfo.destRouteId, "", input.IngressLinkId, routeInfo.priority)
 			continue
 		} else if msg.ResId != "" {
 			routeInfo := router.getRouteInfo(msg.ResId)
@@ -384,9 +443,12 @@ func (router *WshRouter) runServer() {
 				// no route info, nothing to do
 				continue
 			}
-			router.sendMessageToLink(msgBytes, routeInfo.sourceLinkId, input.IngressLinkId)
+			router.sendMessageToLink(msgBytes, routeInfo.sourceLinkId, input.IngressLinkId, routeInfo.priority)
 			if !msg.Cont {
 				router.unregisterRouteInfo(msg.ResId)
+				router.recordRpcDone(routeInfo, msg.Error)
+			} else if routeInfo.trace != nil {
+				routeInfo.trace.Responses++
 			}
 			continue
 		} else {
@@ -441,7 +503,8 @@ func (router *WshRouter) runUpstreamBufferLoop() {
 
 		upstreamLinkId, upstream := router.getUpstreamClient()
 		if upstream != nil {
-			router.sendRpcMessageToLink(upstreamLinkId, upstream, msg.msgBytes, baseds.NoLinkId, msg.debugStr)
+			// only control-plane messages (route announcements) are queued here
+			router.sendRpcMessageToLink(upstreamLinkId, upstream, msg.msgBytes, baseds.NoLinkId, RpcPriority_High, msg.debugStr)
 		}
 	}
 }
@@ -449,7 +512,7 @@ func (router *WshRouter) runUpstreamBufferLoop() {
 func (router *WshRouter) drainLinkBacklog_withLock(linkId baseds.LinkId, lm *linkMeta, backlog []backlogMessageWrap) []backlogMessageWrap {
 	for len(backlog) > 0 {
 		msg := backlog[0]
-		sent := lm.client.SendRpcMessage(msg.msgBytes, msg.ingressLinkId, msg.debugStr)
+		sent := sendToClientWithPriority(lm.client, msg.msgBytes, msg.ingressLinkId, msg.priority, msg.debugStr)
 		if !sent {
 			return backlog
 		}
@@ -510,6 +573,7 @@ func (router *WshRouter) RegisterUntrustedLink(client AbstractRpcClient) baseds.
 		linkId:  linkId,
 		trusted: false,
 		client:  client,
+		stats:   &linkStats{},
 	}
 	log.Printf("wshrouter register link %s", lm.Name())
 	router.linkMap[linkId] = lm
@@ -529,6 +593,21 @@ func (router *WshRouter) trustLink(linkId baseds.LinkId, linkKind string) {
 	lm.linkKind = linkKind
 }
 
+func (router *WshRouter) setLinkCapabilities(linkId baseds.LinkId, caps []string) {
+	router.lock.Lock()
+	defer router.lock.Unlock()
+	lm := router.linkMap[linkId]
+	if lm == nil {
+		return
+	}
+	// a restricted link stays restricted, authenticating without capabilities doesn't lift them
+	if caps == nil {
+		return
+	}
+	log.Printf("wshrouter link %s capabilities=%v", lm.Name(), caps)
+	lm.capabilities = caps
+}
+
 func (router *WshRouter) runLinkClientRecvLoop(linkId baseds.LinkId, client AbstractRpcClient) {
 	defer func() {
 		panichandler.PanicHandler("WshRouter:runLinkClientRecvLoop", recover())
@@ -557,6 +636,8 @@ func (router *WshRouter) runLinkClientRecvLoop(linkId baseds.LinkId, client Abst
 			exitReason = "link-gone"
 			break
 		}
+		lm.stats.MsgsRecv.Add(1)
+		lm.stats.BytesRecv.Add(int64(len(msgBytes)))
 		if rpcMsg.IsRpcRequest() {
 			if lm.sourceRouteId != "" {
 				rpcMsg.Source = lm.sourceRouteId
@@ -564,6 +645,10 @@ func (router *WshRouter) runLinkClientRecvLoop(linkId baseds.LinkId, client Abst
 			if rpcMsg.Route == "" {
 				rpcMsg.Route = DefaultRoute
 			}
+			// sticky across routers, a link can't clear it
+			if lm.capabilities != nil {
+				rpcMsg.Restricted = true
+			}
 			msgBytes, err = json.Marshal(rpcMsg)
 			if err != nil {
 				continue
@@ -577,6 +662,10 @@ func (router *WshRouter) runLinkClientRecvLoop(linkId baseds.LinkId, client Abst
 				}
 				log.Printf("wshrouter control-msg route=%s link=%s command=%s source=%s", rpcMsg.Route, lm.Name(), rpcMsg.Command, rpcMsg.Source)
 			}
+			if !isControlRoute && !wshrpc.CapabilitiesAllow(lm.capabilities, rpcMsg.Command) {
+				sendControlErrorResponse(rpcMsg, *lm, router, fmt.Sprintf("command %q is not permitted by this link's capabilities (%s)", rpcMsg.Command, strings.Join(lm.capabilities, ",")))
+				continue
+			}
 		} else {
 			// non-request messages (responses)
 			if !lm.trusted {
@@ -729,6 +818,23 @@ func (router *WshRouter) UnregisterLink(linkId baseds.LinkId) {
 	}
 }
 
+// the connection (conn: route) bound to the link, "" if there is none or more than one
+func (router *WshRouter) getLinkConnName(linkId baseds.LinkId) string {
+	router.lock.Lock()
+	defer router.lock.Unlock()
+	var connName string
+	for routeId, routeLinkId := range router.routeMap {
+		if routeLinkId != linkId || !strings.HasPrefix(routeId, RoutePrefix_Conn) {
+			continue
+		}
+		if connName != "" {
+			return ""
+		}
+		connName = strings.TrimPrefix(routeId, RoutePrefix_Conn)
+	}
+	return connName
+}
+
 func isBindableRouteId(routeId string) bool {
 	if routeId == "" || strings.HasPrefix(routeId, ControlPrefix) || strings.HasPrefix(routeId, RoutePrefix_Link) {
 		return false
@@ -845,14 +951,18 @@ func (router *WshRouter) unsubscribeFromBroker(routeId string) {
 }
 
 func sendControlUnauthenticatedErrorResponse(cmdMsg RpcMessage, linkMeta linkMeta, router *WshRouter) {
+	sendControlErrorResponse(cmdMsg, linkMeta, router, fmt.Sprintf("link is unauthenticated (%s), cannot call %q", linkMeta.Name(), cmdMsg.Command))
+}
+
+func sendControlErrorResponse(cmdMsg RpcMessage, linkMeta linkMeta, router *WshRouter, errStr string) {
 	if cmdMsg.ReqId == "" {
 		return
 	}
 	rtnMsg := RpcMessage{
 		Source: ControlRoute,
 		ResId:  cmdMsg.ReqId,
-		Error:  fmt.Sprintf("link is unauthenticated (%s), cannot call %q", linkMeta.Name(), cmdMsg.Command),
+		Error:  errStr,
 	}
 	rtnBytes, _ := json.Marshal(rtnMsg)
-	router.sendRpcMessageToLink(linkMeta.linkId, linkMeta.client, rtnBytes, baseds.NoLinkId, "unauthenticated")
+	router.sendRpcMessageToLink(linkMeta.linkId, linkMeta.client, rtnBytes, baseds.NoLinkId, RpcPriority_High, "controlerror")
 }
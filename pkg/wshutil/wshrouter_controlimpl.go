@@ -108,6 +108,7 @@ func (impl *WshRouterControlImpl) AuthenticateCommand(ctx context.Context, data
 	}
 
 	rtnData := wshrpc.CommandAuthenticateRtnData{RouteId: routeId}
+	impl.Router.setLinkCapabilities(linkId, newCtx.Capabilities)
 	if newCtx.IsRouter {
 		log.Printf("wshrouter authenticate success linkid=%d (router)", linkId)
 		impl.Router.trustLink(linkId, LinkKind_Router)
@@ -208,6 +209,7 @@ func (impl *WshRouterControlImpl) AuthenticateTokenCommand(ctx context.Context,
 		return wshrpc.CommandAuthenticateRtnData{}, fmt.Errorf("no routeid in token response")
 	}
 	log.Printf("wshrouter authenticate-token success linkid=%d routeid=%q", linkId, rtnData.RouteId)
+	impl.Router.setLinkCapabilities(linkId, rtnData.RpcContext.Capabilities)
 	impl.Router.trustLink(linkId, LinkKind_Leaf)
 	impl.Router.bindRoute(linkId, rtnData.RouteId, true)
 
@@ -0,0 +1,82 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wshutil
+
+import (
+	"strings"
+
+	"github.com/SalyyS1/SLTerm/pkg/baseds"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+)
+
+// messages are queued per link in priority order: interactive input and control-plane traffic go out
+// ahead of normal rpcs, and bulk data (file transfers, stream data) goes last.  priority is decided by
+// the rpc's command when the request passes through the router, and its responses inherit it, so all
+// messages of one rpc keep their relative order.
+
+const (
+	RpcPriority_High   = 0
+	RpcPriority_Normal = 1
+	RpcPriority_Bulk   = 2
+)
+
+var HighPriorityCommands = map[string]bool{
+	wshrpc.Command_ControllerInput: true,
+	wshrpc.Command_StreamDataAck:   true,
+	wshrpc.Command_Ping:            true,
+	"controllerresync":             true,
+	"setblockfocus":                true,
+}
+
+var BulkPriorityCommands = map[string]bool{
+	wshrpc.Command_StreamData: true,
+	"remotestreamfile":        true,
+	"remotefilecopy":          true,
+	"remotewritefile":         true,
+	"remotefilejoin":          true,
+	"remotelistentries":       true,
+	"fileread":                true,
+	"filereadstream":          true,
+	"filewrite":               true,
+	"fileappend":              true,
+	"filecopy":                true,
+	"filejoin":                true,
+	"fileliststream":          true,
+	"wavefilereadstream":      true,
+	"writetempfile":           true,
+}
+
+// PriorityRpcClient is implemented by link clients with more than one outbound lane
+type PriorityRpcClient interface {
+	SendRpcMessagePriority(msg []byte, ingressLinkId baseds.LinkId, priority int, debugStr string) bool
+}
+
+func GetCommandPriority(command string, route string) int {
+	if strings.HasPrefix(route, ControlPrefix) || HighPriorityCommands[command] {
+		return RpcPriority_High
+	}
+	if BulkPriorityCommands[command] {
+		return RpcPriority_Bulk
+	}
+	return RpcPriority_Normal
+}
+
+func sendToClientWithPriority(client AbstractRpcClient, msgBytes []byte, ingressLinkId baseds.LinkId, priority int, debugStr string) bool {
+	if pclient, ok := client.(PriorityRpcClient); ok {
+		return pclient.SendRpcMessagePriority(msgBytes, ingressLinkId, priority, debugStr)
+	}
+	return client.SendRpcMessage(msgBytes, ingressLinkId, debugStr)
+}
+
+// insertByPriority keeps the backlog ordered by priority (fifo within a priority)
+func insertByPriority(backlog []backlogMessageWrap, msg backlogMessageWrap) []backlogMessageWrap {
+	idx := len(backlog)
+	for idx > 0 && backlog[idx-1].priority > msg.priority {
+		idx--
+	}
+	backlog = append(backlog, backlogMessageWrap{})
+	copy(backlog[idx+1:], backlog[idx:])
+	backlog[idx] = msg
+	return backlog
+}
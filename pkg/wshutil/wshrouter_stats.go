@@ -0,0 +1,205 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wshutil
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/baseds"
+	"github.com/SalyyS1/SLTerm/pkg/wshrpc"
+)
+
+// per-command counters and latency histograms for rpcs passing through this process's routers.
+// an rpc counts as timed out if it fails with a timeout error or its response arrives after the
+// caller's deadline.  rpcs that never get a response show up as in-flight/stale.  link byte
+// counts live on the router's linkMeta.
+
+const MaxRpcTraceEntries = 500
+
+var RpcLatencyBucketsMs = []int64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+
+type rpcCommandMetrics struct {
+	Count     int64
+	Errors    int64
+	Timeouts  int64
+	TotalMs   int64
+	MaxMs     int64
+	Latencies []int64
+}
+
+type rpcMetricsCollector struct {
+	lock       *sync.Mutex
+	sinceTs    int64
+	commands   map[string]*rpcCommandMetrics
+	sampleRate float64
+	traces     []wshrpc.RpcTraceEntry
+}
+
+type linkStats struct {
+	MsgsSent  atomic.Int64
+	MsgsRecv  atomic.Int64
+	BytesSent atomic.Int64
+	BytesRecv atomic.Int64
+}
+
+var rpcMetrics = &rpcMetricsCollector{
+	lock:     &sync.Mutex{},
+	sinceTs:  time.Now().UnixMilli(),
+	commands: make(map[string]*rpcCommandMetrics),
+}
+
+func (rm *rpcMetricsCollector) getCommand_nolock(command string) *rpcCommandMetrics {
+	cm := rm.commands[command]
+	if cm == nil {
+		cm = &rpcCommandMetrics{Latencies: make([]int64, len(RpcLatencyBucketsMs)+1)}
+		rm.commands[command] = cm
+	}
+	return cm
+}
+
+func (rm *rpcMetricsCollector) shouldTrace() bool {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+	return rm.sampleRate > 0 && rand.Float64() < rm.sampleRate
+}
+
+func (rm *rpcMetricsCollector) recordCall(command string, dur time.Duration, errStr string, timedOut bool) {
+	if command == "" {
+		return
+	}
+	durMs := dur.Milliseconds()
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+	cm := rm.getCommand_nolock(command)
+	cm.Count++
+	if errStr != "" {
+		cm.Errors++
+	}
+	if timedOut {
+		cm.Timeouts++
+	}
+	cm.TotalMs += durMs
+	if durMs > cm.MaxMs {
+		cm.MaxMs = durMs
+	}
+	bucket := sort.Search(len(RpcLatencyBucketsMs), func(i int) bool { return RpcLatencyBucketsMs[i] >= durMs })
+	cm.Latencies[bucket]++
+}
+
+func (rm *rpcMetricsCollector) recordTrace(entry wshrpc.RpcTraceEntry) {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+	rm.traces = append(rm.traces, entry)
+	if len(rm.traces) > MaxRpcTraceEntries {
+		rm.traces = rm.traces[len(rm.traces)-MaxRpcTraceEntries:]
+	}
+}
+
+func isTimeoutError(errStr string) bool {
+	return strings.HasPrefix(errStr, "EC-TIME") || strings.Contains(errStr, "context deadline exceeded")
+}
+
+func (router *WshRouter) GetRpcStats(reset bool) *wshrpc.RpcStatsData {
+	rtn := &wshrpc.RpcStatsData{
+		Ts:               time.Now().UnixMilli(),
+		LatencyBucketsMs: RpcLatencyBucketsMs,
+	}
+	now := time.Now()
+	inFlight := make(map[string]int)
+	stale := make(map[string]int)
+	router.lock.Lock()
+	for _, info := range router.rpcMap {
+		if info.timeoutMs > 0 && now.Sub(info.startTs).Milliseconds() > info.timeoutMs {
+			stale[info.command]++
+			continue
+		}
+		inFlight[info.command]++
+	}
+	routes := make(map[baseds.LinkId][]string)
+	for routeId, linkId := range router.routeMap {
+		routes[linkId] = append(routes[linkId], routeId)
+	}
+	for linkId, lm := range router.linkMap {
+		ls := wshrpc.RpcLinkStats{
+			LinkId:           int(linkId),
+			Name:             lm.Name(),
+			Kind:             lm.linkKind,
+			Routes:           routes[linkId],
+			Backlog:          len(router.linkMsgBacklog[linkId]),
+			BacklogHighWater: router.backlogHighWaterMark[linkId],
+		}
+		sort.Strings(ls.Routes)
+		if lm.stats != nil {
+			ls.MsgsSent = lm.stats.MsgsSent.Load()
+			ls.MsgsRecv = lm.stats.MsgsRecv.Load()
+			ls.BytesSent = lm.stats.BytesSent.Load()
+			ls.BytesRecv = lm.stats.BytesRecv.Load()
+		}
+		rtn.Links = append(rtn.Links, ls)
+	}
+	router.lock.Unlock()
+	sort.Slice(rtn.Links, func(i, j int) bool { return rtn.Links[i].LinkId < rtn.Links[j].LinkId })
+
+	rpcMetrics.lock.Lock()
+	defer rpcMetrics.lock.Unlock()
+	rtn.SinceTs = rpcMetrics.sinceTs
+	rtn.TraceSampleRate = rpcMetrics.sampleRate
+	for command, cm := range rpcMetrics.commands {
+		rtn.Commands = append(rtn.Commands, wshrpc.RpcCommandStats{
+			Command:   command,
+			Count:     cm.Count,
+			Errors:    cm.Errors,
+			Timeouts:  cm.Timeouts,
+			InFlight:  inFlight[command],
+			Stale:     stale[command],
+			TotalMs:   cm.TotalMs,
+			MaxMs:     cm.MaxMs,
+			Latencies: append([]int64(nil), cm.Latencies...),
+		})
+		delete(inFlight, command)
+		delete(stale, command)
+	}
+	for command := range stale {
+		if _, ok := inFlight[command]; !ok {
+			inFlight[command] = 0
+		}
+	}
+	for command, count := range inFlight {
+		if command == "" {
+			continue
+		}
+		rtn.Commands = append(rtn.Commands, wshrpc.RpcCommandStats{Command: command, InFlight: count, Stale: stale[command], Latencies: make([]int64, len(RpcLatencyBucketsMs)+1)})
+	}
+	sort.Slice(rtn.Commands, func(i, j int) bool { return rtn.Commands[i].Command < rtn.Commands[j].Command })
+	if reset {
+		rpcMetrics.commands = make(map[string]*rpcCommandMetrics)
+		rpcMetrics.sinceTs = rtn.Ts
+	}
+	return rtn
+}
+
+func GetRpcTrace(data wshrpc.CommandRpcTraceData) *wshrpc.RpcTraceData {
+	rpcMetrics.lock.Lock()
+	defer rpcMetrics.lock.Unlock()
+	if data.SampleRate != nil {
+		rpcMetrics.sampleRate = min(max(*data.SampleRate, 0), 1)
+	}
+	entries := rpcMetrics.traces
+	if data.MaxEntries > 0 && len(entries) > data.MaxEntries {
+		entries = entries[len(entries)-data.MaxEntries:]
+	}
+	rtn := &wshrpc.RpcTraceData{
+		SampleRate: rpcMetrics.sampleRate,
+		Entries:    append([]wshrpc.RpcTraceEntry{}, entries...),
+	}
+	if data.Clear {
+		rpcMetrics.traces = nil
+	}
+	return rtn
+}
@@ -0,0 +1,102 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wshutil
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/baseds"
+)
+
+// sends the messages on recvCh into the router, captures what the router sends to it on sentCh
+type chanRpcClient struct {
+	recvCh chan []byte
+	sentCh chan []byte
+}
+
+func makeChanRpcClient() *chanRpcClient {
+	return &chanRpcClient{recvCh: make(chan []byte, 8), sentCh: make(chan []byte, 8)}
+}
+
+func (c *chanRpcClient) GetPeerInfo() string { return "test" }
+
+func (c *chanRpcClient) SendRpcMessage(msg []byte, ingressLinkId baseds.LinkId, debugStr string) bool {
+	c.sentCh <- msg
+	return true
+}
+
+func (c *chanRpcClient) RecvRpcMessage() ([]byte, bool) {
+	msg, ok := <-c.recvCh
+	return msg, ok
+}
+
+func TestRestrictedStamp(t *testing.T) {
+	router := NewWshRouter()
+	dest := makeChanRpcClient()
+	defer close(dest.recvCh)
+	if _, err := router.RegisterTrustedLeaf(dest, "test:dest"); err != nil {
+		t.Fatalf("registering leaf: %v", err)
+	}
+	open := makeChanRpcClient()
+	defer close(open.recvCh)
+	router.RegisterTrustedRouter(open)
+	restricted := makeChanRpcClient()
+	defer close(restricted.recvCh)
+	restrictedLinkId := router.RegisterTrustedRouter(restricted)
+	router.setLinkCapabilities(restrictedLinkId, []string{"core"})
+
+	tests := []struct {
+		name   string
+		client *chanRpcClient
+		msg    RpcMessage
+		want   bool
+	}{
+		{"unrestricted link", open, RpcMessage{Command: "eventpublish", Route: "test:dest"}, false},
+		{"restricted link", restricted, RpcMessage{Command: "eventpublish", Route: "test:dest", ReqId: "req1"}, true},
+		{"restricted link, no response", restricted, RpcMessage{Command: "eventpublish", Route: "test:dest"}, true},
+		{"stamped upstream", open, RpcMessage{Command: "eventpublish", Route: "test:dest", Restricted: true}, true},
+	}
+	for _, tc := range tests {
+		barr, _ := json.Marshal(tc.msg)
+		tc.client.recvCh <- barr
+		select {
+		case sent := <-dest.sentCh:
+			var got RpcMessage
+			if err := json.Unmarshal(sent, &got); err != nil {
+				t.Fatalf("%s: bad message: %v", tc.name, err)
+			}
+			if got.Restricted != tc.want {
+				t.Errorf("%s: restricted = %v, want %v", tc.name, got.Restricted, tc.want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: message was not routed", tc.name)
+		}
+	}
+}
+
+func TestGetLinkConnName(t *testing.T) {
+	router := NewWshRouter()
+	conn := makeChanRpcClient()
+	defer close(conn.recvCh)
+	connLinkId := router.RegisterTrustedRouter(conn)
+	other := makeChanRpcClient()
+	defer close(other.recvCh)
+	otherLinkId := router.RegisterTrustedRouter(other)
+
+	router.bindRouteLocally(connLinkId, MakeConnectionRouteId("user@host"), false)
+	router.bindRouteLocally(connLinkId, MakeProcRouteId("p1"), false)
+	if got := router.getLinkConnName(connLinkId); got != "user@host" {
+		t.Errorf("conn link: got %q", got)
+	}
+	if got := router.getLinkConnName(otherLinkId); got != "" {
+		t.Errorf("link without a conn route: got %q", got)
+	}
+	// a link that claims a second connection isn't trusted to be either
+	router.bindRouteLocally(connLinkId, MakeConnectionRouteId("other@host"), false)
+	if got := router.getLinkConnName(connLinkId); got != "" {
+		t.Errorf("link with two conn routes: got %q", got)
+	}
+}
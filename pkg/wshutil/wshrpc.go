@@ -90,6 +90,27 @@ func GetRpcSourceFromContext(ctx context.Context) string {
 	return rtn.(*RpcResponseHandler).GetSource()
 }
 
+// IsUnrestrictedCaller is true for in-process calls and for rpcs that only passed through links without
+// capability restrictions (the frontend, local wsh with the default capabilities).  rpcs from remote
+// connections, scoped tokens and plugins are restricted.
+func IsUnrestrictedCaller(ctx context.Context) bool {
+	handler := GetRpcResponseHandlerFromContext(ctx)
+	if handler == nil {
+		return true
+	}
+	return !handler.restricted
+}
+
+// GetCallerConnName returns the connection a restricted rpc came from (the conn: route of the link it
+// arrived on), "" for unrestricted callers and for restricted links that aren't a connection (tokens, plugins)
+func GetCallerConnName(ctx context.Context) string {
+	handler := GetRpcResponseHandlerFromContext(ctx)
+	if handler == nil || !handler.restricted || DefaultRouter == nil {
+		return ""
+	}
+	return DefaultRouter.getLinkConnName(handler.ingressLinkId)
+}
+
 func GetIsCanceledFromContext(ctx context.Context) bool {
 	rtn := ctx.Value(wshRpcRespHandlerContextKey{})
 	if rtn == nil {
@@ -136,6 +157,9 @@ type RpcMessage struct {
 	Error    string `json:"error,omitempty"`
 	DataType string `json:"datatype,omitempty"`
 	Data     any    `json:"data,omitempty"`
+
+	// set by the routers on requests that came in over a link with capability restrictions
+	Restricted bool `json:"restricted,omitempty"`
 }
 
 func (r *RpcMessage) IsRpcRequest() bool {
@@ -344,6 +368,7 @@ func (w *WshRpc) handleRequestInternal(req *RpcMessage, ingressLinkId baseds.Lin
 		command:         req.Command,
 		commandData:     req.Data,
 		source:          req.Source,
+		restricted:      req.Restricted,
 		ingressLinkId:   ingressLinkId,
 		done:            &atomic.Bool{},
 		canceled:        &atomic.Bool{},
@@ -627,6 +652,7 @@ type RpcResponseHandler struct {
 	contextCancelFn *atomic.Pointer[context.CancelFunc]
 	reqId           string
 	source          string
+	restricted      bool
 	command         string
 	commandData     any
 	rpcCtx          wshrpc.RpcContext
@@ -791,8 +817,9 @@ func (w *WshRpc) SendComplexRequest(command string, data any, opts *wshrpc.RpcOp
 		Command: command,
 		ReqId:   handler.reqId,
 		Data:    data,
-		Timeout: timeoutMs,
-		Route:   opts.Route,
+		Timeout:    timeoutMs,
+		Route:      opts.Route,
+		Restricted: opts.Restricted,
 	}
 	barr, err := json.Marshal(req)
 	if err != nil {
@@ -4,8 +4,17 @@
 package wshutil
 
 import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
 
 	"github.com/SalyyS1/SLTerm/pkg/baseds"
 	"github.com/SalyyS1/SLTerm/pkg/util/utilfn"
@@ -58,3 +67,266 @@ func AdaptMsgChToPty(outputCh chan []byte, oscEsc string, output io.Writer) erro
 	}
 	return nil
 }
+
+// LinkStream carries a router link over a stream (domain socket, ssh-forwarded socket) as json lines,
+// like AdaptStreamToMsgCh/AdaptOutputChToStream, plus two optional features:
+//
+//   - compression: the initiating side sends a "#wshlink {...}" hello line listing the compression it
+//     accepts, and the other side answers with its own hello.  after seeing a peer's hello, large messages
+//     to that peer are sent deflated as a "#z <len>" line followed by <len> raw bytes.  peers that don't
+//     speak this never send a hello, so they only ever see plain json lines (and they drop the unparseable
+//     hello line).
+//   - priority: messages on the proxy's high priority lane are written ahead of queued regular messages.
+//
+// json messages always start with '{', so '#' lines are link-level framing.
+
+const (
+	LinkHelloPrefix         = "#wshlink "
+	LinkCompressedPrefix    = "#z "
+	LinkCompression_Deflate = "deflate"
+
+	LinkCompressMinSize  = 4 * 1024
+	MaxLinkMessageSize   = 128 * 1024 // same as the line limit in utilfn.StreamToLines
+	linkReadBufSize      = 64 * 1024
+	linkCompressMaxRatio = 0.9 // only send compressed if it saves at least 10%
+)
+
+type LinkHello struct {
+	Compress []string `json:"compress,omitempty"`
+}
+
+type LinkStreamStats struct {
+	MsgsWritten    int64 `json:"msgswritten"`
+	CompressedMsgs int64 `json:"compressedmsgs"`
+	RawBytes       int64 `json:"rawbytes"`  // message bytes before compression
+	WireBytes      int64 `json:"wirebytes"` // bytes written to the stream
+}
+
+type LinkStream struct {
+	conn         io.ReadWriter
+	initiate     bool
+	noCompress   bool
+	writeLock    sync.Mutex
+	helloSent    atomic.Bool
+	peerCompress atomic.Bool
+
+	msgsWritten    atomic.Int64
+	compressedMsgs atomic.Int64
+	rawBytes       atomic.Int64
+	wireBytes      atomic.Int64
+}
+
+// initiate should be set on the dialing side of the link.  noCompress disables compression in
+// both directions (no hello is sent or answered).
+func MakeLinkStream(conn io.ReadWriter, initiate bool, noCompress bool) *LinkStream {
+	return &LinkStream{conn: conn, initiate: initiate, noCompress: noCompress}
+}
+
+func (ls *LinkStream) PeerCompression() bool {
+	return ls.peerCompress.Load()
+}
+
+func (ls *LinkStream) GetStats() LinkStreamStats {
+	return LinkStreamStats{
+		MsgsWritten:    ls.msgsWritten.Load(),
+		CompressedMsgs: ls.compressedMsgs.Load(),
+		RawBytes:       ls.rawBytes.Load(),
+		WireBytes:      ls.wireBytes.Load(),
+	}
+}
+
+func (ls *LinkStream) sendHello() error {
+	if ls.noCompress || ls.helloSent.Swap(true) {
+		return nil
+	}
+	barr, err := json.Marshal(LinkHello{Compress: []string{LinkCompression_Deflate}})
+	if err != nil {
+		return err
+	}
+	ls.writeLock.Lock()
+	defer ls.writeLock.Unlock()
+	_, err = fmt.Fprintf(ls.conn, "%s%s\n", LinkHelloPrefix, barr)
+	return err
+}
+
+func compressMessage(msg []byte) []byte {
+	var buf bytes.Buffer
+	fw, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		return nil
+	}
+	if _, err := fw.Write(msg); err != nil {
+		return nil
+	}
+	if err := fw.Close(); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func (ls *LinkStream) writeMessage(msg []byte) error {
+	var compressed []byte
+	if len(msg) >= LinkCompressMinSize && ls.peerCompress.Load() {
+		compressed = compressMessage(msg)
+		if float64(len(compressed)) > float64(len(msg))*linkCompressMaxRatio {
+			compressed = nil
+		}
+	}
+	ls.writeLock.Lock()
+	defer ls.writeLock.Unlock()
+	ls.msgsWritten.Add(1)
+	ls.rawBytes.Add(int64(len(msg)))
+	if compressed != nil {
+		header := fmt.Sprintf("%s%d\n", LinkCompressedPrefix, len(compressed))
+		if _, err := io.WriteString(ls.conn, header); err != nil {
+			return err
+		}
+		if _, err := ls.conn.Write(compressed); err != nil {
+			return err
+		}
+		ls.compressedMsgs.Add(1)
+		ls.wireBytes.Add(int64(len(header) + len(compressed)))
+		return nil
+	}
+	if _, err := ls.conn.Write(msg); err != nil {
+		return err
+	}
+	if _, err := ls.conn.Write([]byte{'\n'}); err != nil {
+		return err
+	}
+	ls.wireBytes.Add(int64(len(msg) + 1))
+	return nil
+}
+
+// WriteLoop writes messages from outputCh (and highCh, if not nil) until outputCh is closed
+func (ls *LinkStream) WriteLoop(outputCh chan []byte, highCh chan []byte) (rtnErr error) {
+	defer func() {
+		if rtnErr != nil {
+			utilfn.DrainChannelSafe(outputCh, "LinkStream.WriteLoop")
+		}
+	}()
+	if ls.initiate {
+		if err := ls.sendHello(); err != nil {
+			return fmt.Errorf("error writing link hello (LinkStream): %w", err)
+		}
+	}
+	for {
+		var msg []byte
+		select {
+		case msg = <-highCh:
+		default:
+			var ok bool
+			select {
+			case msg = <-highCh:
+			case msg, ok = <-outputCh:
+				if !ok {
+					return nil
+				}
+			}
+		}
+		if err := ls.writeMessage(msg); err != nil {
+			return fmt.Errorf("error writing to output (LinkStream): %w", err)
+		}
+	}
+}
+
+// reads one line (without the newline), lines over MaxLinkMessageSize are returned as nil
+func readLinkLine(br *bufio.Reader) ([]byte, error) {
+	var line []byte
+	tooLong := false
+	for {
+		frag, err := br.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		if !tooLong {
+			if len(line)+len(frag) > MaxLinkMessageSize+1 {
+				tooLong = true
+				line = nil
+			} else {
+				line = append(line, frag...)
+			}
+		}
+		if err == nil {
+			break
+		}
+	}
+	if tooLong {
+		return nil, nil
+	}
+	return line[:len(line)-1], nil
+}
+
+func (ls *LinkStream) readCompressed(br *bufio.Reader, header []byte) ([]byte, error) {
+	size, err := strconv.Atoi(string(header[len(LinkCompressedPrefix):]))
+	if err != nil || size < 0 || size > MaxLinkMessageSize {
+		return nil, fmt.Errorf("invalid compressed frame header %q", header)
+	}
+	compressed := make([]byte, size)
+	if _, err := io.ReadFull(br, compressed); err != nil {
+		return nil, err
+	}
+	fr := flate.NewReader(bytes.NewReader(compressed))
+	defer fr.Close()
+	msg, err := io.ReadAll(io.LimitReader(fr, MaxLinkMessageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing frame: %w", err)
+	}
+	if len(msg) > MaxLinkMessageSize {
+		// dropped, like an over-long line
+		return nil, nil
+	}
+	return msg, nil
+}
+
+func (ls *LinkStream) handleHello(line []byte) {
+	var hello LinkHello
+	if err := json.Unmarshal(line[len(LinkHelloPrefix):], &hello); err != nil {
+		log.Printf("invalid link hello: %v\n", err)
+		return
+	}
+	if ls.noCompress {
+		return
+	}
+	if slices.Contains(hello.Compress, LinkCompression_Deflate) {
+		ls.peerCompress.Store(true)
+	}
+	if !ls.initiate {
+		if err := ls.sendHello(); err != nil {
+			log.Printf("error answering link hello: %v\n", err)
+		}
+	}
+}
+
+// ReadLoop reads messages into output until the stream ends (returns the read error, io.EOF on a clean close)
+func (ls *LinkStream) ReadLoop(output chan baseds.RpcInputChType, readCallback func()) error {
+	br := bufio.NewReaderSize(ls.conn, linkReadBufSize)
+	for {
+		line, err := readLinkLine(br)
+		if err != nil {
+			return err
+		}
+		if readCallback != nil {
+			readCallback()
+		}
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] == '#' {
+			switch {
+			case bytes.HasPrefix(line, []byte(LinkCompressedPrefix)):
+				msg, err := ls.readCompressed(br, line)
+				if err != nil {
+					return err
+				}
+				if msg != nil {
+					output <- baseds.RpcInputChType{MsgBytes: msg}
+				}
+			case bytes.HasPrefix(line, []byte(LinkHelloPrefix)):
+				ls.handleHello(line)
+			}
+			continue
+		}
+		output <- baseds.RpcInputChType{MsgBytes: line}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2025, Salyvn.
+// SPDX-License-Identifier: Apache-2.0
+
+package wshutil
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SalyyS1/SLTerm/pkg/baseds"
+)
+
+func recvMsg(t *testing.T, ch chan baseds.RpcInputChType) []byte {
+	select {
+	case input := <-ch:
+		return input.MsgBytes
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+		return nil
+	}
+}
+
+func TestLinkStreamCompression(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+	client := MakeLinkStream(clientConn, true, false)
+	server := MakeLinkStream(serverConn, false, false)
+	clientOut, serverOut := make(chan []byte, 10), make(chan []byte, 10)
+	clientIn, serverIn := make(chan baseds.RpcInputChType, 10), make(chan baseds.RpcInputChType, 10)
+	go client.WriteLoop(clientOut, nil)
+	go client.ReadLoop(clientIn, nil)
+	go server.WriteLoop(serverOut, nil)
+	go server.ReadLoop(serverIn, nil)
+
+	// the first message goes out before the hello round trip completes, so it is never compressed
+	clientOut <- []byte(`{"command":"ping"}`)
+	if got := recvMsg(t, serverIn); string(got) != `{"command":"ping"}` {
+		t.Fatalf("unexpected message %q", got)
+	}
+	large := []byte(`{"data":"` + strings.Repeat("abcdefgh", 2048) + `"}`)
+	serverOut <- large
+	if got := recvMsg(t, clientIn); !bytes.Equal(got, large) {
+		t.Fatalf("large message did not round trip")
+	}
+	if !client.PeerCompression() || !server.PeerCompression() {
+		t.Fatalf("compression was not negotiated")
+	}
+	if stats := server.GetStats(); stats.CompressedMsgs != 1 || stats.WireBytes >= stats.RawBytes {
+		t.Errorf("expected the large message to be compressed, stats: %+v", stats)
+	}
+}
+
+func TestInsertByPriority(t *testing.T) {
+	var backlog []backlogMessageWrap
+	for idx, priority := range []int{RpcPriority_Bulk, RpcPriority_Normal, RpcPriority_Bulk, RpcPriority_High, RpcPriority_Normal} {
+		backlog = insertByPriority(backlog, backlogMessageWrap{priority: priority, debugStr: string(rune('a' + idx))})
+	}
+	var order []string
+	for _, msg := range backlog {
+		order = append(order, msg.debugStr)
+	}
+	if got := strings.Join(order, ""); got != "dbeac" {
+		t.Errorf("expected backlog order dbeac, got %s", got)
+	}
+}
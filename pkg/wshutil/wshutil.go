@@ -232,6 +232,8 @@ func MakeClientJWTToken(rpcCtx wshrpc.RpcContext) (string, error) {
 		BlockId:   rpcCtx.BlockId,
 		Conn:      rpcCtx.Conn,
 		Router:    rpcCtx.IsRouter,
+
+		Capabilities: rpcCtx.Capabilities,
 	}
 	return wavejwt.Sign(claims)
 }
@@ -244,6 +246,8 @@ func claimsToRpcCtx(claims *wavejwt.WaveJwtClaims) *wshrpc.RpcContext {
 		BlockId:   claims.BlockId,
 		Conn:      claims.Conn,
 		IsRouter:  claims.Router,
+
+		Capabilities: claims.Capabilities,
 	}
 }
 
@@ -276,10 +280,12 @@ type WriteFlusher interface {
 	Flush() error
 }
 
-// blocking, returns if there is an error, or on EOF of input
-func HandleStdIOClient(logName string, input chan utilfn.LineOutput, output io.Writer) {
+// blocking, returns if there is an error, or on EOF of input.  caps restricts what the link may
+// call (nil is unrestricted), see wshrpc.CapabilitiesAllow.
+func HandleStdIOClient(logName string, input chan utilfn.LineOutput, output io.Writer, caps []string) {
 	proxy := MakeRpcProxy(logName)
 	linkId := DefaultRouter.RegisterTrustedRouter(proxy)
+	DefaultRouter.setLinkCapabilities(linkId, caps)
 	rawCh := make(chan []byte, DefaultInputChSize)
 	go func() {
 		defer func() {
@@ -327,11 +333,14 @@ func HandleStdIOClient(logName string, input chan utilfn.LineOutput, output io.W
 func handleDomainSocketClient(conn net.Conn, readCallback func()) {
 	var linkIdContainer atomic.Int32
 	proxy := MakeRpcProxy("domain")
+	proxy.EnablePriorityLane()
+	// connservers initiate compression negotiation, other clients (wsh, plugins) get plain json lines
+	linkStream := MakeLinkStream(conn, false, false)
 	go func() {
 		defer func() {
-			panichandler.PanicHandler("handleDomainSocketClient:AdaptOutputChToStream", recover())
+			panichandler.PanicHandler("handleDomainSocketClient:LinkStream.WriteLoop", recover())
 		}()
-		writeErr := AdaptOutputChToStream(proxy.ToRemoteCh, conn)
+		writeErr := linkStream.WriteLoop(proxy.ToRemoteCh, proxy.ToRemoteHighCh)
 		if writeErr != nil {
 			log.Printf("error writing to domain socket: %v\n", writeErr)
 		}
@@ -339,7 +348,7 @@ func handleDomainSocketClient(conn net.Conn, readCallback func()) {
 	go func() {
 		// when input is closed, close the connection
 		defer func() {
-			panichandler.PanicHandler("handleDomainSocketClient:AdaptStreamToMsgCh", recover())
+			panichandler.PanicHandler("handleDomainSocketClient:LinkStream.ReadLoop", recover())
 		}()
 		defer func() {
 			conn.Close()
@@ -350,7 +359,7 @@ func handleDomainSocketClient(conn net.Conn, readCallback func()) {
 				DefaultRouter.UnregisterLink(baseds.LinkId(linkId))
 			}
 		}()
-		AdaptStreamToMsgCh(conn, proxy.FromRemoteCh, readCallback)
+		linkStream.ReadLoop(proxy.FromRemoteCh, readCallback)
 	}()
 	linkId := DefaultRouter.RegisterUntrustedLink(proxy)
 	linkIdContainer.Store(int32(linkId))